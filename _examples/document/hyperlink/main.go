@@ -1,6 +1,8 @@
 package main
 
 import (
+	"log"
+
 	"github.com/unidoc/unioffice/color"
 	"github.com/unidoc/unioffice/document"
 	"github.com/unidoc/unioffice/schema/soo/wml"
@@ -14,7 +16,10 @@ func main() {
 	hlStyle.SetName("Hyperlink")
 	hlStyle.SetBasedOn("DefaultParagraphFont")
 	hlStyle.RunProperties().Color().SetThemeColor(wml.ST_ThemeColorHyperlink)
-	clr := color.FromHex("#0563C1")
+	clr, err := color.FromHex("#0563C1")
+	if err != nil {
+		log.Fatalf("error parsing color: %s", err)
+	}
 	hlStyle.RunProperties().Color().SetColor(clr)
 	hlStyle.RunProperties().SetUnderline(wml.ST_UnderlineSingle, clr)
 
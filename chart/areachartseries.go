@@ -56,3 +56,10 @@ func (c AreaChartSeries) Properties() drawing.ShapeProperties {
 	}
 	return drawing.MakeShapeProperties(c.x.SpPr)
 }
+
+// AddTrendline adds a trendline of the given type to the series.
+func (c AreaChartSeries) AddTrendline(kind crt.ST_TrendlineType) Trendline {
+	t := newTrendline(kind)
+	c.x.Trendline = append(c.x.Trendline, t.x)
+	return t
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/unidoc/unioffice/chart"
+	crt "github.com/unidoc/unioffice/schema/soo/dml/chart"
 )
 
 func TestNullAxis(t *testing.T) {
@@ -18,3 +19,70 @@ func TestNullAxis(t *testing.T) {
 		t.Errorf("expected null axis to have ID 0, go %d", chart.NullAxis.AxisID())
 	}
 }
+
+func TestSecondaryValueAxis(t *testing.T) {
+	spc := crt.NewChartSpace()
+	c := chart.MakeChart(spc)
+
+	bc := c.AddBarChart()
+	bc.AddSeries().Values().SetValues([]float64{1, 2, 3})
+	ca := c.AddCategoryAxis()
+	va := c.AddValueAxis()
+	ca.SetCrosses(va)
+	va.SetCrosses(ca)
+	bc.AddAxis(ca)
+	bc.AddAxis(va)
+	va.SetMaximum(10)
+
+	lc := c.AddLineChart()
+	lc.AddSeries().Values().SetValues([]float64{100, 250, 400})
+	va2, ca2 := c.AddSecondaryValueAxis()
+	va2.SetMinimum(0)
+	va2.SetMaximum(500)
+	lc.SetAxis(va2, ca2)
+
+	if err := spc.Validate(); err != nil {
+		t.Errorf("created an invalid chart: %s", err)
+	}
+
+	axes := spc.Chart.PlotArea.CChoice
+	if len(axes.ValAx) != 2 || len(axes.CatAx) != 2 {
+		t.Fatalf("expected 2 value and 2 category axes, got %d and %d", len(axes.ValAx), len(axes.CatAx))
+	}
+	ids := map[uint32]struct{}{}
+	for _, id := range []uint32{ca.AxisID(), va.AxisID(), ca2.AxisID(), va2.AxisID()} {
+		ids[id] = struct{}{}
+	}
+	if len(ids) != 4 {
+		t.Errorf("expected unique axis IDs")
+	}
+
+	sec := va2.X()
+	if sec.AxPos.ValAttr != crt.ST_AxPosR {
+		t.Errorf("expected the secondary axis on the right, got %s", sec.AxPos.ValAttr)
+	}
+	if sec.CrossAx.ValAttr != ca2.AxisID() || ca2.X().CrossAx.ValAttr != va2.AxisID() {
+		t.Errorf("expected the secondary axes to cross each other")
+	}
+	if sec.Choice == nil || sec.Choice.Crosses == nil || sec.Choice.Crosses.ValAttr != crt.ST_CrossesMax {
+		t.Errorf("expected the secondary axis to cross at the maximum")
+	}
+	if sec.Scaling.Min.ValAttr != 0 || sec.Scaling.Max.ValAttr != 500 {
+		t.Errorf("unexpected secondary axis scale")
+	}
+	if va.X().Scaling.Max.ValAttr != 10 {
+		t.Errorf("unexpected primary axis scale")
+	}
+	if ca2.X().Delete == nil || !*ca2.X().Delete.ValAttr {
+		t.Errorf("expected the secondary category axis to be hidden")
+	}
+
+	lineAx := lc.X().AxId
+	if len(lineAx) != 2 || lineAx[0].ValAttr != ca2.AxisID() || lineAx[1].ValAttr != va2.AxisID() {
+		t.Errorf("expected the line chart to use the secondary axes")
+	}
+	barAx := bc.X().AxId
+	if len(barAx) != 2 || barAx[0].ValAttr != ca.AxisID() || barAx[1].ValAttr != va.AxisID() {
+		t.Errorf("expected the bar chart to use the primary axes")
+	}
+}
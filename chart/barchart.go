@@ -51,3 +51,25 @@ func (c BarChart) AddAxis(axis Axis) {
 	axisID.ValAttr = axis.AxisID()
 	c.x.AxId = append(c.x.AxId, axisID)
 }
+
+// SetAxis replaces the axes of the chart with a value and category axis, such
+// as those returned by Chart.AddSecondaryValueAxis.
+func (c BarChart) SetAxis(valAx, catAx Axis) {
+	c.x.AxId = nil
+	c.AddAxis(catAx)
+	c.AddAxis(valAx)
+}
+
+// AddSeriesFromRange adds a series with categories and values referencing
+// cell ranges, such as Sheet1!$A$2:$A$5.  If the chart was created with range
+// data, the current cell contents are cached in the series.
+func (c BarChart) AddSeriesFromRange(categories, values string) BarChartSeries {
+	bs := c.AddSeries()
+	bs.CategoryAxis().SetLabelReference(categories)
+	bs.Values().SetReference(values)
+	if c.data != nil {
+		bs.CategoryAxis().SetLabelCache(c.data.CellValues(categories))
+		bs.Values().SetCache(c.data.CellValues(values))
+	}
+	return bs
+}
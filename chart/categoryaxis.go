@@ -8,6 +8,7 @@
 package chart
 
 import (
+	"github.com/unidoc/unioffice"
 	"github.com/unidoc/unioffice/color"
 	"github.com/unidoc/unioffice/drawing"
 	"github.com/unidoc/unioffice/schema/soo/dml"
@@ -22,6 +23,11 @@ func MakeCategoryAxis(x *crt.CT_CatAx) CategoryAxis {
 	return CategoryAxis{x}
 }
 
+// X returns the inner wrapped XML type.
+func (c CategoryAxis) X() *crt.CT_CatAx {
+	return c.x
+}
+
 func (c CategoryAxis) MajorGridLines() GridLines {
 	if c.x.MajorGridlines == nil {
 		c.x.MajorGridlines = crt.NewCT_ChartLines()
@@ -91,3 +97,9 @@ func (c CategoryAxis) SetTickLabelPosition(p crt.ST_TickLblPos) {
 		c.x.TickLblPos.ValAttr = p
 	}
 }
+
+// SetDeleted controls if the axis is hidden.
+func (c CategoryAxis) SetDeleted(b bool) {
+	c.x.Delete = crt.NewCT_Boolean()
+	c.x.Delete.ValAttr = unioffice.Bool(b)
+}
@@ -53,3 +53,20 @@ func (a CategoryAxisDataSource) SetValues(v []string) {
 	}
 
 }
+
+// SetLabelCache sets the cached values of a label reference.
+func (a CategoryAxisDataSource) SetLabelCache(v []string) {
+	if a.x.Choice == nil || a.x.Choice.StrRef == nil {
+		return
+	}
+	cache := crt.NewCT_StrData()
+	cache.PtCount = crt.NewCT_UnsignedInt()
+	cache.PtCount.ValAttr = uint32(len(v))
+	for i, x := range v {
+		if x == "" {
+			continue
+		}
+		cache.Pt = append(cache.Pt, &crt.CT_StrVal{IdxAttr: uint32(i), V: x})
+	}
+	a.x.Choice.StrRef.StrCache = cache
+}
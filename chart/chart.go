@@ -20,11 +20,26 @@ import (
 
 // Chart is a generic chart.
 type Chart struct {
-	x *crt.ChartSpace
+	x    *crt.ChartSpace
+	data RangeData
+}
+
+// RangeData provides the contents of the cells referenced by series so that
+// their values can be cached in the chart.
+type RangeData interface {
+	// CellValues returns the value of each cell within a range reference such
+	// as Sheet1!$A$1:$A$5 in row major order.
+	CellValues(ref string) []string
 }
 
 func MakeChart(x *crt.ChartSpace) Chart {
-	return Chart{x}
+	return Chart{x: x}
+}
+
+// MakeChartWithRangeData constructs a chart that caches the values of series
+// added from cell ranges using d.
+func MakeChartWithRangeData(x *crt.ChartSpace, d RangeData) Chart {
+	return Chart{x, d}
 }
 
 // X returns the inner wrapped XML type.
@@ -39,7 +54,7 @@ func (c Chart) AddLineChart() LineChart {
 	chc.LineChart = crt.NewCT_LineChart()
 	chc.LineChart.Grouping = crt.NewCT_Grouping()
 	chc.LineChart.Grouping.ValAttr = crt.ST_GroupingStandard
-	return LineChart{x: chc.LineChart}
+	return LineChart{chartBase: chartBase{c.data}, x: chc.LineChart}
 }
 
 func setup3DChart(c *crt.CT_Chart) {
@@ -73,7 +88,7 @@ func (c Chart) AddLine3DChart() Line3DChart {
 	chc.Line3DChart = crt.NewCT_Line3DChart()
 	chc.Line3DChart.Grouping = crt.NewCT_Grouping()
 	chc.Line3DChart.Grouping.ValAttr = crt.ST_GroupingStandard
-	return Line3DChart{x: chc.Line3DChart}
+	return Line3DChart{chartBase: chartBase{c.data}, x: chc.Line3DChart}
 }
 
 // AddStockChart adds a new stock chart.
@@ -82,7 +97,7 @@ func (c Chart) AddStockChart() StockChart {
 	c.x.Chart.PlotArea.Choice = append(c.x.Chart.PlotArea.Choice, chc)
 	chc.StockChart = crt.NewCT_StockChart()
 
-	b := StockChart{x: chc.StockChart}
+	b := StockChart{chartBase: chartBase{c.data}, x: chc.StockChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -95,7 +110,7 @@ func (c Chart) AddBarChart() BarChart {
 	chc.BarChart.Grouping = crt.NewCT_BarGrouping()
 	chc.BarChart.Grouping.ValAttr = crt.ST_BarGroupingStandard
 
-	b := BarChart{x: chc.BarChart}
+	b := BarChart{chartBase: chartBase{c.data}, x: chc.BarChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -109,7 +124,7 @@ func (c Chart) AddBar3DChart() Bar3DChart {
 	chc.Bar3DChart.Grouping = crt.NewCT_BarGrouping()
 	chc.Bar3DChart.Grouping.ValAttr = crt.ST_BarGroupingStandard
 
-	b := Bar3DChart{x: chc.Bar3DChart}
+	b := Bar3DChart{chartBase: chartBase{c.data}, x: chc.Bar3DChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -120,7 +135,7 @@ func (c Chart) AddAreaChart() AreaChart {
 	c.x.Chart.PlotArea.Choice = append(c.x.Chart.PlotArea.Choice, chc)
 	chc.AreaChart = crt.NewCT_AreaChart()
 
-	b := AreaChart{x: chc.AreaChart}
+	b := AreaChart{chartBase: chartBase{c.data}, x: chc.AreaChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -132,7 +147,7 @@ func (c Chart) AddArea3DChart() Area3DChart {
 	c.x.Chart.PlotArea.Choice = append(c.x.Chart.PlotArea.Choice, chc)
 	chc.Area3DChart = crt.NewCT_Area3DChart()
 
-	b := Area3DChart{x: chc.Area3DChart}
+	b := Area3DChart{chartBase: chartBase{c.data}, x: chc.Area3DChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -143,7 +158,7 @@ func (c Chart) AddRadarChart() RadarChart {
 	c.x.Chart.PlotArea.Choice = append(c.x.Chart.PlotArea.Choice, chc)
 	chc.RadarChart = crt.NewCT_RadarChart()
 
-	b := RadarChart{x: chc.RadarChart}
+	b := RadarChart{chartBase: chartBase{c.data}, x: chc.RadarChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -154,7 +169,7 @@ func (c Chart) AddPieChart() PieChart {
 	c.x.Chart.PlotArea.Choice = append(c.x.Chart.PlotArea.Choice, chc)
 	chc.PieChart = crt.NewCT_PieChart()
 
-	b := PieChart{x: chc.PieChart}
+	b := PieChart{chartBase: chartBase{c.data}, x: chc.PieChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -166,7 +181,7 @@ func (c Chart) AddPie3DChart() Pie3DChart {
 	c.x.Chart.PlotArea.Choice = append(c.x.Chart.PlotArea.Choice, chc)
 	chc.Pie3DChart = crt.NewCT_Pie3DChart()
 
-	b := Pie3DChart{x: chc.Pie3DChart}
+	b := Pie3DChart{chartBase: chartBase{c.data}, x: chc.Pie3DChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -185,7 +200,7 @@ func (c Chart) AddSurfaceChart() SurfaceChart {
 	c.x.Chart.View3D.Perspective = crt.NewCT_Perspective()
 	c.x.Chart.View3D.Perspective.ValAttr = unioffice.Uint8(0)
 
-	b := SurfaceChart{x: chc.SurfaceChart}
+	b := SurfaceChart{chartBase: chartBase{c.data}, x: chc.SurfaceChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -197,7 +212,7 @@ func (c Chart) AddSurface3DChart() Surface3DChart {
 	chc.Surface3DChart = crt.NewCT_Surface3DChart()
 
 	setup3DChart(c.x.Chart)
-	b := Surface3DChart{x: chc.Surface3DChart}
+	b := Surface3DChart{chartBase: chartBase{c.data}, x: chc.Surface3DChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -208,7 +223,7 @@ func (c Chart) AddPieOfPieChart() PieOfPieChart {
 	c.x.Chart.PlotArea.Choice = append(c.x.Chart.PlotArea.Choice, chc)
 	chc.OfPieChart = crt.NewCT_OfPieChart()
 
-	b := PieOfPieChart{x: chc.OfPieChart}
+	b := PieOfPieChart{chartBase: chartBase{c.data}, x: chc.OfPieChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -218,7 +233,7 @@ func (c Chart) AddDoughnutChart() DoughnutChart {
 	chc := crt.NewCT_PlotAreaChoice()
 	c.x.Chart.PlotArea.Choice = append(c.x.Chart.PlotArea.Choice, chc)
 	chc.DoughnutChart = crt.NewCT_DoughnutChart()
-	b := DoughnutChart{x: chc.DoughnutChart}
+	b := DoughnutChart{chartBase: chartBase{c.data}, x: chc.DoughnutChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -229,7 +244,7 @@ func (c Chart) AddScatterChart() ScatterChart {
 	c.x.Chart.PlotArea.Choice = append(c.x.Chart.PlotArea.Choice, chc)
 	chc.ScatterChart = crt.NewCT_ScatterChart()
 
-	b := ScatterChart{x: chc.ScatterChart}
+	b := ScatterChart{chartBase: chartBase{c.data}, x: chc.ScatterChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -240,7 +255,7 @@ func (c Chart) AddBubbleChart() BubbleChart {
 	c.x.Chart.PlotArea.Choice = append(c.x.Chart.PlotArea.Choice, chc)
 	chc.BubbleChart = crt.NewCT_BubbleChart()
 
-	b := BubbleChart{x: chc.BubbleChart}
+	b := BubbleChart{chartBase: chartBase{c.data}, x: chc.BubbleChart}
 	b.InitializeDefaults()
 	return b
 }
@@ -266,7 +281,7 @@ func (c Chart) AddValueAxis() ValueAxis {
 		c.x.Chart.PlotArea.CChoice = crt.NewCT_PlotAreaChoice1()
 	}
 	va.AxId = crt.NewCT_UnsignedInt()
-	va.AxId.ValAttr = 0x7FFFFFFF & rand.Uint32()
+	va.AxId.ValAttr = c.newAxisID()
 	c.x.Chart.PlotArea.CChoice.ValAx = append(c.x.Chart.PlotArea.CChoice.ValAx, va)
 
 	va.Delete = crt.NewCT_Boolean()
@@ -294,6 +309,51 @@ func (c Chart) AddValueAxis() ValueAxis {
 	return vax
 }
 
+// newAxisID returns a random axis ID that isn't used by any other axis in the
+// chart.
+func (c Chart) newAxisID() uint32 {
+	used := map[uint32]struct{}{}
+	if cc := c.x.Chart.PlotArea.CChoice; cc != nil {
+		for _, ax := range cc.ValAx {
+			used[ax.AxId.ValAttr] = struct{}{}
+		}
+		for _, ax := range cc.CatAx {
+			used[ax.AxId.ValAttr] = struct{}{}
+		}
+		for _, ax := range cc.DateAx {
+			used[ax.AxId.ValAttr] = struct{}{}
+		}
+		for _, ax := range cc.SerAx {
+			used[ax.AxId.ValAttr] = struct{}{}
+		}
+	}
+	for {
+		id := 0x7FFFFFFF & rand.Uint32()
+		if _, ok := used[id]; !ok && id != 0 {
+			return id
+		}
+	}
+}
+
+// AddSecondaryValueAxis adds a value axis on the right side of the chart along
+// with a hidden category axis for it to cross.  Plots are drawn against the
+// secondary axes by passing them to the plot's SetAxis method.
+func (c Chart) AddSecondaryValueAxis() (ValueAxis, CategoryAxis) {
+	ca := c.AddCategoryAxis()
+	ca.SetDeleted(true)
+	ca.x.MajorGridlines = nil
+
+	va := c.AddValueAxis()
+	va.x.MajorGridlines = nil
+	va.SetPosition(crt.ST_AxPosR)
+
+	ca.SetCrosses(va)
+	va.SetCrosses(ca)
+	// place the value axis at the far end of the category axis
+	va.SetCrossesMode(crt.ST_CrossesMax)
+	return va, ca
+}
+
 // AddCategoryAxis adds a category axis.
 func (c Chart) AddCategoryAxis() CategoryAxis {
 	ca := crt.NewCT_CatAx()
@@ -302,7 +362,7 @@ func (c Chart) AddCategoryAxis() CategoryAxis {
 	}
 
 	ca.AxId = crt.NewCT_UnsignedInt()
-	ca.AxId.ValAttr = 0x7FFFFFFF & rand.Uint32()
+	ca.AxId.ValAttr = c.newAxisID()
 	c.x.Chart.PlotArea.CChoice.CatAx = append(c.x.Chart.PlotArea.CChoice.CatAx, ca)
 
 	ca.Auto = crt.NewCT_Boolean()
@@ -324,7 +384,7 @@ func (c Chart) AddDateAxis() DateAxis {
 		c.x.Chart.PlotArea.CChoice = crt.NewCT_PlotAreaChoice1()
 	}
 	va.AxId = crt.NewCT_UnsignedInt()
-	va.AxId.ValAttr = 0x7FFFFFFF & rand.Uint32()
+	va.AxId.ValAttr = c.newAxisID()
 	c.x.Chart.PlotArea.CChoice.DateAx = append(c.x.Chart.PlotArea.CChoice.DateAx, va)
 
 	va.Delete = crt.NewCT_Boolean()
@@ -355,7 +415,7 @@ func (c Chart) AddSeriesAxis() SeriesAxis {
 	}
 
 	sa.AxId = crt.NewCT_UnsignedInt()
-	sa.AxId.ValAttr = 0x7FFFFFFF & rand.Uint32()
+	sa.AxId.ValAttr = c.newAxisID()
 	c.x.Chart.PlotArea.CChoice.SerAx = append(c.x.Chart.PlotArea.CChoice.SerAx, sa)
 
 	sa.Delete = crt.NewCT_Boolean()
@@ -367,6 +427,20 @@ func (c Chart) AddSeriesAxis() SeriesAxis {
 	return sax
 }
 
+// AddDataTable adds a data table below the plot area, replacing any existing
+// data table.
+func (c Chart) AddDataTable() DataTable {
+	c.x.Chart.PlotArea.DTable = crt.NewCT_DTable()
+	dt := DataTable{c.x.Chart.PlotArea.DTable}
+	dt.InitializeDefaults()
+	return dt
+}
+
+// RemoveDataTable removes the data table if the chart has one.
+func (c Chart) RemoveDataTable() {
+	c.x.Chart.PlotArea.DTable = nil
+}
+
 // RemoveLegend removes the legend if the chart has one.
 func (c Chart) RemoveLegend() {
 	c.x.Chart.Legend = nil
@@ -17,6 +17,7 @@ var defaultColors = []color.Color{color.RGB(0x33, 0x66, 0xcc), color.RGB(0xDC, 0
 	color.RGB(0x3B, 0x3E, 0xAC)}
 
 type chartBase struct {
+	data RangeData
 }
 
 func (c chartBase) nextColor(idx int) color.Color {
@@ -0,0 +1,59 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package chart
+
+import (
+	"github.com/unidoc/unioffice"
+	crt "github.com/unidoc/unioffice/schema/soo/dml/chart"
+)
+
+// DataTable is a table of the chart's values displayed below the plot area.
+type DataTable struct {
+	x *crt.CT_DTable
+}
+
+// X returns the inner wrapped XML type.
+func (d DataTable) X() *crt.CT_DTable {
+	return d.x
+}
+
+// InitializeDefaults shows the borders, outline and legend keys of the
+// table.
+func (d DataTable) InitializeDefaults() {
+	d.SetShowHorizontalBorder(true)
+	d.SetShowVerticalBorder(true)
+	d.SetShowOutline(true)
+	d.SetShowLegendKeys(true)
+}
+
+// SetShowHorizontalBorder controls if horizontal borders are drawn between
+// the table rows.
+func (d DataTable) SetShowHorizontalBorder(b bool) {
+	d.x.ShowHorzBorder = crt.NewCT_Boolean()
+	d.x.ShowHorzBorder.ValAttr = unioffice.Bool(b)
+}
+
+// SetShowVerticalBorder controls if vertical borders are drawn between the
+// table columns.
+func (d DataTable) SetShowVerticalBorder(b bool) {
+	d.x.ShowVertBorder = crt.NewCT_Boolean()
+	d.x.ShowVertBorder.ValAttr = unioffice.Bool(b)
+}
+
+// SetShowOutline controls if an outline is drawn around the table.
+func (d DataTable) SetShowOutline(b bool) {
+	d.x.ShowOutline = crt.NewCT_Boolean()
+	d.x.ShowOutline.ValAttr = unioffice.Bool(b)
+}
+
+// SetShowLegendKeys controls if the legend keys of each series are displayed
+// next to the series in the table.
+func (d DataTable) SetShowLegendKeys(b bool) {
+	d.x.ShowKeys = crt.NewCT_Boolean()
+	d.x.ShowKeys.ValAttr = unioffice.Bool(b)
+}
@@ -41,3 +41,11 @@ func (c LineChart) AddAxis(axis Axis) {
 	axisID.ValAttr = axis.AxisID()
 	c.x.AxId = append(c.x.AxId, axisID)
 }
+
+// SetAxis replaces the axes of the chart with a value and category axis, such
+// as those returned by Chart.AddSecondaryValueAxis.
+func (c LineChart) SetAxis(valAx, catAx Axis) {
+	c.x.AxId = nil
+	c.AddAxis(catAx)
+	c.AddAxis(valAx)
+}
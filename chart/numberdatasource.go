@@ -9,6 +9,9 @@ package chart
 
 import (
 	"fmt"
+	"strconv"
+
+	"github.com/unidoc/unioffice"
 
 	crt "github.com/unidoc/unioffice/schema/soo/dml/chart"
 )
@@ -64,3 +67,18 @@ func (n NumberDataSource) SetValues(v []float64) {
 	}
 
 }
+
+// SetCache sets the cached values of a number reference.  Values that aren't
+// numbers are left out of the cache as Excel does for blank cells.
+func (n NumberDataSource) SetCache(v []string) {
+	n.CreateEmptyNumberCache()
+	cache := n.x.Choice.NumRef.NumCache
+	cache.FormatCode = unioffice.String("General")
+	cache.PtCount.ValAttr = uint32(len(v))
+	for i, x := range v {
+		if _, err := strconv.ParseFloat(x, 64); err != nil {
+			continue
+		}
+		cache.Pt = append(cache.Pt, &crt.CT_NumVal{IdxAttr: uint32(i), V: x})
+	}
+}
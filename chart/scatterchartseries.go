@@ -103,3 +103,10 @@ func (c ScatterChartSeries) InitializeDefaults() {
 	c.Labels().SetShowSeriesName(false)
 	c.Labels().SetShowLeaderLines(false)
 }
+
+// AddTrendline adds a trendline of the given type to the series.
+func (c ScatterChartSeries) AddTrendline(kind crt.ST_TrendlineType) Trendline {
+	t := newTrendline(kind)
+	c.x.Trendline = append(c.x.Trendline, t.x)
+	return t
+}
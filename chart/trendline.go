@@ -0,0 +1,113 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package chart
+
+import (
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/drawing"
+	"github.com/unidoc/unioffice/schema/soo/dml"
+	crt "github.com/unidoc/unioffice/schema/soo/dml/chart"
+)
+
+// Trendline is a trendline fitted to the values of a series.
+type Trendline struct {
+	x *crt.CT_Trendline
+}
+
+// MakeTrendline constructs a Trendline wrapper.
+func MakeTrendline(x *crt.CT_Trendline) Trendline {
+	return Trendline{x}
+}
+
+// X returns the inner wrapped XML type.
+func (t Trendline) X() *crt.CT_Trendline {
+	return t.x
+}
+
+func newTrendline(kind crt.ST_TrendlineType) Trendline {
+	t := Trendline{crt.NewCT_Trendline()}
+	t.x.TrendlineType.ValAttr = kind
+	// moving averages and polynomials are invalid without a period and order
+	switch kind {
+	case crt.ST_TrendlineTypeMovingAvg:
+		t.SetPeriod(2)
+	case crt.ST_TrendlineTypePoly:
+		t.SetOrder(2)
+	}
+	t.SetDisplayRSquared(false)
+	t.SetDisplayEquation(false)
+	return t
+}
+
+// Type returns the type of the trendline.
+func (t Trendline) Type() crt.ST_TrendlineType {
+	return t.x.TrendlineType.ValAttr
+}
+
+// SetName sets the name of the trendline displayed in the legend.
+func (t Trendline) SetName(name string) {
+	t.x.Name = unioffice.String(name)
+}
+
+// SetPeriod sets the number of points averaged by a moving average trendline.
+// The period must be at least two.
+func (t Trendline) SetPeriod(p uint32) {
+	if p < 2 {
+		p = 2
+	}
+	t.x.Period = crt.NewCT_Period()
+	t.x.Period.ValAttr = unioffice.Uint32(p)
+}
+
+// SetOrder sets the order (2-6) of a polynomial trendline.
+func (t Trendline) SetOrder(o uint8) {
+	if o < 2 {
+		o = 2
+	} else if o > 6 {
+		o = 6
+	}
+	t.x.Order = crt.NewCT_Order()
+	t.x.Order.ValAttr = unioffice.Uint8(o)
+}
+
+// SetForecast extends the trendline forward and backward by the given number
+// of periods.
+func (t Trendline) SetForecast(forward, backward float64) {
+	t.x.Forward = nil
+	t.x.Backward = nil
+	if forward != 0 {
+		t.x.Forward = crt.NewCT_Double()
+		t.x.Forward.ValAttr = forward
+	}
+	if backward != 0 {
+		t.x.Backward = crt.NewCT_Double()
+		t.x.Backward.ValAttr = backward
+	}
+}
+
+// SetDisplayEquation controls if the equation of the trendline is displayed
+// on the chart.
+func (t Trendline) SetDisplayEquation(b bool) {
+	t.x.DispEq = crt.NewCT_Boolean()
+	t.x.DispEq.ValAttr = unioffice.Bool(b)
+}
+
+// SetDisplayRSquared controls if the R² value of the trendline is displayed
+// on the chart.
+func (t Trendline) SetDisplayRSquared(b bool) {
+	t.x.DispRSqr = crt.NewCT_Boolean()
+	t.x.DispRSqr.ValAttr = unioffice.Bool(b)
+}
+
+// Properties returns the trendline shape properties.
+func (t Trendline) Properties() drawing.ShapeProperties {
+	if t.x.SpPr == nil {
+		t.x.SpPr = dml.NewCT_ShapeProperties()
+	}
+	return drawing.MakeShapeProperties(t.x.SpPr)
+}
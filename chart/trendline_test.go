@@ -0,0 +1,65 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package chart_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/chart"
+	crt "github.com/unidoc/unioffice/schema/soo/dml/chart"
+)
+
+func TestTrendline(t *testing.T) {
+	spc := crt.NewChartSpace()
+	c := chart.MakeChart(spc)
+	lc := c.AddLineChart()
+	ser := lc.AddSeries()
+	ser.Values().CreateEmptyNumberCache()
+
+	tl := ser.AddTrendline(crt.ST_TrendlineTypeLinear)
+	tl.SetDisplayEquation(true)
+	if tl.Type() != crt.ST_TrendlineTypeLinear {
+		t.Errorf("expected a linear trendline, got %s", tl.Type())
+	}
+
+	ma := ser.AddTrendline(crt.ST_TrendlineTypeMovingAvg)
+	if ma.X().Period == nil || *ma.X().Period.ValAttr != 2 {
+		t.Errorf("expected a moving average to have a default period")
+	}
+	ma.SetPeriod(4)
+	poly := ser.AddTrendline(crt.ST_TrendlineTypePoly)
+	if poly.X().Order == nil || *poly.X().Order.ValAttr != 2 {
+		t.Errorf("expected a polynomial to have a default order")
+	}
+	poly.SetOrder(10)
+	if *poly.X().Order.ValAttr != 6 {
+		t.Errorf("expected the order to be clamped to 6, got %d", *poly.X().Order.ValAttr)
+	}
+	c.AddDataTable()
+
+	if err := spc.Validate(); err != nil {
+		t.Errorf("created an invalid chart: %s", err)
+	}
+	out, err := xml.Marshal(spc)
+	if err != nil {
+		t.Fatalf("error marshaling chart: %s", err)
+	}
+	got := string(out)
+	for _, exp := range []string{
+		`<c:trendline><c:trendlineType val="linear"></c:trendlineType><c:dispRSqr val="0"></c:dispRSqr><c:dispEq val="1"></c:dispEq></c:trendline>`,
+		`<c:trendlineType val="movingAvg"></c:trendlineType><c:period val="4"></c:period>`,
+		`<c:trendlineType val="poly"></c:trendlineType><c:order val="6"></c:order>`,
+		`<c:dTable><c:showHorzBorder val="1"></c:showHorzBorder>`,
+	} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("expected output to contain %s", exp)
+		}
+	}
+}
@@ -8,6 +8,7 @@
 package chart
 
 import (
+	"github.com/unidoc/unioffice"
 	"github.com/unidoc/unioffice/drawing"
 	"github.com/unidoc/unioffice/schema/soo/dml"
 	crt "github.com/unidoc/unioffice/schema/soo/dml/chart"
@@ -80,3 +81,36 @@ func (v ValueAxis) Properties() drawing.ShapeProperties {
 func (v ValueAxis) SetCrosses(axis Axis) {
 	v.x.CrossAx.ValAttr = axis.AxisID()
 }
+
+// SetCrossesMode controls where the axis crosses the axis it is assigned to
+// with SetCrosses.
+func (v ValueAxis) SetCrossesMode(m crt.ST_Crosses) {
+	v.x.Choice = crt.NewEG_AxSharedChoice()
+	v.x.Choice.Crosses = crt.NewCT_Crosses()
+	v.x.Choice.Crosses.ValAttr = m
+}
+
+// SetMinimum sets the minimum value displayed on the axis.
+func (v ValueAxis) SetMinimum(min float64) {
+	v.scaling().Min = crt.NewCT_Double()
+	v.x.Scaling.Min.ValAttr = min
+}
+
+// SetMaximum sets the maximum value displayed on the axis.
+func (v ValueAxis) SetMaximum(max float64) {
+	v.scaling().Max = crt.NewCT_Double()
+	v.x.Scaling.Max.ValAttr = max
+}
+
+// SetDeleted controls if the axis is hidden.
+func (v ValueAxis) SetDeleted(b bool) {
+	v.x.Delete = crt.NewCT_Boolean()
+	v.x.Delete.ValAttr = unioffice.Bool(b)
+}
+
+func (v ValueAxis) scaling() *crt.CT_Scaling {
+	if v.x.Scaling == nil {
+		v.x.Scaling = crt.NewCT_Scaling()
+	}
+	return v.x.Scaling
+}
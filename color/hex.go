@@ -7,20 +7,44 @@
 
 package color
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
-func FromHex(s string) Color {
-	if len(s) == 0 {
-		return Auto
+// FromHex parses a hex color in the form "#RRGGBB", "RRGGBB", "#RGB" or "RGB".
+// The shorthand form is expanded by repeating each digit, so "#0AF" is the
+// same as "#00AAFF".  Hex digits are case-insensitive.
+func FromHex(s string) (Color, error) {
+	h := strings.TrimPrefix(s, "#")
+	if len(h) == 3 {
+		h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
 	}
-	if s[0] == '#' {
-		s = s[1:]
+	if len(h) != 6 {
+		return Color{}, fmt.Errorf("invalid hex color %q", s)
 	}
-	//func Sscanf(str string, format string, a ...interface{}) (n int, err error) {
-	var r, g, b uint8
-	n, _ := fmt.Sscanf(s, "%02x%02x%02x", &r, &g, &b)
-	if n == 3 {
-		return RGB(r, g, b)
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid hex color %q", s)
 	}
-	return Auto
+	return RGB(uint8(v>>16), uint8(v>>8), uint8(v)), nil
+}
+
+// Hex returns the color formatted as "#rrggbb", or an empty string for the
+// 'Auto' color.
+func (c Color) Hex() string {
+	if c.isAuto {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)
+}
+
+// FromName returns the CSS named color with the given name, e.g. "CornflowerBlue".
+// Names are case-insensitive.
+func FromName(name string) (Color, error) {
+	if c, ok := namedColors[strings.ToLower(name)]; ok {
+		return c, nil
+	}
+	return Color{}, fmt.Errorf("unknown color name %q", name)
 }
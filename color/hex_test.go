@@ -0,0 +1,69 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package color_test
+
+import (
+	"testing"
+
+	"github.com/unidoc/unioffice/color"
+)
+
+func TestFromHex(t *testing.T) {
+	td := []struct {
+		Inp string
+		Exp string
+	}{
+		{"#ff0000", "#ff0000"},
+		{"00FF00", "#00ff00"},
+		{"#0563C1", "#0563c1"},
+		{"#0563c1", "#0563c1"},
+		{"#0af", "#00aaff"},
+		{"0AF", "#00aaff"},
+		{"#FfF", "#ffffff"},
+	}
+	for _, tc := range td {
+		c, err := color.FromHex(tc.Inp)
+		if err != nil {
+			t.Errorf("error parsing %s: %s", tc.Inp, err)
+			continue
+		}
+		if c.Hex() != tc.Exp {
+			t.Errorf("expected %s = %s, got %s", tc.Inp, tc.Exp, c.Hex())
+		}
+	}
+}
+
+func TestFromHexInvalid(t *testing.T) {
+	for _, inp := range []string{"", "#", "#12", "#1234", "#12345g", "ff00000", "#+12345", "red"} {
+		if _, err := color.FromHex(inp); err == nil {
+			t.Errorf("expected an error parsing %q", inp)
+		}
+	}
+}
+
+func TestFromName(t *testing.T) {
+	c, err := color.FromName("cornflowerblue")
+	if err != nil {
+		t.Fatalf("error looking up color: %s", err)
+	}
+	if c != color.CornflowerBlue {
+		t.Errorf("expected CornflowerBlue, got %s", c.Hex())
+	}
+	if c, _ := color.FromName("RebeccaPurple"); c.Hex() != "#663399" {
+		t.Errorf("expected #663399, got %s", c.Hex())
+	}
+	if _, err := color.FromName("notacolor"); err == nil {
+		t.Errorf("expected an error for an unknown color name")
+	}
+}
+
+func TestHexAuto(t *testing.T) {
+	if color.Auto.Hex() != "" {
+		t.Errorf("expected no hex value for the auto color")
+	}
+}
@@ -158,3 +158,155 @@ var White = Color{0xFF, 0xFF, 0xFF, 255, false}
 var WhiteSmoke = Color{0xF5, 0xF5, 0xF5, 255, false}
 var Yellow = Color{0xFF, 0xFF, 0x00, 255, false}
 var YellowGreen = Color{0x9A, 0xCD, 0x32, 255, false}
+
+// namedColors maps lower case CSS color names to colors.
+var namedColors = map[string]Color{
+	"aliceblue":            AliceBlue,
+	"antiquewhite":         AntiqueWhite,
+	"aqua":                 Aqua,
+	"aquamarine":           Aquamarine,
+	"azure":                Azure,
+	"beige":                Beige,
+	"bisque":               Bisque,
+	"black":                Black,
+	"blanchedalmond":       BlanchedAlmond,
+	"blue":                 Blue,
+	"blueviolet":           BlueViolet,
+	"brown":                Brown,
+	"burlywood":            BurlyWood,
+	"cadetblue":            CadetBlue,
+	"chartreuse":           Chartreuse,
+	"chocolate":            Chocolate,
+	"coral":                Coral,
+	"cornflowerblue":       CornflowerBlue,
+	"cornsilk":             Cornsilk,
+	"crimson":              Crimson,
+	"cyan":                 Cyan,
+	"darkblue":             DarkBlue,
+	"darkcyan":             DarkCyan,
+	"darkgoldenrod":        DarkGoldenRod,
+	"darkgray":             DarkGray,
+	"darkgrey":             DarkGrey,
+	"darkgreen":            DarkGreen,
+	"darkkhaki":            DarkKhaki,
+	"darkmagenta":          DarkMagenta,
+	"darkolivegreen":       DarkOliveGreen,
+	"darkorange":           DarkOrange,
+	"darkorchid":           DarkOrchid,
+	"darkred":              DarkRed,
+	"darksalmon":           DarkSalmon,
+	"darkseagreen":         DarkSeaGreen,
+	"darkslateblue":        DarkSlateBlue,
+	"darkslategray":        DarkSlateGray,
+	"darkslategrey":        DarkSlateGrey,
+	"darkturquoise":        DarkTurquoise,
+	"darkviolet":           DarkViolet,
+	"deeppink":             DeepPink,
+	"deepskyblue":          DeepSkyBlue,
+	"dimgray":              DimGray,
+	"dimgrey":              DimGrey,
+	"dodgerblue":           DodgerBlue,
+	"firebrick":            FireBrick,
+	"floralwhite":          FloralWhite,
+	"forestgreen":          ForestGreen,
+	"fuchsia":              Fuchsia,
+	"gainsboro":            Gainsboro,
+	"ghostwhite":           GhostWhite,
+	"gold":                 Gold,
+	"goldenrod":            GoldenRod,
+	"gray":                 Gray,
+	"green":                Green,
+	"greenyellow":          GreenYellow,
+	"honeydew":             HoneyDew,
+	"hotpink":              HotPink,
+	"indianred":            IndianRed,
+	"indigo":               Indigo,
+	"ivory":                Ivory,
+	"khaki":                Khaki,
+	"lavender":             Lavender,
+	"lavenderblush":        LavenderBlush,
+	"lawngreen":            LawnGreen,
+	"lemonchiffon":         LemonChiffon,
+	"lightblue":            LightBlue,
+	"lightcoral":           LightCoral,
+	"lightcyan":            LightCyan,
+	"lightgoldenrodyellow": LightGoldenRodYellow,
+	"lightgray":            LightGray,
+	"lightgrey":            LightGrey,
+	"lightgreen":           LightGreen,
+	"lightpink":            LightPink,
+	"lightsalmon":          LightSalmon,
+	"lightseagreen":        LightSeaGreen,
+	"lightskyblue":         LightSkyBlue,
+	"lightslategray":       LightSlateGray,
+	"lightslategrey":       LightSlateGrey,
+	"lightsteelblue":       LightSteelBlue,
+	"lightyellow":          LightYellow,
+	"lime":                 Lime,
+	"limegreen":            LimeGreen,
+	"linen":                Linen,
+	"magenta":              Magenta,
+	"maroon":               Maroon,
+	"mediumaquamarine":     MediumAquaMarine,
+	"mediumblue":           MediumBlue,
+	"mediumorchid":         MediumOrchid,
+	"mediumpurple":         MediumPurple,
+	"mediumseagreen":       MediumSeaGreen,
+	"mediumslateblue":      MediumSlateBlue,
+	"mediumspringgreen":    MediumSpringGreen,
+	"mediumturquoise":      MediumTurquoise,
+	"mediumvioletred":      MediumVioletRed,
+	"midnightblue":         MidnightBlue,
+	"mintcream":            MintCream,
+	"mistyrose":            MistyRose,
+	"moccasin":             Moccasin,
+	"navajowhite":          NavajoWhite,
+	"navy":                 Navy,
+	"oldlace":              OldLace,
+	"olive":                Olive,
+	"olivedrab":            OliveDrab,
+	"orange":               Orange,
+	"orangered":            OrangeRed,
+	"orchid":               Orchid,
+	"palegoldenrod":        PaleGoldenRod,
+	"palegreen":            PaleGreen,
+	"paleturquoise":        PaleTurquoise,
+	"palevioletred":        PaleVioletRed,
+	"papayawhip":           PapayaWhip,
+	"peachpuff":            PeachPuff,
+	"peru":                 Peru,
+	"pink":                 Pink,
+	"plum":                 Plum,
+	"powderblue":           PowderBlue,
+	"purple":               Purple,
+	"rebeccapurple":        RebeccaPurple,
+	"red":                  Red,
+	"rosybrown":            RosyBrown,
+	"royalblue":            RoyalBlue,
+	"saddlebrown":          SaddleBrown,
+	"salmon":               Salmon,
+	"sandybrown":           SandyBrown,
+	"successgreen":         SuccessGreen,
+	"seagreen":             SeaGreen,
+	"seashell":             SeaShell,
+	"sienna":               Sienna,
+	"silver":               Silver,
+	"skyblue":              SkyBlue,
+	"slateblue":            SlateBlue,
+	"slategray":            SlateGray,
+	"slategrey":            SlateGrey,
+	"snow":                 Snow,
+	"springgreen":          SpringGreen,
+	"steelblue":            SteelBlue,
+	"tan":                  Tan,
+	"teal":                 Teal,
+	"thistle":              Thistle,
+	"tomato":               Tomato,
+	"turquoise":            Turquoise,
+	"violet":               Violet,
+	"wheat":                Wheat,
+	"white":                White,
+	"whitesmoke":           WhiteSmoke,
+	"yellow":               Yellow,
+	"yellowgreen":          YellowGreen,
+}
@@ -92,3 +92,16 @@ func (a AppProperties) Company() string {
 func (a AppProperties) SetCompany(s string) {
 	a.x.Company = &s
 }
+
+// Manager returns the name of the manager of the document's author.
+func (a AppProperties) Manager() string {
+	if a.x.Manager != nil {
+		return *a.x.Manager
+	}
+	return ""
+}
+
+// SetManager sets the name of the manager of the document's author.
+func (a AppProperties) SetManager(s string) {
+	a.x.Manager = &s
+}
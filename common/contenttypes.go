@@ -30,8 +30,8 @@ func NewContentTypes() ContentTypes {
 	ct.AddDefault("jpg", "image/jpg")
 	ct.AddDefault("wmf", "image/x-wmf")
 
-	ct.AddOverride("/docProps/core.xml", "application/vnd.openxmlformats-package.core-properties+xml")
-	ct.AddOverride("/docProps/app.xml", "application/vnd.openxmlformats-officedocument.extended-properties+xml")
+	ct.AddOverride("/docProps/core.xml", unioffice.CorePropertiesContentType)
+	ct.AddOverride("/docProps/app.xml", unioffice.ExtendedPropertiesContentType)
 
 	return ct
 }
@@ -81,6 +81,9 @@ func (c ContentTypes) EnsureDefault(ext, contentType string) {
 
 // EnsureOverride ensures that an override for the given path exists, adding it if necessary
 func (c ContentTypes) EnsureOverride(path, contentType string) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
 	for _, ovr := range c.x.Override {
 		// found one, so just ensure the content type matches and bail
 		if ovr.PartNameAttr == path {
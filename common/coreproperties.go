@@ -9,6 +9,7 @@ package common
 
 import (
 	"encoding/xml"
+	"strings"
 	"time"
 
 	"github.com/unidoc/unioffice"
@@ -173,3 +174,41 @@ func (c CoreProperties) SetDescription(s string) {
 	}
 	c.x.Description.Data = []byte(s)
 }
+
+// Subject returns the subject of the document
+func (c CoreProperties) Subject() string {
+	if c.x.Subject != nil {
+		return string(c.x.Subject.Data)
+	}
+	return ""
+}
+
+// SetSubject records the subject of the document.
+func (c CoreProperties) SetSubject(s string) {
+	if c.x.Subject == nil {
+		c.x.Subject = &unioffice.XSDAny{XMLName: xml.Name{Local: "dc:subject"}}
+	}
+	c.x.Subject.Data = []byte(s)
+}
+
+// Keywords returns the keywords of the document, separated by commas if more
+// than one keyword value is present.
+func (c CoreProperties) Keywords() string {
+	if c.x.Keywords == nil {
+		return ""
+	}
+	kw := []string{}
+	for _, v := range c.x.Keywords.Value {
+		kw = append(kw, v.Content)
+	}
+	return strings.Join(kw, ", ")
+}
+
+// SetKeywords records the keywords of the document, typically a comma
+// separated list.
+func (c CoreProperties) SetKeywords(s string) {
+	c.x.Keywords = core_properties.NewCT_Keywords()
+	kw := core_properties.NewCT_Keyword()
+	kw.Content = s
+	c.x.Keywords.Value = append(c.x.Keywords.Value, kw)
+}
@@ -19,6 +19,10 @@ type CustomProperties struct {
 	x *custom_properties.Properties
 }
 
+// customPropertiesFmtID is the format ID that identifies user defined custom
+// properties.
+const customPropertiesFmtID = "{D5CDD505-2E9C-101B-9397-08002B2CF9AE}"
+
 // CustomProperty contains document specific property
 type CustomProperty *custom_properties.CT_Property
 
@@ -55,6 +59,7 @@ func (c CustomProperties) getNewProperty(name string) *custom_properties.CT_Prop
 		}
 	}
 	newProperty := custom_properties.NewCT_Property()
+	newProperty.FmtidAttr = customPropertiesFmtID
 	newProperty.NameAttr = &name
 	newProperty.PidAttr = maxPid + 1
 	return newProperty
@@ -66,7 +71,7 @@ func (c CustomProperties) setProperty(newProperty *custom_properties.CT_Property
 		c.x.Property = append(c.x.Property, newProperty)
 	} else {
 		newProperty.FmtidAttr = existingProperty.FmtidAttr
-		if existingProperty.PidAttr == 0 {
+		if existingProperty.PidAttr != 0 {
 			newProperty.PidAttr = existingProperty.PidAttr
 		}
 		newProperty.LinkTargetAttr = existingProperty.LinkTargetAttr
@@ -273,3 +278,65 @@ func (c CustomProperties) SetPropertyAsVstream(name string, vstream *docPropsVTy
 	property.Vstream = vstream
 	c.setProperty(property)
 }
+
+// SetString sets a custom property to a string value, replacing any existing
+// property with the same name.
+func (c CustomProperties) SetString(name, value string) {
+	c.SetPropertyAsLpwstr(name, value)
+}
+
+// SetNumber sets a custom property to a numeric value, replacing any existing
+// property with the same name.
+func (c CustomProperties) SetNumber(name string, value float64) {
+	c.SetPropertyAsR8(name, value)
+}
+
+// SetBool sets a custom property to a boolean value, replacing any existing
+// property with the same name.
+func (c CustomProperties) SetBool(name string, value bool) {
+	c.SetPropertyAsBool(name, value)
+}
+
+// SetDate sets a custom property to a date value, replacing any existing
+// property with the same name.
+func (c CustomProperties) SetDate(name string, value time.Time) {
+	c.SetPropertyAsFiletime(name, value.UTC())
+}
+
+// PropertyByName returns the value of the named custom property.  Strings are
+// returned as a string, numbers as float64, booleans as bool and dates as a
+// time.Time.  Other value types are returned as the wrapped
+// *custom_properties.CT_Property.
+func (c CustomProperties) PropertyByName(name string) (interface{}, bool) {
+	p := c.GetPropertyByName(name)
+	if p == nil {
+		return nil, false
+	}
+	switch {
+	case p.Lpwstr != nil:
+		return *p.Lpwstr, true
+	case p.Lpstr != nil:
+		return *p.Lpstr, true
+	case p.Bstr != nil:
+		return *p.Bstr, true
+	case p.R8 != nil:
+		return *p.R8, true
+	case p.R4 != nil:
+		return float64(*p.R4), true
+	case p.Decimal != nil:
+		return *p.Decimal, true
+	case p.I4 != nil:
+		return float64(*p.I4), true
+	case p.Int != nil:
+		return float64(*p.Int), true
+	case p.I8 != nil:
+		return float64(*p.I8), true
+	case p.Bool != nil:
+		return *p.Bool, true
+	case p.Filetime != nil:
+		return *p.Filetime, true
+	case p.Date != nil:
+		return *p.Date, true
+	}
+	return (*custom_properties.CT_Property)(p), true
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"image"
 
+	"github.com/unidoc/unioffice"
 	"github.com/unidoc/unioffice/zippkg"
 )
 
@@ -25,12 +26,64 @@ type DocBase struct {
 	CustomProperties CustomProperties
 	Thumbnail        image.Image // thumbnail preview of the document
 
-	Images     []ImageRef
+	Images []ImageRef
+	// ExtraFiles are the parts that aren't otherwise supported, such as custom
+	// XML or parts referenced by unknown relationships.  They are read as-is
+	// and written back unchanged, along with their relationships and content
+	// types, so that saving doesn't drop content.
 	ExtraFiles []ExtraFile
 	TmpPath    string // path where temporary files are stored when opening documents
 
 }
 
+// EnsurePropertyParts ensures that the package relationships and content
+// types refer to the core and application property parts.  These parts are
+// always written on save, but may be missing from documents that were read
+// from a file that didn't contain them.
+func (d *DocBase) EnsurePropertyParts(dt unioffice.DocType) {
+	for _, typ := range []string{unioffice.CorePropertiesType, unioffice.ExtendedPropertiesType} {
+		found := false
+		for _, r := range d.Rels.Relationships() {
+			if r.Type() == typ {
+				found = true
+				break
+			}
+		}
+		if !found {
+			d.Rels.AddRelationship(unioffice.RelativeFilename(dt, "", typ, 0), typ)
+		}
+	}
+	d.ContentTypes.EnsureOverride(unioffice.AbsoluteFilename(dt, unioffice.CorePropertiesType, 0), unioffice.CorePropertiesContentType)
+	d.ContentTypes.EnsureOverride(unioffice.AbsoluteFilename(dt, unioffice.ExtendedPropertiesType, 0), unioffice.ExtendedPropertiesContentType)
+}
+
+// SetThumbnail sets the preview image shown by file explorers.  The image is
+// stored as a JPEG in the docProps/thumbnail.jpeg part, which is referenced
+// from the package relationships.  Passing nil removes the thumbnail.
+func (d *DocBase) SetThumbnail(img image.Image) {
+	d.Thumbnail = img
+	// the thumbnail location doesn't depend on the document type
+	fn := unioffice.AbsoluteFilename(unioffice.Unknown, unioffice.ThumbnailType, 0)
+	if img != nil {
+		d.ContentTypes.EnsureDefault("jpeg", "image/jpeg")
+	}
+	for _, r := range d.Rels.Relationships() {
+		if r.Type() != unioffice.ThumbnailType && r.Type() != unioffice.ThumbnailTypeStrict {
+			continue
+		}
+		if img == nil {
+			d.Rels.Remove(r)
+		} else {
+			r.X().TypeAttr = unioffice.ThumbnailType
+			r.SetTarget(fn)
+		}
+		return
+	}
+	if img != nil {
+		d.Rels.AddRelationship(fn, unioffice.ThumbnailType)
+	}
+}
+
 // AddExtraFileFromZip is used when reading an unsupported file from an OOXML
 // file. This ensures that unsupported file content will at least round-trip
 // correctly.
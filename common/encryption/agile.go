@@ -0,0 +1,410 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// The block keys that are hashed with the password hash, or the key data salt,
+// to derive the key or IV for each purpose.
+var (
+	blockKeyVerifierInput = []byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}
+	blockKeyVerifierValue = []byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}
+	blockKeyEncryptedKey  = []byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}
+	blockKeyHmacKey       = []byte{0x5f, 0xb2, 0xad, 0x01, 0x0c, 0xb9, 0xe1, 0xf6}
+	blockKeyHmacValue     = []byte{0xa0, 0x67, 0x7f, 0x02, 0xb2, 0x2c, 0x84, 0x33}
+)
+
+const (
+	agileSpinCount   = 100000
+	agileSaltSize    = 16
+	agileKeyBits     = 256
+	agileSegmentSize = 4096
+
+	passwordKeyEncryptorURI = "http://schemas.microsoft.com/office/2006/keyEncryptor/password"
+)
+
+// agileEncryption is the XML encryption descriptor of the agile encryption
+// info stream.
+type agileEncryption struct {
+	KeyData       agileKeyData `xml:"keyData"`
+	DataIntegrity *struct {
+		EncryptedHmacKey   string `xml:"encryptedHmacKey,attr"`
+		EncryptedHmacValue string `xml:"encryptedHmacValue,attr"`
+	} `xml:"dataIntegrity"`
+	KeyEncryptors []struct {
+		URI          string       `xml:"uri,attr"`
+		EncryptedKey agileKeyData `xml:"encryptedKey"`
+	} `xml:"keyEncryptors>keyEncryptor"`
+}
+
+// agileKeyData contains the attributes of the keyData and encryptedKey
+// elements.
+type agileKeyData struct {
+	SaltSize                   int    `xml:"saltSize,attr"`
+	BlockSize                  int    `xml:"blockSize,attr"`
+	KeyBits                    int    `xml:"keyBits,attr"`
+	HashSize                   int    `xml:"hashSize,attr"`
+	CipherAlgorithm            string `xml:"cipherAlgorithm,attr"`
+	CipherChaining             string `xml:"cipherChaining,attr"`
+	HashAlgorithm              string `xml:"hashAlgorithm,attr"`
+	SaltValue                  string `xml:"saltValue,attr"`
+	SpinCount                  int    `xml:"spinCount,attr"`
+	EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+	EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+	EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+}
+
+const agileDescriptor = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\r\n" +
+	`<encryption xmlns="http://schemas.microsoft.com/office/2006/encryption" ` +
+	`xmlns:p="http://schemas.microsoft.com/office/2006/keyEncryptor/password" ` +
+	`xmlns:c="http://schemas.microsoft.com/office/2006/keyEncryptor/certificate">` +
+	`<keyData saltSize="16" blockSize="16" keyBits="256" hashSize="64" cipherAlgorithm="AES" ` +
+	`cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" saltValue="%s"/>` +
+	`<dataIntegrity encryptedHmacKey="%s" encryptedHmacValue="%s"/>` +
+	`<keyEncryptors><keyEncryptor uri="` + passwordKeyEncryptorURI + `">` +
+	`<p:encryptedKey spinCount="%d" saltSize="16" blockSize="16" keyBits="256" hashSize="64" ` +
+	`cipherAlgorithm="AES" cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" saltValue="%s" ` +
+	`encryptedVerifierHashInput="%s" encryptedVerifierHashValue="%s" encryptedKeyValue="%s"/>` +
+	`</keyEncryptor></keyEncryptors></encryption>`
+
+// encryptAgile returns the encryption info and encrypted package streams for
+// the package.
+func encryptAgile(pkg []byte, password string) ([]byte, []byte, error) {
+	random := func(n int) ([]byte, error) {
+		b := make([]byte, n)
+		_, err := rand.Read(b)
+		return b, err
+	}
+	keySalt, err := random(agileSaltSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	passwordSalt, err := random(agileSaltSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	secretKey, err := random(agileKeyBits / 8)
+	if err != nil {
+		return nil, nil, err
+	}
+	verifier, err := random(agileSaltSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	hmacKey, err := random(sha512.Size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the secret key and a verifier of the password are encrypted with keys
+	// derived from the password
+	h := passwordHash(sha512.New, passwordSalt, password, agileSpinCount)
+	encVerifierInput, err := cbcEncrypt(deriveKey(sha512.New, h, blockKeyVerifierInput, agileKeyBits/8), passwordSalt, verifier)
+	if err != nil {
+		return nil, nil, err
+	}
+	encVerifierValue, err := cbcEncrypt(deriveKey(sha512.New, h, blockKeyVerifierValue, agileKeyBits/8), passwordSalt, hashOf(sha512.New, verifier))
+	if err != nil {
+		return nil, nil, err
+	}
+	encKey, err := cbcEncrypt(deriveKey(sha512.New, h, blockKeyEncryptedKey, agileKeyBits/8), passwordSalt, secretKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encPkg, err := cryptPackage(true, secretKey, keySalt, sha512.New, aes.BlockSize, pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mac := hmac.New(sha512.New, hmacKey)
+	mac.Write(encPkg)
+	encHmacKey, err := cbcEncrypt(secretKey, deriveIV(sha512.New, keySalt, blockKeyHmacKey, aes.BlockSize), hmacKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	encHmacValue, err := cbcEncrypt(secretKey, deriveIV(sha512.New, keySalt, blockKeyHmacValue, aes.BlockSize), mac.Sum(nil))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b64 := base64.StdEncoding.EncodeToString
+	info := bytes.Buffer{}
+	writeUint32s(&info, 0x00040004, 0x40) // version 4.4, reserved flags
+	fmt.Fprintf(&info, agileDescriptor, b64(keySalt), b64(encHmacKey), b64(encHmacValue),
+		agileSpinCount, b64(passwordSalt), b64(encVerifierInput), b64(encVerifierValue), b64(encKey))
+	return info.Bytes(), encPkg, nil
+}
+
+// decryptAgile decrypts an agile encrypted package given the XML encryption
+// descriptor.
+func decryptAgile(descriptor, encPkg []byte, password string) ([]byte, error) {
+	desc := agileEncryption{}
+	if err := xml.Unmarshal(descriptor, &desc); err != nil {
+		return nil, fmt.Errorf("error parsing encryption info: %s", err)
+	}
+	var ek *agileKeyData
+	for i, ke := range desc.KeyEncryptors {
+		if ke.URI == passwordKeyEncryptorURI {
+			ek = &desc.KeyEncryptors[i].EncryptedKey
+		}
+	}
+	if ek == nil {
+		return nil, errors.New("file isn't encrypted with a password")
+	}
+	kd := desc.KeyData
+	for _, d := range []*agileKeyData{&kd, ek} {
+		if d.CipherAlgorithm != "AES" || d.CipherChaining != "ChainingModeCBC" {
+			return nil, fmt.Errorf("unsupported cipher %s %s", d.CipherAlgorithm, d.CipherChaining)
+		}
+		if d.KeyBits%64 != 0 || d.KeyBits < 128 || d.KeyBits > 256 || d.BlockSize != aes.BlockSize {
+			return nil, fmt.Errorf("unsupported key size %d", d.KeyBits)
+		}
+	}
+	keyHash, err := hashFunc(kd.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	pwHash, err := hashFunc(ek.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	b64 := base64.StdEncoding.DecodeString
+	salt, err := b64(ek.SaltValue)
+	if err != nil {
+		return nil, err
+	}
+	encVerifierInput, err := b64(ek.EncryptedVerifierHashInput)
+	if err != nil {
+		return nil, err
+	}
+	encVerifierValue, err := b64(ek.EncryptedVerifierHashValue)
+	if err != nil {
+		return nil, err
+	}
+	encKey, err := b64(ek.EncryptedKeyValue)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := fitBytes(salt, ek.BlockSize)
+	keyLen := ek.KeyBits / 8
+	h := passwordHash(pwHash, salt, password, ek.SpinCount)
+	verifier, err := cbcDecrypt(deriveKey(pwHash, h, blockKeyVerifierInput, keyLen), iv, encVerifierInput)
+	if err != nil {
+		return nil, err
+	}
+	verifierHash, err := cbcDecrypt(deriveKey(pwHash, h, blockKeyVerifierValue, keyLen), iv, encVerifierValue)
+	if err != nil {
+		return nil, err
+	}
+	if ek.SaltSize > len(verifier) {
+		return nil, errors.New("invalid password verifier")
+	}
+	expected := hashOf(pwHash, verifier[:ek.SaltSize])
+	if len(verifierHash) < len(expected) || subtle.ConstantTimeCompare(expected, verifierHash[:len(expected)]) != 1 {
+		return nil, ErrInvalidPassword
+	}
+	secretKey, err := cbcDecrypt(deriveKey(pwHash, h, blockKeyEncryptedKey, keyLen), iv, encKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(secretKey) < kd.KeyBits/8 {
+		return nil, errors.New("invalid encrypted key")
+	}
+	secretKey = secretKey[:kd.KeyBits/8]
+
+	keySalt, err := b64(kd.SaltValue)
+	if err != nil {
+		return nil, err
+	}
+	if desc.DataIntegrity != nil {
+		if err := checkIntegrity(desc.DataIntegrity.EncryptedHmacKey, desc.DataIntegrity.EncryptedHmacValue,
+			secretKey, keySalt, keyHash, kd.BlockSize, encPkg); err != nil {
+			return nil, err
+		}
+	}
+	return cryptPackage(false, secretKey, keySalt, keyHash, kd.BlockSize, encPkg)
+}
+
+// checkIntegrity verifies the HMAC of the encrypted package.
+func checkIntegrity(encHmacKey, encHmacValue string, secretKey, keySalt []byte, newHash func() hash.Hash, blockSize int, encPkg []byte) error {
+	encKey, err := base64.StdEncoding.DecodeString(encHmacKey)
+	if err != nil {
+		return err
+	}
+	encValue, err := base64.StdEncoding.DecodeString(encHmacValue)
+	if err != nil {
+		return err
+	}
+	hmacKey, err := cbcDecrypt(secretKey, deriveIV(newHash, keySalt, blockKeyHmacKey, blockSize), encKey)
+	if err != nil {
+		return err
+	}
+	value, err := cbcDecrypt(secretKey, deriveIV(newHash, keySalt, blockKeyHmacValue, blockSize), encValue)
+	if err != nil {
+		return err
+	}
+	size := newHash().Size()
+	if len(hmacKey) < size || len(value) < size {
+		return errors.New("invalid data integrity information")
+	}
+	mac := hmac.New(newHash, hmacKey[:size])
+	mac.Write(encPkg)
+	if !hmac.Equal(mac.Sum(nil), value[:size]) {
+		return errors.New("encrypted package failed the integrity check")
+	}
+	return nil
+}
+
+// cryptPackage encrypts or decrypts the package.  The package is encrypted in
+// segments, each with its own IV, and prefixed with its unencrypted size.
+func cryptPackage(encrypt bool, key, keySalt []byte, newHash func() hash.Hash, blockSize int, data []byte) ([]byte, error) {
+	out := bytes.Buffer{}
+	size := uint64(len(data))
+	if encrypt {
+		binary.Write(&out, binary.LittleEndian, size)
+	} else {
+		if len(data) < 8 {
+			return nil, errors.New("encrypted package is truncated")
+		}
+		size = binary.LittleEndian.Uint64(data)
+		data = data[8:]
+	}
+	for i := 0; len(data) > 0; i++ {
+		n := agileSegmentSize
+		if n > len(data) {
+			n = len(data)
+		}
+		blockKey := make([]byte, 4)
+		binary.LittleEndian.PutUint32(blockKey, uint32(i))
+		iv := deriveIV(newHash, keySalt, blockKey, blockSize)
+		var seg []byte
+		var err error
+		if encrypt {
+			seg, err = cbcEncrypt(key, iv, data[:n])
+		} else {
+			seg, err = cbcDecrypt(key, iv, data[:n])
+		}
+		if err != nil {
+			return nil, err
+		}
+		out.Write(seg)
+		data = data[n:]
+	}
+	if encrypt {
+		return out.Bytes(), nil
+	}
+	if uint64(out.Len()) < size {
+		return nil, errors.New("encrypted package is truncated")
+	}
+	return out.Bytes()[:size], nil
+}
+
+// passwordHash returns the password hash after the given number of
+// iterations, each of which hashes the iteration number and previous hash.
+func passwordHash(newHash func() hash.Hash, salt []byte, password string, spinCount int) []byte {
+	h := hashOf(newHash, salt, utf16LE(password))
+	it := make([]byte, 4)
+	hf := newHash()
+	for i := 0; i < spinCount; i++ {
+		binary.LittleEndian.PutUint32(it, uint32(i))
+		hf.Reset()
+		hf.Write(it)
+		hf.Write(h)
+		h = hf.Sum(h[:0])
+	}
+	return h
+}
+
+// deriveKey returns a key for the given block key derived from the password
+// hash.
+func deriveKey(newHash func() hash.Hash, h, blockKey []byte, keyLen int) []byte {
+	return fitBytes(hashOf(newHash, h, blockKey), keyLen)
+}
+
+// deriveIV returns the IV for the given block key derived from the key data
+// salt.
+func deriveIV(newHash func() hash.Hash, salt, blockKey []byte, blockSize int) []byte {
+	return fitBytes(hashOf(newHash, salt, blockKey), blockSize)
+}
+
+// fitBytes truncates b to n bytes, or pads it with 0x36 bytes if it's
+// shorter.
+func fitBytes(b []byte, n int) []byte {
+	if len(b) >= n {
+		return b[:n]
+	}
+	return append(append([]byte{}, b...), bytes.Repeat([]byte{0x36}, n-len(b))...)
+}
+
+func hashOf(newHash func() hash.Hash, parts ...[]byte) []byte {
+	h := newHash()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+func hashFunc(name string) (func() hash.Hash, error) {
+	switch name {
+	case "SHA1", "SHA-1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA384":
+		return sha512.New384, nil
+	case "SHA512":
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unsupported hash algorithm %s", name)
+}
+
+// cbcEncrypt encrypts data with AES in CBC mode, padding it with zeros to a
+// multiple of the block size.
+func cbcEncrypt(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	n := (len(data) + aes.BlockSize - 1) / aes.BlockSize * aes.BlockSize
+	out := make([]byte, n)
+	copy(out, data)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, out)
+	return out, nil
+}
+
+func cbcDecrypt(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("encrypted data isn't a multiple of the block size")
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+	return out, nil
+}
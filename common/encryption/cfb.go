@@ -0,0 +1,490 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package encryption
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// Encrypted files are stored in a compound file, described by [MS-CFB], which
+// is a small file system within a file.  Streams are stored in chains of
+// sectors, linked through the file allocation table (FAT).  Streams smaller
+// than the mini stream cutoff are instead stored in chains of mini sectors
+// within the mini stream, linked through the mini FAT.  The streams and the
+// storages that contain them are listed in the directory, where the children
+// of each storage form a red-black tree.
+
+const (
+	cfbSectorSize       = 512
+	cfbMiniSectorSize   = 64
+	cfbMiniStreamCutoff = 4096
+	cfbDirEntrySize     = 128
+	cfbHeaderDIFATLen   = 109
+
+	cfbFreeSect   = 0xFFFFFFFF
+	cfbEndOfChain = 0xFFFFFFFE
+	cfbFATSect    = 0xFFFFFFFD
+	cfbDIFSect    = 0xFFFFFFFC
+	cfbNoStream   = 0xFFFFFFFF
+
+	cfbTypeStorage = 1
+	cfbTypeStream  = 2
+	cfbTypeRoot    = 5
+)
+
+var cfbSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// isCompoundFile returns true if b starts with the compound file signature.
+func isCompoundFile(b []byte) bool {
+	return bytes.HasPrefix(b, cfbSignature)
+}
+
+// cfbEntry is a stream, or a storage containing other entries, to be written
+// to a compound file.
+type cfbEntry struct {
+	name     string
+	data     []byte
+	children []*cfbEntry
+}
+
+// cfbStorage returns a storage entry containing the children.
+func cfbStorage(name string, children ...*cfbEntry) *cfbEntry {
+	if children == nil {
+		children = []*cfbEntry{}
+	}
+	return &cfbEntry{name: name, children: children}
+}
+
+// cfbDirEntry is an entry in the directory of a compound file.
+type cfbDirEntry struct {
+	name               string
+	typ                byte
+	left, right, child uint32
+	start              uint32
+	size               uint64
+	data               []byte
+	black              bool
+}
+
+// cfbLess orders directory entries as compound files require, shorter names
+// first and then by their upper case names.
+func cfbLess(a, b string) bool {
+	la, lb := len(utf16.Encode([]rune(a))), len(utf16.Encode([]rune(b)))
+	if la != lb {
+		return la < lb
+	}
+	return strings.ToUpper(a) < strings.ToUpper(b)
+}
+
+// writeCompoundFile returns a compound file containing the entries in its root
+// storage.
+func writeCompoundFile(entries []*cfbEntry) ([]byte, error) {
+	dir := []*cfbDirEntry{{name: "Root Entry", typ: cfbTypeRoot, left: cfbNoStream, right: cfbNoStream, black: true}}
+
+	// each storage's children are stored as a balanced binary tree, with all of
+	// its nodes black
+	var addTree func(children []*cfbEntry) (uint32, error)
+	addTree = func(children []*cfbEntry) (uint32, error) {
+		if len(children) == 0 {
+			return cfbNoStream, nil
+		}
+		mid := len(children) / 2
+		c := children[mid]
+		if len(utf16.Encode([]rune(c.name))) > 31 {
+			return 0, fmt.Errorf("compound file entry name %q is too long", c.name)
+		}
+		de := &cfbDirEntry{name: c.name, typ: cfbTypeStream, data: c.data, black: true, child: cfbNoStream}
+		if c.children != nil {
+			de.typ = cfbTypeStorage
+		}
+		id := uint32(len(dir))
+		dir = append(dir, de)
+		var err error
+		if de.left, err = addTree(children[:mid]); err != nil {
+			return 0, err
+		}
+		if de.right, err = addTree(children[mid+1:]); err != nil {
+			return 0, err
+		}
+		if c.children != nil {
+			if de.child, err = addTree(sortedEntries(c.children)); err != nil {
+				return 0, err
+			}
+		}
+		return id, nil
+	}
+	var err error
+	if dir[0].child, err = addTree(sortedEntries(entries)); err != nil {
+		return nil, err
+	}
+
+	fat := []uint32{}
+	body := bytes.Buffer{}
+	// allocate stores data in a new chain of sectors and returns the first
+	allocate := func(data []byte) uint32 {
+		if len(data) == 0 {
+			return cfbEndOfChain
+		}
+		start := uint32(len(fat))
+		n := (len(data) + cfbSectorSize - 1) / cfbSectorSize
+		for i := 0; i < n; i++ {
+			if i == n-1 {
+				fat = append(fat, cfbEndOfChain)
+			} else {
+				fat = append(fat, uint32(len(fat)+1))
+			}
+		}
+		body.Write(data)
+		body.Write(make([]byte, n*cfbSectorSize-len(data)))
+		return start
+	}
+
+	miniStream := bytes.Buffer{}
+	miniFAT := []uint32{}
+	for _, de := range dir {
+		if de.typ != cfbTypeStream {
+			continue
+		}
+		de.size = uint64(len(de.data))
+		switch {
+		case len(de.data) == 0:
+			de.start = cfbEndOfChain
+		case len(de.data) < cfbMiniStreamCutoff:
+			de.start = uint32(len(miniFAT))
+			n := (len(de.data) + cfbMiniSectorSize - 1) / cfbMiniSectorSize
+			for i := 0; i < n; i++ {
+				if i == n-1 {
+					miniFAT = append(miniFAT, cfbEndOfChain)
+				} else {
+					miniFAT = append(miniFAT, uint32(len(miniFAT)+1))
+				}
+			}
+			miniStream.Write(de.data)
+			miniStream.Write(make([]byte, n*cfbMiniSectorSize-len(de.data)))
+		default:
+			de.start = allocate(de.data)
+		}
+	}
+	dir[0].start = allocate(miniStream.Bytes())
+	dir[0].size = uint64(miniStream.Len())
+	if dir[0].size == 0 {
+		dir[0].start = cfbEndOfChain
+	}
+
+	firstMiniFAT := uint32(cfbEndOfChain)
+	numMiniFAT := 0
+	if len(miniFAT) > 0 {
+		firstMiniFAT = allocate(uint32Sector(miniFAT, cfbSectorSize))
+		numMiniFAT = (len(miniFAT)*4 + cfbSectorSize - 1) / cfbSectorSize
+	}
+
+	dirBuf := bytes.Buffer{}
+	for _, de := range dir {
+		dirBuf.Write(de.marshal())
+	}
+	for dirBuf.Len()%cfbSectorSize != 0 {
+		dirBuf.Write((&cfbDirEntry{left: cfbNoStream, right: cfbNoStream, child: cfbNoStream}).marshal())
+	}
+	firstDir := allocate(dirBuf.Bytes())
+
+	// the FAT and DIFAT sectors are themselves listed in the FAT
+	const perFAT = cfbSectorSize / 4
+	const perDIFAT = perFAT - 1
+	nData := len(fat)
+	nFAT, nDIFAT := 0, 0
+	for {
+		total := nData + nFAT + nDIFAT
+		fatNeeded := (total + perFAT - 1) / perFAT
+		difatNeeded := 0
+		if fatNeeded > cfbHeaderDIFATLen {
+			difatNeeded = (fatNeeded - cfbHeaderDIFATLen + perDIFAT - 1) / perDIFAT
+		}
+		if fatNeeded == nFAT && difatNeeded == nDIFAT {
+			break
+		}
+		nFAT, nDIFAT = fatNeeded, difatNeeded
+	}
+	fatSectors := []uint32{}
+	for i := 0; i < nFAT; i++ {
+		fatSectors = append(fatSectors, uint32(len(fat)))
+		fat = append(fat, cfbFATSect)
+	}
+	difatStart := uint32(len(fat))
+	for i := 0; i < nDIFAT; i++ {
+		fat = append(fat, cfbDIFSect)
+	}
+	body.Write(uint32Sector(fat, cfbSectorSize))
+	for i := 0; i < nDIFAT; i++ {
+		ids := []uint32{}
+		for j := cfbHeaderDIFATLen + i*perDIFAT; j < cfbHeaderDIFATLen+(i+1)*perDIFAT; j++ {
+			if j < len(fatSectors) {
+				ids = append(ids, fatSectors[j])
+			} else {
+				ids = append(ids, cfbFreeSect)
+			}
+		}
+		if i == nDIFAT-1 {
+			ids = append(ids, cfbEndOfChain)
+		} else {
+			ids = append(ids, difatStart+uint32(i)+1)
+		}
+		body.Write(uint32Sector(ids, cfbSectorSize))
+	}
+
+	hdr := make([]byte, cfbSectorSize)
+	copy(hdr, cfbSignature)
+	le := binary.LittleEndian
+	le.PutUint16(hdr[24:], 0x003E) // minor version
+	le.PutUint16(hdr[26:], 0x0003) // major version
+	le.PutUint16(hdr[28:], 0xFFFE) // byte order
+	le.PutUint16(hdr[30:], 9)      // sector shift
+	le.PutUint16(hdr[32:], 6)      // mini sector shift
+	le.PutUint32(hdr[44:], uint32(nFAT))
+	le.PutUint32(hdr[48:], firstDir)
+	le.PutUint32(hdr[56:], cfbMiniStreamCutoff)
+	le.PutUint32(hdr[60:], firstMiniFAT)
+	le.PutUint32(hdr[64:], uint32(numMiniFAT))
+	if nDIFAT > 0 {
+		le.PutUint32(hdr[68:], difatStart)
+	} else {
+		le.PutUint32(hdr[68:], cfbEndOfChain)
+	}
+	le.PutUint32(hdr[72:], uint32(nDIFAT))
+	for i := 0; i < cfbHeaderDIFATLen; i++ {
+		id := uint32(cfbFreeSect)
+		if i < len(fatSectors) {
+			id = fatSectors[i]
+		}
+		le.PutUint32(hdr[76+4*i:], id)
+	}
+	return append(hdr, body.Bytes()...), nil
+}
+
+func sortedEntries(entries []*cfbEntry) []*cfbEntry {
+	ret := append([]*cfbEntry{}, entries...)
+	sort.Slice(ret, func(i, j int) bool { return cfbLess(ret[i].name, ret[j].name) })
+	return ret
+}
+
+// uint32Sector encodes the values, padded with free sector markers to a
+// multiple of the sector size.
+func uint32Sector(v []uint32, sectorSize int) []byte {
+	n := (len(v)*4 + sectorSize - 1) / sectorSize * sectorSize
+	b := make([]byte, n)
+	for i := 0; i < n/4; i++ {
+		id := uint32(cfbFreeSect)
+		if i < len(v) {
+			id = v[i]
+		}
+		binary.LittleEndian.PutUint32(b[4*i:], id)
+	}
+	return b
+}
+
+func (de *cfbDirEntry) marshal() []byte {
+	b := make([]byte, cfbDirEntrySize)
+	le := binary.LittleEndian
+	if de.name != "" {
+		name := utf16.Encode([]rune(de.name))
+		for i, c := range name {
+			le.PutUint16(b[2*i:], c)
+		}
+		le.PutUint16(b[64:], uint16(2*(len(name)+1)))
+	}
+	b[66] = de.typ
+	if de.black {
+		b[67] = 1
+	}
+	le.PutUint32(b[68:], de.left)
+	le.PutUint32(b[72:], de.right)
+	le.PutUint32(b[76:], de.child)
+	le.PutUint32(b[116:], de.start)
+	le.PutUint64(b[120:], de.size)
+	return b
+}
+
+// readCompoundFile returns the streams of a compound file, keyed by their
+// paths with storage names separated by slashes, e.g. 'Storage/Stream'.
+func readCompoundFile(b []byte) (map[string][]byte, error) {
+	if len(b) < cfbSectorSize || !isCompoundFile(b) {
+		return nil, errors.New("not a compound file")
+	}
+	le := binary.LittleEndian
+	shift := le.Uint16(b[30:])
+	miniShift := le.Uint16(b[32:])
+	if shift != 9 && shift != 12 || miniShift != 6 {
+		return nil, fmt.Errorf("unsupported compound file sector size 2^%d", shift)
+	}
+	sectorSize := 1 << shift
+	miniCutoff := uint64(le.Uint32(b[56:]))
+
+	sector := func(id uint32) ([]byte, error) {
+		off := (int64(id) + 1) * int64(sectorSize)
+		if off+int64(sectorSize) > int64(len(b)) {
+			// the last sector may be truncated
+			if off < int64(len(b)) && id < cfbDIFSect {
+				return b[off:], nil
+			}
+			return nil, fmt.Errorf("compound file sector %d out of range", id)
+		}
+		return b[off : off+int64(sectorSize)], nil
+	}
+
+	// the FAT sectors are listed in the header and then in the DIFAT chain
+	numFAT := int(le.Uint32(b[44:]))
+	fatSectors := []uint32{}
+	for i := 0; i < cfbHeaderDIFATLen && len(fatSectors) < numFAT; i++ {
+		fatSectors = append(fatSectors, le.Uint32(b[76+4*i:]))
+	}
+	next := le.Uint32(b[68:])
+	for i := 0; len(fatSectors) < numFAT && next < cfbDIFSect; i++ {
+		if i > len(b)/sectorSize {
+			return nil, errors.New("compound file DIFAT chain is cyclic")
+		}
+		s, err := sector(next)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j < sectorSize/4-1 && len(fatSectors) < numFAT; j++ {
+			fatSectors = append(fatSectors, le.Uint32(s[4*j:]))
+		}
+		next = le.Uint32(s[sectorSize-4:])
+	}
+	fat := []uint32{}
+	for _, id := range fatSectors {
+		s, err := sector(id)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j+4 <= len(s); j += 4 {
+			fat = append(fat, le.Uint32(s[j:]))
+		}
+	}
+
+	chain := func(start uint32, table []uint32, read func(uint32) ([]byte, error)) ([]byte, error) {
+		buf := bytes.Buffer{}
+		for id, n := start, 0; id < cfbDIFSect; n++ {
+			if n > len(table) || int(id) >= len(table) {
+				return nil, errors.New("invalid compound file sector chain")
+			}
+			s, err := read(id)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(s)
+			id = table[id]
+		}
+		return buf.Bytes(), nil
+	}
+
+	dirData, err := chain(le.Uint32(b[48:]), fat, sector)
+	if err != nil {
+		return nil, err
+	}
+	dir := []*cfbDirEntry{}
+	for off := 0; off+cfbDirEntrySize <= len(dirData); off += cfbDirEntrySize {
+		e := dirData[off : off+cfbDirEntrySize]
+		nameLen := int(le.Uint16(e[64:]))
+		if nameLen > 64 {
+			nameLen = 64
+		}
+		name := make([]uint16, 0, 32)
+		for i := 0; i+1 < nameLen; i += 2 {
+			if c := le.Uint16(e[i:]); c != 0 {
+				name = append(name, c)
+			}
+		}
+		dir = append(dir, &cfbDirEntry{
+			name:  string(utf16.Decode(name)),
+			typ:   e[66],
+			left:  le.Uint32(e[68:]),
+			right: le.Uint32(e[72:]),
+			child: le.Uint32(e[76:]),
+			start: le.Uint32(e[116:]),
+			size:  le.Uint64(e[120:]),
+		})
+	}
+	if len(dir) == 0 || dir[0].typ != cfbTypeRoot {
+		return nil, errors.New("compound file has no root entry")
+	}
+	if sectorSize == cfbSectorSize {
+		// the upper half of the size may contain garbage in version 3 files
+		for _, de := range dir {
+			de.size &= 0xFFFFFFFF
+		}
+	}
+
+	miniStream, err := chain(dir[0].start, fat, sector)
+	if err != nil {
+		return nil, err
+	}
+	miniFATData, err := chain(le.Uint32(b[60:]), fat, sector)
+	if err != nil {
+		return nil, err
+	}
+	miniFAT := []uint32{}
+	for j := 0; j+4 <= len(miniFATData); j += 4 {
+		miniFAT = append(miniFAT, le.Uint32(miniFATData[j:]))
+	}
+	miniSector := func(id uint32) ([]byte, error) {
+		off := int(id) * cfbMiniSectorSize
+		if off+cfbMiniSectorSize > len(miniStream) {
+			return nil, fmt.Errorf("compound file mini sector %d out of range", id)
+		}
+		return miniStream[off : off+cfbMiniSectorSize], nil
+	}
+
+	streams := map[string][]byte{}
+	visited := map[uint32]bool{}
+	var walk func(id uint32, prefix string) error
+	walk = func(id uint32, prefix string) error {
+		if id == cfbNoStream {
+			return nil
+		}
+		if int(id) >= len(dir) || visited[id] {
+			return errors.New("invalid compound file directory")
+		}
+		visited[id] = true
+		de := dir[id]
+		switch de.typ {
+		case cfbTypeStream:
+			var data []byte
+			var err error
+			if de.size < miniCutoff {
+				data, err = chain(de.start, miniFAT, miniSector)
+			} else {
+				data, err = chain(de.start, fat, sector)
+			}
+			if err != nil {
+				return err
+			}
+			if uint64(len(data)) < de.size {
+				return fmt.Errorf("compound file stream %s is truncated", de.name)
+			}
+			streams[prefix+de.name] = data[:de.size]
+		case cfbTypeStorage:
+			if err := walk(de.child, prefix+de.name+"/"); err != nil {
+				return err
+			}
+		}
+		if err := walk(de.left, prefix); err != nil {
+			return err
+		}
+		return walk(de.right, prefix)
+	}
+	if err := walk(dir[0].child, ""); err != nil {
+		return nil, err
+	}
+	return streams, nil
+}
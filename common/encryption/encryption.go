@@ -0,0 +1,148 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+// Package encryption implements the password based encryption of Office Open
+// XML packages described by [MS-OFFCRYPTO], as used by Office for password
+// protected documents.  An encrypted package is stored in a compound file
+// along with the information needed to derive its key from the password.
+package encryption
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+// ErrInvalidPassword is returned when decrypting with the wrong password.
+var ErrInvalidPassword = errors.New("invalid password")
+
+// Stream names within the compound file.
+const (
+	encryptionInfoStream   = "EncryptionInfo"
+	encryptedPackageStream = "EncryptedPackage"
+	dataSpacesStorage      = "\x06DataSpaces"
+)
+
+// IsEncrypted returns true if b, the start of a file, is an encrypted package
+// rather than a zip file.  Any compound file is reported as encrypted, it
+// isn't checked that it contains an encrypted package.
+func IsEncrypted(b []byte) bool {
+	return isCompoundFile(b)
+}
+
+// Encrypt encrypts a package, e.g. the contents of an xlsx file, with
+// password using agile encryption as Office 2010 and later do.  The package
+// is encrypted with AES-256 using a random key that is itself encrypted with a
+// key derived from the password using SHA-512.
+func Encrypt(pkg []byte, password string) ([]byte, error) {
+	info, encPkg, err := encryptAgile(pkg, password)
+	if err != nil {
+		return nil, err
+	}
+	return writeCompoundFile([]*cfbEntry{
+		{name: encryptionInfoStream, data: info},
+		{name: encryptedPackageStream, data: encPkg},
+		newDataSpaces(),
+	})
+}
+
+// Decrypt decrypts an encrypted package with password, returning the
+// package.  Both agile encryption and the standard encryption used by Office
+// 2007 are supported.  ErrInvalidPassword is returned if the password is
+// wrong.
+func Decrypt(data []byte, password string) ([]byte, error) {
+	streams, err := readCompoundFile(data)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := streams[encryptionInfoStream]
+	if !ok {
+		return nil, errors.New("compound file doesn't contain an encrypted package")
+	}
+	encPkg, ok := streams[encryptedPackageStream]
+	if !ok {
+		return nil, errors.New("compound file doesn't contain an encrypted package")
+	}
+	if len(info) < 8 {
+		return nil, errors.New("encryption info is truncated")
+	}
+	major := binary.LittleEndian.Uint16(info)
+	minor := binary.LittleEndian.Uint16(info[2:])
+	switch {
+	case major == 4 && minor == 4:
+		return decryptAgile(info[8:], encPkg, password)
+	case (major == 2 || major == 3 || major == 4) && minor == 2:
+		return decryptStandard(info[4:], encPkg, password)
+	default:
+		return nil, fmt.Errorf("unsupported encryption version %d.%d", major, minor)
+	}
+}
+
+// newDataSpaces returns the storage that describes the transform applied to
+// the encrypted package.  It is the same for all agile encrypted files.
+func newDataSpaces() *cfbEntry {
+	version := bytes.Buffer{}
+	writeUnicodeLP(&version, "Microsoft.Container.DataSpaces")
+	writeUint32s(&version, 1, 1, 1) // reader, updater and writer version 1.0
+
+	dsMap := bytes.Buffer{}
+	entry := bytes.Buffer{}
+	writeUint32s(&entry, 1, 0) // a single reference to a stream
+	writeUnicodeLP(&entry, encryptedPackageStream)
+	writeUnicodeLP(&entry, "StrongEncryptionDataSpace")
+	writeUint32s(&dsMap, 8, 1, uint32(entry.Len()+4))
+	dsMap.Write(entry.Bytes())
+
+	dsDef := bytes.Buffer{}
+	writeUint32s(&dsDef, 8, 1)
+	writeUnicodeLP(&dsDef, "StrongEncryptionTransform")
+
+	primary := bytes.Buffer{}
+	id := bytes.Buffer{}
+	writeUnicodeLP(&id, "{FF9A3F03-56EF-4613-BDD5-5A41C1D07246}")
+	writeUint32s(&primary, uint32(id.Len()+8), 1)
+	primary.Write(id.Bytes())
+	writeUnicodeLP(&primary, "Microsoft.Container.EncryptionTransform")
+	writeUint32s(&primary, 1, 1, 1)
+	// an empty encryption name, block size, cipher mode and reserved value
+	writeUint32s(&primary, 0, 0, 0, 4)
+
+	return cfbStorage(dataSpacesStorage,
+		&cfbEntry{name: "Version", data: version.Bytes()},
+		&cfbEntry{name: "DataSpaceMap", data: dsMap.Bytes()},
+		cfbStorage("DataSpaceInfo", &cfbEntry{name: "StrongEncryptionDataSpace", data: dsDef.Bytes()}),
+		cfbStorage("TransformInfo", cfbStorage("StrongEncryptionTransform",
+			&cfbEntry{name: "\x06Primary", data: primary.Bytes()})),
+	)
+}
+
+// writeUnicodeLP writes a length prefixed UTF-16 string padded to a multiple
+// of four bytes.
+func writeUnicodeLP(b *bytes.Buffer, s string) {
+	u := utf16.Encode([]rune(s))
+	writeUint32s(b, uint32(2*len(u)))
+	binary.Write(b, binary.LittleEndian, u)
+	if len(u)%2 == 1 {
+		b.Write([]byte{0, 0})
+	}
+}
+
+func writeUint32s(b *bytes.Buffer, v ...uint32) {
+	binary.Write(b, binary.LittleEndian, v)
+}
+
+// utf16LE returns s encoded as little endian UTF-16, as passwords are hashed.
+func utf16LE(s string) []byte {
+	u := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(u))
+	for i, c := range u {
+		binary.LittleEndian.PutUint16(b[2*i:], c)
+	}
+	return b
+}
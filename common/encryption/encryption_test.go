@@ -0,0 +1,74 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package encryption_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/unidoc/unioffice/common/encryption"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	// sizes that are stored in the mini stream, span several segments and
+	// require more FAT sectors than fit in the header
+	for _, size := range []int{0, 100, 4096, 10000, 8 << 20} {
+		pkg := make([]byte, size)
+		rand.New(rand.NewSource(int64(size))).Read(pkg)
+
+		enc, err := encryption.Encrypt(pkg, "pässwörd")
+		if err != nil {
+			t.Fatalf("error encrypting %d bytes: %s", size, err)
+		}
+		if !encryption.IsEncrypted(enc) {
+			t.Errorf("expected encrypted file to be detected")
+		}
+		if size > 100 && bytes.Contains(enc, pkg[:100]) {
+			t.Errorf("expected the package to be encrypted")
+		}
+
+		dec, err := encryption.Decrypt(enc, "pässwörd")
+		if err != nil {
+			t.Fatalf("error decrypting %d bytes: %s", size, err)
+		}
+		if !bytes.Equal(dec, pkg) {
+			t.Errorf("expected the decrypted package to match the original %d bytes", size)
+		}
+	}
+}
+
+func TestDecryptInvalidPassword(t *testing.T) {
+	enc, err := encryption.Encrypt([]byte("package"), "secret")
+	if err != nil {
+		t.Fatalf("error encrypting: %s", err)
+	}
+	if _, err := encryption.Decrypt(enc, "Secret"); err != encryption.ErrInvalidPassword {
+		t.Errorf("expected ErrInvalidPassword, got %v", err)
+	}
+	if _, err := encryption.Decrypt([]byte("PK\x03\x04"), "secret"); err == nil {
+		t.Errorf("expected an error decrypting a zip file")
+	}
+	if encryption.IsEncrypted([]byte("PK\x03\x04")) {
+		t.Errorf("expected a zip file not to be detected as encrypted")
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	pkg := bytes.Repeat([]byte("package"), 1000)
+	enc, err := encryption.Encrypt(pkg, "secret")
+	if err != nil {
+		t.Fatalf("error encrypting: %s", err)
+	}
+	// the encrypted package follows the header and is the first stream
+	// stored outside of the mini stream
+	enc[512+100] ^= 0xFF
+	if _, err := encryption.Decrypt(enc, "secret"); err == nil {
+		t.Errorf("expected an error decrypting a modified package")
+	}
+}
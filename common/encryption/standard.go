@@ -0,0 +1,140 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Standard encryption, as written by Office 2007, encrypts the whole package
+// with AES in ECB mode using a key derived from the password with SHA-1.
+
+const (
+	standardSpinCount = 50000
+
+	standardFlagCryptoAPI = 0x04
+	standardFlagExternal  = 0x10
+	standardFlagAES       = 0x20
+
+	standardAlgAES128 = 0x660E
+	standardAlgAES192 = 0x660F
+	standardAlgAES256 = 0x6610
+	standardAlgSHA1   = 0x8004
+)
+
+// decryptStandard decrypts a standard encrypted package given the encryption
+// info following its version.
+func decryptStandard(info, encPkg []byte, password string) ([]byte, error) {
+	le := binary.LittleEndian
+	if len(info) < 8 {
+		return nil, errors.New("encryption info is truncated")
+	}
+	flags := le.Uint32(info)
+	hdrSize := int(le.Uint32(info[4:]))
+	if flags&standardFlagExternal != 0 {
+		return nil, errors.New("unsupported external encryption")
+	}
+	if flags&standardFlagAES == 0 || flags&standardFlagCryptoAPI == 0 {
+		return nil, errors.New("unsupported RC4 encryption")
+	}
+	if hdrSize < 32 || len(info) < 8+hdrSize {
+		return nil, errors.New("encryption info is truncated")
+	}
+	hdr := info[8 : 8+hdrSize]
+	alg := le.Uint32(hdr[8:])
+	algHash := le.Uint32(hdr[12:])
+	keyBits := int(le.Uint32(hdr[16:]))
+	switch alg {
+	case standardAlgAES128, standardAlgAES192, standardAlgAES256:
+	default:
+		return nil, fmt.Errorf("unsupported cipher algorithm 0x%x", alg)
+	}
+	if algHash != 0 && algHash != standardAlgSHA1 {
+		return nil, fmt.Errorf("unsupported hash algorithm 0x%x", algHash)
+	}
+	if keyBits != 128 && keyBits != 192 && keyBits != 256 {
+		return nil, fmt.Errorf("unsupported key size %d", keyBits)
+	}
+
+	v := info[8+hdrSize:]
+	if len(v) < 4 {
+		return nil, errors.New("encryption verifier is truncated")
+	}
+	saltSize := int(le.Uint32(v))
+	if saltSize != 16 || len(v) < 4+saltSize+16+4+32 {
+		return nil, errors.New("encryption verifier is truncated")
+	}
+	salt := v[4 : 4+saltSize]
+	encVerifier := v[4+saltSize : 4+saltSize+16]
+	hashSize := int(le.Uint32(v[4+saltSize+16:]))
+	encVerifierHash := v[4+saltSize+20 : 4+saltSize+20+32]
+
+	key := standardKey(salt, password, keyBits/8)
+	verifier, err := ecbDecrypt(key, encVerifier)
+	if err != nil {
+		return nil, err
+	}
+	verifierHash, err := ecbDecrypt(key, encVerifierHash)
+	if err != nil {
+		return nil, err
+	}
+	expected := hashOf(sha1.New, verifier)
+	if hashSize != len(expected) || subtle.ConstantTimeCompare(expected, verifierHash[:hashSize]) != 1 {
+		return nil, ErrInvalidPassword
+	}
+
+	if len(encPkg) < 8 {
+		return nil, errors.New("encrypted package is truncated")
+	}
+	size := le.Uint64(encPkg)
+	data := encPkg[8:]
+	data = data[:len(data)/aes.BlockSize*aes.BlockSize]
+	pkg, err := ecbDecrypt(key, data)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(pkg)) < size {
+		return nil, errors.New("encrypted package is truncated")
+	}
+	return pkg[:size], nil
+}
+
+// standardKey derives the key from the password as CryptoAPI does.
+func standardKey(salt []byte, password string, keyLen int) []byte {
+	h := passwordHash(sha1.New, salt, password, standardSpinCount)
+	h = hashOf(sha1.New, h, []byte{0, 0, 0, 0})
+	derive := func(pad byte) []byte {
+		buf := bytes.Repeat([]byte{pad}, 64)
+		for i, c := range h {
+			buf[i] ^= c
+		}
+		return hashOf(sha1.New, buf)
+	}
+	return append(derive(0x36), derive(0x5C)...)[:keyLen]
+}
+
+func ecbDecrypt(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("encrypted data isn't a multiple of the block size")
+	}
+	out := make([]byte, len(data))
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Decrypt(out[i:], data[i:])
+	}
+	return out, nil
+}
@@ -135,8 +135,15 @@ func (r Relationships) CopyRelationship(idAttr string) (Relationship, bool) {
 // Hyperlink is just an appropriately configured relationship.
 type Hyperlink Relationship
 
-// AddHyperlink adds an external hyperlink relationship.
+// AddHyperlink adds an external hyperlink relationship.  An existing
+// relationship to the same target is reused rather than adding a duplicate.
 func (r Relationships) AddHyperlink(target string) Hyperlink {
+	for _, x := range r.x.Relationship {
+		if x.TypeAttr == unioffice.HyperLinkType && x.TargetAttr == target &&
+			x.TargetModeAttr == relationships.ST_TargetModeExternal {
+			return Hyperlink(Relationship{x})
+		}
+	}
 	rel := r.AddRelationship(target, unioffice.HyperLinkType)
 	rel.x.TargetModeAttr = relationships.ST_TargetModeExternal
 	return Hyperlink(rel)
@@ -7,7 +7,10 @@
 
 package common
 
-import "github.com/unidoc/unioffice/schema/soo/dml"
+import (
+	"github.com/unidoc/unioffice/color"
+	"github.com/unidoc/unioffice/schema/soo/dml"
+)
 
 // Theme is a drawingml theme.
 type Theme struct {
@@ -19,7 +22,110 @@ func NewTheme() Theme {
 	return Theme{dml.NewTheme()}
 }
 
+// MakeTheme constructs a Theme wrapping an existing theme.
+func MakeTheme(x *dml.Theme) Theme {
+	return Theme{x}
+}
+
 // X returns the inner wrapped XML type.
 func (t Theme) X() *dml.Theme {
 	return t.x
 }
+
+// ThemeColorSlot identifies one of the colors in a theme's color scheme.  The
+// values match the theme color indexes used by spreadsheets, where the light
+// colors precede the dark ones.
+type ThemeColorSlot byte
+
+// ThemeColorSlot constants
+const (
+	ThemeColorLight1 ThemeColorSlot = iota
+	ThemeColorDark1
+	ThemeColorLight2
+	ThemeColorDark2
+	ThemeColorAccent1
+	ThemeColorAccent2
+	ThemeColorAccent3
+	ThemeColorAccent4
+	ThemeColorAccent5
+	ThemeColorAccent6
+	ThemeColorHyperlink
+	ThemeColorFollowedHyperlink
+)
+
+// slot returns the color scheme entry for a slot, creating the color scheme
+// if necessary.
+func (t Theme) slot(s ThemeColorSlot) **dml.CT_Color {
+	if t.x.ThemeElements == nil {
+		t.x.ThemeElements = dml.NewCT_BaseStyles()
+	}
+	cs := t.x.ThemeElements.ClrScheme
+	if cs == nil {
+		cs = dml.NewCT_ColorScheme()
+		t.x.ThemeElements.ClrScheme = cs
+	}
+	switch s {
+	case ThemeColorLight1:
+		return &cs.Lt1
+	case ThemeColorDark1:
+		return &cs.Dk1
+	case ThemeColorLight2:
+		return &cs.Lt2
+	case ThemeColorDark2:
+		return &cs.Dk2
+	case ThemeColorAccent1:
+		return &cs.Accent1
+	case ThemeColorAccent2:
+		return &cs.Accent2
+	case ThemeColorAccent3:
+		return &cs.Accent3
+	case ThemeColorAccent4:
+		return &cs.Accent4
+	case ThemeColorAccent5:
+		return &cs.Accent5
+	case ThemeColorAccent6:
+		return &cs.Accent6
+	case ThemeColorHyperlink:
+		return &cs.Hlink
+	case ThemeColorFollowedHyperlink:
+		return &cs.FolHlink
+	}
+	return nil
+}
+
+// SetColor sets a color in the theme's color scheme.  Content that refers to
+// the slot is displayed in the new color.
+func (t Theme) SetColor(s ThemeColorSlot, c color.Color) {
+	p := t.slot(s)
+	if p == nil {
+		return
+	}
+	clr := dml.NewCT_Color()
+	clr.SrgbClr = dml.NewCT_SRgbColor()
+	clr.SrgbClr.ValAttr = *c.AsRGBString()
+	*p = clr
+}
+
+// Color returns a color from the theme's color scheme.  System colors are
+// resolved using the last computed value stored in the theme.
+func (t Theme) Color(s ThemeColorSlot) (color.Color, bool) {
+	if t.x.ThemeElements == nil || t.x.ThemeElements.ClrScheme == nil {
+		return color.Color{}, false
+	}
+	p := t.slot(s)
+	if p == nil || *p == nil {
+		return color.Color{}, false
+	}
+	hex := ""
+	switch clr := *p; {
+	case clr.SrgbClr != nil:
+		hex = clr.SrgbClr.ValAttr
+	case clr.SysClr != nil && clr.SysClr.LastClrAttr != nil:
+		hex = *clr.SysClr.LastClrAttr
+	}
+	c, err := color.FromHex(hex)
+	if err != nil {
+		return color.Color{}, false
+	}
+	return c, true
+}
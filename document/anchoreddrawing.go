@@ -69,13 +69,13 @@ func (a AnchoredDrawing) SetOffset(x, y measurement.Distance) {
 // SetXOffset sets the X offset for an image relative to the origin.
 func (a AnchoredDrawing) SetXOffset(x measurement.Distance) {
 	a.x.PositionH.Choice = &wml.WdCT_PosHChoice{}
-	a.x.PositionH.Choice.PosOffset = unioffice.Int32(int32(x / measurement.EMU))
+	a.x.PositionH.Choice.PosOffset = unioffice.Int32(int32(x.ToEMU()))
 }
 
 // SetYOffset sets the Y offset for an image relative to the origin.
 func (a AnchoredDrawing) SetYOffset(y measurement.Distance) {
 	a.x.PositionV.Choice = &wml.WdCT_PosVChoice{}
-	a.x.PositionV.Choice.PosOffset = unioffice.Int32(int32(y / measurement.EMU))
+	a.x.PositionV.Choice.PosOffset = unioffice.Int32(int32(y.ToEMU()))
 }
 
 // SetAlignment positions an anchored image via alignment.  Offset is
@@ -99,8 +99,11 @@ func (a AnchoredDrawing) SetVAlignment(v wml.WdST_AlignV) {
 
 // SetSize sets the size of the displayed image on the page.
 func (a AnchoredDrawing) SetSize(w, h measurement.Distance) {
-	a.x.Extent.CxAttr = int64(float64(w*measurement.Pixel72) / measurement.EMU)
-	a.x.Extent.CyAttr = int64(float64(h*measurement.Pixel72) / measurement.EMU)
+	a.x.Extent.CxAttr = w.ToEMU()
+	a.x.Extent.CyAttr = h.ToEMU()
+	if a.x.Graphic != nil {
+		setPictureExtent(a.x.Graphic.GraphicData, a.x.Extent.CxAttr, a.x.Extent.CyAttr)
+	}
 }
 
 // SetTextWrapNone unsets text wrapping so the image can float on top of the
@@ -108,13 +111,36 @@ func (a AnchoredDrawing) SetSize(w, h measurement.Distance) {
 // used to place a logo at the top of a page at an absolute position that
 // doesn't interfere with text.
 func (a AnchoredDrawing) SetTextWrapNone() {
+	a.x.BehindDocAttr = false
 	a.x.Choice = &wml.WdEG_WrapTypeChoice{}
 	a.x.Choice.WrapNone = wml.NewWdCT_WrapNone()
 }
 
 // SetTextWrapSquare sets the text wrap to square with a given wrap type.
 func (a AnchoredDrawing) SetTextWrapSquare(t wml.WdST_WrapText) {
+	a.x.BehindDocAttr = false
 	a.x.Choice = &wml.WdEG_WrapTypeChoice{}
 	a.x.Choice.WrapSquare = wml.NewWdCT_WrapSquare()
 	a.x.Choice.WrapSquare.WrapTextAttr = t
 }
+
+// SetTextWrapTopAndBottom sets the text wrap so that text is only placed above
+// and below the image, leaving the space to either side of it empty.
+func (a AnchoredDrawing) SetTextWrapTopAndBottom() {
+	a.x.BehindDocAttr = false
+	a.x.Choice = &wml.WdEG_WrapTypeChoice{}
+	a.x.Choice.WrapTopAndBottom = wml.NewWdCT_WrapTopBottom()
+}
+
+// SetTextWrapBehindText places the image behind the text without wrapping it,
+// e.g. for a watermark.
+func (a AnchoredDrawing) SetTextWrapBehindText() {
+	a.SetTextWrapNone()
+	a.x.BehindDocAttr = true
+}
+
+// SetTextWrapInFrontOfText places the image in front of the text without
+// wrapping it.  It is equivalent to SetTextWrapNone.
+func (a AnchoredDrawing) SetTextWrapInFrontOfText() {
+	a.SetTextWrapNone()
+}
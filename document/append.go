@@ -0,0 +1,669 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+// Append appends the paragraphs and tables of other to the end of the
+// document, separated by a section break.  The appended section keeps the page
+// settings of other.
+//
+// The styles, numbering definitions, images, hyperlinks, footnotes, endnotes
+// and comments that the appended content refers to are copied along with it
+// and are given new IDs where they would collide with those of the document.
+// Styles that are defined identically in both documents are shared.  The
+// headers and footers of other aren't copied, so the appended section uses
+// those of the document.  An error is returned if the appended content refers
+// to any other kind of part, such as a chart, as it can't be copied.
+func (d *Document) Append(other *Document) error {
+	if other == nil {
+		return errors.New("document to append must not be nil")
+	}
+	if other == d {
+		return errors.New("can't append a document to itself")
+	}
+	m := newDocMerger(d, other)
+	if err := m.copyStyles(); err != nil {
+		return fmt.Errorf("error copying styles: %s", err)
+	}
+
+	body := wml.NewCT_Body()
+	if err := copyXML(other.x.Body, body, m.rewrite); err != nil {
+		return fmt.Errorf("error copying document body: %s", err)
+	}
+	if m.err != nil {
+		return m.err
+	}
+	m.copyCommentsEx()
+
+	// the relationships to the headers and footers of other aren't copied
+	for _, ble := range body.EG_BlockLevelElts {
+		for _, c := range ble.EG_ContentBlockContent {
+			for _, p := range c.P {
+				if p.PPr != nil && p.PPr.SectPr != nil {
+					p.PPr.SectPr.EG_HdrFtrReferences = nil
+				}
+			}
+		}
+	}
+
+	if len(d.x.Body.EG_BlockLevelElts) > 0 {
+		d.AddSection()
+	}
+	d.x.Body.EG_BlockLevelElts = append(d.x.Body.EG_BlockLevelElts, body.EG_BlockLevelElts...)
+	if body.SectPr != nil {
+		body.SectPr.EG_HdrFtrReferences = nil
+		// the appended content always starts on a new page
+		body.SectPr.Type = nil
+		d.x.Body.SectPr = body.SectPr
+	}
+	// the appended content may use annotation IDs beyond those already found
+	d.annotationIDSeeded = false
+	return nil
+}
+
+// docMerger copies content from src into d, remapping the IDs that the
+// content uses to refer to other parts of the document.
+type docMerger struct {
+	d, src *Document
+
+	styles       map[string]string
+	styleIDs     map[string]struct{}
+	nums         map[int64]int64
+	abstractNums map[int64]int64
+	rels         map[string]string
+	footnotes    map[int64]int64
+	endnotes     map[int64]int64
+	comments     map[int64]int64
+	paraIDs      map[string]string
+	newParaID    func() string
+
+	lastDocPrID    int64
+	bookmarkOffset int64
+	err            error
+}
+
+func newDocMerger(d, src *Document) *docMerger {
+	m := &docMerger{
+		d:            d,
+		src:          src,
+		styles:       map[string]string{},
+		styleIDs:     map[string]struct{}{},
+		nums:         map[int64]int64{},
+		abstractNums: map[int64]int64{},
+		rels:         map[string]string{},
+		footnotes:    map[int64]int64{},
+		endnotes:     map[int64]int64{},
+		comments:     map[int64]int64{},
+		paraIDs:      map[string]string{},
+		newParaID:    d.newParaIDs(),
+	}
+
+	// drawing IDs must be unique throughout the document and bookmark IDs
+	// throughout the body
+	maxBookmark := int64(-1)
+	for _, part := range d.storyParts() {
+		walkXML(part, func(el *xml.StartElement) {
+			switch {
+			case el.Name.Space == "wp" && el.Name.Local == "docPr":
+				if id, err := strconv.ParseInt(attrValue(el, "id"), 10, 64); err == nil && id > m.lastDocPrID {
+					m.lastDocPrID = id
+				}
+			case el.Name.Space == "w" && el.Name.Local == "bookmarkStart":
+				if id, err := strconv.ParseInt(attrValue(el, "id"), 10, 64); err == nil && id > maxBookmark {
+					maxBookmark = id
+				}
+			}
+		})
+	}
+	m.bookmarkOffset = maxBookmark + 1
+	for _, s := range d.Styles.X().Style {
+		if s.StyleIdAttr != nil {
+			m.styleIDs[*s.StyleIdAttr] = struct{}{}
+		}
+	}
+	for _, s := range src.Styles.X().Style {
+		if s.StyleIdAttr != nil {
+			m.styleIDs[*s.StyleIdAttr] = struct{}{}
+		}
+	}
+	return m
+}
+
+// storyParts returns the parts of the document that contain content.
+func (d *Document) storyParts() []interface{} {
+	ret := []interface{}{d.x.Body}
+	for _, h := range d.headers {
+		ret = append(ret, h)
+	}
+	for _, f := range d.footers {
+		ret = append(ret, f)
+	}
+	if d.footNotes != nil {
+		ret = append(ret, d.footNotes)
+	}
+	if d.endNotes != nil {
+		ret = append(ret, d.endNotes)
+	}
+	return ret
+}
+
+// copyStyles copies the styles used by the source document body and notes,
+// along with the styles they are based on, linked to or followed by.
+func (m *docMerger) copyStyles() error {
+	srcStyles := map[string]*wml.CT_Style{}
+	for _, s := range m.src.Styles.X().Style {
+		if s.StyleIdAttr != nil {
+			srcStyles[*s.StyleIdAttr] = s
+		}
+	}
+
+	used := map[string]bool{}
+	queue := []string{}
+	use := func(id string) {
+		if _, ok := srcStyles[id]; ok && !used[id] {
+			used[id] = true
+			queue = append(queue, id)
+		}
+	}
+	parts := []interface{}{m.src.x.Body}
+	if m.src.footNotes != nil {
+		parts = append(parts, m.src.footNotes)
+	}
+	if m.src.endNotes != nil {
+		parts = append(parts, m.src.endNotes)
+	}
+	if m.src.comments != nil {
+		parts = append(parts, m.src.comments)
+	}
+	for _, part := range parts {
+		if _, err := walkXML(part, func(el *xml.StartElement) {
+			if el.Name.Space != "w" {
+				return
+			}
+			switch el.Name.Local {
+			case "pStyle", "rStyle", "tblStyle":
+				use(attrValue(el, "val"))
+			}
+		}); err != nil {
+			return err
+		}
+	}
+	for len(queue) > 0 {
+		s := srcStyles[queue[0]]
+		queue = queue[1:]
+		for _, ref := range []*wml.CT_String{s.BasedOn, s.Link, s.Next} {
+			if ref != nil {
+				use(ref.ValAttr)
+			}
+		}
+	}
+
+	toCopy := []*wml.CT_Style{}
+	for _, s := range m.src.Styles.X().Style {
+		if s.StyleIdAttr == nil || !used[*s.StyleIdAttr] {
+			continue
+		}
+		id := *s.StyleIdAttr
+		if existing, ok := m.d.Styles.StyleByID(id); ok {
+			same, err := sameXML(existing.X(), s)
+			if err != nil {
+				return err
+			}
+			if same {
+				m.styles[id] = id
+				continue
+			}
+			m.styles[id] = m.uniqueStyleID(id)
+		} else {
+			m.styles[id] = id
+		}
+		toCopy = append(toCopy, s)
+	}
+
+	// copy once all of the IDs are known so that the references between the
+	// copied styles are mapped
+	for _, s := range toCopy {
+		cp := wml.NewCT_Style()
+		if err := copyXML(s, cp, m.rewrite); err != nil {
+			return err
+		}
+		cp.DefaultAttr = nil
+		oldID := *s.StyleIdAttr
+		newID := m.styles[oldID]
+		cp.StyleIdAttr = &newID
+		if newID != oldID && cp.Name != nil {
+			cp.Name.ValAttr += " " + strings.TrimPrefix(newID, oldID)
+		}
+		m.d.Styles.X().Style = append(m.d.Styles.X().Style, cp)
+	}
+	return m.err
+}
+
+// uniqueStyleID returns a style ID, based on id, that isn't used by either
+// document.
+func (m *docMerger) uniqueStyleID(id string) string {
+	for i := 2; ; i++ {
+		cand := fmt.Sprintf("%s%d", id, i)
+		if _, ok := m.styleIDs[cand]; !ok {
+			m.styleIDs[cand] = struct{}{}
+			return cand
+		}
+	}
+}
+
+// rewrite maps the IDs referred to by an element of the source document to
+// those of the destination document.
+func (m *docMerger) rewrite(el *xml.StartElement) {
+	switch el.Name.Space {
+	case "w":
+		switch el.Name.Local {
+		case "pStyle", "rStyle", "tblStyle", "basedOn", "link", "next":
+			mapAttr(el, "val", m.style)
+		case "numId":
+			mapAttr(el, "val", m.numID)
+		case "footnoteReference":
+			mapAttr(el, "id", m.footnoteID)
+		case "endnoteReference":
+			mapAttr(el, "id", m.endnoteID)
+		case "commentRangeStart", "commentRangeEnd", "commentReference":
+			mapAttr(el, "id", m.commentID)
+		case "bookmarkStart", "bookmarkEnd":
+			mapAttr(el, "id", func(v string) string {
+				id, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return v
+				}
+				return strconv.FormatInt(id+m.bookmarkOffset, 10)
+			})
+		}
+	case "wp":
+		if el.Name.Local == "docPr" {
+			mapAttr(el, "id", func(string) string {
+				m.lastDocPrID++
+				return strconv.FormatInt(m.lastDocPrID, 10)
+			})
+		}
+	}
+	for i, a := range el.Attr {
+		switch {
+		case a.Name.Space == "r":
+			el.Attr[i].Value = m.relID(a.Value)
+		case a.Name.Space == "w14" && a.Name.Local == "paraId":
+			el.Attr[i].Value = m.paraID(a.Value)
+		}
+	}
+}
+
+// paraID gives each copied paragraph a paragraph ID that isn't used in the
+// document.
+func (m *docMerger) paraID(v string) string {
+	if id, ok := m.paraIDs[v]; ok {
+		return id
+	}
+	id := m.newParaID()
+	m.paraIDs[v] = id
+	return id
+}
+
+// copyCommentsEx copies the entries of the commentsExtended part for the
+// comments that were copied, so that replies stay in their threads.
+func (m *docMerger) copyCommentsEx() {
+	if m.src.commentsEx == nil {
+		return
+	}
+	for _, ex := range m.src.commentsEx.Comments {
+		id, ok := m.paraIDs[ex.ParaID]
+		if !ok {
+			continue
+		}
+		cp := &commentEx{ParaID: id, Done: ex.Done}
+		if ex.ParaIDParent != "" {
+			parent, ok := m.paraIDs[ex.ParaIDParent]
+			if !ok {
+				continue
+			}
+			cp.ParaIDParent = parent
+		}
+		m.d.ensureCommentsEx()
+		m.d.commentsEx.Comments = append(m.d.commentsEx.Comments, cp)
+	}
+}
+
+func (m *docMerger) style(id string) string {
+	if v, ok := m.styles[id]; ok {
+		return v
+	}
+	return id
+}
+
+// numID copies the numbering definition with the given ID, and its abstract
+// numbering definition, the first time it is used.
+func (m *docMerger) numID(v string) string {
+	old, err := strconv.ParseInt(v, 10, 64)
+	// zero removes numbering
+	if err != nil || old == 0 || m.src.Numbering.X() == nil {
+		return v
+	}
+	if id, ok := m.nums[old]; ok {
+		return strconv.FormatInt(id, 10)
+	}
+	var num *wml.CT_Num
+	for _, n := range m.src.Numbering.X().Num {
+		if n.NumIdAttr == old {
+			num = n
+		}
+	}
+	if num == nil || num.AbstractNumId == nil {
+		return v
+	}
+
+	if m.d.Numbering.X() == nil {
+		m.d.Numbering = NewNumbering()
+		m.d.ContentTypes.AddOverride("/word/numbering.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml")
+		m.d.docRels.AddRelationship("numbering.xml", unioffice.NumberingType)
+	}
+	dst := m.d.Numbering.X()
+	absID, ok := m.abstractNums[num.AbstractNumId.ValAttr]
+	if !ok {
+		absID = 0
+		for _, an := range dst.AbstractNum {
+			if an.AbstractNumIdAttr >= absID {
+				absID = an.AbstractNumIdAttr + 1
+			}
+		}
+		for _, an := range m.src.Numbering.X().AbstractNum {
+			if an.AbstractNumIdAttr != num.AbstractNumId.ValAttr {
+				continue
+			}
+			cp := wml.NewCT_AbstractNum()
+			if err := copyXML(an, cp, m.rewrite); err != nil {
+				m.err = err
+				return v
+			}
+			cp.AbstractNumIdAttr = absID
+			dst.AbstractNum = append(dst.AbstractNum, cp)
+		}
+		m.abstractNums[num.AbstractNumId.ValAttr] = absID
+	}
+
+	cp := wml.NewCT_Num()
+	if err := copyXML(num, cp, m.rewrite); err != nil {
+		m.err = err
+		return v
+	}
+	cp.NumIdAttr = m.d.Numbering.nextNumID()
+	cp.AbstractNumId.ValAttr = absID
+	dst.Num = append(dst.Num, cp)
+	m.nums[old] = cp.NumIdAttr
+	return strconv.FormatInt(cp.NumIdAttr, 10)
+}
+
+// relID copies the target of a relationship from the source document's main
+// part the first time it is used.  The notes and comments of the document
+// refer to the relationships of the main part too, as they don't have their
+// own.
+func (m *docMerger) relID(v string) string {
+	if id, ok := m.rels[v]; ok {
+		return id
+	}
+	newID := v
+	found := false
+	for _, rel := range m.src.docRels.Relationships() {
+		if rel.ID() != v {
+			continue
+		}
+		found = true
+		switch rel.Type() {
+		case unioffice.ImageType, unioffice.ImageTypeStrict:
+			iref, ok := m.src.GetImageByRelID(v)
+			if !ok {
+				unioffice.Log("image %s not found", v)
+				break
+			}
+			img := common.Image{Size: iref.Size(), Format: iref.Format(), Path: iref.Path(), Data: iref.Data()}
+			cp, err := m.d.AddImage(img)
+			if err != nil {
+				m.err = fmt.Errorf("error copying image: %s", err)
+				break
+			}
+			newID = cp.RelID()
+		case unioffice.HyperLinkType:
+			newID = common.Relationship(m.d.docRels.AddHyperlink(rel.Target())).ID()
+		case unioffice.HeaderType, unioffice.FooterType:
+			// headers and footers aren't copied, and their references are
+			// removed from the appended content
+		default:
+			// keeping the ID would refer to an unrelated part of the document
+			if m.err == nil {
+				m.err = fmt.Errorf("can't copy relationship %s of type %s", v, rel.Type())
+			}
+		}
+	}
+	if !found && m.err == nil {
+		m.err = fmt.Errorf("relationship %s not found", v)
+	}
+	m.rels[v] = newID
+	return newID
+}
+
+func (m *docMerger) footnoteID(v string) string {
+	if m.src.footNotes == nil {
+		return v
+	}
+	return m.noteID(v, m.src.footNotes.Footnote, m.footnotes, func(cp *wml.CT_FtnEdn) {
+		m.d.ensureFootnotes()
+		cp.IdAttr = newNote(m.d.footNotes.Footnote).IdAttr
+		m.d.footNotes.Footnote = append(m.d.footNotes.Footnote, cp)
+	})
+}
+
+func (m *docMerger) endnoteID(v string) string {
+	if m.src.endNotes == nil {
+		return v
+	}
+	return m.noteID(v, m.src.endNotes.Endnote, m.endnotes, func(cp *wml.CT_FtnEdn) {
+		m.d.ensureEndnotes()
+		cp.IdAttr = newNote(m.d.endNotes.Endnote).IdAttr
+		m.d.endNotes.Endnote = append(m.d.endNotes.Endnote, cp)
+	})
+}
+
+// noteID copies the footnote or endnote with the given ID the first time it is
+// referred to, with add assigning the new ID and storing the copy.
+func (m *docMerger) noteID(v string, notes []*wml.CT_FtnEdn, ids map[int64]int64, add func(cp *wml.CT_FtnEdn)) string {
+	old, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return v
+	}
+	if id, ok := ids[old]; ok {
+		return strconv.FormatInt(id, 10)
+	}
+	for _, n := range notes {
+		if n.IdAttr != old {
+			continue
+		}
+		cp := wml.NewCT_FtnEdn()
+		if err := copyXML(n, cp, m.rewrite); err != nil {
+			m.err = err
+			return v
+		}
+		add(cp)
+		ids[old] = cp.IdAttr
+		return strconv.FormatInt(cp.IdAttr, 10)
+	}
+	return v
+}
+
+// commentID copies the comment with the given ID the first time its range or
+// reference is found.
+func (m *docMerger) commentID(v string) string {
+	old, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || m.src.comments == nil {
+		return v
+	}
+	if id, ok := m.comments[old]; ok {
+		return strconv.FormatInt(id, 10)
+	}
+	for _, c := range m.src.comments.Comment {
+		if c.IdAttr != old {
+			continue
+		}
+		cp := wml.NewCT_Comment()
+		if err := copyXML(c, cp, m.rewrite); err != nil {
+			m.err = err
+			return v
+		}
+		m.d.ensureComments()
+		cp.IdAttr = m.d.nextCommentID()
+		m.d.comments.Comment = append(m.d.comments.Comment, cp)
+		m.comments[old] = cp.IdAttr
+		return strconv.FormatInt(cp.IdAttr, 10)
+	}
+	return v
+}
+
+func attrValue(el *xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func mapAttr(el *xml.StartElement, local string, fn func(string) string) {
+	for i, a := range el.Attr {
+		if a.Name.Local == local {
+			el.Attr[i].Value = fn(a.Value)
+		}
+	}
+}
+
+// copyNamespaces are declared on the root element when marshaling part of a
+// document so that the prefixed element names can be unmarshaled again.
+var copyNamespaces = []xml.Attr{
+	{Name: xml.Name{Local: "xmlns"}, Value: "http://schemas.openxmlformats.org/wordprocessingml/2006/main"},
+	{Name: xml.Name{Local: "xmlns:a"}, Value: "http://schemas.openxmlformats.org/drawingml/2006/main"},
+	{Name: xml.Name{Local: "xmlns:m"}, Value: "http://schemas.openxmlformats.org/officeDocument/2006/math"},
+	{Name: xml.Name{Local: "xmlns:ma"}, Value: "http://schemas.openxmlformats.org/schemaLibrary/2006/main"},
+	{Name: xml.Name{Local: "xmlns:pic"}, Value: "http://schemas.openxmlformats.org/drawingml/2006/picture"},
+	{Name: xml.Name{Local: "xmlns:r"}, Value: "http://schemas.openxmlformats.org/officeDocument/2006/relationships"},
+	{Name: xml.Name{Local: "xmlns:s"}, Value: "http://schemas.openxmlformats.org/officeDocument/2006/sharedTypes"},
+	{Name: xml.Name{Local: "xmlns:w"}, Value: "http://schemas.openxmlformats.org/wordprocessingml/2006/main"},
+	{Name: xml.Name{Local: "xmlns:w14"}, Value: "http://schemas.microsoft.com/office/word/2010/wordml"},
+	{Name: xml.Name{Local: "xmlns:wp"}, Value: "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"},
+}
+
+// walkXML marshals v, calling fn with each element so that it can modify its
+// attributes, and returns the resulting XML.  The elements are identified by
+// the prefixes that the schema types are marshaled with, e.g. 'w:p'.  The
+// element for v itself is named 'w:root', so its attributes must be mapped by
+// the caller.
+func walkXML(v interface{}, fn func(el *xml.StartElement)) ([]byte, error) {
+	buf := bytes.Buffer{}
+	enc := xml.NewEncoder(&buf)
+	start := xml.StartElement{Name: xml.Name{Local: "w:root"}, Attr: copyNamespaces}
+	if err := enc.EncodeElement(v, start); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+
+	name := func(n xml.Name) string {
+		if n.Space == "" {
+			return n.Local
+		}
+		return n.Space + ":" + n.Local
+	}
+	out := bytes.Buffer{}
+	dec := xml.NewDecoder(&buf)
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			t = t.Copy()
+			if fn != nil {
+				fn(&t)
+			}
+			out.WriteString("<" + name(t.Name))
+			for _, a := range t.Attr {
+				out.WriteString(" " + name(a.Name) + `="`)
+				xml.EscapeText(&out, []byte(a.Value))
+				out.WriteString(`"`)
+			}
+			out.WriteString(">")
+		case xml.EndElement:
+			out.WriteString("</" + name(t.Name) + ">")
+		case xml.CharData:
+			xml.EscapeText(&out, t)
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// copyXML deep copies src into dst, calling fn with each element so that it
+// can modify its attributes, see walkXML.
+func copyXML(src, dst interface{}, fn func(el *xml.StartElement)) error {
+	b, err := walkXML(src, fn)
+	if err != nil {
+		return err
+	}
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	start, ok := tok.(xml.StartElement)
+	if !ok {
+		return fmt.Errorf("expected a start element, got %T", tok)
+	}
+	// the namespace declarations are dropped as the generated types match
+	// attributes by local name only, so xmlns:w would be read as the w
+	// attribute of w:pgSz
+	attrs := start.Attr[:0]
+	for _, a := range start.Attr {
+		if a.Name.Space != "xmlns" && a.Name.Local != "xmlns" {
+			attrs = append(attrs, a)
+		}
+	}
+	start.Attr = attrs
+	return dec.DecodeElement(dst, &start)
+}
+
+// sameXML returns true if a and b marshal to the same XML.
+func sameXML(a, b interface{}) (bool, error) {
+	ax, err := walkXML(a, nil)
+	if err != nil {
+		return false, err
+	}
+	bx, err := walkXML(b, nil)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ax, bx), nil
+}
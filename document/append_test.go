@@ -0,0 +1,296 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/document"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+func newAppendTestDoc(t *testing.T, font, imgFile, text string) *document.Document {
+	doc := document.New()
+	caption := doc.Styles.AddStyle("Caption", wml.ST_StyleTypeParagraph, false)
+	caption.SetName("Caption")
+	caption.SetBasedOn("Normal")
+	caption.RunProperties().SetFontFamily(font)
+
+	img, err := common.ImageFromFile(imgFile)
+	if err != nil {
+		t.Fatalf("unable to create image: %s", err)
+	}
+	iref, err := doc.AddImage(img)
+	if err != nil {
+		t.Fatalf("unable to add image to doc: %s", err)
+	}
+	inline, err := doc.AddParagraph().AddRun().AddDrawingInline(iref)
+	if err != nil {
+		t.Fatalf("unable to add inline image: %s", err)
+	}
+	inline.SetSize(measurement.Inch, measurement.Inch)
+
+	p := doc.AddParagraph()
+	p.SetStyle("Caption")
+	p.AddRun().AddText(text)
+	return doc
+}
+
+func TestAppend(t *testing.T) {
+	doc := newAppendTestDoc(t, "Arial", "testdata/gopher.png", "first")
+	other := newAppendTestDoc(t, "Courier New", "testdata/gophercolor.png", "second")
+	quote := other.Styles.AddStyle("Quote", wml.ST_StyleTypeParagraph, false)
+	quote.SetName("Quote")
+	quote.RunProperties().SetItalic(true)
+	p := other.AddParagraph()
+	p.SetStyle("Quote")
+	p.AddRun().AddText("quoted")
+
+	if err := doc.Append(other); err != nil {
+		t.Fatalf("error appending document: %s", err)
+	}
+	if err := doc.Append(doc); err == nil {
+		t.Errorf("expected an error appending a document to itself")
+	}
+	if err := doc.Validate(); err != nil {
+		t.Errorf("created an invalid document: %s", err)
+	}
+
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving document: %s", err)
+	}
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading document: %s", err)
+	}
+
+	paras := doc2.Paragraphs()
+	// image, caption, section break, image, caption, quote
+	if len(paras) != 6 {
+		t.Fatalf("expected 6 paragraphs, got %d", len(paras))
+	}
+	if paras[2].X().PPr == nil || paras[2].X().PPr.SectPr == nil {
+		t.Errorf("expected a section break between the documents")
+	}
+
+	if got := paras[1].Style(); got != "Caption" {
+		t.Errorf("expected style Caption, got %s", got)
+	}
+	if got := paras[4].Style(); got != "Caption2" {
+		t.Errorf("expected the conflicting style to be renamed to Caption2, got %s", got)
+	}
+	if got := paras[5].Style(); got != "Quote" {
+		t.Errorf("expected style Quote, got %s", got)
+	}
+	if got := paras[4].Runs()[0].Text(); got != "second" {
+		t.Errorf("expected appended text 'second', got %s", got)
+	}
+	renamed, ok := doc2.Styles.StyleByID("Caption2")
+	if !ok {
+		t.Fatalf("expected the renamed style to be copied")
+	}
+	if renamed.Name() == "Caption" {
+		t.Errorf("expected the renamed style to have a distinct name")
+	}
+	if rf := renamed.X().RPr.RFonts; rf == nil || *rf.AsciiAttr != "Courier New" {
+		t.Errorf("expected the renamed style to keep its font")
+	}
+	if _, ok := doc2.Styles.StyleByID("Quote"); !ok {
+		t.Errorf("expected the Quote style to be copied")
+	}
+
+	if len(doc2.Images) != 2 {
+		t.Fatalf("expected 2 images, got %d", len(doc2.Images))
+	}
+	first := paras[0].Runs()[0].DrawingInline()[0]
+	second := paras[3].Runs()[0].DrawingInline()[0]
+	img1, ok1 := first.GetImage()
+	img2, ok2 := second.GetImage()
+	if !ok1 || !ok2 {
+		t.Fatalf("expected to find both images")
+	}
+	if img1.RelID() == img2.RelID() {
+		t.Errorf("expected the images to have distinct relationships")
+	}
+	if img1.Size() == img2.Size() {
+		t.Errorf("expected the appended image to refer to the appended image data")
+	}
+	if first.X().DocPr.IdAttr == second.X().DocPr.IdAttr {
+		t.Errorf("expected unique drawing IDs, got %d twice", first.X().DocPr.IdAttr)
+	}
+}
+
+func TestAppendComments(t *testing.T) {
+	doc := document.New()
+	run := doc.AddParagraph().AddRun()
+	run.AddText("dst")
+	run.AddComment("Jane Doe", "dst comment")
+	other := document.New()
+	run = other.AddParagraph().AddRun()
+	run.AddText("src")
+	run.AddComment("John Smith", "src comment")
+
+	if err := doc.Append(other); err != nil {
+		t.Fatalf("error appending document: %s", err)
+	}
+	cmts := doc.Comments()
+	if len(cmts) != 2 {
+		t.Fatalf("expected 2 comments, got %d", len(cmts))
+	}
+	if cmts[0].ID() == cmts[1].ID() {
+		t.Errorf("expected the appended comment to get a new ID, got %d twice", cmts[0].ID())
+	}
+	byID := map[int64]string{}
+	for _, c := range cmts {
+		byID[c.ID()] = c.Text()
+	}
+
+	paras := doc.Paragraphs()
+	exp := map[string]string{"dst": "dst comment", "src": "src comment"}
+	for _, p := range paras {
+		text := ""
+		ids := []int64{}
+		for _, pc := range p.X().EG_PContent {
+			for _, rc := range pc.EG_ContentRunContent {
+				for _, rle := range rc.EG_RunLevelElts {
+					for _, m := range rle.EG_RangeMarkupElements {
+						if m.CommentRangeStart != nil {
+							ids = append(ids, m.CommentRangeStart.IdAttr)
+						}
+					}
+				}
+				if rc.R == nil {
+					continue
+				}
+				for _, ic := range rc.R.EG_RunInnerContent {
+					if ic.T != nil {
+						text += ic.T.Content
+					}
+					if ic.CommentReference != nil {
+						ids = append(ids, ic.CommentReference.IdAttr)
+					}
+				}
+			}
+		}
+		if _, ok := exp[text]; !ok {
+			continue
+		}
+		if len(ids) != 2 || ids[0] != ids[1] {
+			t.Fatalf("expected a comment range and reference around %s, got %v", text, ids)
+		}
+		if got := byID[ids[0]]; got != exp[text] {
+			t.Errorf("expected %s to refer to %q, got %q", text, exp[text], got)
+		}
+		delete(exp, text)
+	}
+	if len(exp) != 0 {
+		t.Errorf("expected to find commented paragraphs %v", exp)
+	}
+}
+
+func TestAppendCommentReplies(t *testing.T) {
+	doc := document.New()
+	run := doc.AddParagraph().AddRun()
+	run.AddText("dst")
+	run.AddComment("Jane Doe", "dst comment").AddReply("John Smith", "dst reply")
+	other := document.New()
+	run = other.AddParagraph().AddRun()
+	run.AddText("src")
+	run.AddComment("John Smith", "src comment").AddReply("Jane Doe", "src reply")
+
+	if err := doc.Append(other); err != nil {
+		t.Fatalf("error appending document: %s", err)
+	}
+	cmts := doc.Comments()
+	if len(cmts) != 4 {
+		t.Fatalf("expected 4 comments, got %d", len(cmts))
+	}
+	for _, tc := range []struct {
+		Comment, Reply string
+	}{
+		{"dst comment", "dst reply"},
+		{"src comment", "src reply"},
+	} {
+		for _, c := range cmts {
+			if c.Text() != tc.Comment {
+				continue
+			}
+			if replies := c.Replies(); len(replies) != 1 || replies[0].Text() != tc.Reply {
+				t.Errorf("expected %q to have the reply %q, got %d replies", tc.Comment, tc.Reply, len(replies))
+			}
+		}
+	}
+}
+
+func TestAppendNoteRelationships(t *testing.T) {
+	doc := document.New()
+	doc.AddParagraph().AddHyperLink().SetTarget("http://example.com/dst")
+	other := document.New()
+	run := other.AddParagraph().AddRun()
+	run.AddText("src")
+	hl := run.AddFootnote("see").AddParagraph().AddHyperLink()
+	hl.SetTarget("http://example.com/note")
+	hl.AddRun().AddText("note link")
+
+	if err := doc.Append(other); err != nil {
+		t.Fatalf("error appending document: %s", err)
+	}
+	var id string
+	for _, fn := range doc.Footnotes() {
+		for _, p := range fn.Paragraphs() {
+			for _, pc := range p.X().EG_PContent {
+				if pc.Hyperlink != nil && pc.Hyperlink.IdAttr != nil {
+					id = *pc.Hyperlink.IdAttr
+				}
+			}
+		}
+	}
+	if id == "" {
+		t.Fatalf("expected the footnote hyperlink to be copied")
+	}
+
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving document: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "word/_rels/document.xml.rels" {
+			continue
+		}
+		rc, _ := f.Open()
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if exp := `Target="http://example.com/note" Type="` + unioffice.HyperLinkType + `" Id="` + id + `"`; !strings.Contains(string(b), exp) {
+			t.Errorf("expected the footnote hyperlink %s to target the note link, got %s", id, b)
+		}
+	}
+}
+
+func TestAppendUncopiedRelationship(t *testing.T) {
+	for _, id := range []string{"rId1", "rId99"} {
+		doc := document.New()
+		other := document.New()
+		// rId1 is the settings part, which can't be copied
+		other.AddParagraph().AddHyperLink().X().IdAttr = unioffice.String(id)
+		if err := doc.Append(other); err == nil {
+			t.Errorf("expected an error appending a reference to %s", id)
+		}
+	}
+}
@@ -7,7 +7,17 @@
 
 package document
 
-import "github.com/unidoc/unioffice/schema/soo/wml"
+import (
+	"errors"
+	"fmt"
+	"unicode"
+
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+// maxBookmarkNameLength is the maximum length of a bookmark name allowed by
+// Word.
+const maxBookmarkNameLength = 40
 
 // Bookmark is a bookmarked location within a document that can be referenced
 // with a hyperlink.
@@ -31,3 +41,32 @@ func (b Bookmark) SetName(name string) {
 func (b Bookmark) Name() string {
 	return b.x.NameAttr
 }
+
+// ID returns the document unique numeric ID of the bookmark which pairs the
+// start of the bookmark with its end.
+func (b Bookmark) ID() int64 {
+	return b.x.IdAttr
+}
+
+// ValidateBookmarkName returns an error if the name can't be used as a
+// bookmark name in Word.  Names must start with a letter and contain only
+// letters, digits and underscores, and be no longer than 40 characters.  Names
+// starting with an underscore are also accepted as Word uses them for hidden
+// bookmarks.
+func ValidateBookmarkName(name string) error {
+	if name == "" {
+		return errors.New("bookmark name must not be empty")
+	}
+	if len([]rune(name)) > maxBookmarkNameLength {
+		return fmt.Errorf("bookmark name must not exceed %d characters", maxBookmarkNameLength)
+	}
+	for i, c := range name {
+		switch {
+		case unicode.IsLetter(c), c == '_':
+		case i > 0 && unicode.IsDigit(c):
+		default:
+			return fmt.Errorf("invalid character %q in bookmark name %s", c, name)
+		}
+	}
+	return nil
+}
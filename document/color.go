@@ -45,3 +45,9 @@ func (c Color) SetThemeShade(s uint8) {
 	shd := fmt.Sprintf("%02x", s)
 	c.x.ThemeShadeAttr = &shd
 }
+
+// SetThemeTint sets the tint based off the theme color.
+func (c Color) SetThemeTint(t uint8) {
+	tnt := fmt.Sprintf("%02x", t)
+	c.x.ThemeTintAttr = &tnt
+}
@@ -0,0 +1,514 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+// Comment is a review comment on a range of the document.
+type Comment struct {
+	d *Document
+	x *wml.CT_Comment
+}
+
+// X returns the inner wrapped XML type.
+func (c Comment) X() *wml.CT_Comment {
+	return c.x
+}
+
+// ID returns the comment ID that the comment range and reference refer to.
+func (c Comment) ID() int64 {
+	return c.x.IdAttr
+}
+
+// Author returns the author of the comment.
+func (c Comment) Author() string {
+	return c.x.AuthorAttr
+}
+
+// Date returns the time the comment was made, or the zero time if it is unset.
+func (c Comment) Date() time.Time {
+	if c.x.DateAttr == nil {
+		return time.Time{}
+	}
+	return *c.x.DateAttr
+}
+
+// Paragraphs returns the paragraphs of the comment.
+func (c Comment) Paragraphs() []Paragraph {
+	ret := []Paragraph{}
+	for _, ble := range c.x.EG_BlockLevelElts {
+		for _, bc := range ble.EG_ContentBlockContent {
+			for _, p := range bc.P {
+				ret = append(ret, Paragraph{c.d, p})
+			}
+		}
+	}
+	return ret
+}
+
+// AddParagraph adds a paragraph to the comment.
+func (c Comment) AddParagraph() Paragraph {
+	ble := wml.NewEG_BlockLevelElts()
+	c.x.EG_BlockLevelElts = append(c.x.EG_BlockLevelElts, ble)
+	bc := wml.NewEG_ContentBlockContent()
+	ble.EG_ContentBlockContent = append(ble.EG_ContentBlockContent, bc)
+	p := wml.NewCT_P()
+	bc.P = append(bc.P, p)
+	return Paragraph{c.d, p}
+}
+
+// Text returns the text of the comment, with paragraphs separated by newlines.
+func (c Comment) Text() string {
+	paras := []string{}
+	for _, p := range c.Paragraphs() {
+		buf := strings.Builder{}
+		for _, r := range p.Runs() {
+			buf.WriteString(r.Text())
+		}
+		paras = append(paras, buf.String())
+	}
+	return strings.Join(paras, "\n")
+}
+
+// AddReply adds a reply to the comment, which Word shows in a thread below
+// it.  The reply is recorded in the commentsExtended part, which is created if
+// necessary, and its range is the same as that of the comment.  As Word
+// doesn't nest replies, a reply to a reply is added to the thread of the
+// comment that started it.
+func (c Comment) AddReply(author, text string) Comment {
+	reply := c.d.addComment(author, text)
+	if _, _, ok := c.d.findCommentContent(c.ID(), isCommentStart); !ok {
+		unioffice.Log("comment %d isn't in the document, reply %d won't be shown", c.ID(), reply.ID())
+		return reply
+	}
+	ends, ei, ok := c.d.findCommentContent(c.ID(), isCommentReference)
+	if !ok {
+		ends, ei, ok = c.d.findCommentContent(c.ID(), isCommentEnd)
+	}
+	if !ok {
+		unioffice.Log("comment %d has no end, reply %d won't be shown", c.ID(), reply.ID())
+		return reply
+	}
+
+	ei = skipRunContent(ends, ei+1, func(rc *wml.EG_ContentRunContent) bool {
+		return isCommentEnd(rc, -1) || isCommentReference(rc, -1)
+	})
+	ends.insert(ei, commentMarker(reply.ID(), true), commentReference(reply.ID()))
+	// found again as inserting the end may have moved the start
+	starts, si, _ := c.d.findCommentContent(c.ID(), isCommentStart)
+	si = skipRunContent(starts, si+1, func(rc *wml.EG_ContentRunContent) bool {
+		return isCommentStart(rc, -1)
+	})
+	starts.insert(si, commentMarker(reply.ID(), false))
+
+	c.d.ensureCommentsEx()
+	ids := c.d.newParaIDs()
+	parent := c.paraID(ids)
+	if ex := c.d.commentsEx.find(parent); ex == nil {
+		c.d.commentsEx.Comments = append(c.d.commentsEx.Comments, &commentEx{ParaID: parent})
+	} else if ex.ParaIDParent != "" {
+		parent = ex.ParaIDParent
+	}
+	c.d.commentsEx.Comments = append(c.d.commentsEx.Comments, &commentEx{
+		ParaID:       reply.paraID(ids),
+		ParaIDParent: parent,
+	})
+	return reply
+}
+
+// Replies returns the replies to the comment recorded in the commentsExtended
+// part, in the order they were made.
+func (c Comment) Replies() []Comment {
+	ret := []Comment{}
+	id := c.lastParaID()
+	if c.d.commentsEx == nil || id == "" {
+		return ret
+	}
+	byParaID := map[string]Comment{}
+	for _, cmt := range c.d.Comments() {
+		if pid := cmt.lastParaID(); pid != "" {
+			byParaID[pid] = cmt
+		}
+	}
+	for _, ex := range c.d.commentsEx.Comments {
+		if ex.ParaIDParent != id {
+			continue
+		}
+		if reply, ok := byParaID[ex.ParaID]; ok {
+			ret = append(ret, reply)
+		}
+	}
+	return ret
+}
+
+// lastParaID returns the paragraph ID of the last paragraph of the comment,
+// which identifies the comment in the commentsExtended part, or an empty
+// string if it doesn't have one.
+func (c Comment) lastParaID() string {
+	paras := c.Paragraphs()
+	if len(paras) == 0 || paras[len(paras)-1].x.ParaIdAttr == nil {
+		return ""
+	}
+	return *paras[len(paras)-1].x.ParaIdAttr
+}
+
+// paraID returns the paragraph ID identifying the comment in the
+// commentsExtended part, assigning one from ids if necessary.
+func (c Comment) paraID(ids func() string) string {
+	id := c.lastParaID()
+	if id == "" {
+		paras := c.Paragraphs()
+		if len(paras) == 0 {
+			paras = append(paras, c.AddParagraph())
+		}
+		id = ids()
+		paras[len(paras)-1].x.ParaIdAttr = unioffice.String(id)
+	}
+	return id
+}
+
+// AddComment adds a review comment by author containing text on the run.  The
+// comments part is created if necessary.
+func (r Run) AddComment(author, text string) Comment {
+	c := r.d.addComment(author, text)
+	for _, p := range r.d.Paragraphs() {
+		for _, pc := range p.x.EG_PContent {
+			for i, rc := range pc.EG_ContentRunContent {
+				if rc.R == r.x {
+					insertRunContent(pc, i+1, commentMarker(c.ID(), true), commentReference(c.ID()))
+					insertRunContent(pc, i, commentMarker(c.ID(), false))
+					return c
+				}
+			}
+		}
+	}
+	// not in the document body, so just reference the comment from the run
+	r.x.EG_RunInnerContent = append(r.x.EG_RunInnerContent, &wml.EG_RunInnerContent{
+		CommentReference: &wml.CT_Markup{IdAttr: c.ID()}})
+	return c
+}
+
+// Comments returns the review comments of the document.
+func (d *Document) Comments() []Comment {
+	ret := []Comment{}
+	if d.comments == nil {
+		return ret
+	}
+	for _, c := range d.comments.Comment {
+		ret = append(ret, Comment{d, c})
+	}
+	return ret
+}
+
+// ensureComments creates the comments part if it doesn't exist.
+func (d *Document) ensureComments() {
+	if d.comments == nil {
+		d.comments = wml.NewComments()
+		d.docRels.AddRelationship("comments.xml", unioffice.CommentsType)
+		d.ContentTypes.AddOverride("/word/comments.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.comments+xml")
+	}
+}
+
+// nextCommentID returns an ID that isn't used by any comment in the comments
+// part, which must exist.
+func (d *Document) nextCommentID() int64 {
+	id := int64(0)
+	for _, c := range d.comments.Comment {
+		if c.IdAttr >= id {
+			id = c.IdAttr + 1
+		}
+	}
+	return id
+}
+
+// addComment adds a comment to the comments part, creating it if necessary.
+func (d *Document) addComment(author, text string) Comment {
+	d.ensureComments()
+	x := wml.NewCT_Comment()
+	x.IdAttr = d.nextCommentID()
+	x.AuthorAttr = author
+	now := time.Now().UTC().Truncate(time.Second)
+	x.DateAttr = &now
+	if initials := authorInitials(author); initials != "" {
+		x.InitialsAttr = unioffice.String(initials)
+	}
+	d.comments.Comment = append(d.comments.Comment, x)
+
+	c := Comment{d, x}
+	p := c.AddParagraph()
+	mark := p.AddRun()
+	mark.X().EG_RunInnerContent = append(mark.X().EG_RunInnerContent, &wml.EG_RunInnerContent{AnnotationRef: wml.NewCT_Empty()})
+	p.AddRun().AddText(text)
+	return c
+}
+
+// ensureCommentsEx creates the commentsExtended part if it doesn't exist.
+func (d *Document) ensureCommentsEx() {
+	if d.commentsEx == nil {
+		d.commentsEx = newCommentsEx()
+		d.docRels.AddRelationship("commentsExtended.xml", unioffice.CommentsExtendedType)
+		d.ContentTypes.AddOverride("/word/commentsExtended.xml", unioffice.CommentsExtendedContentType)
+	}
+}
+
+// maxParaID is the limit on paragraph IDs, which must be less than 0x80000000.
+const maxParaID = 0x7fffffff
+
+// newParaIDs returns a function that returns paragraph IDs that aren't used by
+// any paragraph in the document.
+func (d *Document) newParaIDs() func() string {
+	used := map[int64]bool{}
+	last := int64(0)
+	parts := d.storyParts()
+	if d.comments != nil {
+		parts = append(parts, d.comments)
+	}
+	for _, part := range parts {
+		walkXML(part, func(el *xml.StartElement) {
+			for _, a := range el.Attr {
+				if a.Name.Space != "w14" || a.Name.Local != "paraId" {
+					continue
+				}
+				if id, err := strconv.ParseInt(a.Value, 16, 64); err == nil {
+					used[id] = true
+					if id > last {
+						last = id
+					}
+				}
+			}
+		})
+	}
+	return func() string {
+		for {
+			last++
+			if last > maxParaID {
+				last = 1
+			}
+			if !used[last] {
+				used[last] = true
+				return fmt.Sprintf("%08X", last)
+			}
+		}
+	}
+}
+
+// runContentRef refers to run content within a paragraph.
+type runContentRef struct {
+	pc *wml.EG_PContent
+	i  int
+}
+
+func (r runContentRef) content() *wml.EG_ContentRunContent {
+	return r.pc.EG_ContentRunContent[r.i]
+}
+
+// paragraphRunContent is the run content of a paragraph in order, which may be
+// spread over several paragraph contents.
+type paragraphRunContent []runContentRef
+
+func runContentOf(p *wml.CT_P) paragraphRunContent {
+	ret := paragraphRunContent{}
+	for _, pc := range p.EG_PContent {
+		for i := range pc.EG_ContentRunContent {
+			ret = append(ret, runContentRef{pc, i})
+		}
+	}
+	return ret
+}
+
+// insert inserts run content before the k'th run content of the paragraph, or
+// after the last if k is the number of run contents.
+func (p paragraphRunContent) insert(k int, rcs ...*wml.EG_ContentRunContent) {
+	at := p[len(p)-1]
+	at.i++
+	if k < len(p) {
+		at = p[k]
+	}
+	insertRunContent(at.pc, at.i, rcs...)
+}
+
+// findCommentContent returns the run content of the paragraph containing the
+// run content matched by fn for the comment with an ID, and its index.
+func (d *Document) findCommentContent(id int64, fn func(rc *wml.EG_ContentRunContent, id int64) bool) (paragraphRunContent, int, bool) {
+	for _, p := range d.Paragraphs() {
+		rcs := runContentOf(p.x)
+		for k, rcr := range rcs {
+			if fn(rcr.content(), id) {
+				return rcs, k, true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// skipRunContent returns the index of the first run content from k that
+// isn't matched by fn.
+func skipRunContent(p paragraphRunContent, k int, fn func(rc *wml.EG_ContentRunContent) bool) int {
+	for k < len(p) && fn(p[k].content()) {
+		k++
+	}
+	return k
+}
+
+func insertRunContent(pc *wml.EG_PContent, i int, rcs ...*wml.EG_ContentRunContent) {
+	content := make([]*wml.EG_ContentRunContent, 0, len(pc.EG_ContentRunContent)+len(rcs))
+	content = append(content, pc.EG_ContentRunContent[:i]...)
+	content = append(content, rcs...)
+	pc.EG_ContentRunContent = append(content, pc.EG_ContentRunContent[i:]...)
+}
+
+// commentMarker returns the start or end of the range of a comment.
+func commentMarker(id int64, end bool) *wml.EG_ContentRunContent {
+	m := wml.NewEG_RangeMarkupElements()
+	mr := wml.NewCT_MarkupRange()
+	mr.IdAttr = id
+	if end {
+		m.CommentRangeEnd = mr
+	} else {
+		m.CommentRangeStart = mr
+	}
+	rle := wml.NewEG_RunLevelElts()
+	rle.EG_RangeMarkupElements = append(rle.EG_RangeMarkupElements, m)
+	rc := wml.NewEG_ContentRunContent()
+	rc.EG_RunLevelElts = append(rc.EG_RunLevelElts, rle)
+	return rc
+}
+
+// commentReference returns a run referencing a comment.
+func commentReference(id int64) *wml.EG_ContentRunContent {
+	rc := wml.NewEG_ContentRunContent()
+	rc.R = wml.NewCT_R()
+	rc.R.EG_RunInnerContent = append(rc.R.EG_RunInnerContent, &wml.EG_RunInnerContent{
+		CommentReference: &wml.CT_Markup{IdAttr: id}})
+	return rc
+}
+
+// commentStartID returns the ID of the comment started by run content.
+func commentStartID(rc *wml.EG_ContentRunContent) (int64, bool) {
+	for _, rle := range rc.EG_RunLevelElts {
+		for _, m := range rle.EG_RangeMarkupElements {
+			if m.CommentRangeStart != nil {
+				return m.CommentRangeStart.IdAttr, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// isCommentStart, isCommentEnd and isCommentReference return true if the run
+// content starts, ends or references the comment with an ID, or any comment if
+// the ID is negative.
+func isCommentStart(rc *wml.EG_ContentRunContent, id int64) bool {
+	sid, ok := commentStartID(rc)
+	return ok && (id < 0 || sid == id)
+}
+
+func isCommentEnd(rc *wml.EG_ContentRunContent, id int64) bool {
+	for _, rle := range rc.EG_RunLevelElts {
+		for _, m := range rle.EG_RangeMarkupElements {
+			if m.CommentRangeEnd != nil && (id < 0 || m.CommentRangeEnd.IdAttr == id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isCommentReference(rc *wml.EG_ContentRunContent, id int64) bool {
+	if rc.R == nil {
+		return false
+	}
+	for _, ic := range rc.R.EG_RunInnerContent {
+		if ic.CommentReference != nil && (id < 0 || ic.CommentReference.IdAttr == id) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorInitials returns the initials of an author's name, e.g. "JD" for
+// "Jane Doe".
+func authorInitials(author string) string {
+	buf := strings.Builder{}
+	for _, w := range strings.Fields(author) {
+		for _, r := range w {
+			buf.WriteRune(r)
+			break
+		}
+	}
+	return strings.ToUpper(buf.String())
+}
+
+// w15Namespace is the namespace of the Word 2012 extensions, which include the
+// commentsExtended part.  It isn't part of the generated schema types.
+const w15Namespace = "http://schemas.microsoft.com/office/word/2012/wordml"
+
+// commentsEx is the w15 CT_CommentsEx, which records the threads of replies to
+// comments.  Comments are identified by the paragraph ID of their last
+// paragraph.
+type commentsEx struct {
+	Comments []*commentEx `xml:"commentEx"`
+}
+
+type commentEx struct {
+	ParaID       string `xml:"paraId,attr"`
+	ParaIDParent string `xml:"paraIdParent,attr"`
+	Done         bool   `xml:"done,attr"`
+}
+
+func newCommentsEx() *commentsEx {
+	return &commentsEx{}
+}
+
+// find returns the entry for the comment with a paragraph ID, or nil if there
+// isn't one.
+func (c *commentsEx) find(paraID string) *commentEx {
+	for _, ex := range c.Comments {
+		if ex.ParaID == paraID {
+			return ex
+		}
+	}
+	return nil
+}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (c *commentsEx) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "w15:commentsEx"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:w15"}, Value: w15Namespace})
+	e.EncodeToken(start)
+	for _, ex := range c.Comments {
+		el := xml.StartElement{Name: xml.Name{Local: "w15:commentEx"}}
+		el.Attr = append(el.Attr, xml.Attr{Name: xml.Name{Local: "w15:paraId"}, Value: ex.ParaID})
+		if ex.ParaIDParent != "" {
+			el.Attr = append(el.Attr, xml.Attr{Name: xml.Name{Local: "w15:paraIdParent"}, Value: ex.ParaIDParent})
+		}
+		done := "0"
+		if ex.Done {
+			done = "1"
+		}
+		el.Attr = append(el.Attr, xml.Attr{Name: xml.Name{Local: "w15:done"}, Value: done})
+		e.EncodeToken(el)
+		e.EncodeToken(el.End())
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (c *commentsEx) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain commentsEx
+	return d.DecodeElement((*plain)(c), &start)
+}
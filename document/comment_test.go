@@ -0,0 +1,131 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/document"
+)
+
+func TestComments(t *testing.T) {
+	doc := document.New()
+	para := doc.AddParagraph()
+	para.AddRun().AddText("The results are ")
+	phrase := para.AddRun()
+	phrase.AddText("statistically significant")
+	para.AddRun().AddText(".")
+
+	cmt := phrase.AddComment("Jane Doe", "Which test was used?")
+	reply := cmt.AddReply("John Smith", "A two-tailed t-test.")
+	if cmt.ID() == reply.ID() {
+		t.Errorf("expected distinct comment IDs")
+	}
+
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	// the replies range starts and ends with the comment's range
+	body := files["word/document.xml"]
+	exp := `<w:commentRangeStart w:id="0"/><w:commentRangeStart w:id="1"/>` +
+		`<w:r><w:t>statistically significant</w:t></w:r>` +
+		`<w:commentRangeEnd w:id="0"/><w:r><w:commentReference w:id="0"/></w:r>` +
+		`<w:commentRangeEnd w:id="1"/><w:r><w:commentReference w:id="1"/></w:r>`
+	if !strings.Contains(body, exp) {
+		t.Errorf("expected comment ranges around the phrase, got %s", body)
+	}
+	if !strings.Contains(files["word/comments.xml"], `w:author="Jane Doe"`) {
+		t.Errorf("expected the comment author in the comments part")
+	}
+	if !strings.Contains(files["word/_rels/document.xml.rels"], `Target="comments.xml"`) {
+		t.Errorf("expected a relationship to the comments part")
+	}
+	if !strings.Contains(files["[Content_Types].xml"], `/word/comments.xml`) {
+		t.Errorf("expected a content type for the comments part")
+	}
+	if !strings.Contains(files["word/commentsExtended.xml"], `w15:paraIdParent=`) {
+		t.Errorf("expected the reply in the commentsExtended part, got %s", files["word/commentsExtended.xml"])
+	}
+	if !strings.Contains(files["word/_rels/document.xml.rels"], `Target="commentsExtended.xml"`) {
+		t.Errorf("expected a relationship to the commentsExtended part")
+	}
+	if !strings.Contains(files["[Content_Types].xml"], `/word/commentsExtended.xml`) {
+		t.Errorf("expected a content type for the commentsExtended part")
+	}
+
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading document: %s", err)
+	}
+	cmts := doc2.Comments()
+	if len(cmts) != 2 {
+		t.Fatalf("expected 2 comments, got %d", len(cmts))
+	}
+	if cmts[0].Author() != "Jane Doe" || cmts[0].Text() != "Which test was used?" {
+		t.Errorf("unexpected comment %s: %s", cmts[0].Author(), cmts[0].Text())
+	}
+	if cmts[0].X().InitialsAttr == nil || *cmts[0].X().InitialsAttr != "JD" {
+		t.Errorf("expected initials JD")
+	}
+	if cmts[0].Date().IsZero() {
+		t.Errorf("expected the comment to be dated")
+	}
+	replies := cmts[0].Replies()
+	if len(replies) != 1 || replies[0].Text() != "A two-tailed t-test." {
+		t.Fatalf("expected the reply to be read back, got %d replies", len(replies))
+	}
+	if len(replies[0].Replies()) != 0 {
+		t.Errorf("expected no replies to the reply")
+	}
+
+	// replies can be added to a comment that has been read
+	cmts[0].AddReply("Jane Doe", "Thanks.")
+	if replies := cmts[0].Replies(); len(replies) != 2 || replies[1].Text() != "Thanks." {
+		t.Errorf("expected 2 replies, got %d", len(replies))
+	}
+}
+
+func TestCommentsOnSameRunAreNotReplies(t *testing.T) {
+	doc := document.New()
+	run := doc.AddParagraph().AddRun()
+	run.AddText("results")
+	a := run.AddComment("Jane Doe", "Which test was used?")
+	b := run.AddComment("John Smith", "Is the sample large enough?")
+	if len(a.Replies()) != 0 || len(b.Replies()) != 0 {
+		t.Errorf("expected comments on the same run not to be replies")
+	}
+	reply := a.AddReply("John Smith", "A two-tailed t-test.")
+	if replies := a.Replies(); len(replies) != 1 || replies[0].ID() != reply.ID() {
+		t.Errorf("expected only the reply to be returned, got %d replies", len(replies))
+	}
+	if len(b.Replies()) != 0 {
+		t.Errorf("expected no replies to the other comment")
+	}
+
+	// a reply to a reply is added to the thread of the comment
+	reply.AddReply("Jane Doe", "Thanks.")
+	if replies := a.Replies(); len(replies) != 2 || replies[1].Text() != "Thanks." {
+		t.Errorf("expected 2 replies in the thread, got %d", len(replies))
+	}
+}
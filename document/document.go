@@ -56,6 +56,13 @@ type Document struct {
 	fontTable   *wml.Fonts
 	endNotes    *wml.Endnotes
 	footNotes   *wml.Footnotes
+	comments    *wml.Comments
+	commentsEx  *commentsEx
+
+	// annotationID is the last annotation ID given out, which is found the
+	// first time an ID is needed
+	annotationID       int64
+	annotationIDSeeded bool
 }
 
 // New constructs an empty document that content can be added to.
@@ -108,7 +115,7 @@ func (d *Document) createCustomProperties() {
 }
 
 func (d *Document) addCustomRelationships() {
-	d.ContentTypes.AddOverride("/docProps/custom.xml", "application/vnd.openxmlformats-officedocument.custom-properties+xml")
+	d.ContentTypes.AddOverride("/docProps/custom.xml", unioffice.CustomPropertiesContentType)
 	d.Rels.AddRelationship("docProps/custom.xml", unioffice.CustomPropertiesType)
 }
 
@@ -140,6 +147,16 @@ func (d *Document) Headers() []Header {
 	return ret
 }
 
+// Themes returns the themes of the document.  Changing a theme's colors
+// recolors all of the content that refers to them.
+func (d *Document) Themes() []common.Theme {
+	ret := []common.Theme{}
+	for _, t := range d.themes {
+		ret = append(ret, common.MakeTheme(t))
+	}
+	return ret
+}
+
 // Footers returns the footers defined in the document.
 func (d *Document) Footers() []Footer {
 	ret := []Footer{}
@@ -173,12 +190,60 @@ func (d *Document) BodySection() Section {
 	return Section{d, d.x.Body.SectPr}
 }
 
+// AddSection ends the current section with a section break and returns the
+// new section that follows it.  The settings of the section being ended are
+// moved to the sectPr of a new paragraph that marks the break, while the
+// returned section starts on a new page and inherits the page size and
+// margins of the previous section.
+func (d *Document) AddSection() Section {
+	prev := d.BodySection().X()
+	p := d.AddParagraph()
+	p.Properties().X().SectPr = prev
+
+	// the page settings are deep copied as the schema types hold their
+	// attribute values through pointers
+	next := wml.NewCT_SectPr()
+	if prev.PgSz != nil {
+		next.PgSz = wml.NewCT_PageSz()
+		if err := copyXML(prev.PgSz, next.PgSz, nil); err != nil {
+			unioffice.Log("error copying page size: %s", err)
+		}
+	}
+	if prev.PgMar != nil {
+		next.PgMar = wml.NewCT_PageMar()
+		if err := copyXML(prev.PgMar, next.PgMar, nil); err != nil {
+			unioffice.Log("error copying page margins: %s", err)
+		}
+	}
+	if prev.Cols != nil {
+		next.Cols = wml.NewCT_Columns()
+		if err := copyXML(prev.Cols, next.Cols, nil); err != nil {
+			unioffice.Log("error copying columns: %s", err)
+		}
+	}
+	next.Type = wml.NewCT_SectType()
+	next.Type.ValAttr = wml.ST_SectionMarkNextPage
+	d.x.Body.SectPr = next
+	return Section{d, next}
+}
+
+// SetPageSizeLandscape sets the last section of the document to landscape
+// orientation with the given page size.  The dimensions are swapped if
+// necessary so that the page is wider than it is tall.
+func (d *Document) SetPageSizeLandscape(w, h measurement.Distance) {
+	if h > w {
+		w, h = h, w
+	}
+	d.BodySection().SetPageSizeAndOrientation(w, h, wml.ST_PageOrientationLandscape)
+}
+
 // Save writes the document to an io.Writer in the Zip package format.
 func (d *Document) Save(w io.Writer) error {
 	if err := d.x.Validate(); err != nil {
 		unioffice.Log("validation error in document: %s", err)
 	}
 	dt := unioffice.DocTypeDocument
+	d.EnsurePropertyParts(dt)
 
 	if !license.GetLicenseKey().IsLicensed() && flag.Lookup("test.v") == nil {
 		fmt.Println("Unlicensed version of UniOffice")
@@ -260,6 +325,16 @@ func (d *Document) Save(w io.Writer) error {
 			return err
 		}
 	}
+	if d.comments != nil {
+		if err := zippkg.MarshalXMLByType(z, dt, unioffice.CommentsType, d.comments); err != nil {
+			return err
+		}
+	}
+	if d.commentsEx != nil {
+		if err := zippkg.MarshalXMLByType(z, dt, unioffice.CommentsExtendedType, d.commentsEx); err != nil {
+			return err
+		}
+	}
 	for i, thm := range d.themes {
 		if err := zippkg.MarshalXMLByTypeIndex(z, dt, unioffice.ThemeType, i+1, thm); err != nil {
 			return err
@@ -528,6 +603,12 @@ func OpenTemplate(filename string) (*Document, error) {
 
 // Read reads a document from an io.Reader.
 func Read(r io.ReaderAt, size int64) (*Document, error) {
+	return ReadWithOptions(r, size, zippkg.ReadOptions{})
+}
+
+// ReadWithOptions reads a document from an io.Reader, applying the limits in
+// opts before any of its content is decompressed.
+func ReadWithOptions(r io.ReaderAt, size int64, opts zippkg.ReadOptions) (*Document, error) {
 	doc := New()
 	// numbering is not required
 	doc.Numbering.x = nil
@@ -545,6 +626,9 @@ func Read(r io.ReaderAt, size int64) (*Document, error) {
 
 	files := []*zip.File{}
 	files = append(files, zr.File...)
+	if err := opts.CheckSize(files); err != nil {
+		return nil, err
+	}
 
 	addCustom := false
 	for _, f := range files {
@@ -616,6 +700,9 @@ func (d *Document) validateBookmarks() error {
 		if _, ok := bmnames[bm.Name()]; ok {
 			return fmt.Errorf("duplicate bookmark %s found", bm.Name())
 		}
+		if err := ValidateBookmarkName(bm.Name()); err != nil {
+			return err
+		}
 		bmnames[bm.Name()] = struct{}{}
 	}
 	return nil
@@ -671,15 +758,15 @@ func (d *Document) AddImage(i common.Image) (common.ImageRef, error) {
 		return r, errors.New("image must have a valid size")
 	}
 
-	d.Images = append(d.Images, r)
-	fn := fmt.Sprintf("media/image%d.%s", len(d.Images), i.Format)
+	fn := fmt.Sprintf("media/image%d.%s", len(d.Images)+1, i.Format)
 	rel := d.docRels.AddRelationship(fn, unioffice.ImageType)
+	r.SetRelID(rel.X().IdAttr)
+	d.Images = append(d.Images, r)
 	d.ContentTypes.EnsureDefault("png", "image/png")
 	d.ContentTypes.EnsureDefault("jpeg", "image/jpeg")
 	d.ContentTypes.EnsureDefault("jpg", "image/jpeg")
 	d.ContentTypes.EnsureDefault("wmf", "image/x-wmf")
 	d.ContentTypes.EnsureDefault(i.Format, "image/"+i.Format)
-	r.SetRelID(rel.X().IdAttr)
 	return r, nil
 }
 
@@ -852,6 +939,16 @@ func (d *Document) onNewRelationship(decMap *zippkg.DecodeMap, target, typ strin
 		decMap.AddTarget(target, d.footNotes, typ, 0)
 		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, 0)
 
+	case unioffice.CommentsType, unioffice.CommentsTypeStrict:
+		d.comments = wml.NewComments()
+		decMap.AddTarget(target, d.comments, typ, 0)
+		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, 0)
+
+	case unioffice.CommentsExtendedType:
+		d.commentsEx = newCommentsEx()
+		decMap.AddTarget(target, d.commentsEx, typ, 0)
+		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, 0)
+
 	case unioffice.ImageType, unioffice.ImageTypeStrict:
 		var iref common.ImageRef
 		for i, f := range files {
@@ -868,6 +965,7 @@ func (d *Document) onNewRelationship(decMap *zippkg.DecodeMap, target, typ strin
 					return err
 				}
 				iref = common.MakeImageRef(img, &d.DocBase, d.docRels)
+				iref.SetRelID(rel.IdAttr)
 				d.Images = append(d.Images, iref)
 				files[i] = nil
 			}
@@ -13,10 +13,13 @@ import (
 	"os"
 	"testing"
 
+	"github.com/unidoc/unioffice/color"
 	"github.com/unidoc/unioffice/common"
 	"github.com/unidoc/unioffice/document"
+	"github.com/unidoc/unioffice/measurement"
 	"github.com/unidoc/unioffice/schema/soo/wml"
 	"github.com/unidoc/unioffice/testhelper"
+	"github.com/unidoc/unioffice/zippkg"
 )
 
 func TestSimpleDoc(t *testing.T) {
@@ -165,6 +168,186 @@ func TestInsertTable(t *testing.T) {
 	}
 }
 
+func TestTableColumnWidths(t *testing.T) {
+	doc := document.New()
+	tbl := doc.AddTable()
+	tbl.Properties().Borders().SetAll(wml.ST_BorderSingle, color.Black, 1*measurement.Point)
+	for r := 0; r < 4; r++ {
+		row := tbl.AddRow()
+		for c := 0; c < 3; c++ {
+			cell := row.AddCell()
+			cell.AddParagraph().AddRun().AddText("x")
+			if r%2 == 1 {
+				cell.Properties().SetShading(wml.ST_ShdSolid, color.LightGray, color.Auto)
+			}
+		}
+	}
+	tbl.SetColumnWidths([]measurement.Distance{1 * measurement.Inch, 2 * measurement.Inch, 1 * measurement.Inch})
+
+	grid := tbl.X().TblGrid.GridCol
+	if len(grid) != 3 || *grid[1].WAttr.ST_UnsignedDecimalNumber != 2880 {
+		t.Errorf("expected 3 grid columns with the second 2880 twips wide")
+	}
+	if tbl.X().TblPr.TblLayout == nil || tbl.X().TblPr.TblLayout.TypeAttr != wml.ST_TblLayoutTypeFixed {
+		t.Errorf("expected a fixed table layout")
+	}
+	cell := tbl.Rows()[1].Cells()[1]
+	if w := cell.X().TcPr.TcW.WAttr.ST_DecimalNumberOrPercent.ST_UnqualifiedPercentage; w == nil || *w != 2880 {
+		t.Errorf("expected cell width to match the grid column")
+	}
+	if cell.X().TcPr.Shd == nil {
+		t.Errorf("expected odd rows to be shaded")
+	}
+	if err := doc.Validate(); err != nil {
+		t.Errorf("created an invalid document: %s", err)
+	}
+}
+
+func TestLandscapeSection(t *testing.T) {
+	doc := document.New()
+	doc.BodySection().SetPageSizeAndOrientation(8.5*measurement.Inch, 11*measurement.Inch, wml.ST_PageOrientationPortrait)
+	p := doc.AddParagraph()
+	p.AddRun().AddText("portrait")
+	p.AddPageBreak()
+	doc.AddParagraph().AddRun().AddText("still portrait")
+
+	sec := doc.AddSection()
+	doc.SetPageSizeLandscape(8.5*measurement.Inch, 11*measurement.Inch)
+	sec.SetPageMargins(measurement.Inch, measurement.Inch, measurement.Inch, measurement.Inch, 0, 0, 0)
+	doc.AddParagraph().AddRun().AddText("landscape")
+
+	if err := doc.Validate(); err != nil {
+		t.Errorf("created an invalid document: %s", err)
+	}
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving document: %s", err)
+	}
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading document: %s", err)
+	}
+
+	paras := doc2.Paragraphs()
+	if len(paras) != 4 {
+		t.Fatalf("expected 4 paragraphs, got %d", len(paras))
+	}
+	if br := paras[0].Runs()[1].X().EG_RunInnerContent[0].Br; br == nil || br.TypeAttr != wml.ST_BrTypePage {
+		t.Errorf("expected a page break in the first paragraph")
+	}
+	first := paras[2].X().PPr.SectPr
+	if first == nil || first.PgSz.OrientAttr != wml.ST_PageOrientationPortrait {
+		t.Fatalf("expected the section break paragraph to hold the portrait section")
+	}
+	last := doc2.BodySection().X()
+	if last.PgSz.OrientAttr != wml.ST_PageOrientationLandscape {
+		t.Errorf("expected the last section to be landscape")
+	}
+	if w := *last.PgSz.WAttr.ST_UnsignedDecimalNumber; w != 15840 {
+		t.Errorf("expected landscape width of 15840 twips, got %d", w)
+	}
+	if last.Type == nil || last.Type.ValAttr != wml.ST_SectionMarkNextPage {
+		t.Errorf("expected the landscape section to start on a new page")
+	}
+	if *first.PgSz.WAttr.ST_UnsignedDecimalNumber != 12240 {
+		t.Errorf("expected the portrait section size to be unchanged")
+	}
+}
+
+func TestAddSectionCopiesPageSettings(t *testing.T) {
+	doc := document.New()
+	doc.BodySection().SetPageSizeAndOrientation(8.5*measurement.Inch, 11*measurement.Inch, wml.ST_PageOrientationPortrait)
+	doc.BodySection().SetPageMargins(measurement.Inch, measurement.Inch, measurement.Inch, measurement.Inch, 0, 0, 0)
+	first := doc.BodySection().X()
+
+	sec := doc.AddSection()
+	if sec.X().PgSz == nil || sec.X().PgMar == nil {
+		t.Fatalf("expected the new section to inherit the page size and margins")
+	}
+	*sec.X().PgSz.WAttr.ST_UnsignedDecimalNumber = 15840
+	*sec.X().PgMar.LeftAttr.ST_UnsignedDecimalNumber = 720
+	*sec.X().PgMar.TopAttr.Int64 = 720
+
+	if w := *first.PgSz.WAttr.ST_UnsignedDecimalNumber; w != 12240 {
+		t.Errorf("expected the first section width to be unchanged, got %d", w)
+	}
+	if l := *first.PgMar.LeftAttr.ST_UnsignedDecimalNumber; l != 1440 {
+		t.Errorf("expected the first section left margin to be unchanged, got %d", l)
+	}
+	if top := *first.PgMar.TopAttr.Int64; top != 1440 {
+		t.Errorf("expected the first section top margin to be unchanged, got %d", top)
+	}
+}
+
+func TestFloatingImage(t *testing.T) {
+	doc := document.New()
+	img, err := common.ImageFromFile("testdata/gopher.png")
+	if err != nil {
+		t.Fatalf("unable to create image: %s", err)
+	}
+	iref, err := doc.AddImage(img)
+	if err != nil {
+		t.Fatalf("unable to add image to doc: %s", err)
+	}
+
+	para := doc.AddParagraph()
+	anchored, err := para.AddRun().AddDrawingAnchored(iref)
+	if err != nil {
+		t.Fatalf("unable to add anchored image: %s", err)
+	}
+	anchored.SetOrigin(wml.WdST_RelFromHMargin, wml.WdST_RelFromVParagraph)
+	anchored.SetHAlignment(wml.WdST_AlignHRight)
+	anchored.SetYOffset(measurement.Inch)
+	anchored.SetSize(2*measurement.Inch, measurement.Inch)
+	anchored.SetTextWrapSquare(wml.WdST_WrapTextLeft)
+	para.AddRun().AddText("text wrapping around the image")
+
+	inline, err := doc.AddParagraph().AddRun().AddDrawingInline(iref)
+	if err != nil {
+		t.Fatalf("unable to add inline image: %s", err)
+	}
+	inline.SetSize(measurement.Inch, measurement.Inch)
+
+	if err := doc.Validate(); err != nil {
+		t.Errorf("created an invalid document: %s", err)
+	}
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving document: %s", err)
+	}
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading document: %s", err)
+	}
+
+	paras := doc2.Paragraphs()
+	anchors := paras[0].Runs()[0].DrawingAnchored()
+	if len(anchors) != 1 {
+		t.Fatalf("expected 1 anchored drawing, got %d", len(anchors))
+	}
+	anc := anchors[0].X()
+	if anc.PositionH.Choice.Align != wml.WdST_AlignHRight {
+		t.Errorf("expected the image to be aligned right")
+	}
+	if off := *anc.PositionV.Choice.PosOffset; off != 914400 {
+		t.Errorf("expected a vertical offset of 914400 EMU, got %d", off)
+	}
+	if anc.Extent.CxAttr != 1828800 || anc.Extent.CyAttr != 914400 {
+		t.Errorf("expected a 2x1 inch extent, got %dx%d", anc.Extent.CxAttr, anc.Extent.CyAttr)
+	}
+	if anc.Choice.WrapSquare == nil || anc.Choice.WrapSquare.WrapTextAttr != wml.WdST_WrapTextLeft {
+		t.Errorf("expected square text wrapping on the left")
+	}
+	if _, ok := anchors[0].GetImage(); !ok {
+		t.Errorf("expected to find the anchored image")
+	}
+
+	inlines := paras[1].Runs()[0].DrawingInline()
+	if len(inlines) != 1 || inlines[0].X().Extent.CxAttr != 914400 {
+		t.Errorf("expected a one inch inline image")
+	}
+}
+
 func TestInsertRun(t *testing.T) {
 	doc := document.New()
 	if len(doc.Paragraphs()) != 0 {
@@ -240,6 +423,87 @@ func TestDuplicateBookmarks(t *testing.T) {
 	}
 }
 
+func TestInvalidBookmarkName(t *testing.T) {
+	doc := document.New()
+	doc.AddParagraph().AddBookmark("1bookmark")
+	if err := doc.Validate(); err == nil {
+		t.Errorf("expected error due to an invalid bookmark name")
+	}
+}
+
+func TestBookmarkHyperlink(t *testing.T) {
+	doc := document.New()
+	doc.AddParagraph().AddInternalHyperlink("Summary", "See the summary")
+	doc.AddParagraph().AddBookmark("Introduction")
+	heading := doc.AddParagraph()
+	heading.AddRun().AddText("Summary")
+	bm := heading.AddBookmark("Summary")
+
+	if err := doc.Validate(); err != nil {
+		t.Errorf("created an invalid document: %s", err)
+	}
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving document: %s", err)
+	}
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading document: %s", err)
+	}
+
+	bms := doc2.Bookmarks()
+	if len(bms) != 2 {
+		t.Fatalf("expected 2 bookmarks, got %d", len(bms))
+	}
+	if bms[0].ID() == bms[1].ID() {
+		t.Errorf("expected bookmarks to have unique ids")
+	}
+	if bms[1].Name() != "Summary" || bms[1].ID() != bm.ID() {
+		t.Errorf("expected the Summary bookmark to round trip")
+	}
+	// the bookmark end must share the id of the start
+	for _, pc := range doc2.Paragraphs()[2].X().EG_PContent {
+		for _, rc := range pc.EG_ContentRunContent {
+			for _, re := range rc.EG_RunLevelElts {
+				for _, rm := range re.EG_RangeMarkupElements {
+					if rm.BookmarkEnd != nil && rm.BookmarkEnd.IdAttr != bm.ID() {
+						t.Errorf("expected bookmark end id %d, got %d", bm.ID(), rm.BookmarkEnd.IdAttr)
+					}
+				}
+			}
+		}
+	}
+
+	hl := doc2.Paragraphs()[0].X().EG_PContent[0].Hyperlink
+	if hl == nil || hl.AnchorAttr == nil || *hl.AnchorAttr != "Summary" || hl.IdAttr != nil {
+		t.Fatalf("expected an internal hyperlink to the Summary bookmark")
+	}
+	if got := hl.EG_ContentRunContent[0].R.EG_RunInnerContent[0].T.Content; got != "See the summary" {
+		t.Errorf("expected the hyperlink text, got %s", got)
+	}
+}
+
+func TestValidateBookmarkName(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		valid bool
+	}{
+		{"Summary", true},
+		{"Section_2", true},
+		{"_Toc123", true},
+		{"", false},
+		{"2ndSection", false},
+		{"has space", false},
+		{"dash-name", false},
+		{"a234567890123456789012345678901234567890", true},
+		{"a2345678901234567890123456789012345678901", false},
+	} {
+		if err := document.ValidateBookmarkName(tc.name); (err == nil) != tc.valid {
+			t.Errorf("ValidateBookmarkName(%q): expected valid = %v, got %v", tc.name, tc.valid, err)
+		}
+	}
+}
+
 func TestHeaderAndFooterImages(t *testing.T) {
 	doc := document.New()
 	img1, err := common.ImageFromFile("testdata/gopher.png")
@@ -387,3 +651,26 @@ func TestGetTables(t *testing.T) {
 		t.Errorf("nested table not enumerated. found %d, expected 2", len(tables))
 	}
 }
+
+func TestReadWithOptions(t *testing.T) {
+	buf, err := ioutil.ReadFile("testdata/simple-1.docx")
+	if err != nil {
+		t.Fatalf("error reading fixture: %s", err)
+	}
+	doc, err := document.ReadWithOptions(bytes.NewReader(buf), int64(len(buf)), zippkg.ReadOptions{MaxDecompressedSize: 10 << 20})
+	if err != nil {
+		t.Fatalf("error reading document: %s", err)
+	}
+	if len(doc.Paragraphs()) == 0 {
+		t.Errorf("expected paragraphs in the document")
+	}
+
+	if _, err := document.ReadWithOptions(bytes.NewReader(buf), int64(len(buf)), zippkg.ReadOptions{MaxDecompressedSize: 100}); err == nil {
+		t.Errorf("expected an error when exceeding the decompressed size limit")
+	}
+
+	garbage := []byte("this is not a zip file")
+	if _, err := document.Read(bytes.NewReader(garbage), int64(len(garbage))); err == nil {
+		t.Errorf("expected an error reading a non-zip file")
+	}
+}
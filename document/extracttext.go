@@ -0,0 +1,176 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+// TextPart identifies the part of a document that extracted text came from.
+type TextPart byte
+
+// TextPart constants.
+const (
+	TextPartBody TextPart = iota
+	TextPartHeader
+	TextPartFooter
+	TextPartFootnote
+)
+
+// ExtractTextOptions controls which parts of a document text is extracted
+// from.
+type ExtractTextOptions struct {
+	// IncludeFootnotes includes the text of footnotes after the body, headers
+	// and footers.
+	IncludeFootnotes bool
+}
+
+// ExtractedParagraph is the text of a single paragraph along with where it was
+// found in the document.
+type ExtractedParagraph struct {
+	Text string
+	Part TextPart
+	// Style is the paragraph style ID, if any.
+	Style string
+	// HeadingLevel is the heading level (1-9) of the paragraph, or zero if the
+	// paragraph isn't a heading.
+	HeadingLevel int
+	// InTable is true if the paragraph is within a table cell.
+	InTable bool
+}
+
+// ExtractText returns the text of the document body, tables, headers and
+// footers with each paragraph on its own line.  Tabs and line breaks within
+// paragraphs are preserved as \t and \n.
+func (d *Document) ExtractText() string {
+	return d.ExtractTextWithOptions(ExtractTextOptions{})
+}
+
+// ExtractTextWithOptions returns the text of the document as ExtractText does,
+// with the parts included controlled by opts.
+func (d *Document) ExtractTextWithOptions(opts ExtractTextOptions) string {
+	sb := strings.Builder{}
+	for _, p := range d.ExtractTextStructured(opts) {
+		sb.WriteString(p.Text)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// ExtractTextStructured returns the text of each paragraph in the document in
+// order, starting with the headers, followed by the body and the footers and
+// optionally the footnotes.
+func (d *Document) ExtractTextStructured(opts ExtractTextOptions) []ExtractedParagraph {
+	ret := []ExtractedParagraph{}
+	for _, h := range d.headers {
+		ret = extractBlocks(ret, h.EG_ContentBlockContent, TextPartHeader, false)
+	}
+	if d.x.Body != nil {
+		for _, ble := range d.x.Body.EG_BlockLevelElts {
+			ret = extractBlocks(ret, ble.EG_ContentBlockContent, TextPartBody, false)
+		}
+	}
+	for _, f := range d.footers {
+		ret = extractBlocks(ret, f.EG_ContentBlockContent, TextPartFooter, false)
+	}
+	if opts.IncludeFootnotes && d.footNotes != nil {
+		for _, fn := range d.footNotes.Footnote {
+			// skip the separators
+			if fn.TypeAttr != wml.ST_FtnEdnUnset && fn.TypeAttr != wml.ST_FtnEdnNormal {
+				continue
+			}
+			for _, ble := range fn.EG_BlockLevelElts {
+				ret = extractBlocks(ret, ble.EG_ContentBlockContent, TextPartFootnote, false)
+			}
+		}
+	}
+	return ret
+}
+
+func extractBlocks(ret []ExtractedParagraph, bcs []*wml.EG_ContentBlockContent, part TextPart, inTable bool) []ExtractedParagraph {
+	for _, bc := range bcs {
+		for _, p := range bc.P {
+			ret = append(ret, extractParagraph(p, part, inTable))
+		}
+		for _, tbl := range bc.Tbl {
+			for _, crc := range tbl.EG_ContentRowContent {
+				for _, tr := range crc.Tr {
+					for _, ccc := range tr.EG_ContentCellContent {
+						for _, tc := range ccc.Tc {
+							for _, ble := range tc.EG_BlockLevelElts {
+								ret = extractBlocks(ret, ble.EG_ContentBlockContent, part, true)
+							}
+						}
+					}
+				}
+			}
+		}
+		if bc.Sdt != nil && bc.Sdt.SdtContent != nil {
+			for _, p := range bc.Sdt.SdtContent.P {
+				ret = append(ret, extractParagraph(p, part, inTable))
+			}
+		}
+	}
+	return ret
+}
+
+func extractParagraph(p *wml.CT_P, part TextPart, inTable bool) ExtractedParagraph {
+	ep := ExtractedParagraph{Part: part, InTable: inTable}
+	if p.PPr != nil {
+		if p.PPr.PStyle != nil {
+			ep.Style = p.PPr.PStyle.ValAttr
+			if lvl, err := strconv.Atoi(strings.TrimPrefix(ep.Style, "Heading")); err == nil && strings.HasPrefix(ep.Style, "Heading") {
+				ep.HeadingLevel = lvl
+			}
+		}
+		if p.PPr.OutlineLvl != nil && ep.HeadingLevel == 0 && p.PPr.OutlineLvl.ValAttr < 9 {
+			ep.HeadingLevel = int(p.PPr.OutlineLvl.ValAttr) + 1
+		}
+	}
+	sb := strings.Builder{}
+	extractPContent(&sb, p.EG_PContent)
+	ep.Text = sb.String()
+	return ep
+}
+
+func extractPContent(sb *strings.Builder, pcs []*wml.EG_PContent) {
+	for _, pc := range pcs {
+		extractRunContent(sb, pc.EG_ContentRunContent)
+		if pc.Hyperlink != nil {
+			extractRunContent(sb, pc.Hyperlink.EG_ContentRunContent)
+		}
+		for _, fs := range pc.FldSimple {
+			extractPContent(sb, fs.EG_PContent)
+		}
+	}
+}
+
+func extractRunContent(sb *strings.Builder, rcs []*wml.EG_ContentRunContent) {
+	for _, rc := range rcs {
+		if rc.R != nil {
+			for _, ic := range rc.R.EG_RunInnerContent {
+				switch {
+				case ic.T != nil:
+					sb.WriteString(ic.T.Content)
+				case ic.Tab != nil:
+					sb.WriteByte('\t')
+				case ic.Br != nil, ic.Cr != nil:
+					sb.WriteByte('\n')
+				case ic.NoBreakHyphen != nil:
+					sb.WriteByte('-')
+				}
+			}
+		}
+		if rc.Sdt != nil && rc.Sdt.SdtContent != nil {
+			extractRunContent(sb, rc.Sdt.SdtContent.EG_ContentRunContent)
+		}
+	}
+}
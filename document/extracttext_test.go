@@ -0,0 +1,89 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/unidoc/unioffice/document"
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+func TestExtractText(t *testing.T) {
+	doc := document.New()
+	hdr := doc.AddHeader()
+	hdr.AddParagraph().AddRun().AddText("Page header")
+	doc.BodySection().SetHeader(hdr, wml.ST_HdrFtrDefault)
+
+	h := doc.AddParagraph()
+	h.SetStyle("Heading1")
+	h.AddRun().AddText("Title")
+
+	p := doc.AddParagraph()
+	r := p.AddRun()
+	r.AddText("Name:")
+	r.AddTab()
+	r.AddText("Gopher")
+	r.AddBreak()
+	r.AddText("Second line")
+	p.AddRun().AddField(document.FieldCurrentPage)
+
+	tbl := doc.AddTable()
+	row := tbl.AddRow()
+	row.AddCell().AddParagraph().AddRun().AddText("A1")
+	row.AddCell().AddParagraph().AddRun().AddText("B1")
+
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving document: %s", err)
+	}
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading document: %s", err)
+	}
+
+	exp := "Page header\nTitle\nName:\tGopher\nSecond line\nA1\nB1\n"
+	if got := doc2.ExtractText(); got != exp {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+
+	paras := doc2.ExtractTextStructured(document.ExtractTextOptions{})
+	if len(paras) != 5 {
+		t.Fatalf("expected 5 paragraphs, got %d", len(paras))
+	}
+	if paras[0].Part != document.TextPartHeader || paras[1].Part != document.TextPartBody {
+		t.Errorf("expected the header paragraph to be followed by the body")
+	}
+	if paras[1].HeadingLevel != 1 || paras[1].Style != "Heading1" {
+		t.Errorf("expected a level 1 heading, got %d", paras[1].HeadingLevel)
+	}
+	if paras[2].HeadingLevel != 0 || paras[2].InTable {
+		t.Errorf("expected a plain body paragraph")
+	}
+	if !paras[3].InTable || !paras[4].InTable {
+		t.Errorf("expected the cell paragraphs to be marked as in a table")
+	}
+}
+
+func TestExtractTextFootnotes(t *testing.T) {
+	doc, err := document.Open("testdata/issue198.docx")
+	if err != nil {
+		t.Fatalf("error opening document: %s", err)
+	}
+	without := doc.ExtractTextStructured(document.ExtractTextOptions{})
+	with := doc.ExtractTextStructured(document.ExtractTextOptions{IncludeFootnotes: true})
+	if len(with) < len(without) {
+		t.Errorf("expected footnotes to only add paragraphs")
+	}
+	for _, p := range without {
+		if p.Part == document.TextPartFootnote {
+			t.Errorf("expected footnotes to be excluded by default")
+		}
+	}
+}
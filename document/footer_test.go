@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/unidoc/unioffice/document"
+	"github.com/unidoc/unioffice/schema/soo/wml"
 )
 
 func TestFooterRemoveParagraph(t *testing.T) {
@@ -38,3 +39,47 @@ func TestFooterRemoveParagraph(t *testing.T) {
 	}
 
 }
+
+func TestFooterPageNumbers(t *testing.T) {
+	doc := document.New()
+	for _, typ := range []wml.ST_HdrFtr{wml.ST_HdrFtrDefault, wml.ST_HdrFtrEven} {
+		ftr := doc.AddFooter()
+		para := ftr.AddParagraph()
+		para.Properties().SetAlignment(wml.ST_JcCenter)
+		run := para.AddRun()
+		run.AddText("Page ")
+		run.AddPageNumberField()
+		run.AddText(" of ")
+		run.AddFieldNumberOfPages()
+		doc.BodySection().SetFooter(ftr, typ)
+	}
+	// replacing the default footer doesn't add a second reference
+	doc.BodySection().SetFooter(doc.Footers()[0], wml.ST_HdrFtrDefault)
+
+	refs := doc.BodySection().X().EG_HdrFtrReferences
+	if len(refs) != 2 {
+		t.Fatalf("expected 2 footer references, got %d", len(refs))
+	}
+	if refs[0].FooterReference.TypeAttr != wml.ST_HdrFtrDefault || refs[1].FooterReference.TypeAttr != wml.ST_HdrFtrEven {
+		t.Errorf("expected default and even footer references")
+	}
+	if refs[0].FooterReference.IdAttr == refs[1].FooterReference.IdAttr {
+		t.Errorf("expected footers to reference different parts")
+	}
+	if doc.Settings.X().EvenAndOddHeaders == nil {
+		t.Errorf("expected even and odd headers to be enabled")
+	}
+
+	instr := []string{}
+	for _, ic := range doc.Footers()[1].Paragraphs()[0].Runs()[0].X().EG_RunInnerContent {
+		if ic.InstrText != nil {
+			instr = append(instr, ic.InstrText.Content)
+		}
+	}
+	if len(instr) != 2 || instr[0] != document.FieldCurrentPage || instr[1] != document.FieldNumberOfPages {
+		t.Errorf("expected PAGE and NUMPAGES fields, got %v", instr)
+	}
+	if err := doc.Validate(); err != nil {
+		t.Errorf("created an invalid document: %s", err)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document
+
+import (
+	"strings"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/ofc/sharedTypes"
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+// Footnote is a note placed at the bottom of the page containing its
+// reference.
+type Footnote struct {
+	d *Document
+	x *wml.CT_FtnEdn
+}
+
+// X returns the inner wrapped XML type.
+func (f Footnote) X() *wml.CT_FtnEdn {
+	return f.x
+}
+
+// ID returns the footnote ID that the footnote reference refers to.
+func (f Footnote) ID() int64 {
+	return f.x.IdAttr
+}
+
+// Paragraphs returns the paragraphs of the footnote.
+func (f Footnote) Paragraphs() []Paragraph {
+	return noteParagraphs(f.d, f.x)
+}
+
+// AddParagraph adds a paragraph to the footnote.
+func (f Footnote) AddParagraph() Paragraph {
+	return addNoteParagraph(f.d, f.x)
+}
+
+// Endnote is a note placed at the end of the document.
+type Endnote struct {
+	d *Document
+	x *wml.CT_FtnEdn
+}
+
+// X returns the inner wrapped XML type.
+func (e Endnote) X() *wml.CT_FtnEdn {
+	return e.x
+}
+
+// ID returns the endnote ID that the endnote reference refers to.
+func (e Endnote) ID() int64 {
+	return e.x.IdAttr
+}
+
+// Paragraphs returns the paragraphs of the endnote.
+func (e Endnote) Paragraphs() []Paragraph {
+	return noteParagraphs(e.d, e.x)
+}
+
+// AddParagraph adds a paragraph to the endnote.
+func (e Endnote) AddParagraph() Paragraph {
+	return addNoteParagraph(e.d, e.x)
+}
+
+// AddFootnote adds a footnote containing text and inserts a reference to it
+// in a new run following the run.  The footnotes part and the FootnoteText and
+// FootnoteReference styles are created if necessary.
+func (r Run) AddFootnote(text string) Footnote {
+	d := r.d
+	d.ensureFootnotes()
+
+	fn := newNote(d.footNotes.Footnote)
+	d.footNotes.Footnote = append(d.footNotes.Footnote, fn)
+	ref := r.noteReferenceRun("FootnoteReference")
+	ref.X().EG_RunInnerContent = append(ref.X().EG_RunInnerContent, &wml.EG_RunInnerContent{
+		FootnoteReference: &wml.CT_FtnEdnRef{IdAttr: fn.IdAttr}})
+
+	note := Footnote{d, fn}
+	p := note.AddParagraph()
+	p.SetStyle("FootnoteText")
+	mark := p.AddRun()
+	mark.SetStyle("FootnoteReference")
+	mark.X().EG_RunInnerContent = append(mark.X().EG_RunInnerContent, &wml.EG_RunInnerContent{FootnoteRef: wml.NewCT_Empty()})
+	p.AddRun().AddText(" " + text)
+	return note
+}
+
+// AddEndnote adds an endnote containing text and inserts a reference to it in
+// a new run following the run.  The endnotes part and the EndnoteText and
+// EndnoteReference styles are created if necessary.
+func (r Run) AddEndnote(text string) Endnote {
+	d := r.d
+	d.ensureEndnotes()
+
+	en := newNote(d.endNotes.Endnote)
+	d.endNotes.Endnote = append(d.endNotes.Endnote, en)
+	ref := r.noteReferenceRun("EndnoteReference")
+	ref.X().EG_RunInnerContent = append(ref.X().EG_RunInnerContent, &wml.EG_RunInnerContent{
+		EndnoteReference: &wml.CT_FtnEdnRef{IdAttr: en.IdAttr}})
+
+	note := Endnote{d, en}
+	p := note.AddParagraph()
+	p.SetStyle("EndnoteText")
+	mark := p.AddRun()
+	mark.SetStyle("EndnoteReference")
+	mark.X().EG_RunInnerContent = append(mark.X().EG_RunInnerContent, &wml.EG_RunInnerContent{EndnoteRef: wml.NewCT_Empty()})
+	p.AddRun().AddText(" " + text)
+	return note
+}
+
+// Footnotes returns the footnotes of the document, excluding the separators.
+func (d *Document) Footnotes() []Footnote {
+	ret := []Footnote{}
+	if d.footNotes == nil {
+		return ret
+	}
+	for _, fn := range d.footNotes.Footnote {
+		if isNormalNote(fn) {
+			ret = append(ret, Footnote{d, fn})
+		}
+	}
+	return ret
+}
+
+// Endnotes returns the endnotes of the document, excluding the separators.
+func (d *Document) Endnotes() []Endnote {
+	ret := []Endnote{}
+	if d.endNotes == nil {
+		return ret
+	}
+	for _, en := range d.endNotes.Endnote {
+		if isNormalNote(en) {
+			ret = append(ret, Endnote{d, en})
+		}
+	}
+	return ret
+}
+
+// ensureFootnotes creates the footnotes part and the footnote styles if they
+// don't exist.
+func (d *Document) ensureFootnotes() {
+	if d.footNotes == nil {
+		d.footNotes = wml.NewFootnotes()
+		d.footNotes.Footnote = newNoteSeparators()
+		d.docRels.AddRelationship("footnotes.xml", unioffice.FootNotesType)
+		d.ContentTypes.AddOverride("/word/footnotes.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.footnotes+xml")
+
+		pr := wml.NewCT_FtnDocProps()
+		pr.Footnote = newNoteSeparatorRefs()
+		d.Settings.X().FootnotePr = pr
+	}
+	d.Styles.ensureNoteStyles("Footnote")
+}
+
+// ensureEndnotes creates the endnotes part and the endnote styles if they
+// don't exist.
+func (d *Document) ensureEndnotes() {
+	if d.endNotes == nil {
+		d.endNotes = wml.NewEndnotes()
+		d.endNotes.Endnote = newNoteSeparators()
+		d.docRels.AddRelationship("endnotes.xml", unioffice.EndNotesType)
+		d.ContentTypes.AddOverride("/word/endnotes.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.endnotes+xml")
+
+		pr := wml.NewCT_EdnDocProps()
+		pr.Endnote = newNoteSeparatorRefs()
+		d.Settings.X().EndnotePr = pr
+	}
+	d.Styles.ensureNoteStyles("Endnote")
+}
+
+// noteReferenceRun returns a new run, styled as a note reference, following
+// the run.  If the run isn't found in the document body, the reference is
+// placed in the run itself.
+func (r Run) noteReferenceRun(style string) Run {
+	for _, p := range r.d.Paragraphs() {
+		for _, pr := range p.Runs() {
+			if pr.x == r.x {
+				ref := p.InsertRunAfter(r)
+				ref.SetStyle(style)
+				return ref
+			}
+		}
+	}
+	r.SetStyle(style)
+	return r
+}
+
+func isNormalNote(x *wml.CT_FtnEdn) bool {
+	return x.TypeAttr == wml.ST_FtnEdnUnset || x.TypeAttr == wml.ST_FtnEdnNormal
+}
+
+// newNote returns a new note with the next unused ID.
+func newNote(notes []*wml.CT_FtnEdn) *wml.CT_FtnEdn {
+	id := int64(1)
+	for _, n := range notes {
+		if n.IdAttr >= id {
+			id = n.IdAttr + 1
+		}
+	}
+	x := wml.NewCT_FtnEdn()
+	x.IdAttr = id
+	return x
+}
+
+// newNoteSeparators returns the separator and continuation separator notes
+// that Word expects at the start of the footnotes and endnotes parts.
+func newNoteSeparators() []*wml.CT_FtnEdn {
+	ret := []*wml.CT_FtnEdn{}
+	for i, typ := range []wml.ST_FtnEdn{wml.ST_FtnEdnSeparator, wml.ST_FtnEdnContinuationSeparator} {
+		x := wml.NewCT_FtnEdn()
+		x.TypeAttr = typ
+		x.IdAttr = int64(i - 1)
+
+		p := addNoteParagraph(nil, x)
+		p.Properties().Spacing().SetAfter(0)
+		p.Properties().Spacing().SetLineSpacing(12*measurement.Point, wml.ST_LineSpacingRuleAuto)
+		ic := wml.NewEG_RunInnerContent()
+		if typ == wml.ST_FtnEdnSeparator {
+			ic.Separator = wml.NewCT_Empty()
+		} else {
+			ic.ContinuationSeparator = wml.NewCT_Empty()
+		}
+		r := p.AddRun()
+		r.X().EG_RunInnerContent = append(r.X().EG_RunInnerContent, ic)
+		ret = append(ret, x)
+	}
+	return ret
+}
+
+// newNoteSeparatorRefs returns the references to the separator notes that
+// are stored in the document settings.
+func newNoteSeparatorRefs() []*wml.CT_FtnEdnSepRef {
+	return []*wml.CT_FtnEdnSepRef{{IdAttr: -1}, {IdAttr: 0}}
+}
+
+func noteParagraphs(d *Document, x *wml.CT_FtnEdn) []Paragraph {
+	ret := []Paragraph{}
+	for _, ble := range x.EG_BlockLevelElts {
+		for _, c := range ble.EG_ContentBlockContent {
+			for _, p := range c.P {
+				ret = append(ret, Paragraph{d, p})
+			}
+		}
+	}
+	return ret
+}
+
+func addNoteParagraph(d *Document, x *wml.CT_FtnEdn) Paragraph {
+	ble := wml.NewEG_BlockLevelElts()
+	x.EG_BlockLevelElts = append(x.EG_BlockLevelElts, ble)
+	c := wml.NewEG_ContentBlockContent()
+	ble.EG_ContentBlockContent = append(ble.EG_ContentBlockContent, c)
+	p := wml.NewCT_P()
+	c.P = append(c.P, p)
+	return Paragraph{d, p}
+}
+
+// ensureNoteStyles adds the text and reference styles used by footnotes or
+// endnotes, e.g. FootnoteText and FootnoteReference, if they don't exist.
+func (s Styles) ensureNoteStyles(prefix string) {
+	if _, ok := s.StyleByID(prefix + "Text"); !ok {
+		text := s.AddStyle(prefix+"Text", wml.ST_StyleTypeParagraph, false)
+		text.SetName(strings.ToLower(prefix) + " text")
+		text.SetBasedOn("Normal")
+		text.SetUISortOrder(99)
+		text.SetSemiHidden(true)
+		text.SetUnhideWhenUsed(true)
+		text.ParagraphProperties().SetSpacing(0, 0)
+		text.RunProperties().SetSize(10 * measurement.Point)
+	}
+	if _, ok := s.StyleByID(prefix + "Reference"); !ok {
+		ref := s.AddStyle(prefix+"Reference", wml.ST_StyleTypeCharacter, false)
+		ref.SetName(strings.ToLower(prefix) + " reference")
+		ref.SetBasedOn("DefaultParagraphFont")
+		ref.SetUISortOrder(99)
+		ref.SetSemiHidden(true)
+		ref.SetUnhideWhenUsed(true)
+		ref.RunProperties().SetVerticalAlignment(sharedTypes.ST_VerticalAlignRunSuperscript)
+	}
+}
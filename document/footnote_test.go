@@ -0,0 +1,98 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/document"
+)
+
+func TestFootnotes(t *testing.T) {
+	doc := document.New()
+	para := doc.AddParagraph()
+	first := para.AddRun()
+	first.AddText("First claim.")
+	first.AddFootnote("First source.")
+	second := para.AddRun()
+	second.AddText("Second claim.")
+	second.AddFootnote("Second source.")
+	doc.AddParagraph().AddRun().AddEndnote("An endnote.")
+
+	fns := doc.Footnotes()
+	if len(fns) != 2 {
+		t.Fatalf("expected 2 footnotes, got %d", len(fns))
+	}
+	if fns[0].ID() != 1 || fns[1].ID() != 2 {
+		t.Errorf("expected footnote IDs 1 and 2, got %d and %d", fns[0].ID(), fns[1].ID())
+	}
+
+	// each claim is followed by its own reference run
+	runs := para.Runs()
+	if len(runs) != 4 {
+		t.Fatalf("expected 4 runs, got %d", len(runs))
+	}
+	for i, r := range []document.Run{runs[1], runs[3]} {
+		ic := r.X().EG_RunInnerContent
+		if len(ic) != 1 || ic[0].FootnoteReference == nil || ic[0].FootnoteReference.IdAttr != int64(i+1) {
+			t.Errorf("expected reference to footnote %d", i+1)
+		}
+		if r.Style() != "FootnoteReference" {
+			t.Errorf("expected reference style, got %s", r.Style())
+		}
+	}
+
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	for _, exp := range []string{`<w:separator/>`, `<w:footnoteRef/>`, `Second source.`} {
+		if !strings.Contains(files["word/footnotes.xml"], exp) {
+			t.Errorf("expected %s in footnotes part", exp)
+		}
+	}
+	if !strings.Contains(files["word/endnotes.xml"], `An endnote.`) {
+		t.Errorf("expected endnote text in endnotes part")
+	}
+	if !strings.Contains(files["word/_rels/document.xml.rels"], `Target="footnotes.xml"`) {
+		t.Errorf("expected a relationship to the footnotes part")
+	}
+	if !strings.Contains(files["[Content_Types].xml"], `/word/footnotes.xml`) {
+		t.Errorf("expected a content type override for the footnotes part")
+	}
+
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading document: %s", err)
+	}
+	if len(doc2.Footnotes()) != 2 || len(doc2.Endnotes()) != 1 {
+		t.Fatalf("expected 2 footnotes and 1 endnote, got %d and %d", len(doc2.Footnotes()), len(doc2.Endnotes()))
+	}
+	paras := doc2.Footnotes()[1].Paragraphs()
+	if len(paras) != 1 || paras[0].Style() != "FootnoteText" || paras[0].Runs()[1].Text() != " Second source." {
+		t.Errorf("unexpected footnote content")
+	}
+	if _, ok := doc2.Styles.StyleByID("FootnoteReference"); !ok {
+		t.Errorf("expected FootnoteReference style")
+	}
+}
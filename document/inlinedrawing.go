@@ -41,6 +41,9 @@ func (i InlineDrawing) GetImage() (common.ImageRef, bool) {
 
 // SetSize sets the size of the displayed image on the page.
 func (i InlineDrawing) SetSize(w, h measurement.Distance) {
-	i.x.Extent.CxAttr = int64(float64(w*measurement.Pixel72) / measurement.EMU)
-	i.x.Extent.CyAttr = int64(float64(h*measurement.Pixel72) / measurement.EMU)
+	i.x.Extent.CxAttr = w.ToEMU()
+	i.x.Extent.CyAttr = h.ToEMU()
+	if i.x.Graphic != nil {
+		setPictureExtent(i.x.Graphic.GraphicData, i.x.Extent.CxAttr, i.x.Extent.CyAttr)
+	}
 }
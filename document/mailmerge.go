@@ -4,6 +4,7 @@ package document
 
 import (
 	"bytes"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -89,17 +90,8 @@ func parseField(mf string) mergeFieldInfo {
 
 // MergeFields returns the list of all mail merge fields found in the document.
 func (d Document) mergeFields() []mergeFieldInfo {
-	paragraphs := []Paragraph{}
 	mf := []mergeFieldInfo{}
-	for _, t := range d.Tables() {
-		for _, r := range t.Rows() {
-			for _, c := range r.Cells() {
-				paragraphs = append(paragraphs, c.Paragraphs()...)
-			}
-		}
-	}
-	paragraphs = append(paragraphs, d.Paragraphs()...)
-	for _, p := range paragraphs {
+	for _, p := range d.Paragraphs() {
 		runs := p.Runs()
 		begIdx := -1
 		sepIdx := -1
@@ -160,16 +152,37 @@ func (d Document) MergeFields() []string {
 	for k := range flds {
 		ret = append(ret, k)
 	}
+	sort.Strings(ret)
 	return ret
 }
 
 // MailMerge finds mail merge fields and replaces them with the text provided.  It also removes
-// the mail merge source info from the document settings.
+// the mail merge source info from the document settings.  Fields without a
+// value are replaced with empty text.
 func (d *Document) MailMerge(mergeContent map[string]string) {
+	d.fillMergeFields(mergeContent, true)
+	d.Settings.RemoveMailMerge()
+}
+
+// FillMergeFields replaces each mail merge field with the literal value for its
+// field name, removing the field itself so Word displays the value rather than
+// updating the field.  Fields without a value are left intact, unless
+// blankMissing is true in which case they are replaced with empty text.  It
+// returns the number of fields replaced.
+func (d *Document) FillMergeFields(values map[string]string, blankMissing bool) int {
+	return d.fillMergeFields(values, blankMissing)
+}
+
+func (d *Document) fillMergeFields(mergeContent map[string]string, blankMissing bool) int {
 	fields := d.mergeFields()
 	remove := map[Paragraph][]Run{}
+	n := 0
 	for _, v := range fields {
 		repText, ok := mergeContent[v.fieldName]
+		if !ok && !blankMissing {
+			continue
+		}
+		n++
 		if ok {
 			if v.upper {
 				repText = strings.ToUpper(repText)
@@ -213,8 +226,14 @@ func (d *Document) MailMerge(mergeContent map[string]string) {
 		} else {
 			// non-simple so we'll remove the extra stuff
 			runs := v.para.Runs()
+			// without a separate or a run after it, there is no result run so
+			// the begin run is reused to hold the text
+			textIdx := v.sepIdx + 1
+			if v.sepIdx == -1 || textIdx > v.endIdx {
+				textIdx = v.begIdx
+			}
 			for i := v.begIdx; i <= v.endIdx; i++ {
-				if i == v.sepIdx+1 {
+				if i == textIdx {
 					runs[i].ClearContent()
 					runs[i].AddText(repText)
 				} else {
@@ -229,6 +248,5 @@ func (d *Document) MailMerge(mergeContent map[string]string) {
 			p.RemoveRun(r)
 		}
 	}
-
-	d.Settings.RemoveMailMerge()
+	return n
 }
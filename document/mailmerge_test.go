@@ -2,6 +2,8 @@ package document
 
 import (
 	"testing"
+
+	"github.com/unidoc/unioffice/schema/soo/wml"
 )
 
 func TestParseMergeField(t *testing.T) {
@@ -86,3 +88,61 @@ func TestParseMergeField(t *testing.T) {
 		})
 	}
 }
+
+// addMergeField adds a merge field split across runs the way Word writes them.
+func addMergeField(p Paragraph, name string) {
+	r := p.AddRun()
+	r.newIC().FldChar = &wml.CT_FldChar{FldCharTypeAttr: wml.ST_FldCharTypeBegin}
+	ic := r.newIC()
+	ic.InstrText = wml.NewCT_Text()
+	ic.InstrText.Content = " MERGEFIELD " + name + " "
+	r.newIC().FldChar = &wml.CT_FldChar{FldCharTypeAttr: wml.ST_FldCharTypeSeparate}
+	p.AddRun().AddText("\u00ab" + name + "\u00bb")
+	p.AddRun().newIC().FldChar = &wml.CT_FldChar{FldCharTypeAttr: wml.ST_FldCharTypeEnd}
+}
+
+func TestFillMergeFields(t *testing.T) {
+	doc := New()
+	p := doc.AddParagraph()
+	p.AddRun().AddText("Dear ")
+	addMergeField(p, "FirstName")
+	p.AddRun().AddText(" ")
+	p.AddRun().AddField("MERGEFIELD LastName")
+	cell := doc.AddTable().AddRow().AddCell()
+	addMergeField(cell.AddParagraph(), "Title")
+
+	if got := doc.MergeFields(); len(got) != 3 || got[0] != "FirstName" ||
+		got[1] != "LastName" || got[2] != "Title" {
+		t.Fatalf("unexpected merge fields %v", got)
+	}
+
+	n := doc.FillMergeFields(map[string]string{"FirstName": "John", "LastName": "Smith"}, false)
+	if n != 2 {
+		t.Errorf("expected 2 fields to be filled, got %d", n)
+	}
+	text := ""
+	for _, r := range p.Runs() {
+		for _, ic := range r.X().EG_RunInnerContent {
+			if ic.FldChar != nil || ic.InstrText != nil {
+				t.Errorf("expected the filled fields to be removed")
+			}
+		}
+		text += r.Text()
+	}
+	if text != "Dear John Smith" {
+		t.Errorf("expected Dear John Smith, got %q", text)
+	}
+	if got := doc.MergeFields(); len(got) != 1 || got[0] != "Title" {
+		t.Errorf("expected the missing field to be left intact, got %v", got)
+	}
+
+	if n := doc.FillMergeFields(nil, true); n != 1 {
+		t.Errorf("expected 1 field to be blanked, got %d", n)
+	}
+	if got := len(doc.MergeFields()); got != 0 {
+		t.Errorf("expected no remaining merge fields, got %d", got)
+	}
+	if err := doc.Validate(); err != nil {
+		t.Errorf("created an invalid document: %s", err)
+	}
+}
@@ -8,6 +8,8 @@
 package document
 
 import (
+	"fmt"
+
 	"github.com/unidoc/unioffice"
 	"github.com/unidoc/unioffice/schema/soo/ofc/sharedTypes"
 	"github.com/unidoc/unioffice/schema/soo/wml"
@@ -45,9 +47,6 @@ func (n Numbering) InitializeDefault() {
 
 	n.x.AbstractNum = append(n.x.AbstractNum, abs)
 	abs.AbstractNumIdAttr = 1
-	const indentStart = 720
-	const indentDelta = 720
-	const hangingIndent = 360
 	for i := 0; i < 9; i++ {
 		lvl := wml.NewCT_Lvl()
 		lvl.IlvlAttr = int64(i)
@@ -72,14 +71,7 @@ func (n Numbering) InitializeDefault() {
 		lvl.RPr.RFonts.HAnsiAttr = unioffice.String("Symbol")
 		lvl.RPr.RFonts.HintAttr = wml.ST_HintDefault
 
-		lvl.PPr = wml.NewCT_PPrGeneral()
-
-		indent := int64(i*indentDelta + indentStart)
-		lvl.PPr.Ind = wml.NewCT_Ind()
-		lvl.PPr.Ind.LeftAttr = &wml.ST_SignedTwipsMeasure{}
-		lvl.PPr.Ind.LeftAttr.Int64 = unioffice.Int64(indent)
-		lvl.PPr.Ind.HangingAttr = &sharedTypes.ST_TwipsMeasure{}
-		lvl.PPr.Ind.HangingAttr.ST_UnsignedDecimalNumber = unioffice.Uint64(uint64(hangingIndent))
+		setListIndent(lvl, i)
 
 		abs.Lvl = append(abs.Lvl, lvl)
 	}
@@ -109,7 +101,7 @@ func (n Numbering) AddDefinition() NumberingDefinition {
 			nextID = nd.AbstractNumberID() + 1
 		}
 	}
-	nx.NumIdAttr = nextID
+	nx.NumIdAttr = n.nextNumID()
 	nx.AbstractNumId = wml.NewCT_DecimalNumber()
 	nx.AbstractNumId.ValAttr = nextID
 
@@ -120,3 +112,91 @@ func (n Numbering) AddDefinition() NumberingDefinition {
 	n.x.Num = append(n.x.Num, nx)
 	return NumberingDefinition{an}
 }
+
+// AddBulletList adds a new numbering definition for a bulleted list with nine
+// levels, each indented further than the previous one.
+func (n Numbering) AddBulletList() NumberingDefinition {
+	nd := n.AddDefinition()
+	nd.SetMultiLevelType(wml.ST_MultiLevelTypeHybridMultilevel)
+	for i := 0; i < 9; i++ {
+		lvl := nd.AddLevel()
+		lvl.SetFormat(wml.ST_NumberFormatBullet)
+		lvl.SetText("")
+		lvl.SetAlignment(wml.ST_JcLeft)
+		lvl.RunProperties().SetFontFamily("Symbol")
+		setListIndent(lvl.X(), i)
+	}
+	return nd
+}
+
+// AddNumberedList adds a new numbering definition for a numbered list with
+// nine levels.  The levels cycle through decimal, lower case letter and lower
+// case roman numbering (1., a., i.).
+func (n Numbering) AddNumberedList() NumberingDefinition {
+	nd := n.AddDefinition()
+	nd.SetMultiLevelType(wml.ST_MultiLevelTypeHybridMultilevel)
+	formats := []wml.ST_NumberFormat{
+		wml.ST_NumberFormatDecimal,
+		wml.ST_NumberFormatLowerLetter,
+		wml.ST_NumberFormatLowerRoman,
+	}
+	for i := 0; i < 9; i++ {
+		lvl := nd.AddLevel()
+		lvl.SetFormat(formats[i%len(formats)])
+		lvl.SetText(fmt.Sprintf("%%%d.", i+1))
+		lvl.SetAlignment(wml.ST_JcLeft)
+		setListIndent(lvl.X(), i)
+	}
+	return nd
+}
+
+// RestartNumbering adds a new instance of a numbering definition whose
+// numbering starts over at each level's start value.  The returned numbering ID
+// is distinct from any other in the document and can be assigned to
+// paragraphs with Paragraph.SetNumberingDefinitionByID.
+func (n Numbering) RestartNumbering(nd NumberingDefinition) int64 {
+	num := wml.NewCT_Num()
+	num.NumIdAttr = n.nextNumID()
+	num.AbstractNumId = wml.NewCT_DecimalNumber()
+	num.AbstractNumId.ValAttr = nd.AbstractNumberID()
+	for _, lvl := range nd.x.Lvl {
+		ov := wml.NewCT_NumLvl()
+		ov.IlvlAttr = lvl.IlvlAttr
+		ov.StartOverride = wml.NewCT_DecimalNumber()
+		ov.StartOverride.ValAttr = 1
+		if lvl.Start != nil {
+			ov.StartOverride.ValAttr = lvl.Start.ValAttr
+		}
+		num.LvlOverride = append(num.LvlOverride, ov)
+	}
+	n.x.Num = append(n.x.Num, num)
+	return num.NumIdAttr
+}
+
+// nextNumID returns the next unused numbering instance ID.
+func (n Numbering) nextNumID() int64 {
+	id := int64(1)
+	for _, num := range n.x.Num {
+		if num.NumIdAttr >= id {
+			id = num.NumIdAttr + 1
+		}
+	}
+	return id
+}
+
+// setListIndent sets the indentation of a list level, increasing with the
+// depth of the level.
+func setListIndent(lvl *wml.CT_Lvl, depth int) {
+	const indentStart = 720
+	const indentDelta = 720
+	const hangingIndent = 360
+	if lvl.PPr == nil {
+		lvl.PPr = wml.NewCT_PPrGeneral()
+	}
+	indent := int64(depth*indentDelta + indentStart)
+	lvl.PPr.Ind = wml.NewCT_Ind()
+	lvl.PPr.Ind.LeftAttr = &wml.ST_SignedTwipsMeasure{}
+	lvl.PPr.Ind.LeftAttr.Int64 = unioffice.Int64(indent)
+	lvl.PPr.Ind.HangingAttr = &sharedTypes.ST_TwipsMeasure{}
+	lvl.PPr.Ind.HangingAttr.ST_UnsignedDecimalNumber = unioffice.Uint64(uint64(hangingIndent))
+}
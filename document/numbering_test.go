@@ -0,0 +1,115 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/unidoc/unioffice/document"
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+func TestNestedNumberedList(t *testing.T) {
+	doc := document.New()
+	nd := doc.Numbering.AddNumberedList()
+	for _, item := range []struct {
+		text  string
+		level int
+	}{
+		{"one", 0},
+		{"one.a", 1},
+		{"one.a.i", 2},
+		{"one.a.ii", 2},
+		{"one.b", 1},
+		{"two", 0},
+	} {
+		p := doc.AddParagraph()
+		p.SetNumberingDefinition(nd)
+		p.SetNumberingLevel(item.level)
+		p.AddRun().AddText(item.text)
+	}
+
+	restarted := doc.Numbering.RestartNumbering(nd)
+	p := doc.AddParagraph()
+	p.SetNumberingDefinitionByID(restarted)
+	p.SetNumberingLevel(0)
+	p.AddRun().AddText("one again")
+
+	if err := doc.Validate(); err != nil {
+		t.Errorf("created an invalid document: %s", err)
+	}
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving document: %s", err)
+	}
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading document: %s", err)
+	}
+
+	paras := doc2.Paragraphs()
+	if len(paras) != 7 {
+		t.Fatalf("expected 7 paragraphs, got %d", len(paras))
+	}
+	numID := paras[0].X().PPr.NumPr.NumId.ValAttr
+	for i, lvl := range []int64{0, 1, 2, 2, 1, 0} {
+		numPr := paras[i].X().PPr.NumPr
+		if numPr.NumId.ValAttr != numID {
+			t.Errorf("paragraph %d: expected numbering id %d, got %d", i, numID, numPr.NumId.ValAttr)
+		}
+		if numPr.Ilvl.ValAttr != lvl {
+			t.Errorf("paragraph %d: expected level %d, got %d", i, lvl, numPr.Ilvl.ValAttr)
+		}
+	}
+	if got := paras[6].X().PPr.NumPr.NumId.ValAttr; got == numID {
+		t.Errorf("expected restarted list to have a distinct numbering id")
+	}
+
+	seen := map[int64]bool{}
+	for _, num := range doc2.Numbering.X().Num {
+		if seen[num.NumIdAttr] {
+			t.Errorf("duplicate numbering id %d", num.NumIdAttr)
+		}
+		seen[num.NumIdAttr] = true
+	}
+
+	var def document.NumberingDefinition
+	for _, d := range doc2.Numbering.Definitions() {
+		if d.AbstractNumberID() == nd.AbstractNumberID() {
+			def = d
+		}
+	}
+	if def.X() == nil {
+		t.Fatalf("expected the numbered list definition to round trip")
+	}
+	lvls := def.Levels()
+	if len(lvls) != 9 {
+		t.Fatalf("expected 9 levels, got %d", len(lvls))
+	}
+	if lvls[1].X().NumFmt.ValAttr != wml.ST_NumberFormatLowerLetter || *lvls[2].X().LvlText.ValAttr != "%3." {
+		t.Errorf("unexpected format for nested levels")
+	}
+}
+
+func TestBulletList(t *testing.T) {
+	doc := document.New()
+	before := len(doc.Numbering.X().Num)
+	nd := doc.Numbering.AddBulletList()
+	if len(doc.Numbering.X().Num) != before+1 {
+		t.Fatalf("expected a numbering instance to be added")
+	}
+	for _, lvl := range nd.Levels() {
+		if lvl.X().NumFmt.ValAttr != wml.ST_NumberFormatBullet {
+			t.Errorf("expected bullet format, got %s", lvl.X().NumFmt.ValAttr)
+		}
+	}
+	if err := doc.Validate(); err != nil {
+		t.Errorf("created an invalid document: %s", err)
+	}
+}
@@ -8,6 +8,8 @@
 package document
 
 import (
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/schema/soo/ofc/sharedTypes"
 	"github.com/unidoc/unioffice/schema/soo/wml"
 )
 
@@ -86,6 +88,13 @@ func (p Paragraph) AddRun() Run {
 	return Run{p.d, r}
 }
 
+// AddPageBreak adds a new run containing a page break to the paragraph.
+func (p Paragraph) AddPageBreak() Run {
+	r := p.AddRun()
+	r.AddPageBreak()
+	return r
+}
+
 // Runs returns all of the runs in a paragraph.
 func (p Paragraph) Runs() []Run {
 	ret := []Run{}
@@ -166,9 +175,35 @@ func (p Paragraph) AddHyperLink() HyperLink {
 	return HyperLink{p.d, pc.Hyperlink}
 }
 
+// AddInternalHyperlink adds a hyperlink with the given text that links to a
+// bookmark within the document.
+func (p Paragraph) AddInternalHyperlink(bookmarkName, text string) HyperLink {
+	hl := p.AddHyperLink()
+	hl.x.AnchorAttr = unioffice.String(bookmarkName)
+	hl.x.HistoryAttr = &sharedTypes.ST_OnOff{}
+	hl.x.HistoryAttr.Bool = unioffice.Bool(true)
+	hl.AddRun().AddText(text)
+	return hl
+}
+
 // AddBookmark adds a bookmark to a document that can then be used from a hyperlink. Name is a document
-// unique name that identifies the bookmark so it can be referenced from hyperlinks.
+// unique name that identifies the bookmark so it can be referenced from hyperlinks.  Names
+// that aren't valid according to ValidateBookmarkName or that are already in use are logged
+// and reported by Document.Validate, but the bookmark is still added.
 func (p Paragraph) AddBookmark(name string) Bookmark {
+	if err := ValidateBookmarkName(name); err != nil {
+		unioffice.Log("%s", err)
+	}
+	id := int64(0)
+	for _, bm := range p.d.Bookmarks() {
+		if bm.Name() == name {
+			unioffice.Log("duplicate bookmark name %s", name)
+		}
+		if bm.ID() >= id {
+			id = bm.ID() + 1
+		}
+	}
+
 	pc := wml.NewEG_PContent()
 	rc := wml.NewEG_ContentRunContent()
 	pc.EG_ContentRunContent = append(pc.EG_ContentRunContent, rc)
@@ -178,11 +213,13 @@ func (p Paragraph) AddBookmark(name string) Bookmark {
 
 	markEl := wml.NewEG_RangeMarkupElements()
 	bmStart := wml.NewCT_Bookmark()
+	bmStart.IdAttr = id
 	markEl.BookmarkStart = bmStart
 	relt.EG_RangeMarkupElements = append(relt.EG_RangeMarkupElements, markEl)
 
 	markEl = wml.NewEG_RangeMarkupElements()
 	markEl.BookmarkEnd = wml.NewCT_MarkupRange()
+	markEl.BookmarkEnd.IdAttr = id
 
 	relt.EG_RangeMarkupElements = append(relt.EG_RangeMarkupElements, markEl)
 
@@ -219,12 +256,14 @@ func (p Paragraph) SetNumberingDefinition(nd NumberingDefinition) {
 	for _, n := range p.d.Numbering.x.Num {
 		if n.AbstractNumId != nil && n.AbstractNumId.ValAttr == nd.AbstractNumberID() {
 			numID = n.NumIdAttr
+			break
 		}
 	}
 	if numID == -1 {
 		num := wml.NewCT_Num()
+		num.NumIdAttr = p.d.Numbering.nextNumID()
+		numID = num.NumIdAttr
 		p.d.Numbering.x.Num = append(p.d.Numbering.x.Num, num)
-		num.NumIdAttr = int64(len(p.d.Numbering.x.Num))
 		num.AbstractNumId = wml.NewCT_DecimalNumber()
 		num.AbstractNumId.ValAttr = nd.AbstractNumberID()
 	}
@@ -0,0 +1,168 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document
+
+import (
+	"regexp"
+	"strings"
+)
+
+// replaceFunc returns the start and end offsets of each match within s along
+// with the text to replace each match with.
+type replaceFunc func(s string) (matches [][]int, repls []string)
+
+// Replace replaces each occurrence of find with replace in the paragraphs of
+// the document body, tables, headers and footers, returning the number of
+// replacements made.  Word often splits text across several runs, so matches
+// may span runs within a paragraph.  The replacement text takes the formatting
+// of the run that the match starts in.  Runs containing anything other than
+// text, such as fields, tabs or images, are never modified and matches can't
+// span them.
+func (d *Document) Replace(find, replace string) int {
+	if find == "" {
+		return 0
+	}
+	return d.replace(func(s string) ([][]int, []string) {
+		matches := [][]int{}
+		repls := []string{}
+		for off := 0; ; {
+			idx := strings.Index(s[off:], find)
+			if idx == -1 {
+				break
+			}
+			matches = append(matches, []int{off + idx, off + idx + len(find)})
+			repls = append(repls, replace)
+			off += idx + len(find)
+		}
+		return matches, repls
+	})
+}
+
+// ReplaceRegexp replaces each match of re with repl, returning the number of
+// replacements made.  Within repl, $ signs are interpreted as in
+// regexp.Regexp.Expand.  It otherwise behaves the same as Replace.  Empty
+// matches are ignored.
+func (d *Document) ReplaceRegexp(re *regexp.Regexp, repl string) int {
+	return d.replace(func(s string) ([][]int, []string) {
+		matches := [][]int{}
+		repls := []string{}
+		for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+			if m[0] == m[1] {
+				continue
+			}
+			matches = append(matches, m[:2])
+			repls = append(repls, string(re.ExpandString(nil, repl, s, m)))
+		}
+		return matches, repls
+	})
+}
+
+func (d *Document) replace(fn replaceFunc) int {
+	paras := d.Paragraphs()
+	for _, h := range d.Headers() {
+		paras = append(paras, h.Paragraphs()...)
+	}
+	for _, f := range d.Footers() {
+		paras = append(paras, f.Paragraphs()...)
+	}
+	n := 0
+	for _, p := range paras {
+		n += p.replace(fn)
+	}
+	return n
+}
+
+// replace performs the replacements within consecutive runs of text in the
+// paragraph.
+func (p Paragraph) replace(fn replaceFunc) int {
+	n := 0
+	segment := []Run{}
+	for _, r := range p.Runs() {
+		if r.isTextOnly() {
+			segment = append(segment, r)
+			continue
+		}
+		n += p.replaceInRuns(segment, fn)
+		segment = segment[:0]
+	}
+	n += p.replaceInRuns(segment, fn)
+	return n
+}
+
+func (p Paragraph) replaceInRuns(runs []Run, fn replaceFunc) int {
+	if len(runs) == 0 {
+		return 0
+	}
+	texts := make([]string, len(runs))
+	offsets := make([]int, len(runs))
+	sb := strings.Builder{}
+	for i, r := range runs {
+		offsets[i] = sb.Len()
+		texts[i] = r.Text()
+		sb.WriteString(texts[i])
+	}
+	matches, repls := fn(sb.String())
+	if len(matches) == 0 {
+		return 0
+	}
+	changed := make([]bool, len(runs))
+	runAt := func(pos int) int {
+		for i := len(offsets) - 1; i > 0; i-- {
+			if offsets[i] <= pos {
+				return i
+			}
+		}
+		return 0
+	}
+
+	// working backwards keeps the offsets of earlier matches valid
+	for m := len(matches) - 1; m >= 0; m-- {
+		start, end := matches[m][0], matches[m][1]
+		first, last := runAt(start), runAt(end-1)
+		if first == last {
+			t := texts[first]
+			texts[first] = t[:start-offsets[first]] + repls[m] + t[end-offsets[first]:]
+		} else {
+			texts[first] = texts[first][:start-offsets[first]] + repls[m]
+			for i := first + 1; i < last; i++ {
+				texts[i] = ""
+				changed[i] = true
+			}
+			texts[last] = texts[last][end-offsets[last]:]
+			changed[last] = true
+		}
+		changed[first] = true
+	}
+
+	for i, r := range runs {
+		if !changed[i] {
+			continue
+		}
+		if texts[i] == "" {
+			p.RemoveRun(r)
+			continue
+		}
+		r.ClearContent()
+		r.AddText(texts[i])
+	}
+	return len(matches)
+}
+
+// isTextOnly returns true if the run contains only text.
+func (r Run) isTextOnly() bool {
+	if len(r.x.EG_RunInnerContent) == 0 {
+		return false
+	}
+	for _, ic := range r.x.EG_RunInnerContent {
+		if ic.T == nil || ic.Br != nil || ic.Tab != nil || ic.FldChar != nil ||
+			ic.InstrText != nil || ic.Drawing != nil {
+			return false
+		}
+	}
+	return true
+}
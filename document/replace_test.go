@@ -0,0 +1,94 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/unidoc/unioffice/document"
+)
+
+func paragraphText(p document.Paragraph) string {
+	s := ""
+	for _, r := range p.Runs() {
+		s += r.Text()
+	}
+	return s
+}
+
+func TestReplaceAcrossRuns(t *testing.T) {
+	doc := document.New()
+	p := doc.AddParagraph()
+	r := p.AddRun()
+	r.AddText("Dear Mr. Sm")
+	r = p.AddRun()
+	r.Properties().SetBold(true)
+	r.AddText("it")
+	r = p.AddRun()
+	r.Properties().SetItalic(true)
+	r.AddText("h, welcome Mr. Smith")
+
+	field := doc.AddParagraph()
+	field.AddRun().AddText("Mr. ")
+	field.AddRun().AddField(document.FieldDate)
+	field.AddRun().AddText("Smith")
+
+	if n := doc.Replace("Mr. Smith", "Ms. Jones"); n != 2 {
+		t.Errorf("expected 2 replacements, got %d", n)
+	}
+	if got := paragraphText(p); got != "Dear Ms. Jones, welcome Ms. Jones" {
+		t.Errorf("unexpected paragraph text %q", got)
+	}
+	runs := p.Runs()
+	if len(runs) != 2 {
+		t.Fatalf("expected the emptied run to be removed, got %d runs", len(runs))
+	}
+	if runs[0].Text() != "Dear Ms. Jones" || runs[0].Properties().IsBold() {
+		t.Errorf("expected the replacement to use the formatting of the first run, got %q", runs[0].Text())
+	}
+	if runs[1].Text() != ", welcome Ms. Jones" || !runs[1].Properties().IsItalic() {
+		t.Errorf("expected the remainder to keep its formatting, got %q", runs[1].Text())
+	}
+	if got := len(field.Runs()); got != 3 {
+		t.Errorf("expected matches not to span fields, got %d runs", got)
+	}
+
+	if n := doc.Replace("missing", "x"); n != 0 {
+		t.Errorf("expected no replacements, got %d", n)
+	}
+	if n := doc.Replace("", "x"); n != 0 {
+		t.Errorf("expected no replacements for an empty string, got %d", n)
+	}
+	if err := doc.Validate(); err != nil {
+		t.Errorf("created an invalid document: %s", err)
+	}
+}
+
+func TestReplaceRegexp(t *testing.T) {
+	doc := document.New()
+	p := doc.AddParagraph()
+	p.AddRun().AddText("Invoice 2018-")
+	p.AddRun().AddText("01-15 and 2019-12-31")
+	hdr := doc.AddHeader()
+	hdr.AddParagraph().AddRun().AddText("Printed 2020-02-29")
+
+	re := regexp.MustCompile(`(\d{4})-(\d{2})-(\d{2})`)
+	if n := doc.ReplaceRegexp(re, "$3/$2/$1"); n != 3 {
+		t.Errorf("expected 3 replacements, got %d", n)
+	}
+	if got := paragraphText(p); got != "Invoice 15/01/2018 and 31/12/2019" {
+		t.Errorf("unexpected paragraph text %q", got)
+	}
+	if got := paragraphText(hdr.Paragraphs()[0]); got != "Printed 29/02/2020" {
+		t.Errorf("unexpected header text %q", got)
+	}
+	if n := doc.ReplaceRegexp(regexp.MustCompile(`x*`), "y"); n != 0 {
+		t.Errorf("expected empty matches to be ignored, got %d", n)
+	}
+}
@@ -112,6 +112,17 @@ func (r Run) AddField(code string) {
 	r.AddFieldWithFormatting(code, "", true)
 }
 
+// AddPageNumberField adds a field displaying the current page number.
+func (r Run) AddPageNumberField() {
+	r.AddField(FieldCurrentPage)
+}
+
+// AddFieldNumberOfPages adds a field displaying the number of pages in the
+// document.
+func (r Run) AddFieldNumberOfPages() {
+	r.AddField(FieldNumberOfPages)
+}
+
 // Properties returns the run properties.
 func (r Run) Properties() RunProperties {
 	if r.x.RPr == nil {
@@ -120,6 +131,21 @@ func (r Run) Properties() RunProperties {
 	return RunProperties{r.x.RPr}
 }
 
+// Style returns the character style of a run, or an empty string if it is
+// unset.
+func (r Run) Style() string {
+	if r.x.RPr != nil && r.x.RPr.RStyle != nil {
+		return r.x.RPr.RStyle.ValAttr
+	}
+	return ""
+}
+
+// SetStyle sets the character style of a run and is identical to setting it on
+// the run's Properties()
+func (r Run) SetStyle(s string) {
+	r.Properties().SetStyle(s)
+}
+
 // AddBreak adds a line break to a run.
 func (r Run) AddBreak() {
 	ic := r.newIC()
@@ -147,6 +173,20 @@ func (r Run) DrawingAnchored() []AnchoredDrawing {
 	return ret
 }
 
+// DrawingInline returns a slice of InlineDrawings.
+func (r Run) DrawingInline() []InlineDrawing {
+	ret := []InlineDrawing{}
+	for _, ic := range r.x.EG_RunInnerContent {
+		if ic.Drawing == nil {
+			continue
+		}
+		for _, inl := range ic.Drawing.Inline {
+			ret = append(ret, InlineDrawing{r.d, inl})
+		}
+	}
+	return ret
+}
+
 // AddDrawingAnchored adds an anchored (floating) drawing from an ImageRef.
 func (r Run) AddDrawingAnchored(img common.ImageRef) (AnchoredDrawing, error) {
 	ic := r.newIC()
@@ -175,8 +215,8 @@ func (r Run) AddDrawingAnchored(img common.ImageRef) (AnchoredDrawing, error) {
 	anchor.PositionV.Choice = &wml.WdCT_PosVChoice{}
 	anchor.PositionV.Choice.PosOffset = unioffice.Int32(0)
 
-	anchor.Extent.CxAttr = int64(float64(img.Size().X*measurement.Pixel72) / measurement.EMU)
-	anchor.Extent.CyAttr = int64(float64(img.Size().Y*measurement.Pixel72) / measurement.EMU)
+	anchor.Extent.CxAttr = (measurement.Distance(img.Size().X) * measurement.Pixel72).ToEMU()
+	anchor.Extent.CyAttr = (measurement.Distance(img.Size().Y) * measurement.Pixel72).ToEMU()
 	anchor.Choice = &wml.WdEG_WrapTypeChoice{}
 	anchor.Choice.WrapSquare = wml.NewWdCT_WrapSquare()
 	anchor.Choice.WrapSquare.WrapTextAttr = wml.WdST_WrapTextBothSides
@@ -209,8 +249,8 @@ func (r Run) AddDrawingAnchored(img common.ImageRef) (AnchoredDrawing, error) {
 	p.SpPr.Xfrm.Off.XAttr.ST_CoordinateUnqualified = unioffice.Int64(0)
 	p.SpPr.Xfrm.Off.YAttr.ST_CoordinateUnqualified = unioffice.Int64(0)
 	p.SpPr.Xfrm.Ext = dml.NewCT_PositiveSize2D()
-	p.SpPr.Xfrm.Ext.CxAttr = int64(img.Size().X * measurement.Point)
-	p.SpPr.Xfrm.Ext.CyAttr = int64(img.Size().Y * measurement.Point)
+	p.SpPr.Xfrm.Ext.CxAttr = (measurement.Distance(img.Size().X) * measurement.Pixel72).ToEMU()
+	p.SpPr.Xfrm.Ext.CyAttr = (measurement.Distance(img.Size().Y) * measurement.Pixel72).ToEMU()
 	// required by Word on OSX for the image to display
 	p.SpPr.PrstGeom = dml.NewCT_PresetGeometry2D()
 	p.SpPr.PrstGeom.PrstAttr = dml.ST_ShapeTypeRect
@@ -242,8 +282,8 @@ func (r Run) AddDrawingInline(img common.ImageRef) (InlineDrawing, error) {
 	inl.DistBAttr = unioffice.Uint32(0)
 	inl.DistRAttr = unioffice.Uint32(0)
 
-	inl.Extent.CxAttr = int64(float64(img.Size().X*measurement.Pixel72) / measurement.EMU)
-	inl.Extent.CyAttr = int64(float64(img.Size().Y*measurement.Pixel72) / measurement.EMU)
+	inl.Extent.CxAttr = (measurement.Distance(img.Size().X) * measurement.Pixel72).ToEMU()
+	inl.Extent.CyAttr = (measurement.Distance(img.Size().Y) * measurement.Pixel72).ToEMU()
 
 	// Mac Word chokes if the ID is greater than an int32, even though the field is a
 	// uint32 in the XSD
@@ -273,11 +313,25 @@ func (r Run) AddDrawingInline(img common.ImageRef) (InlineDrawing, error) {
 	p.SpPr.Xfrm.Off.XAttr.ST_CoordinateUnqualified = unioffice.Int64(0)
 	p.SpPr.Xfrm.Off.YAttr.ST_CoordinateUnqualified = unioffice.Int64(0)
 	p.SpPr.Xfrm.Ext = dml.NewCT_PositiveSize2D()
-	p.SpPr.Xfrm.Ext.CxAttr = int64(img.Size().X * measurement.Point)
-	p.SpPr.Xfrm.Ext.CyAttr = int64(img.Size().Y * measurement.Point)
+	p.SpPr.Xfrm.Ext.CxAttr = (measurement.Distance(img.Size().X) * measurement.Pixel72).ToEMU()
+	p.SpPr.Xfrm.Ext.CyAttr = (measurement.Distance(img.Size().Y) * measurement.Pixel72).ToEMU()
 	// required by Word on OSX for the image to display
 	p.SpPr.PrstGeom = dml.NewCT_PresetGeometry2D()
 	p.SpPr.PrstGeom.PrstAttr = dml.ST_ShapeTypeRect
 
 	return inline, nil
 }
+
+// setPictureExtent keeps the size of the picture in a drawing consistent with
+// the size of its extent.
+func setPictureExtent(gd *dml.CT_GraphicalObjectData, cx, cy int64) {
+	if gd == nil {
+		return
+	}
+	for _, a := range gd.Any {
+		if p, ok := a.(*pic.Pic); ok && p.SpPr != nil && p.SpPr.Xfrm != nil && p.SpPr.Xfrm.Ext != nil {
+			p.SpPr.Xfrm.Ext.CxAttr = cx
+			p.SpPr.Xfrm.Ext.CyAttr = cy
+		}
+	}
+}
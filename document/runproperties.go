@@ -8,6 +8,8 @@
 package document
 
 import (
+	"math"
+
 	"github.com/unidoc/unioffice"
 	"github.com/unidoc/unioffice/color"
 	"github.com/unidoc/unioffice/measurement"
@@ -25,7 +27,7 @@ func (r RunProperties) X() *wml.CT_RPr {
 	return r.x
 }
 
-// SetStyle sets the font size.
+// SetStyle sets the character style of a run.
 func (r RunProperties) SetStyle(style string) {
 	if style == "" {
 		r.x.RStyle = nil
@@ -218,6 +220,22 @@ func (r RunProperties) SetColor(c color.Color) {
 	r.x.Color.ValAttr.ST_HexColorRGB = c.AsRGBString()
 }
 
+// SetThemeColor sets the run color to a theme color, lightened or darkened by
+// tint which ranges from -1.0 (darkest) to 1.0 (lightest).
+func (r RunProperties) SetThemeColor(t wml.ST_ThemeColor, tint float64) {
+	r.x.Color = wml.NewCT_Color()
+	c := Color{r.x.Color}
+	c.SetColor(color.Auto)
+	c.SetThemeColor(t)
+	tint = math.Max(-1, math.Min(1, tint))
+	switch {
+	case tint > 0:
+		c.SetThemeTint(uint8(math.Round(255 * (1 - tint))))
+	case tint < 0:
+		c.SetThemeShade(uint8(math.Round(255 * (1 + tint))))
+	}
+}
+
 // SetHighlight highlights text in a specified color.
 func (r RunProperties) SetHighlight(c wml.ST_HighlightColor) {
 	r.x.Highlight = wml.NewCT_Highlight()
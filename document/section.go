@@ -12,6 +12,7 @@ import (
 
 	"github.com/unidoc/unioffice"
 	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/ofc/sharedTypes"
 	"github.com/unidoc/unioffice/schema/soo/wml"
 )
 
@@ -26,10 +27,22 @@ func (s Section) X() *wml.CT_SectPr {
 	return s.x
 }
 
-// SetHeader sets a section header.
+// SetHeader sets a section header, replacing any existing header of the same
+// type.  Setting an even page header also enables separate even and odd
+// headers in the document settings, while setting a first page header enables
+// a distinct first page for the section.
 func (s Section) SetHeader(h Header, t wml.ST_HdrFtr) {
-	hdrRef := wml.NewEG_HdrFtrReferences()
-	s.x.EG_HdrFtrReferences = append(s.x.EG_HdrFtrReferences, hdrRef)
+	var hdrRef *wml.EG_HdrFtrReferences
+	for _, ref := range s.x.EG_HdrFtrReferences {
+		if ref.HeaderReference != nil && ref.HeaderReference.TypeAttr == t {
+			hdrRef = ref
+			break
+		}
+	}
+	if hdrRef == nil {
+		hdrRef = wml.NewEG_HdrFtrReferences()
+		s.x.EG_HdrFtrReferences = append(s.x.EG_HdrFtrReferences, hdrRef)
+	}
 	hdrRef.HeaderReference = wml.NewCT_HdrFtrRef()
 	hdrRef.HeaderReference.TypeAttr = t
 	hdrID := s.d.docRels.FindRIDForN(h.Index(), unioffice.HeaderType)
@@ -37,12 +50,24 @@ func (s Section) SetHeader(h Header, t wml.ST_HdrFtr) {
 		log.Print("unable to determine header ID")
 	}
 	hdrRef.HeaderReference.IdAttr = hdrID
+	s.enableHdrFtrType(t)
 }
 
-// SetFooter sets a section footer.
+// SetFooter sets a section footer, replacing any existing footer of the same
+// type.  As with SetHeader, even and first page footers update the settings
+// needed for Word to display them.
 func (s Section) SetFooter(f Footer, t wml.ST_HdrFtr) {
-	ftrRef := wml.NewEG_HdrFtrReferences()
-	s.x.EG_HdrFtrReferences = append(s.x.EG_HdrFtrReferences, ftrRef)
+	var ftrRef *wml.EG_HdrFtrReferences
+	for _, ref := range s.x.EG_HdrFtrReferences {
+		if ref.FooterReference != nil && ref.FooterReference.TypeAttr == t {
+			ftrRef = ref
+			break
+		}
+	}
+	if ftrRef == nil {
+		ftrRef = wml.NewEG_HdrFtrReferences()
+		s.x.EG_HdrFtrReferences = append(s.x.EG_HdrFtrReferences, ftrRef)
+	}
 	ftrRef.FooterReference = wml.NewCT_HdrFtrRef()
 	ftrRef.FooterReference.TypeAttr = t
 	hdrID := s.d.docRels.FindRIDForN(f.Index(), unioffice.FooterType)
@@ -50,6 +75,18 @@ func (s Section) SetFooter(f Footer, t wml.ST_HdrFtr) {
 		log.Print("unable to determine footer ID")
 	}
 	ftrRef.FooterReference.IdAttr = hdrID
+	s.enableHdrFtrType(t)
+}
+
+// enableHdrFtrType turns on the settings Word requires before it displays
+// even and first page headers and footers.
+func (s Section) enableHdrFtrType(t wml.ST_HdrFtr) {
+	switch t {
+	case wml.ST_HdrFtrEven:
+		s.d.Settings.SetEvenAndOddHeaders(true)
+	case wml.ST_HdrFtrFirst:
+		s.x.TitlePg = wml.NewCT_OnOff()
+	}
 }
 
 // SetPageMargins sets the page margins for a section
@@ -66,3 +103,16 @@ func (s Section) SetPageMargins(top, right, bottom, left, header, footer, gutter
 
 	s.x.PgMar = margins
 }
+
+// SetPageSizeAndOrientation sets the page size and orientation for a section.
+// For landscape pages w should be the larger of the two dimensions, as Word
+// doesn't swap them based on the orientation.
+func (s Section) SetPageSizeAndOrientation(w, h measurement.Distance, orientation wml.ST_PageOrientation) {
+	sz := wml.NewCT_PageSz()
+	sz.WAttr = &sharedTypes.ST_TwipsMeasure{}
+	sz.WAttr.ST_UnsignedDecimalNumber = unioffice.Uint64(uint64(w / measurement.Twips))
+	sz.HAttr = &sharedTypes.ST_TwipsMeasure{}
+	sz.HAttr.ST_UnsignedDecimalNumber = unioffice.Uint64(uint64(h / measurement.Twips))
+	sz.OrientAttr = orientation
+	s.x.PgSz = sz
+}
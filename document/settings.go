@@ -46,6 +46,27 @@ func (s Settings) SetUpdateFieldsOnOpen(b bool) {
 	}
 }
 
+// SetEvenAndOddHeaders controls whether even pages use their own headers and
+// footers.  It is enabled automatically when an even page header or footer is
+// set on a section.
+func (s Settings) SetEvenAndOddHeaders(b bool) {
+	if !b {
+		s.x.EvenAndOddHeaders = nil
+	} else {
+		s.x.EvenAndOddHeaders = wml.NewCT_OnOff()
+	}
+}
+
+// SetTrackChanges controls whether Word records revisions as the document is
+// edited.
+func (s Settings) SetTrackChanges(b bool) {
+	if !b {
+		s.x.TrackRevisions = nil
+	} else {
+		s.x.TrackRevisions = wml.NewCT_OnOff()
+	}
+}
+
 // RemoveMailMerge removes any mail merge settings
 func (s Settings) RemoveMailMerge() {
 	s.x.MailMerge = nil
@@ -15,6 +15,11 @@ type StructuredDocumentTag struct {
 	x *wml.CT_SdtBlock
 }
 
+// X returns the inner wrapped XML type.
+func (s StructuredDocumentTag) X() *wml.CT_SdtBlock {
+	return s.x
+}
+
 // Paragraphs returns the paragraphs within a structured document tag.
 func (s StructuredDocumentTag) Paragraphs() []Paragraph {
 	if s.x.SdtContent == nil {
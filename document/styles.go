@@ -51,6 +51,30 @@ func (s Styles) AddStyle(styleID string, t wml.ST_StyleType, isDefault bool) Sty
 	return Style{ss}
 }
 
+// AddLinkedStyles adds a paragraph style and a character style that are linked
+// to each other, allowing the same formatting to be applied to either a whole
+// paragraph or to individual runs.  The character style has the ID of the
+// paragraph style with a 'Char' suffix.
+func (s Styles) AddLinkedStyles(styleID, name string) (paragraph, character Style) {
+	paragraph = s.AddStyle(styleID, wml.ST_StyleTypeParagraph, false)
+	paragraph.SetName(name)
+	character = s.AddStyle(styleID+"Char", wml.ST_StyleTypeCharacter, false)
+	character.SetName(name + " Char")
+	paragraph.SetLinkedStyle(character.StyleID())
+	character.SetLinkedStyle(paragraph.StyleID())
+	return paragraph, character
+}
+
+// StyleByID returns the style with the given ID, e.g. 'Heading1'.
+func (s Styles) StyleByID(styleID string) (Style, bool) {
+	for _, st := range s.x.Style {
+		if st.StyleIdAttr != nil && *st.StyleIdAttr == styleID {
+			return Style{st}, true
+		}
+	}
+	return Style{}, false
+}
+
 // InitializeDefault constructs the default styles.
 func (s Styles) InitializeDefault() {
 	s.initializeDocDefaults()
@@ -150,8 +174,9 @@ func (s Styles) initializeStyleDefaults() {
 
 		hdng := s.AddStyle(id, wml.ST_StyleTypeParagraph, false)
 		hdng.SetName(fmt.Sprintf("heading %d", i+1))
+		hdng.SetBasedOn(normal.StyleID())
 		hdng.SetNextStyle(normal.StyleID())
-		hdng.SetLinkedStyle(hdng.StyleID())
+		hdng.SetLinkedStyle(hdngChar.StyleID())
 		hdng.SetUISortOrder(9 + i)
 		hdng.SetPrimaryStyle(true)
 		hdng.ParagraphProperties().SetKeepNext(true)
@@ -7,7 +7,10 @@ import (
 	"os"
 	"testing"
 
+	"github.com/unidoc/unioffice/color"
 	"github.com/unidoc/unioffice/document"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/wml"
 	"github.com/unidoc/unioffice/testhelper"
 	"github.com/unidoc/unioffice/zippkg"
 )
@@ -62,3 +65,59 @@ func TestStylesList(t *testing.T) {
 		}
 	}
 }
+
+func TestCustomStyle(t *testing.T) {
+	doc := document.New()
+	caption := doc.Styles.AddStyle("Caption", wml.ST_StyleTypeParagraph, false)
+	caption.SetName("Caption")
+	caption.SetBasedOn("Normal")
+	caption.RunProperties().SetFontFamily("Arial")
+	caption.RunProperties().SetSize(9 * measurement.Point)
+	caption.RunProperties().SetColor(color.Gray)
+	caption.ParagraphProperties().SetSpacing(0, 10*measurement.Point)
+
+	para, char := doc.Styles.AddLinkedStyles("Quote", "Quote")
+	para.RunProperties().SetItalic(true)
+	char.RunProperties().SetItalic(true)
+
+	p := doc.AddParagraph()
+	p.SetStyle("Caption")
+	p.AddRun().AddText("Figure 1")
+	r := doc.AddParagraph().AddRun()
+	r.SetStyle(char.StyleID())
+	r.AddText("quoted")
+
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading document: %s", err)
+	}
+	if got := doc2.Paragraphs()[0].Style(); got != "Caption" {
+		t.Errorf("expected paragraph style Caption, got %s", got)
+	}
+	if got := doc2.Paragraphs()[1].Runs()[0].Style(); got != "QuoteChar" {
+		t.Errorf("expected run style QuoteChar, got %s", got)
+	}
+	st, ok := doc2.Styles.StyleByID("Caption")
+	if !ok {
+		t.Fatalf("expected Caption style to exist")
+	}
+	if st.X().BasedOn == nil || st.X().BasedOn.ValAttr != "Normal" {
+		t.Errorf("expected Caption to be based on Normal")
+	}
+	if rf := st.X().RPr.RFonts; rf == nil || rf.AsciiAttr == nil || *rf.AsciiAttr != "Arial" {
+		t.Errorf("expected Caption font to be Arial")
+	}
+	if q, _ := doc2.Styles.StyleByID("Quote"); q.X().Link == nil || q.X().Link.ValAttr != "QuoteChar" {
+		t.Errorf("expected Quote to be linked to QuoteChar")
+	}
+	if h, ok := doc2.Styles.StyleByID("Heading1"); !ok || h.X().Link == nil || h.X().Link.ValAttr != "Heading1Char" {
+		t.Errorf("expected built-in Heading1 style linked to Heading1Char")
+	}
+	if _, ok := doc2.Styles.StyleByID("Missing"); ok {
+		t.Errorf("expected no style for an unknown ID")
+	}
+}
@@ -8,6 +8,9 @@
 package document
 
 import (
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/ofc/sharedTypes"
 	"github.com/unidoc/unioffice/schema/soo/wml"
 )
 
@@ -30,6 +33,42 @@ func (t Table) Properties() TableProperties {
 	return TableProperties{t.x.TblPr}
 }
 
+// SetColumnWidths sets the width of each grid column of the table.  The table
+// layout is set to fixed so that Word honors the widths instead of resizing
+// the columns to fit their contents, and the widths of the existing cells are
+// updated to match the grid.
+func (t Table) SetColumnWidths(widths []measurement.Distance) {
+	t.x.TblGrid = wml.NewCT_TblGrid()
+	total := measurement.Distance(0)
+	for _, w := range widths {
+		col := wml.NewCT_TblGridCol()
+		col.WAttr = &sharedTypes.ST_TwipsMeasure{}
+		col.WAttr.ST_UnsignedDecimalNumber = unioffice.Uint64(uint64(w / measurement.Twips))
+		t.x.TblGrid.GridCol = append(t.x.TblGrid.GridCol, col)
+		total += w
+	}
+	t.Properties().SetLayout(wml.ST_TblLayoutTypeFixed)
+	t.Properties().SetWidth(total)
+
+	for _, r := range t.Rows() {
+		gc := 0
+		for _, c := range r.Cells() {
+			span := 1
+			if tcPr := c.X().TcPr; tcPr != nil && tcPr.GridSpan != nil && tcPr.GridSpan.ValAttr > 1 {
+				span = int(tcPr.GridSpan.ValAttr)
+			}
+			w := measurement.Distance(0)
+			for i := gc; i < gc+span && i < len(widths); i++ {
+				w += widths[i]
+			}
+			gc += span
+			if w > 0 {
+				c.Properties().SetWidth(w)
+			}
+		}
+	}
+}
+
 // AddRow adds a row to a table.
 func (t Table) AddRow() Row {
 	c := wml.NewEG_ContentRowContent()
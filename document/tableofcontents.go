@@ -0,0 +1,126 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/schema/soo/ofc/sharedTypes"
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+// TOCOptions controls the contents of a table of contents.
+type TOCOptions struct {
+	// MinLevel and MaxLevel are the range of heading levels included in the
+	// table of contents, defaulting to headings 1 through 3.
+	MinLevel, MaxLevel int
+	// Hyperlinks makes the entries links to their headings.
+	Hyperlinks bool
+	// Title is an optional title paragraph placed before the entries.
+	Title string
+}
+
+// TableOfContents is a table of contents field within a document.  The
+// entries are computed by Word when the field is updated.
+type TableOfContents struct {
+	d *Document
+	x *wml.CT_SdtBlock
+}
+
+// X returns the inner wrapped XML type.
+func (t TableOfContents) X() *wml.CT_SdtBlock {
+	return t.x
+}
+
+// FieldCode returns the TOC field code, e.g. TOC \o "1-3" \h \z \u
+func (t TableOfContents) FieldCode() string {
+	for _, p := range t.x.SdtContent.P {
+		for _, r := range (Paragraph{t.d, p}).Runs() {
+			for _, ic := range r.x.EG_RunInnerContent {
+				if ic.InstrText != nil {
+					return strings.TrimSpace(ic.InstrText.Content)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// InsertTableOfContents adds a table of contents to the end of the document.
+// Only the field is written with placeholder text, so the document settings
+// are updated to have Word prompt to update the fields when the document is
+// opened.
+func (d *Document) InsertTableOfContents(opts TOCOptions) TableOfContents {
+	if opts.MinLevel <= 0 {
+		opts.MinLevel = 1
+	}
+	if opts.MaxLevel <= 0 {
+		opts.MaxLevel = 3
+	}
+	if opts.MaxLevel > 9 {
+		opts.MaxLevel = 9
+	}
+	if opts.MinLevel > opts.MaxLevel {
+		opts.MinLevel = opts.MaxLevel
+	}
+
+	elts := wml.NewEG_BlockLevelElts()
+	d.x.Body.EG_BlockLevelElts = append(d.x.Body.EG_BlockLevelElts, elts)
+	c := wml.NewEG_ContentBlockContent()
+	elts.EG_ContentBlockContent = append(elts.EG_ContentBlockContent, c)
+
+	sdt := wml.NewCT_SdtBlock()
+	c.Sdt = sdt
+	sdt.SdtPr = wml.NewCT_SdtPr()
+	sdt.SdtPr.Choice = &wml.CT_SdtPrChoice{}
+	sdt.SdtPr.Choice.DocPartObj = wml.NewCT_SdtDocPart()
+	sdt.SdtPr.Choice.DocPartObj.DocPartGallery = wml.NewCT_String()
+	sdt.SdtPr.Choice.DocPartObj.DocPartGallery.ValAttr = "Table of Contents"
+	sdt.SdtPr.Choice.DocPartObj.DocPartUnique = wml.NewCT_OnOff()
+	sdt.SdtContent = wml.NewCT_SdtContentBlock()
+
+	if opts.Title != "" {
+		p := Paragraph{d, wml.NewCT_P()}
+		sdt.SdtContent.P = append(sdt.SdtContent.P, p.x)
+		p.SetStyle("TOCHeading")
+		p.AddRun().AddText(opts.Title)
+	}
+
+	code := fmt.Sprintf(`%s \o "%d-%d"`, FieldTOC, opts.MinLevel, opts.MaxLevel)
+	if opts.Hyperlinks {
+		code += ` \h`
+	}
+	code += ` \z \u`
+
+	p := Paragraph{d, wml.NewCT_P()}
+	sdt.SdtContent.P = append(sdt.SdtContent.P, p.x)
+	r := p.AddRun()
+	ic := r.newIC()
+	ic.FldChar = wml.NewCT_FldChar()
+	ic.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeBegin
+	ic.FldChar.DirtyAttr = &sharedTypes.ST_OnOff{}
+	ic.FldChar.DirtyAttr.Bool = unioffice.Bool(true)
+	ic = r.newIC()
+	ic.InstrText = wml.NewCT_Text()
+	ic.InstrText.SpaceAttr = unioffice.String("preserve")
+	ic.InstrText.Content = " " + code + " "
+	ic = r.newIC()
+	ic.FldChar = wml.NewCT_FldChar()
+	ic.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeSeparate
+
+	p.AddRun().AddText("Update the field to generate the table of contents.")
+
+	ic = p.AddRun().newIC()
+	ic.FldChar = wml.NewCT_FldChar()
+	ic.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeEnd
+
+	d.Settings.SetUpdateFieldsOnOpen(true)
+	return TableOfContents{d, sdt}
+}
@@ -0,0 +1,80 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/unidoc/unioffice/document"
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+func TestInsertTableOfContents(t *testing.T) {
+	doc := document.New()
+	toc := doc.InsertTableOfContents(document.TOCOptions{Hyperlinks: true, Title: "Contents"})
+	if got := toc.FieldCode(); got != `TOC \o "1-3" \h \z \u` {
+		t.Errorf("unexpected field code %s", got)
+	}
+	for lvl := 1; lvl <= 3; lvl++ {
+		para := doc.AddParagraph()
+		para.Properties().SetHeadingLevel(lvl)
+		para.AddRun().AddText(fmt.Sprintf("Heading %d", lvl))
+	}
+	other := doc.InsertTableOfContents(document.TOCOptions{MinLevel: 2, MaxLevel: 2})
+	if got := other.FieldCode(); got != `TOC \o "2-2" \z \u` {
+		t.Errorf("unexpected field code %s", got)
+	}
+	if err := doc.Validate(); err != nil {
+		t.Errorf("created an invalid document: %s", err)
+	}
+
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving document: %s", err)
+	}
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading document: %s", err)
+	}
+	if doc2.Settings.X().UpdateFields == nil {
+		t.Errorf("expected fields to be updated when the document is opened")
+	}
+
+	sdts := doc2.StructuredDocumentTags()
+	if len(sdts) != 2 {
+		t.Fatalf("expected 2 structured document tags, got %d", len(sdts))
+	}
+	sdt := sdts[0].X()
+	if sdt.SdtPr.Choice == nil || sdt.SdtPr.Choice.DocPartObj == nil ||
+		sdt.SdtPr.Choice.DocPartObj.DocPartGallery.ValAttr != "Table of Contents" {
+		t.Errorf("expected a table of contents document part")
+	}
+	paras := sdts[0].Paragraphs()
+	if len(paras) != 2 || paras[0].Style() != "TOCHeading" {
+		t.Fatalf("expected a title paragraph followed by the field")
+	}
+
+	// begin, instruction, separate, placeholder text, end
+	exp := []wml.ST_FldCharType{wml.ST_FldCharTypeBegin, wml.ST_FldCharTypeUnset,
+		wml.ST_FldCharTypeSeparate, wml.ST_FldCharTypeUnset, wml.ST_FldCharTypeEnd}
+	got := []wml.ST_FldCharType{}
+	for _, r := range paras[1].Runs() {
+		for _, ic := range r.X().EG_RunInnerContent {
+			if ic.FldChar != nil {
+				got = append(got, ic.FldChar.FldCharTypeAttr)
+			} else {
+				got = append(got, wml.ST_FldCharTypeUnset)
+			}
+		}
+	}
+	if fmt.Sprint(got) != fmt.Sprint(exp) {
+		t.Errorf("expected field structure %v, got %v", exp, got)
+	}
+}
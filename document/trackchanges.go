@@ -0,0 +1,140 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+// SetTrackChanges controls whether Word records revisions as the document is
+// edited, and is identical to setting it on the document's Settings.
+func (d *Document) SetTrackChanges(b bool) {
+	d.Settings.SetTrackChanges(b)
+}
+
+// InsertTracked adds a run containing text to the end of the paragraph, marked
+// as a revision inserted by author.
+func (p Paragraph) InsertTracked(text, author string) Run {
+	ins := p.d.newRevision(author)
+	r := Run{p.d, wml.NewCT_R()}
+	r.AddText(text)
+	rc := wml.NewEG_ContentRunContent()
+	rc.R = r.x
+	ins.EG_ContentRunContent = append(ins.EG_ContentRunContent, rc)
+
+	rle := wml.NewEG_RunLevelElts()
+	rle.Ins = ins
+	pc := wml.NewEG_PContent()
+	p.x.EG_PContent = append(p.x.EG_PContent, pc)
+	prc := wml.NewEG_ContentRunContent()
+	prc.EG_RunLevelElts = append(prc.EG_RunLevelElts, rle)
+	pc.EG_ContentRunContent = append(pc.EG_ContentRunContent, prc)
+	return r
+}
+
+// DeleteTracked marks a run of the paragraph as a revision deleted by author.
+// The run remains in the document until the revision is accepted.  Deleting a
+// run that is itself a tracked insertion removes it, as Word does.
+func (p Paragraph) DeleteTracked(r Run, author string) {
+	for _, pc := range p.x.EG_PContent {
+		for i, rc := range pc.EG_ContentRunContent {
+			if rc.R == r.x {
+				del := p.d.newRevision(author)
+				del.EG_ContentRunContent = append(del.EG_ContentRunContent, rc)
+				rle := wml.NewEG_RunLevelElts()
+				rle.Del = del
+				drc := wml.NewEG_ContentRunContent()
+				drc.EG_RunLevelElts = append(drc.EG_RunLevelElts, rle)
+				pc.EG_ContentRunContent[i] = drc
+				markDeleted(r.x)
+				return
+			}
+			for _, rle := range rc.EG_RunLevelElts {
+				if rle.Ins == nil {
+					continue
+				}
+				for j, irc := range rle.Ins.EG_ContentRunContent {
+					if irc.R == r.x {
+						rle.Ins.EG_ContentRunContent = append(rle.Ins.EG_ContentRunContent[:j], rle.Ins.EG_ContentRunContent[j+1:]...)
+						if len(rle.Ins.EG_ContentRunContent) == 0 {
+							rle.Ins = nil
+						}
+						return
+					}
+				}
+			}
+		}
+	}
+	unioffice.Log("run isn't in the paragraph, so can't be deleted")
+}
+
+// newRevision returns a revision by author with the next unused revision ID.
+func (d *Document) newRevision(author string) *wml.CT_RunTrackChange {
+	tc := wml.NewCT_RunTrackChange()
+	tc.IdAttr = d.nextAnnotationID()
+	tc.AuthorAttr = author
+	now := time.Now().UTC().Truncate(time.Second)
+	tc.DateAttr = &now
+	return tc
+}
+
+// annotationElements are the elements whose IDs identify annotations, such as
+// revisions, bookmarks and comments.
+var annotationElements = map[string]bool{
+	"ins": true, "del": true, "moveFrom": true, "moveTo": true,
+	"moveFromRangeStart": true, "moveToRangeStart": true,
+	"rPrChange": true, "pPrChange": true, "sectPrChange": true,
+	"tblPrChange": true, "tblPrExChange": true, "tblGridChange": true,
+	"trPrChange": true, "tcPrChange": true, "numberingChange": true,
+	"cellIns": true, "cellDel": true, "cellMerge": true,
+	"bookmarkStart": true, "commentRangeStart": true, "comment": true,
+}
+
+// nextAnnotationID returns an ID that isn't used by any annotation in the
+// document.  The document is only searched for the IDs in use the first time
+// it is called, after which the IDs are counted up from the largest found.
+func (d *Document) nextAnnotationID() int64 {
+	if !d.annotationIDSeeded {
+		d.annotationID = -1
+		parts := d.storyParts()
+		if d.comments != nil {
+			parts = append(parts, d.comments)
+		}
+		for _, part := range parts {
+			walkXML(part, func(el *xml.StartElement) {
+				if el.Name.Space != "w" || !annotationElements[el.Name.Local] {
+					return
+				}
+				if id, err := strconv.ParseInt(attrValue(el, "id"), 10, 64); err == nil && id > d.annotationID {
+					d.annotationID = id
+				}
+			})
+		}
+		d.annotationIDSeeded = true
+	}
+	d.annotationID++
+	return d.annotationID
+}
+
+// markDeleted converts the text of a run to deleted text, which is how Word
+// expects the text of a deleted run to be stored.
+func markDeleted(r *wml.CT_R) {
+	for _, ic := range r.EG_RunInnerContent {
+		if ic.T != nil {
+			ic.DelText, ic.T = ic.T, nil
+		}
+		if ic.InstrText != nil {
+			ic.DelInstrText, ic.InstrText = ic.InstrText, nil
+		}
+	}
+}
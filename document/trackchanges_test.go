@@ -0,0 +1,200 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package document_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/unidoc/unioffice/document"
+	"github.com/unidoc/unioffice/schema/soo/wml"
+)
+
+func savedParts(t *testing.T, doc *document.Document) map[string]string {
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func TestTrackChanges(t *testing.T) {
+	doc := document.New()
+	doc.SetTrackChanges(true)
+	para := doc.AddParagraph()
+	para.AddRun().AddText("The deadline is ")
+	old := para.AddRun()
+	old.AddText("Friday")
+	para.InsertTracked("Monday", "Jane Doe")
+	para.DeleteTracked(old, "Jane Doe")
+
+	ins := regexp.MustCompile(`<w:ins w:author="Jane Doe" w:date="\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ" w:id="0"><w:r><w:t>Monday</w:t></w:r></w:ins>`)
+	del := regexp.MustCompile(`<w:del w:author="Jane Doe" w:date="\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ" w:id="1"><w:r><w:delText>Friday</w:delText></w:r></w:del>`)
+	files := savedParts(t, doc)
+	if body := files["word/document.xml"]; !ins.MatchString(body) || !del.MatchString(body) {
+		t.Errorf("expected a tracked insertion and deletion, got %s", body)
+	}
+	if !bytes.Contains([]byte(files["word/settings.xml"]), []byte("<w:trackRevisions/>")) {
+		t.Errorf("expected revisions to be tracked")
+	}
+
+	// the revisions must survive being read and saved again
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading: %s", err)
+	}
+	if body := savedParts(t, doc2)["word/document.xml"]; !ins.MatchString(body) || !del.MatchString(body) {
+		t.Errorf("expected revisions after reading, got %s", body)
+	}
+
+	doc.SetTrackChanges(false)
+	if bytes.Contains([]byte(savedParts(t, doc)["word/settings.xml"]), []byte("trackRevisions")) {
+		t.Errorf("expected revisions to not be tracked")
+	}
+}
+
+func TestDeleteTrackedInsertion(t *testing.T) {
+	doc := document.New()
+	para := doc.AddParagraph()
+	r := para.InsertTracked("typo", "Jane Doe")
+	para.DeleteTracked(r, "Jane Doe")
+	body := savedParts(t, doc)["word/document.xml"]
+	if regexp.MustCompile(`typo|w:del`).MatchString(body) {
+		t.Errorf("expected the insertion to be removed, got %s", body)
+	}
+}
+
+func TestRevisionIDsAreUniqueAcrossParts(t *testing.T) {
+	doc := document.New()
+	hdr := doc.AddHeader()
+	hdr.AddParagraph().InsertTracked("Draft", "Jane Doe")
+	fn := doc.AddParagraph().AddRun().AddFootnote("a note")
+	fn.Paragraphs()[0].InsertTracked(" revised", "Jane Doe")
+	setID := func(p document.Paragraph, id int64) {
+		for _, pc := range p.X().EG_PContent {
+			for _, rc := range pc.EG_ContentRunContent {
+				for _, rle := range rc.EG_RunLevelElts {
+					if rle.Ins != nil {
+						rle.Ins.IdAttr = id
+					}
+				}
+			}
+		}
+	}
+	setID(hdr.Paragraphs()[0], 7)
+	setID(fn.Paragraphs()[0], 12)
+
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	doc2, err := document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading: %s", err)
+	}
+	para := doc2.AddParagraph()
+	para.InsertTracked("first", "Jane Doe")
+	para.InsertTracked("second", "Jane Doe")
+	body := savedParts(t, doc2)["word/document.xml"]
+	for _, exp := range []string{`w:id="13"><w:r><w:t>first`, `w:id="14"><w:r><w:t>second`} {
+		if !bytes.Contains([]byte(body), []byte(exp)) {
+			t.Errorf("expected %s, got %s", exp, body)
+		}
+	}
+}
+
+func TestMoveBookmarkRoundTrip(t *testing.T) {
+	doc := document.New()
+	para := doc.AddParagraph()
+	mb := wml.NewCT_MoveBookmark()
+	mb.IdAttr = 1
+	mb.NameAttr = "move1"
+	mb.AuthorAttr = "Jane Doe"
+	mb.DateAttr = time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+	rme := wml.NewEG_RangeMarkupElements()
+	rme.MoveFromRangeStart = mb
+	rle := wml.NewEG_RunLevelElts()
+	rle.EG_RangeMarkupElements = append(rle.EG_RangeMarkupElements, rme)
+	rc := wml.NewEG_ContentRunContent()
+	rc.EG_RunLevelElts = append(rc.EG_RunLevelElts, rle)
+	pc := wml.NewEG_PContent()
+	pc.EG_ContentRunContent = append(pc.EG_ContentRunContent, rc)
+	para.X().EG_PContent = append(para.X().EG_PContent, pc)
+	para.AddRun().AddText("moved")
+
+	exp := `<w:moveFromRangeStart w:author="Jane Doe" w:date="2020-03-04T05:06:07Z" w:name="move1" w:id="1"/>`
+	for i := 0; i < 2; i++ {
+		buf := bytes.Buffer{}
+		if err := doc.Save(&buf); err != nil {
+			t.Fatalf("error saving: %s", err)
+		}
+		if body := savedParts(t, doc)["word/document.xml"]; !bytes.Contains([]byte(body), []byte(exp)) {
+			t.Errorf("expected %s, got %s", exp, body)
+		}
+		var err error
+		doc, err = document.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("error reading: %s", err)
+		}
+	}
+}
+
+func TestInvalidRevisionDate(t *testing.T) {
+	doc := document.New()
+	doc.AddParagraph().InsertTracked("text", "Jane Doe")
+	buf := bytes.Buffer{}
+	if err := doc.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+
+	// rewrite the package with a date Word wouldn't write
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	out := bytes.Buffer{}
+	zw := zip.NewWriter(&out)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if f.Name == "word/document.xml" {
+			b = regexp.MustCompile(`w:date="[^"]*"`).ReplaceAll(b, []byte(`w:date="last Tuesday"`))
+		}
+		w, _ := zw.Create(f.Name)
+		w.Write(b)
+	}
+	zw.Close()
+
+	doc2, err := document.Read(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatalf("expected an invalid date to not prevent reading, got %s", err)
+	}
+	if body := savedParts(t, doc2)["word/document.xml"]; !bytes.Contains([]byte(body), []byte("<w:t>text</w:t>")) {
+		t.Errorf("expected the insertion to be read, got %s", body)
+	}
+}
@@ -122,6 +122,14 @@ func AbsoluteFilename(dt DocType, typ string, index int) string {
 		}
 	case TableType, TableTypeStrict, TableContentType:
 		return fmt.Sprintf("xl/tables/table%d.xml", index)
+	case PivotTableType, PivotTableContentType:
+		return fmt.Sprintf("xl/pivotTables/pivotTable%d.xml", index)
+	case PivotCacheDefinitionType, PivotCacheDefinitionContentType:
+		return fmt.Sprintf("xl/pivotCache/pivotCacheDefinition%d.xml", index)
+	case PivotCacheRecordsType, PivotCacheRecordsContentType:
+		return fmt.Sprintf("xl/pivotCache/pivotCacheRecords%d.xml", index)
+	case CalcChainType, CalcChainContentType:
+		return "xl/calcChain.xml"
 
 	case DrawingType, DrawingTypeStrict, DrawingContentType:
 		switch dt {
@@ -135,6 +143,8 @@ func AbsoluteFilename(dt DocType, typ string, index int) string {
 		switch dt {
 		case DocTypeSpreadsheet:
 			return fmt.Sprintf("xl/comments%d.xml", index)
+		case DocTypeDocument:
+			return "word/comments.xml"
 		default:
 			Log("unsupported type %s pair and %v", typ, dt)
 		}
@@ -171,6 +181,8 @@ func AbsoluteFilename(dt DocType, typ string, index int) string {
 		return "word/endnotes.xml"
 	case FootNotesType, FootNotesTypeStrict:
 		return "word/footnotes.xml"
+	case CommentsExtendedType:
+		return "word/commentsExtended.xml"
 	case NumberingType, NumberingTypeStrict:
 		return "word/numbering.xml"
 	case WebSettingsType, WebSettingsTypeStrict:
@@ -189,6 +201,10 @@ func AbsoluteFilename(dt DocType, typ string, index int) string {
 		return fmt.Sprintf("ppt/slideLayouts/slideLayout%d.xml", index)
 	case SlideMasterType:
 		return fmt.Sprintf("ppt/slideMasters/slideMaster%d.xml", index)
+	case NotesSlideType:
+		return fmt.Sprintf("ppt/notesSlides/notesSlide%d.xml", index)
+	case NotesMasterType:
+		return fmt.Sprintf("ppt/notesMasters/notesMaster%d.xml", index)
 
 	default:
 		Log("unsupported type %s", typ)
@@ -55,6 +55,10 @@ func TestSMLFilenames(t *testing.T) {
 		{15, unioffice.ChartType, "xl/charts/chart15.xml"},
 		{12, unioffice.DrawingType, "xl/drawings/drawing12.xml"},
 		{13, unioffice.TableType, "xl/tables/table13.xml"},
+		{2, unioffice.PivotTableType, "xl/pivotTables/pivotTable2.xml"},
+		{2, unioffice.PivotCacheDefinitionType, "xl/pivotCache/pivotCacheDefinition2.xml"},
+		{2, unioffice.PivotCacheRecordsType, "xl/pivotCache/pivotCacheRecords2.xml"},
+		{0, unioffice.CalcChainType, "xl/calcChain.xml"},
 		{2, unioffice.CommentsType, "xl/comments2.xml"},
 		{15, unioffice.WorksheetType, "xl/worksheets/sheet15.xml"},
 		{2, unioffice.VMLDrawingType, "xl/drawings/vmlDrawing2.vml"},
@@ -86,6 +90,8 @@ func TestPMLFilenames(t *testing.T) {
 		{5, unioffice.SlideLayoutType, "ppt/slideLayouts/slideLayout5.xml"},
 		{6, unioffice.SlideMasterType, "ppt/slideMasters/slideMaster6.xml"},
 		{7, unioffice.ThemeType, "ppt/theme/theme7.xml"},
+		{8, unioffice.NotesSlideType, "ppt/notesSlides/notesSlide8.xml"},
+		{1, unioffice.NotesMasterType, "ppt/notesMasters/notesMaster1.xml"},
 	}
 	for _, tc := range td {
 		abs := unioffice.AbsoluteFilename(unioffice.DocTypePresentation, tc.Type, tc.Idx)
@@ -1 +1,3 @@
 module github.com/unidoc/unioffice
+
+require golang.org/x/text v0.3.8
@@ -7,8 +7,12 @@
 
 package measurement
 
+import "math"
+
 // Distance represents a distance and is automatically converted
-// to the units needed internally in the various ECMA 376 formats.
+// to the units needed internally in the various ECMA 376 formats.  Distances
+// are stored in points and can be added, subtracted and scaled directly, e.g.
+// 2*Inch + 5*Millimeter.
 type Distance float64
 
 // Constants for various distance units
@@ -19,7 +23,7 @@ const (
 	Pixel96                 = 1.0 / 96.0 * Inch
 	HalfPoint               = 1.0 / 2.0 * Point
 	Character               = 7 * Point
-	Millimeter              = 2.83465 * Point
+	Millimeter              = 1.0 / 25.4 * Inch
 	Centimeter              = 10 * Millimeter
 	Inch                    = 72 * Point
 	Foot                    = 12 * Inch
@@ -28,3 +32,36 @@ const (
 	HundredthPoint          = 1 / 100.0
 	Dxa                     = Twips
 )
+
+// ToEMU returns the distance in English Metric Units, rounded to the nearest
+// unit.
+func (d Distance) ToEMU() int64 {
+	return int64(math.Round(float64(d / EMU)))
+}
+
+// ToTwips returns the distance in twentieths of a point, rounded to the
+// nearest twip.
+func (d Distance) ToTwips() int64 {
+	return int64(math.Round(float64(d / Twips)))
+}
+
+// ToPoints returns the distance in points.
+func (d Distance) ToPoints() float64 {
+	return float64(d / Point)
+}
+
+// ToInches returns the distance in inches.
+func (d Distance) ToInches() float64 {
+	return float64(d / Inch)
+}
+
+// ToMillimeters returns the distance in millimeters.
+func (d Distance) ToMillimeters() float64 {
+	return float64(d / Millimeter)
+}
+
+// ToCharacters returns the distance in characters of the default font, the
+// unit used for spreadsheet column widths.
+func (d Distance) ToCharacters() float64 {
+	return float64(d / Character)
+}
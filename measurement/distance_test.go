@@ -0,0 +1,62 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package measurement_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unidoc/unioffice/measurement"
+)
+
+func TestConversions(t *testing.T) {
+	in := measurement.Distance(measurement.Inch)
+	if got := in.ToEMU(); got != 914400 {
+		t.Errorf("expected 914400 EMU per inch, got %d", got)
+	}
+	if got := in.ToTwips(); got != 1440 {
+		t.Errorf("expected 1440 twips per inch, got %d", got)
+	}
+	if got := in.ToPoints(); got != 72 {
+		t.Errorf("expected 72 points per inch, got %v", got)
+	}
+
+	td := []struct {
+		Inp  measurement.Distance
+		EMU  int64
+		Twip int64
+	}{
+		{measurement.Point, 12700, 20},
+		{measurement.Centimeter, 360000, 567},
+		{2.54 * measurement.Centimeter, 914400, 1440},
+		{8.5 * measurement.Inch, 7772400, 12240},
+		{measurement.Pixel96, 9525, 15},
+		{measurement.Zero, 0, 0},
+	}
+	for _, tc := range td {
+		if got := tc.Inp.ToEMU(); got != tc.EMU {
+			t.Errorf("expected %v to be %d EMU, got %d", tc.Inp, tc.EMU, got)
+		}
+		if got := tc.Inp.ToTwips(); got != tc.Twip {
+			t.Errorf("expected %v to be %d twips, got %d", tc.Inp, tc.Twip, got)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	d := measurement.Distance(2*measurement.Inch + 36*measurement.Point)
+	if got := d.ToInches(); got != 2.5 {
+		t.Errorf("expected 2.5 inches, got %v", got)
+	}
+	if got := measurement.Distance(10 * measurement.Millimeter).ToMillimeters(); math.Abs(got-10) > 1e-9 {
+		t.Errorf("expected 10 millimeters, got %v", got)
+	}
+	if got := measurement.Distance(70 * measurement.Point).ToCharacters(); got != 10 {
+		t.Errorf("expected 10 characters, got %v", got)
+	}
+}
@@ -9,6 +9,7 @@ package presentation
 
 import (
 	"github.com/unidoc/unioffice/drawing"
+	"github.com/unidoc/unioffice/measurement"
 	"github.com/unidoc/unioffice/schema/soo/dml"
 	"github.com/unidoc/unioffice/schema/soo/pml"
 )
@@ -18,10 +19,25 @@ type Image struct {
 	x *pml.CT_Picture
 }
 
-// Properties returns the properties of the TextBox.
+// X returns the inner wrapped XML type.
+func (i Image) X() *pml.CT_Picture {
+	return i.x
+}
+
+// Properties returns the properties of the image.
 func (i Image) Properties() drawing.ShapeProperties {
 	if i.x.SpPr == nil {
 		i.x.SpPr = dml.NewCT_ShapeProperties()
 	}
 	return drawing.MakeShapeProperties(i.x.SpPr)
 }
+
+// SetPosition sets the position of the top left corner of the image.
+func (i Image) SetPosition(x, y measurement.Distance) {
+	i.Properties().SetPosition(x, y)
+}
+
+// SetSize sets the width and height of the image.
+func (i Image) SetSize(w, h measurement.Distance) {
+	i.Properties().SetSize(w, h)
+}
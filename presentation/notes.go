@@ -0,0 +1,242 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package presentation
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/drawing"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/dml"
+	"github.com/unidoc/unioffice/schema/soo/pml"
+)
+
+// NotesSlide contains the speaker notes of a slide.
+type NotesSlide struct {
+	x *pml.Notes
+}
+
+// X returns the inner wrapped XML type.
+func (n NotesSlide) X() *pml.Notes {
+	return n.x
+}
+
+// SetText replaces the text of the notes with text, adding a paragraph per
+// line.
+func (n NotesSlide) SetText(text string) {
+	body := n.body()
+	body.TxBody = dml.NewCT_TextBody()
+	body.TxBody.LstStyle = dml.NewCT_TextListStyle()
+	for _, line := range strings.Split(text, "\n") {
+		para := dml.NewCT_TextParagraph()
+		if line != "" {
+			tr := dml.NewEG_TextRun()
+			tr.R = dml.NewCT_RegularTextRun()
+			tr.R.T = line
+			para.EG_TextRun = append(para.EG_TextRun, tr)
+		}
+		body.TxBody.P = append(body.TxBody.P, para)
+	}
+}
+
+// Text returns the text of the notes with paragraphs separated by newlines.
+func (n NotesSlide) Text() string {
+	body := n.body()
+	if body.TxBody == nil {
+		return ""
+	}
+	lines := []string{}
+	for _, p := range body.TxBody.P {
+		line := ""
+		for _, tr := range p.EG_TextRun {
+			if tr.R != nil {
+				line += tr.R.T
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// body returns the body placeholder that contains the notes text, creating it
+// if necessary.
+func (n NotesSlide) body() *pml.CT_Shape {
+	for _, c := range n.x.CSld.SpTree.Choice {
+		for _, sp := range c.Sp {
+			if sp.NvSpPr != nil && sp.NvSpPr.NvPr != nil && sp.NvSpPr.NvPr.Ph != nil &&
+				sp.NvSpPr.NvPr.Ph.TypeAttr == pml.ST_PlaceholderTypeBody {
+				return sp
+			}
+		}
+	}
+	body := addNotesPlaceholder(n.x.CSld.SpTree, "Notes Placeholder 2", pml.ST_PlaceholderTypeBody)
+	body.NvSpPr.NvPr.Ph.IdxAttr = unioffice.Uint32(1)
+	return body
+}
+
+// Notes returns the speaker notes of the slide.  The notes, and the notes
+// master that they are based on, are created if they don't exist yet.
+func (s Slide) Notes() NotesSlide {
+	p := s.p
+	for i, sld := range p.slides {
+		if sld != s.x {
+			continue
+		}
+		if p.notes[i] == nil {
+			p.ensureNotesMaster()
+			p.notes[i] = newNotesSlide()
+			rels := common.NewRelationships()
+			rels.AddAutoRelationship(unioffice.DocTypePresentation, unioffice.NotesSlideType,
+				1, unioffice.NotesMasterType)
+			rels.AddAutoRelationship(unioffice.DocTypePresentation, unioffice.NotesSlideType,
+				i+1, unioffice.SlideType)
+			p.notesRels[i] = rels
+			// the notes are numbered when saving, see updateNotesParts
+			p.slideRels[i].AddAutoRelationship(unioffice.DocTypePresentation, unioffice.SlideType,
+				1, unioffice.NotesSlideType)
+		}
+		return NotesSlide{p.notes[i]}
+	}
+	unioffice.Log("slide not found in presentation")
+	return NotesSlide{newNotesSlide()}
+}
+
+func newNotesSlide() *pml.Notes {
+	n := pml.NewNotes()
+	n.CSld.SpTree.NvGrpSpPr.CNvPr.IdAttr = 1
+	img := addNotesPlaceholder(n.CSld.SpTree, "Slide Image Placeholder 1", pml.ST_PlaceholderTypeSldImg)
+	img.TxBody = nil
+	body := addNotesPlaceholder(n.CSld.SpTree, "Notes Placeholder 2", pml.ST_PlaceholderTypeBody)
+	body.NvSpPr.NvPr.Ph.IdxAttr = unioffice.Uint32(1)
+	return n
+}
+
+// ensureNotesMaster adds the notes master, and a copy of the presentation
+// theme for it to use, if the presentation doesn't have a notes master yet.
+func (p *Presentation) ensureNotesMaster() {
+	if len(p.notesMasters) > 0 {
+		return
+	}
+	dt := unioffice.DocTypePresentation
+
+	nm := pml.NewNotesMaster()
+	nm.CSld.SpTree.NvGrpSpPr.CNvPr.IdAttr = 1
+	nm.ClrMap.Bg1Attr = dml.ST_ColorSchemeIndexLt1
+	nm.ClrMap.Bg2Attr = dml.ST_ColorSchemeIndexLt2
+	nm.ClrMap.Tx1Attr = dml.ST_ColorSchemeIndexDk1
+	nm.ClrMap.Tx2Attr = dml.ST_ColorSchemeIndexDk2
+	nm.ClrMap.Accent1Attr = dml.ST_ColorSchemeIndexAccent1
+	nm.ClrMap.Accent2Attr = dml.ST_ColorSchemeIndexAccent2
+	nm.ClrMap.Accent3Attr = dml.ST_ColorSchemeIndexAccent3
+	nm.ClrMap.Accent4Attr = dml.ST_ColorSchemeIndexAccent4
+	nm.ClrMap.Accent5Attr = dml.ST_ColorSchemeIndexAccent5
+	nm.ClrMap.Accent6Attr = dml.ST_ColorSchemeIndexAccent6
+	nm.ClrMap.HlinkAttr = dml.ST_ColorSchemeIndexHlink
+	nm.ClrMap.FolHlinkAttr = dml.ST_ColorSchemeIndexFolHlink
+
+	img := addNotesPlaceholder(nm.CSld.SpTree, "Slide Image Placeholder 1", pml.ST_PlaceholderTypeSldImg)
+	img.TxBody = nil
+	drawing.MakeShapeProperties(img.SpPr).SetPosition(0.75*measurement.Inch, 1.25*measurement.Inch)
+	drawing.MakeShapeProperties(img.SpPr).SetSize(6*measurement.Inch, 3.375*measurement.Inch)
+
+	body := addNotesPlaceholder(nm.CSld.SpTree, "Notes Placeholder 2", pml.ST_PlaceholderTypeBody)
+	body.NvSpPr.NvPr.Ph.IdxAttr = unioffice.Uint32(1)
+	drawing.MakeShapeProperties(body.SpPr).SetPosition(0.75*measurement.Inch, 4.8125*measurement.Inch)
+	drawing.MakeShapeProperties(body.SpPr).SetSize(6*measurement.Inch, 3.9375*measurement.Inch)
+
+	p.notesMasters = append(p.notesMasters, nm)
+	p.ContentTypes.AddOverride(unioffice.AbsoluteFilename(dt, unioffice.NotesMasterType, 1),
+		unioffice.NotesMasterContentType)
+	rel := p.prels.AddAutoRelationship(dt, unioffice.OfficeDocumentType, 1, unioffice.NotesMasterType)
+	p.x.NotesMasterIdLst = pml.NewCT_NotesMasterIdList()
+	p.x.NotesMasterIdLst.NotesMasterId = pml.NewCT_NotesMasterIdListEntry()
+	p.x.NotesMasterIdLst.NotesMasterId.IdAttr = rel.ID()
+
+	// the notes master needs a theme of its own
+	thm := dml.NewTheme()
+	if len(p.themes) > 0 {
+		if err := copyTheme(p.themes[0], thm); err != nil {
+			unioffice.Log("error copying theme for notes master: %s", err)
+		}
+	}
+	p.themes = append(p.themes, thm)
+	p.themeRels = append(p.themeRels, common.NewRelationships())
+	p.ContentTypes.AddOverride(unioffice.AbsoluteFilename(dt, unioffice.ThemeType, len(p.themes)),
+		unioffice.ThemeContentType)
+
+	nmRel := common.NewRelationships()
+	nmRel.AddAutoRelationship(dt, unioffice.NotesMasterType, len(p.themes), unioffice.ThemeType)
+	p.notesMasterRels = append(p.notesMasterRels, nmRel)
+}
+
+// updateNotesParts numbers the notes slides in slide order, updating the
+// relationships between the slides and their notes and the content types to
+// match.
+func (p *Presentation) updateNotesParts() {
+	dt := unioffice.DocTypePresentation
+	stale := []string{}
+	for _, o := range p.ContentTypes.X().Override {
+		if o.ContentTypeAttr == unioffice.NotesSlideContentType {
+			stale = append(stale, o.PartNameAttr)
+		}
+	}
+	for _, fn := range stale {
+		p.ContentTypes.RemoveOverride(fn)
+	}
+	idx := 0
+	for i, n := range p.notes {
+		if n == nil {
+			continue
+		}
+		idx++
+		for _, r := range p.slideRels[i].X().Relationship {
+			if r.TypeAttr == unioffice.NotesSlideType {
+				r.TargetAttr = unioffice.RelativeFilename(dt, unioffice.SlideType, unioffice.NotesSlideType, idx)
+			}
+		}
+		for _, r := range p.notesRels[i].X().Relationship {
+			if r.TypeAttr == unioffice.SlideType {
+				r.TargetAttr = unioffice.RelativeFilename(dt, unioffice.NotesSlideType, unioffice.SlideType, i+1)
+			}
+		}
+		p.ContentTypes.AddOverride(unioffice.AbsoluteFilename(dt, unioffice.NotesSlideType, idx),
+			unioffice.NotesSlideContentType)
+	}
+}
+
+func addNotesPlaceholder(tree *pml.CT_GroupShape, name string, typ pml.ST_PlaceholderType) *pml.CT_Shape {
+	c := pml.NewCT_GroupShapeChoice()
+	tree.Choice = append(tree.Choice, c)
+
+	sp := pml.NewCT_Shape()
+	c.Sp = append(c.Sp, sp)
+	sp.NvSpPr.CNvPr.IdAttr = uint32(len(tree.Choice) + 1)
+	sp.NvSpPr.CNvPr.NameAttr = name
+	sp.NvSpPr.CNvSpPr.SpLocks = dml.NewCT_ShapeLocking()
+	sp.NvSpPr.CNvSpPr.SpLocks.NoGrpAttr = unioffice.Bool(true)
+	sp.NvSpPr.NvPr.Ph = pml.NewCT_Placeholder()
+	sp.NvSpPr.NvPr.Ph.TypeAttr = typ
+
+	sp.TxBody = dml.NewCT_TextBody()
+	sp.TxBody.BodyPr = dml.NewCT_TextBodyProperties()
+	sp.TxBody.LstStyle = dml.NewCT_TextListStyle()
+	sp.TxBody.P = []*dml.CT_TextParagraph{dml.NewCT_TextParagraph()}
+	return sp
+}
+
+// copyTheme copies src into dst.
+func copyTheme(src, dst *dml.Theme) error {
+	b, err := xml.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(b, dst)
+}
@@ -46,6 +46,13 @@ type Presentation struct {
 	layoutRels []common.Relationships
 	themes     []*dml.Theme
 	themeRels  []common.Relationships
+
+	// notes and notesRels are indexed by slide and are nil for slides
+	// without notes
+	notes           []*pml.Notes
+	notesRels       []common.Relationships
+	notesMasters    []*pml.NotesMaster
+	notesMasterRels []common.Relationships
 }
 
 func newEmpty() *Presentation {
@@ -99,7 +106,7 @@ func New() *Presentation {
 	mrel := common.NewRelationships()
 	p.masterRels = append(p.masterRels, mrel)
 
-	ls := pml.NewSldLayout()
+	ls := newDefaultLayout()
 	lrid := mrel.AddAutoRelationship(unioffice.DocTypePresentation, unioffice.SlideMasterType, 1, unioffice.SlideLayoutType)
 	slfn := unioffice.AbsoluteFilename(unioffice.DocTypePresentation, unioffice.SlideLayoutType, 1)
 	p.ContentTypes.AddOverride(slfn, unioffice.SlideLayoutContentType)
@@ -247,7 +254,9 @@ func (p *Presentation) nextSlideID() uint32 {
 	return id
 }
 
-// AddSlide adds a new slide to the presentation.
+// AddSlide adds a new slide to the presentation using the first slide layout.
+// The placeholders of the layout, other than the footer, date and slide number,
+// are copied to the slide with their content cleared.
 func (p *Presentation) AddSlide() Slide {
 	sd := pml.NewCT_SlideIdListEntry()
 	sd.IdAttr = p.nextSlideID()
@@ -275,13 +284,56 @@ func (p *Presentation) AddSlide() Slide {
 
 	srel := common.NewRelationships()
 	p.slideRels = append(p.slideRels, srel)
-	// TODO: make the slide layout configurable
+	p.notes = append(p.notes, nil)
+	p.notesRels = append(p.notesRels, common.Relationships{})
+	if len(p.layouts) == 0 {
+		return Slide{sd, slide, p}
+	}
 	srel.AddAutoRelationship(unioffice.DocTypePresentation, unioffice.SlideType,
-		len(p.layouts), unioffice.SlideLayoutType)
+		1, unioffice.SlideLayoutType)
 
+	for _, c := range p.layouts[0].CSld.SpTree.Choice {
+		for _, sp := range c.Sp {
+			if sp.NvSpPr == nil || sp.NvSpPr.NvPr == nil || sp.NvSpPr.NvPr.Ph == nil {
+				continue
+			}
+			switch sp.NvSpPr.NvPr.Ph.TypeAttr {
+			case pml.ST_PlaceholderTypeFtr, pml.ST_PlaceholderTypeDt, pml.ST_PlaceholderTypeSldNum:
+				continue
+			}
+			cp, err := copyShape(sp)
+			if err != nil {
+				unioffice.Log("error copying layout placeholder: %s", err)
+				continue
+			}
+			chc := pml.NewCT_GroupShapeChoice()
+			chc.Sp = append(chc.Sp, cp)
+			slide.CSld.SpTree.Choice = append(slide.CSld.SpTree.Choice, chc)
+			PlaceHolder{cp, slide}.Clear()
+		}
+	}
 	return Slide{sd, slide, p}
 }
 
+// copyShape returns a deep copy of a shape.
+func copyShape(sp *pml.CT_Shape) (*pml.CT_Shape, error) {
+	buf := bytes.Buffer{}
+	enc := xml.NewEncoder(&buf)
+	start := xml.StartElement{Name: xml.Name{Local: "p:sp"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:a"}, Value: "http://schemas.openxmlformats.org/drawingml/2006/main"})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:p"}, Value: "http://schemas.openxmlformats.org/presentationml/2006/main"})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:r"}, Value: "http://schemas.openxmlformats.org/officeDocument/2006/relationships"})
+	if err := enc.EncodeElement(sp, start); err != nil {
+		return nil, err
+	}
+	enc.Flush()
+	cp := pml.NewCT_Shape()
+	if err := xml.NewDecoder(&buf).Decode(cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
 // AddSlideWithLayout adds a new slide with content copied from a layout.  Normally you should
 // use AddDefaultSlideWithLayout as it will do some post processing similar to PowerPoint to
 // clear place holder text, etc.
@@ -334,6 +386,8 @@ func (p *Presentation) AddSlideWithLayout(l SlideLayout) (Slide, error) {
 
 	srel := common.NewRelationships()
 	p.slideRels = append(p.slideRels, srel)
+	p.notes = append(p.notes, nil)
+	p.notesRels = append(p.notesRels, common.Relationships{})
 	for i, lout := range p.layouts {
 		if lout == l.X() {
 			srel.AddAutoRelationship(unioffice.DocTypePresentation, unioffice.SlideType,
@@ -397,6 +451,7 @@ func (p *Presentation) Save(w io.Writer) error {
 
 
 	dt := unioffice.DocTypePresentation
+	p.EnsurePropertyParts(dt)
 
 	z := zip.NewWriter(w)
 	defer z.Close()
@@ -427,6 +482,7 @@ func (p *Presentation) Save(w io.Writer) error {
 		return err
 	}
 
+	p.updateNotesParts()
 	for i, slide := range p.slides {
 		spath := unioffice.AbsoluteFilename(unioffice.DocTypePresentation, unioffice.SlideType, i+1)
 		zippkg.MarshalXML(z, spath, slide)
@@ -435,6 +491,25 @@ func (p *Presentation) Save(w io.Writer) error {
 			zippkg.MarshalXML(z, rpath, p.slideRels[i].X())
 		}
 	}
+	idx := 0
+	for i, n := range p.notes {
+		if n == nil {
+			continue
+		}
+		idx++
+		npath := unioffice.AbsoluteFilename(unioffice.DocTypePresentation, unioffice.NotesSlideType, idx)
+		zippkg.MarshalXML(z, npath, n)
+		rpath := zippkg.RelationsPathFor(npath)
+		zippkg.MarshalXML(z, rpath, p.notesRels[i].X())
+	}
+	for i, m := range p.notesMasters {
+		mpath := unioffice.AbsoluteFilename(unioffice.DocTypePresentation, unioffice.NotesMasterType, i+1)
+		zippkg.MarshalXML(z, mpath, m)
+		if !p.notesMasterRels[i].IsEmpty() {
+			rpath := zippkg.RelationsPathFor(mpath)
+			zippkg.MarshalXML(z, rpath, p.notesMasterRels[i].X())
+		}
+	}
 	for i, m := range p.masters {
 		mpath := unioffice.AbsoluteFilename(unioffice.DocTypePresentation, unioffice.SlideMasterType, i+1)
 		zippkg.MarshalXML(z, mpath, m)
@@ -547,13 +622,46 @@ func (p *Presentation) onNewRelationship(decMap *zippkg.DecodeMap, target, typ s
 
 	case unioffice.SlideType:
 		sld := pml.NewSld()
+		// notes slides refer back to their slide which has already been read
+		if !decMap.AddTarget(target, sld, typ, uint32(len(p.slides)+1)) {
+			return nil
+		}
 		p.slides = append(p.slides, sld)
-		decMap.AddTarget(target, sld, typ, uint32(len(p.slides)))
 		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, len(p.slides))
 
 		slRel := common.NewRelationships()
-		decMap.AddTarget(zippkg.RelationsPathFor(target), slRel.X(), typ, 0)
+		decMap.AddTarget(zippkg.RelationsPathFor(target), slRel.X(), typ, uint32(len(p.slides)))
 		p.slideRels = append(p.slideRels, slRel)
+		p.notes = append(p.notes, nil)
+		p.notesRels = append(p.notesRels, common.Relationships{})
+
+	case unioffice.NotesSlideType:
+		if src.Typ != unioffice.SlideType || src.Index == 0 || int(src.Index) > len(p.notes) {
+			unioffice.Log("unexpected notes slide reference from %s", src.Path)
+			return nil
+		}
+		n := pml.NewNotes()
+		if !decMap.AddTarget(target, n, typ, src.Index) {
+			return nil
+		}
+		p.notes[src.Index-1] = n
+
+		nRel := common.NewRelationships()
+		decMap.AddTarget(zippkg.RelationsPathFor(target), nRel.X(), typ, src.Index)
+		p.notesRels[src.Index-1] = nRel
+
+	case unioffice.NotesMasterType:
+		nm := pml.NewNotesMaster()
+		target = path.Clean(target)
+		if decMap.AddTarget(target, nm, typ, uint32(len(p.notesMasters)+1)) {
+			p.notesMasters = append(p.notesMasters, nm)
+			decMap.RecordIndex(target, len(p.notesMasters))
+
+			nmRel := common.NewRelationships()
+			decMap.AddTarget(zippkg.RelationsPathFor(target), nmRel.X(), typ, 0)
+			p.notesMasterRels = append(p.notesMasterRels, nmRel)
+		}
+		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, decMap.IndexFor(target))
 
 	case unioffice.SlideMasterType:
 		sm := pml.NewSldMaster()
@@ -671,6 +779,12 @@ func (p *Presentation) RemoveSlide(s Slide) error {
 			copy(p.slideRels[i:], p.slideRels[i+1:])
 			p.slideRels = p.slideRels[0 : len(p.slideRels)-1]
 
+			copy(p.notes[i:], p.notes[i+1:])
+			p.notes = p.notes[0 : len(p.notes)-1]
+
+			copy(p.notesRels[i:], p.notesRels[i+1:])
+			p.notesRels = p.notesRels[0 : len(p.notesRels)-1]
+
 			copy(p.x.SldIdLst.SldId[i:], p.x.SldIdLst.SldId[i+1:])
 			p.x.SldIdLst.SldId = p.x.SldIdLst.SldId[0 : len(p.x.SldIdLst.SldId)-1]
 
@@ -714,9 +828,15 @@ func (p *Presentation) AddImage(i common.Image) (common.ImageRef, error) {
 		return r, errors.New("image must have a valid size")
 	}
 
+	fn := fmt.Sprintf("media/image%d.%s", len(p.Images)+1, i.Format)
+	rel := p.prels.AddRelationship(fn, unioffice.ImageType)
+	r.SetRelID(rel.ID())
 	p.Images = append(p.Images, r)
-	fn := fmt.Sprintf("media/image%d.%s", len(p.Images), i.Format)
-	p.prels.AddRelationship(fn, unioffice.ImageType)
+	ct := "image/" + i.Format
+	if i.Format == "jpg" {
+		ct = "image/jpeg"
+	}
+	p.ContentTypes.EnsureDefault(i.Format, ct)
 	return r, nil
 }
 
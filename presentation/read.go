@@ -19,6 +19,12 @@ import (
 
 // Read reads a document from an io.Reader.
 func Read(r io.ReaderAt, size int64) (*Presentation, error) {
+	return ReadWithOptions(r, size, zippkg.ReadOptions{})
+}
+
+// ReadWithOptions reads a presentation from an io.Reader, applying the limits in
+// opts before any of its content is decompressed.
+func ReadWithOptions(r io.ReaderAt, size int64, opts zippkg.ReadOptions) (*Presentation, error) {
 	doc := newEmpty()
 
 	td, err := ioutil.TempDir("", "gooxml-pptx")
@@ -34,6 +40,9 @@ func Read(r io.ReaderAt, size int64) (*Presentation, error) {
 
 	files := []*zip.File{}
 	files = append(files, zr.File...)
+	if err := opts.CheckSize(files); err != nil {
+		return nil, err
+	}
 
 	decMap := zippkg.DecodeMap{}
 	decMap.SetOnNewRelationshipFunc(doc.onNewRelationship)
@@ -0,0 +1,180 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package presentation
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unioffice/drawing"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/dml"
+	"github.com/unidoc/unioffice/schema/soo/pml"
+)
+
+// Shape is a preset geometry shape, such as a rectangle, within a slide.
+type Shape struct {
+	x *pml.CT_Shape
+}
+
+// X returns the inner wrapped XML type.
+func (s Shape) X() *pml.CT_Shape {
+	return s.x
+}
+
+// Properties returns the properties of the shape.
+func (s Shape) Properties() drawing.ShapeProperties {
+	if s.x.SpPr == nil {
+		s.x.SpPr = dml.NewCT_ShapeProperties()
+	}
+	return drawing.MakeShapeProperties(s.x.SpPr)
+}
+
+// SetPosition sets the position of the top left corner of the shape.
+func (s Shape) SetPosition(x, y measurement.Distance) {
+	s.Properties().SetPosition(x, y)
+}
+
+// SetSize sets the width and height of the shape.
+func (s Shape) SetSize(w, h measurement.Distance) {
+	s.Properties().SetSize(w, h)
+}
+
+// AddParagraph adds a paragraph of text to the shape.
+func (s Shape) AddParagraph() drawing.Paragraph {
+	if s.x.TxBody == nil {
+		s.x.TxBody = dml.NewCT_TextBody()
+		s.x.TxBody.BodyPr.AnchorAttr = dml.ST_TextAnchoringTypeCtr
+	}
+	p := dml.NewCT_TextParagraph()
+	s.x.TxBody.P = append(s.x.TxBody.P, p)
+	return drawing.MakeParagraph(p)
+}
+
+// Connector is a line within a slide.
+type Connector struct {
+	x *pml.CT_Connector
+}
+
+// X returns the inner wrapped XML type.
+func (c Connector) X() *pml.CT_Connector {
+	return c.x
+}
+
+// Properties returns the properties of the line.
+func (c Connector) Properties() drawing.ShapeProperties {
+	return drawing.MakeShapeProperties(c.x.SpPr)
+}
+
+// AddShape adds a shape with a preset geometry to the slide.  The shape is
+// positioned at the top left of the slide and is one inch square.
+func (s Slide) AddShape(typ dml.ST_ShapeType) Shape {
+	c := pml.NewCT_GroupShapeChoice()
+	s.x.CSld.SpTree.Choice = append(s.x.CSld.SpTree.Choice, c)
+
+	sp := pml.NewCT_Shape()
+	c.Sp = append(c.Sp, sp)
+	sp.NvSpPr.CNvPr.IdAttr = s.nextShapeID()
+	sp.NvSpPr.CNvPr.NameAttr = fmt.Sprintf("Shape %d", sp.NvSpPr.CNvPr.IdAttr-1)
+	sp.SpPr = dml.NewCT_ShapeProperties()
+	sp.SpPr.Xfrm = dml.NewCT_Transform2D()
+	sp.SpPr.PrstGeom = dml.NewCT_PresetGeometry2D()
+	sp.SpPr.PrstGeom.PrstAttr = typ
+	sp.Style = newShapeStyle(2, 1, dml.ST_SchemeColorValLt1)
+
+	shp := Shape{sp}
+	shp.SetPosition(0, 0)
+	shp.SetSize(1*measurement.Inch, 1*measurement.Inch)
+	return shp
+}
+
+// AddRectangle adds a rectangle to the slide, see AddShape.
+func (s Slide) AddRectangle() Shape {
+	return s.AddShape(dml.ST_ShapeTypeRect)
+}
+
+// AddLine adds a straight line from (x1, y1) to (x2, y2) to the slide.
+func (s Slide) AddLine(x1, y1, x2, y2 measurement.Distance) Connector {
+	c := pml.NewCT_GroupShapeChoice()
+	s.x.CSld.SpTree.Choice = append(s.x.CSld.SpTree.Choice, c)
+
+	cxn := pml.NewCT_Connector()
+	c.CxnSp = append(c.CxnSp, cxn)
+	cxn.NvCxnSpPr.CNvPr.IdAttr = s.nextShapeID()
+	cxn.NvCxnSpPr.CNvPr.NameAttr = fmt.Sprintf("Straight Connector %d", cxn.NvCxnSpPr.CNvPr.IdAttr-1)
+	cxn.SpPr = dml.NewCT_ShapeProperties()
+	cxn.SpPr.PrstGeom = dml.NewCT_PresetGeometry2D()
+	cxn.SpPr.PrstGeom.PrstAttr = dml.ST_ShapeTypeLine
+	cxn.Style = newShapeStyle(1, 0, dml.ST_SchemeColorValTx1)
+
+	// a line is drawn from the top left to the bottom right of its bounding
+	// box, so flip it for the other directions
+	ln := Connector{cxn}
+	props := ln.Properties()
+	left, right := x1, x2
+	if x2 < x1 {
+		left, right = x2, x1
+		props.SetFlipHorizontal(true)
+	}
+	top, bottom := y1, y2
+	if y2 < y1 {
+		top, bottom = y2, y1
+		props.SetFlipVertical(true)
+	}
+	props.SetPosition(left, top)
+	props.SetSize(right-left, bottom-top)
+	return ln
+}
+
+// nextShapeID returns an unused ID for a new shape on the slide.
+func (s Slide) nextShapeID() uint32 {
+	id := uint32(1)
+	var visit func(tree *pml.CT_GroupShape)
+	use := func(pr *dml.CT_NonVisualDrawingProps) {
+		if pr != nil && pr.IdAttr >= id {
+			id = pr.IdAttr + 1
+		}
+	}
+	visit = func(tree *pml.CT_GroupShape) {
+		use(tree.NvGrpSpPr.CNvPr)
+		for _, c := range tree.Choice {
+			for _, sp := range c.Sp {
+				use(sp.NvSpPr.CNvPr)
+			}
+			for _, grp := range c.GrpSp {
+				visit(grp)
+			}
+			for _, gf := range c.GraphicFrame {
+				use(gf.NvGraphicFramePr.CNvPr)
+			}
+			for _, cxn := range c.CxnSp {
+				use(cxn.NvCxnSpPr.CNvPr)
+			}
+			for _, pic := range c.Pic {
+				use(pic.NvPicPr.CNvPr)
+			}
+		}
+	}
+	visit(s.x.CSld.SpTree)
+	return id
+}
+
+// newShapeStyle returns a style that draws a shape with the line and fill
+// styles of the theme at the given indices, in the first accent color, as
+// PowerPoint does for newly inserted shapes.
+func newShapeStyle(lnIdx, fillIdx uint32, fontClr dml.ST_SchemeColorVal) *dml.CT_ShapeStyle {
+	st := dml.NewCT_ShapeStyle()
+	st.LnRef.IdxAttr = lnIdx
+	st.LnRef.SchemeClr = &dml.CT_SchemeColor{ValAttr: dml.ST_SchemeColorValAccent1}
+	st.FillRef.IdxAttr = fillIdx
+	st.FillRef.SchemeClr = &dml.CT_SchemeColor{ValAttr: dml.ST_SchemeColorValAccent1}
+	st.EffectRef.IdxAttr = 0
+	st.EffectRef.SchemeClr = &dml.CT_SchemeColor{ValAttr: dml.ST_SchemeColorValAccent1}
+	st.FontRef.IdxAttr = dml.ST_FontCollectionIndexMinor
+	st.FontRef.SchemeClr = &dml.CT_SchemeColor{ValAttr: fontClr}
+	return st
+}
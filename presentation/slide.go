@@ -14,6 +14,7 @@ import (
 	"github.com/unidoc/unioffice"
 
 	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/drawing"
 	"github.com/unidoc/unioffice/measurement"
 	"github.com/unidoc/unioffice/schema/soo/dml"
 
@@ -105,6 +106,9 @@ func (s Slide) AddTextBox() TextBox {
 
 	sp := pml.NewCT_Shape()
 	c.Sp = append(c.Sp, sp)
+	sp.NvSpPr.CNvPr.IdAttr = s.nextShapeID()
+	sp.NvSpPr.CNvPr.NameAttr = fmt.Sprintf("TextBox %d", sp.NvSpPr.CNvPr.IdAttr-1)
+	sp.NvSpPr.CNvSpPr.TxBoxAttr = unioffice.Bool(true)
 	sp.SpPr = dml.NewCT_ShapeProperties()
 	sp.SpPr.Xfrm = dml.NewCT_Transform2D()
 	sp.SpPr.PrstGeom = dml.NewCT_PresetGeometry2D()
@@ -121,13 +125,29 @@ func (s Slide) AddTextBox() TextBox {
 	return tb
 }
 
-// AddImage adds an image textbox to a slide.
+// PlaceImage adds an image to the presentation and places it on the slide at
+// the top left of the slide at its natural size, see Image.SetPosition and
+// Image.SetSize.  To place a single copy of an image on several slides, add it
+// with Presentation.AddImage and place it with AddImage instead.
+func (s Slide) PlaceImage(img common.Image) (Image, error) {
+	iref, err := s.p.AddImage(img)
+	if err != nil {
+		return Image{}, err
+	}
+	return s.AddImage(iref), nil
+}
+
+// AddImage places an image, previously added to the presentation with
+// Presentation.AddImage, on the slide.  The image is placed at the top left of
+// the slide at its natural size, see Image.SetPosition and Image.SetSize.
 func (s Slide) AddImage(img common.ImageRef) Image {
 	c := pml.NewCT_GroupShapeChoice()
 	s.x.CSld.SpTree.Choice = append(s.x.CSld.SpTree.Choice, c)
 
 	pic := pml.NewCT_Picture()
 	c.Pic = append(c.Pic, pic)
+	pic.NvPicPr.CNvPr.IdAttr = s.nextShapeID()
+	pic.NvPicPr.CNvPr.NameAttr = fmt.Sprintf("Picture %d", pic.NvPicPr.CNvPr.IdAttr-1)
 
 	pic.NvPicPr.CNvPicPr = dml.NewCT_NonVisualPictureProperties()
 	pic.NvPicPr.CNvPicPr.PicLocks = dml.NewCT_PictureLocking()
@@ -144,6 +164,9 @@ func (s Slide) AddImage(img common.ImageRef) Image {
 			break
 		}
 	}
+	if imgIdx == 0 {
+		unioffice.Log("image must be added to the presentation with AddImage before it is placed on a slide")
+	}
 
 	var imgID string
 	for i, os := range s.p.Slides() {
@@ -169,3 +192,56 @@ func (s Slide) AddImage(img common.ImageRef) Image {
 	ir.Properties().SetPosition(0, 0)
 	return ir
 }
+
+// maxBulletLevel is the deepest paragraph level supported by PowerPoint.
+const maxBulletLevel = 8
+
+// placeholder returns the first placeholder matching any of the given types.
+func (s Slide) placeholder(types ...pml.ST_PlaceholderType) (PlaceHolder, bool) {
+	for _, t := range types {
+		if ph, err := s.GetPlaceholder(t); err == nil {
+			return ph, true
+		}
+	}
+	return PlaceHolder{}, false
+}
+
+// SetTitle sets the text of the slide's title placeholder.
+func (s Slide) SetTitle(text string) {
+	ph, ok := s.placeholder(pml.ST_PlaceholderTypeTitle, pml.ST_PlaceholderTypeCtrTitle)
+	if !ok {
+		unioffice.Log("slide has no title placeholder")
+		return
+	}
+	ph.SetText(text)
+}
+
+// AddBulletPoint adds a paragraph to the slide's body placeholder at the given
+// indentation level, where zero is the top level and eight the deepest.
+func (s Slide) AddBulletPoint(text string, level uint8) {
+	// placeholders without a type default to object placeholders
+	ph, ok := s.placeholder(pml.ST_PlaceholderTypeBody, pml.ST_PlaceholderTypeObj,
+		pml.ST_PlaceholderTypeUnset)
+	if !ok {
+		unioffice.Log("slide has no body placeholder")
+		return
+	}
+	if ph.x.TxBody == nil {
+		ph.Clear()
+	}
+
+	var para drawing.Paragraph
+	// re-use the empty paragraph of a cleared placeholder
+	if ps := ph.x.TxBody.P; len(ps) == 1 && len(ps[0].EG_TextRun) == 0 {
+		para = drawing.MakeParagraph(ps[0])
+	} else {
+		para = ph.AddParagraph()
+	}
+	if level > maxBulletLevel {
+		level = maxBulletLevel
+	}
+	if level > 0 {
+		para.Properties().SetLevel(int32(level))
+	}
+	para.AddRun().SetText(text)
+}
@@ -0,0 +1,314 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package presentation_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/presentation"
+	"github.com/unidoc/unioffice/schema/soo/pml"
+)
+
+func TestAddSlideTitleAndBullets(t *testing.T) {
+	ppt := presentation.New()
+	for i := 1; i <= 3; i++ {
+		slide := ppt.AddSlide()
+		slide.SetTitle(fmt.Sprintf("Slide %d", i))
+		slide.AddBulletPoint("First point", 0)
+		slide.AddBulletPoint("Sub point", 1)
+	}
+	if err := ppt.Validate(); err != nil {
+		t.Fatalf("created an invalid presentation: %s", err)
+	}
+
+	buf := bytes.Buffer{}
+	if err := ppt.Save(&buf); err != nil {
+		t.Fatalf("error saving presentation: %s", err)
+	}
+	ppt2, err := presentation.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading presentation: %s", err)
+	}
+	slides := ppt2.Slides()
+	if len(slides) != 3 {
+		t.Fatalf("expected 3 slides, got %d", len(slides))
+	}
+	for i, slide := range slides {
+		title, err := slide.GetPlaceholder(pml.ST_PlaceholderTypeTitle)
+		if err != nil {
+			t.Fatalf("expected a title placeholder on slide %d", i+1)
+		}
+		if got := title.X().TxBody.P[0].EG_TextRun[0].R.T; got != fmt.Sprintf("Slide %d", i+1) {
+			t.Errorf("unexpected title %q", got)
+		}
+		body, err := slide.GetPlaceholder(pml.ST_PlaceholderTypeBody)
+		if err != nil {
+			t.Fatalf("expected a body placeholder on slide %d", i+1)
+		}
+		paras := body.X().TxBody.P
+		if len(paras) != 2 {
+			t.Fatalf("expected 2 bullet points, got %d", len(paras))
+		}
+		if paras[0].EG_TextRun[0].R.T != "First point" || paras[1].EG_TextRun[0].R.T != "Sub point" {
+			t.Errorf("unexpected bullet point text")
+		}
+		if paras[1].PPr == nil || paras[1].PPr.LvlAttr == nil || *paras[1].PPr.LvlAttr != 1 {
+			t.Errorf("expected the second bullet point to be indented")
+		}
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	found := 0
+	for _, f := range zr.File {
+		if !strings.HasPrefix(f.Name, "ppt/slides/_rels/") {
+			continue
+		}
+		rc, _ := f.Open()
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if !strings.Contains(string(b), "slideLayouts/slideLayout1.xml") {
+			t.Errorf("expected %s to reference the first layout", f.Name)
+		}
+		found++
+	}
+	if found != 3 {
+		t.Errorf("expected 3 slide relationship parts, got %d", found)
+	}
+}
+
+func TestSlideNotes(t *testing.T) {
+	ppt := presentation.New()
+	ppt.AddSlide()
+	slide := ppt.AddSlide()
+	slide.Notes().SetText("Remember to smile\nand breathe")
+	if got := slide.Notes().Text(); got != "Remember to smile\nand breathe" {
+		t.Errorf("unexpected notes text %q", got)
+	}
+	if err := ppt.Validate(); err != nil {
+		t.Fatalf("created an invalid presentation: %s", err)
+	}
+
+	buf := bytes.Buffer{}
+	if err := ppt.Save(&buf); err != nil {
+		t.Fatalf("error saving presentation: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = string(b)
+	}
+	for _, fn := range []string{"ppt/notesSlides/notesSlide1.xml", "ppt/notesMasters/notesMaster1.xml", "ppt/theme/theme2.xml"} {
+		if _, ok := contents[fn]; !ok {
+			t.Errorf("expected %s to be created", fn)
+		}
+	}
+	if !strings.Contains(contents["ppt/slides/_rels/slide2.xml.rels"], "../notesSlides/notesSlide1.xml") {
+		t.Errorf("expected the second slide to reference its notes")
+	}
+	if strings.Contains(contents["ppt/slides/_rels/slide1.xml.rels"], "notesSlide") {
+		t.Errorf("expected the first slide to have no notes")
+	}
+	nrels := contents["ppt/notesSlides/_rels/notesSlide1.xml.rels"]
+	if !strings.Contains(nrels, "../slides/slide2.xml") || !strings.Contains(nrels, "../notesMasters/notesMaster1.xml") {
+		t.Errorf("expected the notes to reference the slide and notes master, got %s", nrels)
+	}
+	if !strings.Contains(contents["ppt/_rels/presentation.xml.rels"], "notesMasters/notesMaster1.xml") {
+		t.Errorf("expected the presentation to reference the notes master")
+	}
+	if !strings.Contains(contents["[Content_Types].xml"], `PartName="/ppt/notesSlides/notesSlide1.xml"`) {
+		t.Errorf("expected a content type for the notes")
+	}
+	if !strings.Contains(contents["ppt/notesSlides/notesSlide1.xml"], `type="body" idx="1"`) {
+		t.Errorf("expected the notes to contain a body placeholder")
+	}
+
+	ppt2, err := presentation.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading presentation: %s", err)
+	}
+	slides := ppt2.Slides()
+	if len(slides) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(slides))
+	}
+	if got := slides[1].Notes().Text(); got != "Remember to smile\nand breathe" {
+		t.Errorf("unexpected notes text after reading %q", got)
+	}
+}
+
+func TestTextBoxAndShapes(t *testing.T) {
+	ppt := presentation.New()
+	slide := ppt.AddSlide()
+	tb := slide.AddTextBox()
+	tb.SetPosition(1*measurement.Inch, 2*measurement.Inch)
+	tb.SetSize(4*measurement.Inch, 1*measurement.Inch)
+	tb.AddRun().SetText("Hello")
+	tb.AddParagraph().AddRun().SetText("World")
+
+	rect := slide.AddRectangle()
+	rect.SetPosition(5*measurement.Inch, 1*measurement.Inch)
+	rect.AddParagraph().AddRun().SetText("Box")
+	slide.AddLine(2*measurement.Inch, 4*measurement.Inch, 1*measurement.Inch, 5*measurement.Inch)
+
+	if err := ppt.Validate(); err != nil {
+		t.Fatalf("created an invalid presentation: %s", err)
+	}
+	buf := bytes.Buffer{}
+	if err := ppt.Save(&buf); err != nil {
+		t.Fatalf("error saving presentation: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	xml := ""
+	for _, f := range zr.File {
+		if f.Name == "ppt/slides/slide1.xml" {
+			rc, _ := f.Open()
+			b, _ := ioutil.ReadAll(rc)
+			rc.Close()
+			xml = string(b)
+		}
+	}
+	for _, exp := range []string{
+		`<p:cNvSpPr txBox="1"/>`,
+		`<a:off x="914400" y="1828800"/><a:ext cx="3657600" cy="914400"/>`,
+		`<a:t>Hello</a:t>`,
+		`<a:t>World</a:t>`,
+		`<a:prstGeom prst="rect"/>`,
+		`<a:t>Box</a:t>`,
+		`<a:xfrm flipH="1"><a:off x="914400" y="3657600"/><a:ext cx="914400" cy="914400"/></a:xfrm>`,
+		`<a:prstGeom prst="line"/>`,
+	} {
+		if !strings.Contains(xml, exp) {
+			t.Errorf("expected slide to contain %s", exp)
+		}
+	}
+
+	ids := map[uint32]bool{}
+	for _, c := range slide.X().CSld.SpTree.Choice {
+		for _, sp := range c.Sp {
+			ids[sp.NvSpPr.CNvPr.IdAttr] = true
+		}
+		for _, cxn := range c.CxnSp {
+			ids[cxn.NvCxnSpPr.CNvPr.IdAttr] = true
+		}
+	}
+	if len(ids) != len(slide.X().CSld.SpTree.Choice) {
+		t.Errorf("expected each shape to have a unique ID")
+	}
+}
+
+func TestSlideImage(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	pngBuf := bytes.Buffer{}
+	if err := png.Encode(&pngBuf, logo); err != nil {
+		t.Fatalf("error encoding image: %s", err)
+	}
+	img, err := common.ImageFromBytes(pngBuf.Bytes())
+	if err != nil {
+		t.Fatalf("error creating image: %s", err)
+	}
+
+	ppt := presentation.New()
+	slide := ppt.AddSlide()
+	pic, err := slide.PlaceImage(img)
+	if err != nil {
+		t.Fatalf("error placing image: %s", err)
+	}
+	pic.SetSize(1*measurement.Inch, 0.5*measurement.Inch)
+	pic.SetPosition(8.5*measurement.Inch, 0.25*measurement.Inch)
+	// the same image can be placed on another slide without a second copy
+	ppt.AddSlide().AddImage(ppt.Images[0])
+
+	if _, err := ppt.AddSlide().PlaceImage(common.Image{}); err == nil {
+		t.Errorf("expected an error placing an invalid image")
+	}
+
+	buf := bytes.Buffer{}
+	if err := ppt.Save(&buf); err != nil {
+		t.Fatalf("error saving presentation: %s", err)
+	}
+	ppt2, err := presentation.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading presentation: %s", err)
+	}
+	if len(ppt2.Images) != 1 {
+		t.Fatalf("expected 1 image, got %d", len(ppt2.Images))
+	}
+	var found *pml.CT_Picture
+	for _, c := range ppt2.Slides()[0].X().CSld.SpTree.Choice {
+		for _, p := range c.Pic {
+			found = p
+		}
+	}
+	if found == nil {
+		t.Fatalf("expected a picture on the slide")
+	}
+	if off := found.SpPr.Xfrm.Off; *off.XAttr.ST_CoordinateUnqualified != int64(8.5*measurement.Inch/measurement.EMU) ||
+		*off.YAttr.ST_CoordinateUnqualified != int64(0.25*measurement.Inch/measurement.EMU) {
+		t.Errorf("unexpected image position")
+	}
+	if found.SpPr.Xfrm.Ext.CyAttr != int64(0.5*measurement.Inch/measurement.EMU) {
+		t.Errorf("expected the image height to be half an inch, got %d", found.SpPr.Xfrm.Ext.CyAttr)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = string(b)
+	}
+	if _, ok := contents["ppt/media/image1.png"]; !ok {
+		t.Errorf("expected the image to be stored in the media folder")
+	}
+	if !strings.Contains(contents["ppt/slides/_rels/slide1.xml.rels"], `Target="../media/image1.png"`) {
+		t.Errorf("expected the slide to reference the image")
+	}
+	if !strings.Contains(contents["ppt/slides/_rels/slide2.xml.rels"], `Target="../media/image1.png"`) {
+		t.Errorf("expected the second slide to reference the same image")
+	}
+	if !strings.Contains(contents["[Content_Types].xml"], `Extension="png" ContentType="image/png"`) {
+		t.Errorf("expected a content type for png images")
+	}
+}
+
+func TestSlideImageJPG(t *testing.T) {
+	ppt := presentation.New()
+	img := common.Image{Size: image.Point{40, 20}, Format: "jpg", Data: &[]byte{0xff, 0xd8}}
+	if _, err := ppt.AddSlide().PlaceImage(img); err != nil {
+		t.Fatalf("error placing image: %s", err)
+	}
+	for _, def := range ppt.ContentTypes.X().Default {
+		if def.ExtensionAttr == "jpg" && def.ContentTypeAttr != "image/jpeg" {
+			t.Errorf("expected jpg images to have the image/jpeg content type, got %s", def.ContentTypeAttr)
+		}
+	}
+}
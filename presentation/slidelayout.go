@@ -8,6 +8,10 @@
 package presentation
 
 import (
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/drawing"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/dml"
 	"github.com/unidoc/unioffice/schema/soo/pml"
 )
 
@@ -33,3 +37,55 @@ func (s SlideLayout) Name() string {
 	}
 	return ""
 }
+
+// newDefaultLayout returns the title and content layout used by new
+// presentations.
+func newDefaultLayout() *pml.SldLayout {
+	ls := pml.NewSldLayout()
+	ls.TypeAttr = pml.ST_SlideLayoutTypeObj
+	ls.CSld.NameAttr = unioffice.String("Title and Content")
+	ls.CSld.SpTree.NvGrpSpPr.CNvPr.IdAttr = 1
+
+	title := addLayoutPlaceholder(ls, "Title 1", pml.ST_PlaceholderTypeTitle)
+	drawing.MakeShapeProperties(title.SpPr).SetPosition(0.5*measurement.Inch, 0.3*measurement.Inch)
+	drawing.MakeShapeProperties(title.SpPr).SetSize(9*measurement.Inch, 1.25*measurement.Inch)
+
+	body := addLayoutPlaceholder(ls, "Content Placeholder 2", pml.ST_PlaceholderTypeBody)
+	body.NvSpPr.NvPr.Ph.IdxAttr = unioffice.Uint32(1)
+	drawing.MakeShapeProperties(body.SpPr).SetPosition(0.5*measurement.Inch, 1.75*measurement.Inch)
+	drawing.MakeShapeProperties(body.SpPr).SetSize(9*measurement.Inch, 4.95*measurement.Inch)
+
+	// the master has no text styles, so define the bullets on the layout
+	lst := body.TxBody.LstStyle
+	for i, ppr := range []**dml.CT_TextParagraphProperties{&lst.Lvl1pPr, &lst.Lvl2pPr,
+		&lst.Lvl3pPr, &lst.Lvl4pPr, &lst.Lvl5pPr, &lst.Lvl6pPr, &lst.Lvl7pPr,
+		&lst.Lvl8pPr, &lst.Lvl9pPr} {
+		*ppr = dml.NewCT_TextParagraphProperties()
+		(*ppr).MarLAttr = unioffice.Int32(int32((i+1)*457200 - 228600))
+		(*ppr).IndentAttr = unioffice.Int32(-228600)
+		pp := drawing.MakeParagraphProperties(*ppr)
+		pp.SetBulletFont("Arial")
+		pp.SetBulletChar("•")
+	}
+	return ls
+}
+
+func addLayoutPlaceholder(ls *pml.SldLayout, name string, typ pml.ST_PlaceholderType) *pml.CT_Shape {
+	c := pml.NewCT_GroupShapeChoice()
+	ls.CSld.SpTree.Choice = append(ls.CSld.SpTree.Choice, c)
+
+	sp := pml.NewCT_Shape()
+	c.Sp = append(c.Sp, sp)
+	sp.NvSpPr.CNvPr.IdAttr = uint32(len(ls.CSld.SpTree.Choice) + 1)
+	sp.NvSpPr.CNvPr.NameAttr = name
+	sp.NvSpPr.CNvSpPr.SpLocks = dml.NewCT_ShapeLocking()
+	sp.NvSpPr.CNvSpPr.SpLocks.NoGrpAttr = unioffice.Bool(true)
+	sp.NvSpPr.NvPr.Ph = pml.NewCT_Placeholder()
+	sp.NvSpPr.NvPr.Ph.TypeAttr = typ
+
+	sp.TxBody = dml.NewCT_TextBody()
+	sp.TxBody.BodyPr = dml.NewCT_TextBodyProperties()
+	sp.TxBody.LstStyle = dml.NewCT_TextListStyle()
+	sp.TxBody.P = []*dml.CT_TextParagraph{dml.NewCT_TextParagraph()}
+	return sp
+}
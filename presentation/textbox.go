@@ -9,6 +9,7 @@ package presentation
 
 import (
 	"github.com/unidoc/unioffice/drawing"
+	"github.com/unidoc/unioffice/measurement"
 	"github.com/unidoc/unioffice/schema/soo/dml"
 	"github.com/unidoc/unioffice/schema/soo/pml"
 )
@@ -18,6 +19,11 @@ type TextBox struct {
 	x *pml.CT_Shape
 }
 
+// X returns the inner wrapped XML type.
+func (t TextBox) X() *pml.CT_Shape {
+	return t.x
+}
+
 // AddParagraph adds a paragraph to the text box
 func (t TextBox) AddParagraph() drawing.Paragraph {
 	p := dml.NewCT_TextParagraph()
@@ -25,6 +31,25 @@ func (t TextBox) AddParagraph() drawing.Paragraph {
 	return drawing.MakeParagraph(p)
 }
 
+// AddRun adds a run to the last paragraph of the text box, adding a paragraph
+// if there are none.
+func (t TextBox) AddRun() drawing.Run {
+	if len(t.x.TxBody.P) == 0 {
+		return t.AddParagraph().AddRun()
+	}
+	return drawing.MakeParagraph(t.x.TxBody.P[len(t.x.TxBody.P)-1]).AddRun()
+}
+
+// SetPosition sets the position of the top left corner of the text box.
+func (t TextBox) SetPosition(x, y measurement.Distance) {
+	t.Properties().SetPosition(x, y)
+}
+
+// SetSize sets the width and height of the text box.
+func (t TextBox) SetSize(w, h measurement.Distance) {
+	t.Properties().SetSize(w, h)
+}
+
 // Properties returns the properties of the TextBox.
 func (t TextBox) Properties() drawing.ShapeProperties {
 	if t.x.SpPr == nil {
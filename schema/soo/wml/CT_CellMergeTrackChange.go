@@ -49,7 +49,7 @@ func (m *CT_CellMergeTrackChange) MarshalXML(e *xml.Encoder, start xml.StartElem
 		Value: fmt.Sprintf("%v", m.AuthorAttr)})
 	if m.DateAttr != nil {
 		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:date"},
-			Value: fmt.Sprintf("%v", *m.DateAttr)})
+			Value: m.DateAttr.Format(time.RFC3339)})
 	}
 	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:id"},
 		Value: fmt.Sprintf("%v", m.IdAttr)})
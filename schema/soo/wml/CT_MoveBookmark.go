@@ -36,7 +36,7 @@ func (m *CT_MoveBookmark) MarshalXML(e *xml.Encoder, start xml.StartElement) err
 	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:author"},
 		Value: fmt.Sprintf("%v", m.AuthorAttr)})
 	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:date"},
-		Value: fmt.Sprintf("%v", m.DateAttr)})
+		Value: m.DateAttr.Format(time.RFC3339)})
 	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:name"},
 		Value: fmt.Sprintf("%v", m.NameAttr)})
 	if m.ColFirstAttr != nil {
@@ -28,6 +28,8 @@ type CT_P struct {
 	RsidPAttr *string
 	// Default Revision Identifier for Runs
 	RsidRDefaultAttr *string
+	// Paragraph Identifier (Word 2010 extension)
+	ParaIdAttr *string
 	// Paragraph Properties
 	PPr         *CT_PPr
 	EG_PContent []*EG_PContent
@@ -59,6 +61,13 @@ func (m *CT_P) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w:rsidRDefault"},
 			Value: fmt.Sprintf("%v", *m.RsidRDefaultAttr)})
 	}
+	if m.ParaIdAttr != nil {
+		// declared here as the part roots don't declare the namespace
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:w14"},
+			Value: "http://schemas.microsoft.com/office/word/2010/wordml"})
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "w14:paraId"},
+			Value: fmt.Sprintf("%v", *m.ParaIdAttr)})
+	}
 	e.EncodeToken(start)
 	if m.PPr != nil {
 		sepPr := xml.StartElement{Name: xml.Name{Local: "w:pPr"}}
@@ -108,6 +117,14 @@ func (m *CT_P) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 			m.RsidRDefaultAttr = &parsed
 			continue
 		}
+		if attr.Name.Local == "paraId" {
+			parsed, err := attr.Value, error(nil)
+			if err != nil {
+				return err
+			}
+			m.ParaIdAttr = &parsed
+			continue
+		}
 		if attr.Name.Local == "rsidRPr" {
 			parsed, err := attr.Value, error(nil)
 			if err != nil {
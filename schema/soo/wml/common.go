@@ -71,7 +71,16 @@ func ParseUnionST_HexColor(s string) (ST_HexColor, error) {
 	return r, nil
 }
 
+// ParseStdlibTime parses an xsd:dateTime, such as the date of a comment or
+// revision, which may omit the time zone.  Dates that can't be parsed are
+// returned as the zero time rather than failing to read the document.
 func ParseStdlibTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	unioffice.Log("ignoring invalid date time %s", s)
 	return time.Time{}, nil
 }
 
@@ -64,6 +64,10 @@ const (
 	CustomPropertiesType   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties"
 	CustomXMLType          = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXml"
 
+	CorePropertiesContentType     = "application/vnd.openxmlformats-package.core-properties+xml"
+	ExtendedPropertiesContentType = "application/vnd.openxmlformats-officedocument.extended-properties+xml"
+	CustomPropertiesContentType   = "application/vnd.openxmlformats-officedocument.custom-properties+xml"
+
 	// SML
 	WorksheetType            = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet"
 	WorksheetContentType     = "application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"
@@ -74,6 +78,14 @@ const (
 	SMLStyleSheetContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"
 	TableType                = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/table"
 	TableContentType         = "application/vnd.openxmlformats-officedocument.spreadsheetml.table+xml"
+	PivotTableType                  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/pivotTable"
+	PivotTableContentType           = "application/vnd.openxmlformats-officedocument.spreadsheetml.pivotTable+xml"
+	PivotCacheDefinitionType        = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/pivotCacheDefinition"
+	PivotCacheDefinitionContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.pivotCacheDefinition+xml"
+	PivotCacheRecordsType           = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/pivotCacheRecords"
+	PivotCacheRecordsContentType    = "application/vnd.openxmlformats-officedocument.spreadsheetml.pivotCacheRecords+xml"
+	CalcChainType                   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/calcChain"
+	CalcChainContentType            = "application/vnd.openxmlformats-officedocument.spreadsheetml.calcChain+xml"
 	ViewPropertiesType       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/viewProps"
 	TableStylesType          = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/tableStyles"
 
@@ -86,6 +98,11 @@ const (
 	FootNotesType   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footnotes"
 	EndNotesType    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/endnotes"
 
+	// CommentsExtendedType is the Word 2012 extension part recording the
+	// threads of replies to comments.
+	CommentsExtendedType        = "http://schemas.microsoft.com/office/2011/relationships/commentsExtended"
+	CommentsExtendedContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.commentsExtended+xml"
+
 	// PML
 	SlideType                  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide"
 	SlideContentType           = "application/vnd.openxmlformats-officedocument.presentationml.slide+xml"
@@ -93,6 +110,10 @@ const (
 	SlideMasterContentType     = "application/vnd.openxmlformats-officedocument.presentationml.slideMaster+xml"
 	SlideLayoutType            = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideLayout"
 	SlideLayoutContentType     = "application/vnd.openxmlformats-officedocument.presentationml.slideLayout+xml"
+	NotesSlideType             = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide"
+	NotesSlideContentType      = "application/vnd.openxmlformats-officedocument.presentationml.notesSlide+xml"
+	NotesMasterType            = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesMaster"
+	NotesMasterContentType     = "application/vnd.openxmlformats-officedocument.presentationml.notesMaster+xml"
 	PresentationPropertiesType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/presProps"
 
 	// VML
@@ -0,0 +1,229 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/measurement"
+	sd "github.com/unidoc/unioffice/schema/soo/dml/spreadsheetDrawing"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+)
+
+// ImagePositioning controls how an anchored image behaves when the cells
+// beneath it are moved or resized.
+type ImagePositioning byte
+
+// ImagePositioning constants.
+const (
+	// ImagePositioningMoveAndSize moves and resizes the image with the cells
+	// it covers.
+	ImagePositioningMoveAndSize ImagePositioning = iota
+	// ImagePositioningMove moves the image with the cell at its top left
+	// corner but keeps its size.
+	ImagePositioningMove
+	// ImagePositioningAbsolute keeps the image at a fixed position and size
+	// regardless of changes to the cells.
+	ImagePositioningAbsolute
+)
+
+// defaultColumnWidth is the width of a column in characters when neither the
+// column nor the sheet specify a width.
+const defaultColumnWidth = 9.140625
+
+// defaultRowHeight is the height of a row when neither the row nor the sheet
+// specify a height.
+const defaultRowHeight measurement.Distance = 15 * measurement.Point
+
+// AnchoredImage is an image placed on a sheet and anchored to its cells.
+type AnchoredImage struct {
+	s Sheet
+	x *sd.CT_TwoCellAnchor
+}
+
+// X returns the inner wrapped XML type.
+func (a AnchoredImage) X() *sd.CT_TwoCellAnchor {
+	return a.x
+}
+
+// AddImage adds an image to the sheet, creating the sheet drawing and the
+// relationships to the image as necessary.  The image is placed at cell A1 at
+// its natural size and moves and resizes with the cells beneath it.
+func (s Sheet) AddImage(img common.Image) (AnchoredImage, error) {
+	iref, err := s.w.AddImage(img)
+	if err != nil {
+		return AnchoredImage{}, err
+	}
+	d := s.drawing()
+	anc := d.AddImage(iref, AnchorTypeTwoCell).(TwoCellAnchor)
+	ai := AnchoredImage{s, anc.x}
+	ai.SetPositioning(ImagePositioningMoveAndSize)
+
+	pic := ai.x.Choice.Pic
+	pic.NvPicPr.CNvPr.IdAttr = uint32(len(d.x.EG_Anchor) + 1)
+	pic.NvPicPr.CNvPr.NameAttr = fmt.Sprintf("Picture %d", len(d.x.EG_Anchor))
+	ai.SetOrigin("A", 1)
+	return ai, nil
+}
+
+// SetPositioning controls how the image behaves when the cells beneath it are
+// moved or resized.
+func (a AnchoredImage) SetPositioning(p ImagePositioning) {
+	switch p {
+	case ImagePositioningMoveAndSize:
+		a.x.EditAsAttr = sd.ST_EditAsTwoCell
+	case ImagePositioningMove:
+		a.x.EditAsAttr = sd.ST_EditAsOneCell
+	case ImagePositioningAbsolute:
+		a.x.EditAsAttr = sd.ST_EditAsAbsolute
+	}
+}
+
+// SetOrigin positions the top left corner of the image at the top left corner
+// of a cell, e.g. SetOrigin("B", 2).  The image size is preserved.
+func (a AnchoredImage) SetOrigin(col string, row uint32) {
+	if row == 0 {
+		unioffice.Log("row numbers must be >= 1")
+		row = 1
+	}
+	from := a.x.From
+	from.Col = int32(reference.ColumnToIndex(col))
+	from.Row = int32(row - 1)
+	from.ColOff.ST_CoordinateUnqualified = unioffice.Int64(0)
+	from.RowOff.ST_CoordinateUnqualified = unioffice.Int64(0)
+	a.updateBottomRight()
+}
+
+// Size returns the size of the image.
+func (a AnchoredImage) Size() (w, h measurement.Distance) {
+	ext := a.x.Choice.Pic.SpPr.Xfrm.Ext
+	return measurement.Distance(ext.CxAttr) * measurement.EMU, measurement.Distance(ext.CyAttr) * measurement.EMU
+}
+
+// SetSize sets the size of the image, adjusting the bottom right anchor so the
+// image covers the correct cells.
+func (a AnchoredImage) SetSize(w, h measurement.Distance) {
+	ext := a.x.Choice.Pic.SpPr.Xfrm.Ext
+	ext.CxAttr = int64(w / measurement.EMU)
+	ext.CyAttr = int64(h / measurement.EMU)
+	a.updateBottomRight()
+}
+
+// updateBottomRight computes the bottom right anchor from the top left anchor
+// and the image size using the column widths and row heights of the sheet.
+func (a AnchoredImage) updateBottomRight() {
+	w, h := a.Size()
+	from, to := a.x.From, a.x.To
+
+	col := uint32(from.Col)
+	off := w + measurement.Distance(*from.ColOff.ST_CoordinateUnqualified)*measurement.EMU
+	for col < maxColumns {
+		cw := a.s.columnWidth(col)
+		if off < cw {
+			break
+		}
+		off -= cw
+		col++
+	}
+	to.Col = int32(col)
+	to.ColOff.ST_CoordinateUnqualified = unioffice.Int64(int64(off / measurement.EMU))
+
+	heights, defHeight := a.s.rowHeights()
+	row := uint32(from.Row)
+	off = h + measurement.Distance(*from.RowOff.ST_CoordinateUnqualified)*measurement.EMU
+	for row < maxRows {
+		rh, ok := heights[row+1]
+		if !ok {
+			rh = defHeight
+		}
+		if off < rh {
+			break
+		}
+		off -= rh
+		row++
+	}
+	to.Row = int32(row)
+	to.RowOff.ST_CoordinateUnqualified = unioffice.Int64(int64(off / measurement.EMU))
+}
+
+// maxColumns and maxRows are the maximum number of columns and rows in a
+// sheet.
+const (
+	maxColumns = 16384
+	maxRows    = 1048576
+)
+
+// drawing returns the drawing for the sheet, creating it if necessary.
+func (s Sheet) drawing() Drawing {
+	for i, wks := range s.w.xws {
+		if wks != s.x || s.x.Drawing == nil {
+			continue
+		}
+		for _, r := range s.w.xwsRels[i].X().Relationship {
+			if r.IdAttr != s.x.Drawing.IdAttr {
+				continue
+			}
+			if j := s.w.drawingIndex(r); j >= 0 {
+				return Drawing{s.w, s.w.drawings[j]}
+			}
+		}
+	}
+	d := s.w.AddDrawing()
+	s.SetDrawing(d)
+	return d
+}
+
+// columnWidth returns the displayed width of the column with the zero based
+// index col.
+func (s Sheet) columnWidth(col uint32) measurement.Distance {
+	w := defaultColumnWidth
+	if s.x.SheetFormatPr != nil && s.x.SheetFormatPr.DefaultColWidthAttr != nil {
+		w = *s.x.SheetFormatPr.DefaultColWidthAttr
+	}
+	for _, cs := range s.x.Cols {
+		for _, c := range cs.Col {
+			if col+1 < c.MinAttr || col+1 > c.MaxAttr {
+				continue
+			}
+			if c.HiddenAttr != nil && *c.HiddenAttr {
+				return 0
+			}
+			if c.WidthAttr != nil {
+				w = *c.WidthAttr
+			}
+		}
+	}
+	// widths are stored in characters of the default font, which Excel
+	// converts to whole pixels
+	px := int((256*w + float64(128/7)) / 256 * 7)
+	return measurement.Distance(px) * measurement.Pixel96
+}
+
+// rowHeights returns the displayed heights of the rows on the sheet that
+// have a custom height or are hidden, keyed by row number, along with the
+// height of all other rows.
+func (s Sheet) rowHeights() (map[uint32]measurement.Distance, measurement.Distance) {
+	def := defaultRowHeight
+	if s.x.SheetFormatPr != nil && s.x.SheetFormatPr.DefaultRowHeightAttr > 0 {
+		def = measurement.Distance(s.x.SheetFormatPr.DefaultRowHeightAttr) * measurement.Point
+	}
+	heights := map[uint32]measurement.Distance{}
+	for _, r := range s.x.SheetData.Row {
+		if r.RAttr == nil {
+			continue
+		}
+		if r.HiddenAttr != nil && *r.HiddenAttr {
+			heights[*r.RAttr] = 0
+		} else if r.HtAttr != nil {
+			heights[*r.RAttr] = measurement.Distance(*r.HtAttr) * measurement.Point
+		}
+	}
+	return heights, def
+}
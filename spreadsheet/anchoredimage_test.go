@@ -0,0 +1,180 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/spreadsheet"
+)
+
+func TestSheetAddImage(t *testing.T) {
+	pngBuf := bytes.Buffer{}
+	if err := png.Encode(&pngBuf, image.NewRGBA(image.Rect(0, 0, 32, 16))); err != nil {
+		t.Fatalf("error encoding png: %s", err)
+	}
+	img, err := common.ImageFromBytes(pngBuf.Bytes())
+	if err != nil {
+		t.Fatalf("error loading image: %s", err)
+	}
+
+	wb := spreadsheet.New()
+	defer wb.Close()
+	sheet := wb.AddSheet()
+	if _, err := sheet.AddImage(common.Image{}); err == nil {
+		t.Errorf("expected an error adding an invalid image")
+	}
+
+	ai, err := sheet.AddImage(img)
+	if err != nil {
+		t.Fatalf("error adding image: %s", err)
+	}
+	// columns default to 64px wide and rows to 15pt high
+	ai.SetSize(100*measurement.Pixel96, 50*measurement.Point)
+	ai.SetOrigin("B", 2)
+	if got := ai.X().From; got.Col != 1 || got.Row != 1 {
+		t.Errorf("expected the image to start at B2, got %d,%d", got.Col, got.Row)
+	}
+	if got := ai.X().To; got.Col != 2 || got.Row != 4 {
+		t.Errorf("expected the image to end in C5, got %d,%d", got.Col, got.Row)
+	}
+	if got := *ai.X().To.ColOff.ST_CoordinateUnqualified; got != 36*9525 {
+		t.Errorf("expected a column offset of 36px, got %d", got)
+	}
+	if got := *ai.X().To.RowOff.ST_CoordinateUnqualified; got != 5*12700 {
+		t.Errorf("expected a row offset of 5pt, got %d", got)
+	}
+
+	abs, err := sheet.AddImage(img)
+	if err != nil {
+		t.Fatalf("error adding image: %s", err)
+	}
+	abs.SetPositioning(spreadsheet.ImagePositioningAbsolute)
+	abs.SetOrigin("F", 10)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("error opening %s: %s", f.Name, err)
+		}
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	for _, fn := range []string{"xl/drawings/drawing1.xml", "xl/drawings/_rels/drawing1.xml.rels",
+		"xl/media/image1.png", "xl/media/image2.png"} {
+		if _, ok := files[fn]; !ok {
+			t.Errorf("expected %s to be written", fn)
+		}
+	}
+	dr := files["xl/drawings/drawing1.xml"]
+	if strings.Count(dr, "<xdr:twoCellAnchor") != 2 || !strings.Contains(dr, `editAs="absolute"`) {
+		t.Errorf("expected two anchored pictures, one absolutely positioned, got %s", dr)
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	if len(wb2.Images) != 2 {
+		t.Errorf("expected 2 images, got %d", len(wb2.Images))
+	}
+	if wb2.Sheets()[0].X().Drawing == nil {
+		t.Errorf("expected the sheet to have a drawing")
+	}
+}
+
+func TestSheetAddImageToExistingDrawing(t *testing.T) {
+	pngBuf := bytes.Buffer{}
+	if err := png.Encode(&pngBuf, image.NewRGBA(image.Rect(0, 0, 32, 16))); err != nil {
+		t.Fatalf("error encoding png: %s", err)
+	}
+	img, err := common.ImageFromBytes(pngBuf.Bytes())
+	if err != nil {
+		t.Fatalf("error loading image: %s", err)
+	}
+
+	// the drawings are numbered in the opposite order to the sheets that use
+	// them
+	wb := spreadsheet.New()
+	defer wb.Close()
+	first := wb.AddSheet()
+	second := wb.AddSheet()
+	second.SetDrawing(wb.AddDrawing())
+	first.SetDrawing(wb.AddDrawing())
+	for _, s := range []spreadsheet.Sheet{first, first, second} {
+		if _, err := s.AddImage(img); err != nil {
+			t.Fatalf("error adding image: %s", err)
+		}
+	}
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if _, err := wb2.Sheets()[0].AddImage(img); err != nil {
+		t.Fatalf("error adding image: %s", err)
+	}
+	cp, err := wb2.CopySheet(0, "Copy")
+	if err != nil {
+		t.Fatalf("error copying sheet: %s", err)
+	}
+	if _, err := cp.AddImage(img); err != nil {
+		t.Fatalf("error adding image: %s", err)
+	}
+	buf.Reset()
+	if err := wb2.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+
+	files := zipContents(t, buf.Bytes())
+	anchors := map[string]int{}
+	for fn, content := range files {
+		if strings.HasPrefix(fn, "xl/drawings/drawing") {
+			anchors[fn] = strings.Count(content, "<xdr:twoCellAnchor")
+		}
+	}
+	if len(anchors) != 3 {
+		t.Fatalf("expected 3 drawings, got %v", anchors)
+	}
+	counts := map[int]int{}
+	for _, n := range anchors {
+		counts[n]++
+	}
+	// the first sheet has three pictures, its copy four and the second sheet
+	// one
+	if counts[1] != 1 || counts[3] != 1 || counts[4] != 1 {
+		t.Errorf("expected drawings with 1, 3 and 4 pictures, got %v", anchors)
+	}
+}
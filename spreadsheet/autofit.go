@@ -0,0 +1,166 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+)
+
+// maxColumnWidth is the largest column width, in characters, that Excel
+// supports.
+const maxColumnWidth = 255
+
+// AutoFitColumn sets the width of a column, e.g. 'B', to fit the widest
+// formatted value in the column.  Excel doesn't store its auto fit setting in
+// the file, so the width is estimated from the number of characters and the
+// font size of each cell and won't always be pixel perfect.  Cells that are
+// part of a merged region are ignored, as they are by Excel.
+func (s Sheet) AutoFitColumn(col string) {
+	s.autoFitColumns(map[uint32]struct{}{reference.ColumnToIndex(col): {}})
+}
+
+// AutoFitAllColumns sets the width of every column that contains a cell value,
+// see AutoFitColumn.
+func (s Sheet) AutoFitAllColumns() {
+	s.autoFitColumns(nil)
+}
+
+// autoFitColumns estimates the width of the columns with the given zero based
+// indices, or of all columns if cols is nil.
+func (s Sheet) autoFitColumns(cols map[uint32]struct{}) {
+	defaultSize := s.defaultFontSize()
+
+	merged := map[uint64]struct{}{}
+	for _, mc := range s.MergedCells() {
+		from, to, err := reference.ParseRangeReference(mc.Reference())
+		if err != nil {
+			continue
+		}
+		for r := from.RowIdx; r <= to.RowIdx; r++ {
+			for c := from.ColumnIdx; c <= to.ColumnIdx; c++ {
+				merged[uint64(r)<<32|uint64(c)] = struct{}{}
+			}
+		}
+	}
+
+	widths := map[uint32]float64{}
+	for _, r := range s.x.SheetData.Row {
+		for _, x := range r.C {
+			if x.RAttr == nil {
+				continue
+			}
+			cref, err := reference.ParseCellReference(*x.RAttr)
+			if err != nil {
+				continue
+			}
+			if cols != nil {
+				if _, ok := cols[cref.ColumnIdx]; !ok {
+					continue
+				}
+			}
+			if _, ok := merged[uint64(cref.RowIdx)<<32|uint64(cref.ColumnIdx)]; ok {
+				continue
+			}
+			c := Cell{s.w, s.x, r, x}
+			// a wrapped value is as wide as its longest line
+			n := 0
+			for _, line := range strings.Split(c.GetFormattedValue(), "\n") {
+				if l := utf8.RuneCountInString(line); l > n {
+					n = l
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			w := float64(n) * c.fontSize(defaultSize) / defaultSize
+			if w > widths[cref.ColumnIdx] {
+				widths[cref.ColumnIdx] = w
+			}
+		}
+	}
+
+	// the maximum digit width, in pixels, of the default font scaled from
+	// Calibri 11 which has a maximum digit width of 7 pixels
+	mdw := math.Max(1, math.Round(7*defaultSize/11))
+	idxs := []uint32{}
+	for idx := range widths {
+		idxs = append(idxs, idx)
+	}
+	sort.Slice(idxs, func(i, j int) bool { return idxs[i] < idxs[j] })
+	for _, idx := range idxs {
+		// width = Truncate([chars * mdw + 5 pixel padding] / mdw * 256) / 256
+		w := math.Trunc((widths[idx]*mdw+5)/mdw*256) / 256
+		w = math.Min(w, maxColumnWidth)
+		s.singleColumn(idx + 1).SetWidth(measurement.Distance(w) * measurement.Character)
+	}
+}
+
+// singleColumn returns the column with the given index (1-N), splitting any
+// existing column definition that spans multiple columns so that changes to
+// the returned column don't affect its neighbors.
+func (s Sheet) singleColumn(idx uint32) Column {
+	for _, colSet := range s.x.Cols {
+		for i, col := range colSet.Col {
+			if idx < col.MinAttr || idx > col.MaxAttr || col.MinAttr == col.MaxAttr {
+				continue
+			}
+			cols := append([]*sml.CT_Col{}, colSet.Col[:i]...)
+			if col.MinAttr < idx {
+				left := *col
+				left.MaxAttr = idx - 1
+				cols = append(cols, &left)
+			}
+			mid := *col
+			mid.MinAttr = idx
+			mid.MaxAttr = idx
+			cols = append(cols, &mid)
+			if col.MaxAttr > idx {
+				right := *col
+				right.MinAttr = idx + 1
+				cols = append(cols, &right)
+			}
+			colSet.Col = append(cols, colSet.Col[i+1:]...)
+			return Column{&mid}
+		}
+	}
+	return s.Column(idx)
+}
+
+// defaultFontSize returns the size of the workbook's default font.
+func (s Sheet) defaultFontSize() float64 {
+	fonts := s.w.StyleSheet.x.Fonts
+	if fonts != nil && len(fonts.Font) > 0 && len(fonts.Font[0].Sz) > 0 && fonts.Font[0].Sz[0].ValAttr > 0 {
+		return fonts.Font[0].Sz[0].ValAttr
+	}
+	return 11
+}
+
+// fontSize returns the size of the font used by the cell, or def if the cell
+// uses the default font.
+func (c Cell) fontSize(def float64) float64 {
+	if c.x.SAttr == nil {
+		return def
+	}
+	xf := c.w.StyleSheet.GetCellStyle(*c.x.SAttr).xf
+	fonts := c.w.StyleSheet.x.Fonts
+	if xf == nil || xf.FontIdAttr == nil || fonts == nil || int(*xf.FontIdAttr) >= len(fonts.Font) {
+		return def
+	}
+	fnt := fonts.Font[*xf.FontIdAttr]
+	if len(fnt.Sz) == 0 || fnt.Sz[0].ValAttr <= 0 {
+		return def
+	}
+	return fnt.Sz[0].ValAttr
+}
@@ -0,0 +1,129 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+)
+
+// CalculationChain returns the cells containing formulas in the order they were
+// last calculated, as recorded in the workbook's calculation chain.  The
+// SheetName of each reference is the unquoted name of the cell's sheet.  It
+// returns nil if the workbook has no calculation chain.
+func (wb *Workbook) CalculationChain() []reference.CellReference {
+	if wb.calcChain == nil {
+		return nil
+	}
+	names := map[int32]string{}
+	for _, s := range wb.x.Sheets.Sheet {
+		names[int32(s.SheetIdAttr)] = s.NameAttr
+	}
+	ret := []reference.CellReference{}
+	// the sheet ID is omitted when it's the same as the previous cell
+	sheetID := int32(0)
+	for _, c := range wb.calcChain.C {
+		if c.IAttr != nil {
+			sheetID = *c.IAttr
+		}
+		if c.RAttr == nil {
+			continue
+		}
+		ref, err := reference.ParseCellReference(*c.RAttr)
+		if err != nil {
+			unioffice.Log("invalid calculation chain cell %s: %s", *c.RAttr, err)
+			continue
+		}
+		ref.SheetName = names[sheetID]
+		ret = append(ret, ref)
+	}
+	return ret
+}
+
+// SetCalculationChain replaces the workbook's calculation chain with cells in
+// the order they should be calculated, each of which must contain a formula
+// and have a SheetName.  Setting an empty chain removes it.  If formulas are
+// changed after the chain is set or read, or the chain doesn't match the
+// formulas in the workbook when it's saved, it's removed and Excel rebuilds it
+// when the workbook is next opened.
+func (wb *Workbook) SetCalculationChain(refs []reference.CellReference) error {
+	if len(refs) == 0 {
+		wb.RemoveCalcChain()
+		return nil
+	}
+	ids := map[string]int32{}
+	for _, s := range wb.x.Sheets.Sheet {
+		ids[s.NameAttr] = int32(s.SheetIdAttr)
+	}
+	chain := sml.NewCalcChain()
+	prevID := int32(-1)
+	for _, ref := range refs {
+		id, ok := ids[ref.SheetName]
+		if !ok {
+			return fmt.Errorf("calculation chain cell %s!%s%d refers to an unknown sheet", ref.SheetName, ref.Column, ref.RowIdx)
+		}
+		c := sml.NewCT_CalcCell()
+		c.RAttr = unioffice.Stringf("%s%d", reference.IndexToColumn(ref.ColumnIdx), ref.RowIdx)
+		if id != prevID {
+			c.IAttr = unioffice.Int32(id)
+			prevID = id
+		}
+		chain.C = append(chain.C, c)
+	}
+
+	if wb.calcChain == nil {
+		dt := unioffice.DocTypeSpreadsheet
+		wb.wbRels.AddRelationship(unioffice.RelativeFilename(dt, unioffice.OfficeDocumentType, unioffice.CalcChainType, 0), unioffice.CalcChainType)
+		wb.ContentTypes.EnsureOverride(unioffice.AbsoluteFilename(dt, unioffice.CalcChainType, 0), unioffice.CalcChainContentType)
+	}
+	wb.calcChain = chain
+	wb.formulasChanged = false
+	return nil
+}
+
+// calcChainMatches returns true if the calculation chain lists exactly the
+// cells of the workbook that contain formulas.
+func (wb *Workbook) calcChainMatches() bool {
+	if len(wb.streams) > 0 {
+		// the cells of streamed sheets have already been written
+		return false
+	}
+	chain := map[string]struct{}{}
+	sheetID := int32(0)
+	for _, c := range wb.calcChain.C {
+		if c.IAttr != nil {
+			sheetID = *c.IAttr
+		}
+		if c.RAttr == nil {
+			return false
+		}
+		chain[fmt.Sprintf("%d!%s", sheetID, *c.RAttr)] = struct{}{}
+	}
+	formulas := 0
+	for i, ws := range wb.xws {
+		id := wb.x.Sheets.Sheet[i].SheetIdAttr
+		for _, r := range ws.SheetData.Row {
+			for _, c := range r.C {
+				if c.F == nil {
+					continue
+				}
+				if c.RAttr == nil {
+					return false
+				}
+				if _, ok := chain[fmt.Sprintf("%d!%s", id, *c.RAttr)]; !ok {
+					return false
+				}
+				formulas++
+			}
+		}
+	}
+	return formulas == len(chain)
+}
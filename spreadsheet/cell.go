@@ -13,6 +13,7 @@ import (
 	"math"
 	"math/big"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/unidoc/unioffice"
@@ -20,6 +21,7 @@ import (
 	"github.com/unidoc/unioffice/schema/soo/sml"
 	"github.com/unidoc/unioffice/spreadsheet/format"
 	"github.com/unidoc/unioffice/spreadsheet/reference"
+	"golang.org/x/text/language"
 )
 
 const iso8601Format = "2006-01-02T15:04:05Z07:00"
@@ -46,13 +48,41 @@ func (c Cell) Reference() string {
 	return ""
 }
 
-// Clear clears the cell's value and type.
+// Clear clears the cell's value, formula and type, keeping its style.
 func (c Cell) Clear() {
 	c.clearValue()
 	c.x.TAttr = sml.ST_CellTypeUnset
 }
 
+// ClearAll clears everything about the cell including its style and any
+// hyperlink, leaving an empty cell.
+func (c Cell) ClearAll() {
+	c.clearValue()
+	c.x.SAttr = nil
+	c.x.CmAttr = nil
+	c.x.VmAttr = nil
+	c.x.PhAttr = nil
+	c.x.ExtLst = nil
+	if c.s.Hyperlinks == nil {
+		return
+	}
+	ref := c.Reference()
+	hls := c.s.Hyperlinks.Hyperlink[:0]
+	for _, hl := range c.s.Hyperlinks.Hyperlink {
+		if hl.RefAttr != ref {
+			hls = append(hls, hl)
+		}
+	}
+	c.s.Hyperlinks.Hyperlink = hls
+	if len(hls) == 0 {
+		c.s.Hyperlinks = nil
+	}
+}
+
 func (c Cell) clearValue() {
+	if c.x.F != nil {
+		c.w.formulasChanged = true
+	}
 	c.x.F = nil
 	c.x.Is = nil
 	c.x.V = nil
@@ -76,21 +106,89 @@ func (c Cell) SetRichTextString() RichText {
 	return RichText{c.x.Is}
 }
 
+// GetRichText returns the formatted runs of a rich text cell, whether stored
+// inline or in the shared strings table.  It returns false if the cell doesn't
+// contain a string.
+func (c Cell) GetRichText() (RichText, bool) {
+	switch c.x.TAttr {
+	case sml.ST_CellTypeInlineStr:
+		if c.x.Is != nil {
+			return RichText{c.x.Is}, true
+		}
+	case sml.ST_CellTypeS:
+		if c.x.V == nil {
+			return RichText{}, false
+		}
+		id, err := strconv.Atoi(*c.x.V)
+		if err != nil {
+			return RichText{}, false
+		}
+		if rst, ok := c.w.SharedStrings.getRst(id); ok {
+			return RichText{rst}, true
+		}
+	}
+	return RichText{}, false
+}
+
 // SetFormulaRaw sets the cell type to formula, and the raw formula to the given string
 func (c Cell) SetFormulaRaw(s string) {
 	c.clearValue()
 	c.x.TAttr = sml.ST_CellTypeStr
-	c.x.F = sml.NewCT_CellFormula()
+	c.x.F = c.newFormula()
 	c.x.F.Content = s
 }
 
+// SetFormula sets the cell's formula, clearing any existing value. The formula
+// is stored without a leading '=', so both "=SUM(A1:A5)" and "SUM(A1:A5)" are
+// accepted. Use SetFormulaResult or SetFormulaResultNumber to store a cached
+// result that will be displayed before the formula is recalculated.
+func (c Cell) SetFormula(formula string) {
+	c.clearValue()
+	c.x.F = c.newFormula()
+	c.x.F.Content = strings.TrimPrefix(formula, "=")
+}
+
+// newFormula returns a new formula for the cell, recording that the formulas of
+// the workbook have changed so the calculation chain is no longer valid.
+func (c Cell) newFormula() *sml.CT_CellFormula {
+	c.w.formulasChanged = true
+	return sml.NewCT_CellFormula()
+}
+
+// SetFormulaResult sets the cached string result of a formula cell.  The cell
+// is left unchanged if it doesn't have a formula.
+func (c Cell) SetFormulaResult(value string) {
+	if !c.HasFormula() {
+		unioffice.Log("cell %s has no formula to set the result of", c.Reference())
+		return
+	}
+	c.x.TAttr = sml.ST_CellTypeStr
+	c.SetCachedFormulaResult(value)
+}
+
+// SetFormulaResultNumber sets the cached numeric result of a formula cell.  The
+// cell is left unchanged if it doesn't have a formula.
+func (c Cell) SetFormulaResultNumber(v float64) {
+	if !c.HasFormula() {
+		unioffice.Log("cell %s has no formula to set the result of", c.Reference())
+		return
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		c.x.TAttr = sml.ST_CellTypeE
+		c.x.V = unioffice.String("#NUM!")
+		return
+	}
+	c.x.TAttr = sml.ST_CellTypeN
+	c.x.V = unioffice.String(strconv.FormatFloat(v, 'f', -1, 64))
+}
+
 // SetFormulaArray sets the cell type to formula array, and the raw formula to
 // the given string. This is equivlent to entering a formula and pressing
 // Ctrl+Shift+Enter in Excel.
 func (c Cell) SetFormulaArray(s string) {
 	c.clearValue()
 	c.x.TAttr = sml.ST_CellTypeStr
-	c.x.F = sml.NewCT_CellFormula()
+	c.x.F = c.newFormula()
 	c.x.F.TAttr = sml.ST_CellFormulaTypeArray
 	c.x.F.Content = s
 }
@@ -101,7 +199,7 @@ func (c Cell) SetFormulaArray(s string) {
 func (c Cell) SetFormulaShared(formula string, rows, cols uint32) error {
 	c.clearValue()
 	c.x.TAttr = sml.ST_CellTypeStr
-	c.x.F = sml.NewCT_CellFormula()
+	c.x.F = c.newFormula()
 	c.x.F.TAttr = sml.ST_CellFormulaTypeShared
 	c.x.F.Content = formula
 	cref, err := reference.ParseCellReference(c.Reference())
@@ -140,8 +238,13 @@ func (c Cell) SetFormulaShared(formula string, rows, cols uint32) error {
 
 // SetString sets the cell type to string, and the value to the given string,
 // returning an ID from the shared strings table. To reuse a string, call
-// SetStringByID with the ID returned.
+// SetStringByID with the ID returned.  If the workbook is set to use inline
+// strings, the string is stored inline and -1 is returned.
 func (c Cell) SetString(s string) int {
+	if c.w.inlineStrings {
+		c.SetInlineString(s)
+		return -1
+	}
 	c.w.ensureSharedStringsRelationships()
 	c.clearValue()
 	id := c.w.SharedStrings.AddString(s)
@@ -223,7 +326,24 @@ func (c Cell) getLocked() bool {
 // be used if you care about replicating what Excel would show, otherwise
 // GetValueAsNumber()/GetValueAsTime
 func (c Cell) GetFormattedValue() string {
+	return c.formattedValue(false)
+}
+
+// GetFormattedValueLocalized returns the formatted cell value in the same way
+// as GetFormattedValue, but displays numbers with the decimal and thousands
+// separators of the locale in the cell's number format, e.g. [$-407] for
+// German as set by SetNumberFormatLocalized.  US separators are used if the
+// format has no known locale.
+func (c Cell) GetFormattedValueLocalized() string {
+	return c.formattedValue(true)
+}
+
+func (c Cell) formattedValue(localized bool) string {
 	f := c.getFormat()
+	l := format.Locale{}
+	if localized {
+		l, _ = format.CodeLocale(f)
+	}
 	switch c.x.TAttr {
 	// boolean
 	case sml.ST_CellTypeB:
@@ -235,7 +355,7 @@ func (c Cell) GetFormattedValue() string {
 	// number
 	case sml.ST_CellTypeN:
 		v, _ := c.GetValueAsNumber()
-		return format.Number(v, f)
+		return c.formatNumber(v, f, l)
 	// error
 	case sml.ST_CellTypeE:
 		if c.x.V != nil {
@@ -249,7 +369,7 @@ func (c Cell) GetFormattedValue() string {
 		s := c.GetString()
 		if format.IsNumber(s) {
 			v, _ := strconv.ParseFloat(s, 64)
-			return format.Number(v, f)
+			return c.formatNumber(v, f, l)
 		}
 		return format.String(s, f)
 	case sml.ST_CellTypeUnset:
@@ -263,19 +383,41 @@ func (c Cell) GetFormattedValue() string {
 
 		v, err := c.GetValueAsNumber()
 		if err == nil {
-			return format.Number(v, f)
+			return c.formatNumber(v, f, l)
 		}
 		return format.String(s, f)
 	}
 }
 
-// GetValueAsNumber retrieves the cell's value as a number
+// formatNumber formats a number with the separators of locale l using the
+// date system of the workbook.
+func (c Cell) formatNumber(v float64, f string, l format.Locale) string {
+	if c.w.Uses1904Dates() {
+		return format.NumberDate1904Localized(v, f, l)
+	}
+	return format.NumberLocalized(v, f, l)
+}
+
+// GetValueAsNumber retrieves the cell's value as a number. Boolean cells are
+// returned as 1 or 0, while string and error cells return an error.
 func (c Cell) GetValueAsNumber() (float64, error) {
-	if c.x.V == nil && c.x.Is == nil {
+	switch c.x.TAttr {
+	case sml.ST_CellTypeB:
+		b, err := c.GetValueAsBool()
+		if err != nil {
+			return math.NaN(), err
+		}
+		return float64(b2i(b)), nil
+	case sml.ST_CellTypeE:
+		return math.NaN(), fmt.Errorf("cell contains error %s", c.GetString())
+	case sml.ST_CellTypeS, sml.ST_CellTypeInlineStr:
+		return math.NaN(), errors.New("cell is not of number type")
+	}
+	if c.x.V == nil {
 		// empty cells have an implicit zero value
 		return 0, nil
 	}
-	if c.x.TAttr == sml.ST_CellTypeS || !format.IsNumber(*c.x.V) {
+	if !format.IsNumber(*c.x.V) {
 		return math.NaN(), errors.New("cell is not of number type")
 	}
 	return strconv.ParseFloat(*c.x.V, 64)
@@ -287,6 +429,81 @@ func (c Cell) SetNumberWithStyle(v float64, f StandardFormat) {
 	c.SetStyle(c.w.StyleSheet.GetOrCreateStandardNumberFormat(f))
 }
 
+// SetNumberFormatLocalized sets the cell's number format to a custom format
+// code marked with the locale given by a language tag, e.g. language.German,
+// keeping the rest of the cell's style.  The code is written with US
+// separators as always, e.g. `#,##0.00 "€"`, and is stored with a locale
+// prefix such as [$-407] which GetFormattedValueLocalized uses to display the
+// value with the locale's separators.
+func (c Cell) SetNumberFormatLocalized(code string, locale language.Tag) error {
+	l, ok := format.LookupLocale(locale)
+	if !ok {
+		return fmt.Errorf("unsupported locale %s", locale)
+	}
+	xf := sml.NewCT_Xf()
+	if c.x.SAttr != nil {
+		if err := copyXML(c.w.StyleSheet.GetCellStyle(*c.x.SAttr).xf, xf); err != nil {
+			return err
+		}
+	}
+	cs := CellStyle{c.w, xf, c.w.StyleSheet.x.CellXfs}
+	cs.SetNumberFormat(format.WithLocale(code, l))
+	c.SetStyle(c.w.StyleSheet.getOrCreateCellStyle(xf))
+	return nil
+}
+
+// SetValue sets the cell to a value of any type with a typed setter, calling
+// the appropriate setter for strings, numbers, booleans and times.  Times are
+// styled as a date, or as a date and time if they have a time of day, and nil
+// clears the cell.  Other types return an error unless the workbook is set to
+// store them as strings with SetStringifyValues.
+func (c Cell) SetValue(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		c.Clear()
+	case string:
+		c.SetString(v)
+	case float64:
+		c.SetNumber(v)
+	case float32:
+		c.SetNumber(float64(v))
+	case int:
+		c.SetNumber(float64(v))
+	case int8:
+		c.SetNumber(float64(v))
+	case int16:
+		c.SetNumber(float64(v))
+	case int32:
+		c.SetNumber(float64(v))
+	case int64:
+		c.SetNumber(float64(v))
+	case uint:
+		c.SetNumber(float64(v))
+	case uint8:
+		c.SetNumber(float64(v))
+	case uint16:
+		c.SetNumber(float64(v))
+	case uint32:
+		c.SetNumber(float64(v))
+	case uint64:
+		c.SetNumber(float64(v))
+	case bool:
+		c.SetBool(v)
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			c.SetDateWithStyle(v)
+		} else {
+			c.SetTimeWithStyle(v)
+		}
+	default:
+		if !c.w.stringifyValues {
+			return fmt.Errorf("unsupported cell value type %T", v)
+		}
+		c.SetString(fmt.Sprint(v))
+	}
+	return nil
+}
+
 // SetBool sets the cell type to boolean and the value to the given boolean
 // value.
 func (c Cell) SetBool(v bool) {
@@ -302,6 +519,12 @@ func (c Cell) SetError(msg string) {
 	c.x.TAttr = sml.ST_CellTypeE
 }
 
+// GetBool returns the value of a boolean cell, as set by SetBool.  An error is
+// returned if the cell isn't of bool type.
+func (c Cell) GetBool() (bool, error) {
+	return c.GetValueAsBool()
+}
+
 // GetValueAsBool retrieves the cell's value as a boolean
 func (c Cell) GetValueAsBool() (bool, error) {
 	if c.x.TAttr != sml.ST_CellTypeB {
@@ -330,22 +553,28 @@ func asUTC(d time.Time) time.Time {
 		d.Minute(), d.Second(), d.Nanosecond(), time.UTC)
 }
 
-// SetTime sets the cell value to a date. It's stored as the number of days past
-// th sheet epoch. When we support v5 strict, we can store an ISO 8601 date
-// string directly, however that's not allowed with v5 transitional  (even
-// though it works in Excel).
-func (c Cell) SetTime(d time.Time) {
-	c.clearValue()
+// leapBugDate is the first date that Excel's 1900 date system gives the
+// correct serial number for.  Excel treats 1900 as a leap year for
+// compatibility with Lotus 1-2-3, so serial 60 is the non-existent 29 Feb 1900
+// and dates before March 1900 are one day off from the 30 Dec 1899 epoch.
+var leapBugDate = time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// firstSerialDate is the earliest date that can be represented by a serial
+// date in the 1900 date system.
+var firstSerialDate = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// timeToSerial converts a time to the number of days past the workbook epoch,
+// returning false if the time can't be represented.
+func (c Cell) timeToSerial(d time.Time) (*big.Float, bool) {
 	d = asUTC(d)
 	epoch := c.w.Epoch()
-	if d.Before(epoch) {
-		// the ECMA 376 standard says these works, but Excel doesn't appear to
-		// support negative serial dates
-		unioffice.Log("times before 1900 are not supported")
-		return
+	if d.Before(epoch) || (!c.w.Uses1904Dates() && d.Before(firstSerialDate)) {
+		return nil, false
 	}
-
 	delta := d.Sub(epoch)
+	if !c.w.Uses1904Dates() && d.Before(leapBugDate) {
+		delta -= 24 * time.Hour
+	}
 
 	result := new(big.Float)
 
@@ -356,7 +585,23 @@ func (c Cell) SetTime(d time.Time) {
 	nsPerDay := new(big.Float)
 	nsPerDay.SetUint64(24 * 60 * 60 * 1e9)
 	result.Quo(deltaNs, nsPerDay)
+	return result, true
+}
 
+// SetTime sets the cell value to a date. It's stored as the number of days past
+// th sheet epoch. When we support v5 strict, we can store an ISO 8601 date
+// string directly, however that's not allowed with v5 transitional  (even
+// though it works in Excel). The cell is not styled via this method, so it will
+// display as a number. SetTimeWithStyle should normally be used instead.
+func (c Cell) SetTime(d time.Time) {
+	c.clearValue()
+	result, ok := c.timeToSerial(d)
+	if !ok {
+		// the ECMA 376 standard says these works, but Excel doesn't appear to
+		// support negative serial dates
+		unioffice.Log("times before 1900 are not supported")
+		return
+	}
 	c.x.V = unioffice.String(result.Text('g', 20))
 }
 
@@ -367,29 +612,15 @@ func (c Cell) SetTime(d time.Time) {
 // display as a number. SetDateWithStyle should normally be used instead.
 func (c Cell) SetDate(d time.Time) {
 	c.clearValue()
-	d = asUTC(d)
-	epoch := c.w.Epoch()
-	if d.Before(epoch) {
+	result, ok := c.timeToSerial(d)
+	if !ok {
 		// the ECMA 376 standard says these works, but Excel doesn't appear to
 		// support negative serial dates
 		unioffice.Log("dates before 1900 are not supported")
 		return
 	}
-	delta := d.Sub(epoch)
-
-	result := new(big.Float)
-
-	deltaNs := new(big.Float)
-	deltaNs.SetPrec(128)
-	deltaNs.SetUint64(uint64(delta))
-
-	nsPerDay := new(big.Float)
-	nsPerDay.SetUint64(24 * 60 * 60 * 1e9)
-	result.Quo(deltaNs, nsPerDay)
-
-	hrs, _ := result.Uint64()
-
-	c.x.V = unioffice.Stringf("%d", hrs)
+	days, _ := result.Uint64()
+	c.x.V = unioffice.Stringf("%d", days)
 }
 
 // GetValueAsTime retrieves the cell's value as a time.  There is no difference
@@ -397,8 +628,8 @@ func (c Cell) SetDate(d time.Time) {
 // typically a date cell won't have a fractional component. GetValueAsTime will
 // work for date cells as well.
 func (c Cell) GetValueAsTime() (time.Time, error) {
-	if c.x.TAttr != sml.ST_CellTypeUnset {
-		return time.Time{}, errors.New("cell type should be unset")
+	if c.x.TAttr != sml.ST_CellTypeUnset && c.x.TAttr != sml.ST_CellTypeN {
+		return time.Time{}, errors.New("cell type should be unset or number")
 	}
 	if c.x.V == nil {
 		return time.Time{}, errors.New("cell has no value")
@@ -407,6 +638,14 @@ func (c Cell) GetValueAsTime() (time.Time, error) {
 	if err != nil {
 		return time.Time{}, err
 	}
+	if f.Sign() < 0 {
+		return time.Time{}, errors.New("negative serial dates are not supported")
+	}
+
+	// serial dates before 1 Mar 1900 don't include the non-existent leap day
+	if !c.w.Uses1904Dates() && f.Cmp(big.NewFloat(60)) < 0 {
+		f.Add(f, big.NewFloat(1))
+	}
 
 	day := new(big.Float)
 	day.SetUint64(uint64(24 * time.Hour))
@@ -432,6 +671,12 @@ func (c Cell) SetDateWithStyle(d time.Time) {
 	c.SetStyle(cs)
 }
 
+// SetTimeWithStyle sets a time with the default date and time style applied.
+func (c Cell) SetTimeWithStyle(d time.Time) {
+	c.SetTime(d)
+	c.SetStyle(c.w.StyleSheet.GetOrCreateStandardNumberFormat(StandardFormatDateTime))
+}
+
 // SetStyle applies a style to the cell.  This style is referenced in the
 // generated XML via CellStyle.Index().
 func (c Cell) SetStyle(cs CellStyle) {
@@ -445,12 +690,14 @@ func (c Cell) SetStyleIndex(idx uint32) {
 }
 
 // GetString returns the string in a cell if it's an inline or string table
-// string. Otherwise it returns an empty string.
+// string, otherwise it returns the raw value of the cell, e.g. "1" for a true
+// boolean cell, or an empty string if it has none.  Use GetFormattedValue to
+// get booleans as they are displayed.
 func (c Cell) GetString() string {
 	switch c.x.TAttr {
 	case sml.ST_CellTypeInlineStr:
-		if c.x.Is != nil && c.x.Is.T != nil {
-			return *c.x.Is.T
+		if c.x.Is != nil && (c.x.Is.T != nil || len(c.x.Is.R) > 0) {
+			return rstText(c.x.Is)
 		}
 		if c.x.V != nil {
 			return *c.x.V
@@ -478,10 +725,10 @@ func (c Cell) GetString() string {
 func (c Cell) GetRawValue() (string, error) {
 	switch c.x.TAttr {
 	case sml.ST_CellTypeInlineStr:
-		if c.x.Is == nil || c.x.Is.T == nil {
+		if c.x.Is == nil {
 			return "", nil
 		}
-		return *c.x.Is.T, nil
+		return rstText(c.x.Is), nil
 	case sml.ST_CellTypeS:
 		if c.x.V == nil {
 			return "", nil
@@ -504,29 +751,63 @@ func (c Cell) GetRawValue() (string, error) {
 
 // SetHyperlink sets a hyperlink on a cell.
 func (c Cell) SetHyperlink(hl common.Hyperlink) {
-	if c.s.Hyperlinks == nil {
-		c.s.Hyperlinks = sml.NewCT_Hyperlinks()
-	}
 	rel := common.Relationship(hl)
-
-	hle := sml.NewCT_Hyperlink()
-	hle.RefAttr = c.Reference()
+	hle := c.hyperlink()
 	hle.IdAttr = unioffice.String(rel.ID())
-	c.s.Hyperlinks.Hyperlink = append(c.s.Hyperlinks.Hyperlink, hle)
+	hle.LocationAttr = nil
 }
 
-// AddHyperlink creates and sets a hyperlink on a cell.
-func (c Cell) AddHyperlink(url string) {
+// AddHyperlink creates and sets a hyperlink on a cell.  An optional tooltip
+// may be provided which is displayed when hovering over the cell.
+func (c Cell) AddHyperlink(url string, tooltip ...string) {
 	// store the relationships so we don't need to do a lookup here?
 	for i, ws := range c.w.xws {
 		if ws == c.s {
 			// add a hyperlink relationship in the worksheet relationships file
 			c.SetHyperlink(c.w.xwsRels[i].AddHyperlink(url))
+			c.setHyperlinkTooltip(tooltip)
 			return
 		}
 	}
 }
 
+// SetHyperlinkToCell sets a hyperlink on the cell that points to a location
+// within the workbook, e.g. SetHyperlinkToCell("Sheet 1", "B2").  An optional
+// tooltip may be provided which is displayed when hovering over the cell.
+func (c Cell) SetHyperlinkToCell(sheetName, ref string, tooltip ...string) {
+	hle := c.hyperlink()
+	hle.IdAttr = nil
+	loc := fmt.Sprintf("'%s'!%s", strings.Replace(sheetName, "'", "''", -1), ref)
+	hle.LocationAttr = unioffice.String(loc)
+	c.setHyperlinkTooltip(tooltip)
+}
+
+// hyperlink returns the hyperlink element for the cell, creating it if
+// necessary.
+func (c Cell) hyperlink() *sml.CT_Hyperlink {
+	if c.s.Hyperlinks == nil {
+		c.s.Hyperlinks = sml.NewCT_Hyperlinks()
+	}
+	ref := c.Reference()
+	for _, hl := range c.s.Hyperlinks.Hyperlink {
+		if hl.RefAttr == ref {
+			return hl
+		}
+	}
+	hle := sml.NewCT_Hyperlink()
+	hle.RefAttr = ref
+	c.s.Hyperlinks.Hyperlink = append(c.s.Hyperlinks.Hyperlink, hle)
+	return hle
+}
+
+func (c Cell) setHyperlinkTooltip(tooltip []string) {
+	hle := c.hyperlink()
+	hle.TooltipAttr = nil
+	if len(tooltip) > 0 {
+		hle.TooltipAttr = unioffice.String(tooltip[0])
+	}
+}
+
 // IsNumber returns true if the cell is a number type cell.
 func (c Cell) IsNumber() bool {
 	switch c.x.TAttr {
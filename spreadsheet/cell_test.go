@@ -8,13 +8,20 @@
 package spreadsheet_test
 
 import (
+	"archive/zip"
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/color"
 	"github.com/unidoc/unioffice/schema/soo/sml"
 	"github.com/unidoc/unioffice/spreadsheet"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+	"golang.org/x/text/language"
 )
 
 func TestCell(t *testing.T) {
@@ -98,6 +105,128 @@ func TestCellGetBool(t *testing.T) {
 	}
 }
 
+func TestCellBoolAndErrorRoundTrip(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Cell("A1").SetBool(true)
+	sheet.Cell("A2").SetBool(false)
+	sheet.Cell("A3").SetError("#DIV/0!")
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	sheet = wb2.Sheets()[0]
+
+	for _, tc := range []struct {
+		ref string
+		exp bool
+		raw string
+	}{{"A1", true, "1"}, {"A2", false, "0"}} {
+		ref, exp := tc.ref, tc.exp
+		c := sheet.Cell(ref)
+		if !c.IsBool() {
+			t.Errorf("expected %s to be a bool cell", ref)
+		}
+		if *c.X().V != tc.raw {
+			t.Errorf("expected %s to be stored as %s, got %s", ref, tc.raw, *c.X().V)
+		}
+		if b, err := c.GetBool(); err != nil || b != exp {
+			t.Errorf("expected %s = %v, got %v (%v)", ref, exp, b, err)
+		}
+	}
+	sheet.Cell("B1").SetNumber(1)
+	sheet.Cell("B2").SetString("TRUE")
+	for _, ref := range []string{"A3", "B1", "B2", "C1"} {
+		if _, err := sheet.Cell(ref).GetBool(); err == nil {
+			t.Errorf("expected an error reading non-bool cell %s as a bool", ref)
+		}
+	}
+	if got := sheet.Cell("A1").GetFormattedValue(); got != "TRUE" {
+		t.Errorf("expected TRUE, got %s", got)
+	}
+
+	c := sheet.Cell("A3")
+	if !c.IsError() || c.IsBool() || c.IsNumber() {
+		t.Errorf("expected A3 to be an error cell")
+	}
+	if got := c.GetString(); got != "#DIV/0!" {
+		t.Errorf("expected #DIV/0!, got %s", got)
+	}
+	if _, err := c.GetValueAsNumber(); err == nil {
+		t.Errorf("expected an error reading an error cell as a number")
+	}
+}
+
+func TestCellSetValue(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	date := time.Date(2019, time.March, 4, 0, 0, 0, 0, time.Local)
+	dateTime := time.Date(2019, time.March, 4, 13, 30, 0, 0, time.Local)
+	td := []struct {
+		v   interface{}
+		typ sml.ST_CellType
+		exp string
+	}{
+		{"text", sml.ST_CellTypeS, "text"},
+		{42, sml.ST_CellTypeN, "42"},
+		{int64(-7), sml.ST_CellTypeN, "-7"},
+		{uint8(8), sml.ST_CellTypeN, "8"},
+		{float32(0.5), sml.ST_CellTypeN, "0.5"},
+		{2.25, sml.ST_CellTypeN, "2.25"},
+		{true, sml.ST_CellTypeB, "1"},
+		{false, sml.ST_CellTypeB, "0"},
+	}
+	for i, tc := range td {
+		c := sheet.Cell(fmt.Sprintf("A%d", i+1))
+		if err := c.SetValue(tc.v); err != nil {
+			t.Fatalf("error setting %T: %s", tc.v, err)
+		}
+		if c.X().TAttr != tc.typ {
+			t.Errorf("expected %T to set type %s, got %s", tc.v, tc.typ, c.X().TAttr)
+		}
+		if got := c.GetString(); got != tc.exp {
+			t.Errorf("expected %T value %s, got %s", tc.v, tc.exp, got)
+		}
+	}
+
+	c := sheet.Cell("B1")
+	c.SetValue(date)
+	if got, err := c.GetValueAsTime(); err != nil || !got.Equal(date) {
+		t.Errorf("expected %s, got %s (%v)", date, got, err)
+	}
+	if got := c.GetFormattedValue(); got != "3/4/19" {
+		t.Errorf("expected a date format, got %s", got)
+	}
+	c = sheet.Cell("B2")
+	c.SetValue(dateTime)
+	if got, err := c.GetValueAsTime(); err != nil || !got.Equal(dateTime) {
+		t.Errorf("expected %s, got %s (%v)", dateTime, got, err)
+	}
+	if cs := wb.StyleSheet.GetCellStyle(*c.X().SAttr); cs.NumberFormat() != uint32(spreadsheet.StandardFormatDateTime) {
+		t.Errorf("expected a date and time format, got %d", cs.NumberFormat())
+	}
+
+	c.SetValue(nil)
+	if !c.IsEmpty() {
+		t.Errorf("expected nil to clear the cell")
+	}
+	if err := c.SetValue(struct{ A int }{1}); err == nil {
+		t.Errorf("expected an error for an unsupported type")
+	}
+	wb.SetStringifyValues(true)
+	if err := c.SetValue(struct{ A int }{1}); err != nil {
+		t.Errorf("expected unsupported types to be stringified, got %s", err)
+	}
+	if got := c.GetString(); got != "{1}" {
+		t.Errorf("expected {1}, got %s", got)
+	}
+}
+
 func TestCellGetDate(t *testing.T) {
 	wb := spreadsheet.New()
 	sheet := wb.AddSheet()
@@ -170,6 +299,135 @@ func TestCellClear(t *testing.T) {
 	}
 }
 
+func TestCellClearKeepsStyle(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	cs := wb.StyleSheet.AddCellStyle()
+	cs.SetHorizontalAlignment(sml.ST_HorizontalAlignmentCenter)
+
+	row := sheet.Row(1)
+	a1 := row.Cell("A")
+	a1.SetString("shared")
+	a1.SetStyle(cs)
+	b1 := row.Cell("B")
+	b1.SetFormulaRaw("A1")
+	b1.SetStyle(cs)
+
+	a1.Clear()
+	if !a1.IsEmpty() || a1.GetString() != "" {
+		t.Errorf("expected A1 to be empty, got %s", a1.GetString())
+	}
+	if a1.X().SAttr == nil || *a1.X().SAttr != cs.Index() {
+		t.Errorf("expected A1 to keep its style")
+	}
+
+	a1.SetNumber(1)
+	row.Clear()
+	for _, c := range row.Cells() {
+		if !c.IsEmpty() {
+			t.Errorf("expected %s to be empty", c.Reference())
+		}
+		if c.X().SAttr == nil || *c.X().SAttr != cs.Index() {
+			t.Errorf("expected %s to keep its style", c.Reference())
+		}
+	}
+
+	b1.AddHyperlink("http://example.com")
+	b1.ClearAll()
+	if b1.X().SAttr != nil {
+		t.Errorf("expected B1 style to be removed")
+	}
+	if sheet.X().Hyperlinks != nil {
+		t.Errorf("expected B1 hyperlink to be removed")
+	}
+}
+
+func TestCellInlineAndSharedStrings(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Cell("A1").SetString("shared")
+	sheet.Cell("A2").SetInlineString("inline")
+	wb.SetInlineStrings(true)
+	if id := sheet.Cell("A3").SetString("default inline"); id != -1 {
+		t.Errorf("expected no shared string ID, got %d", id)
+	}
+	wb.SetInlineStrings(false)
+	sheet.Cell("A4").SetString("shared again")
+
+	if n := len(wb.SharedStrings.X().Si); n != 2 {
+		t.Errorf("expected 2 shared strings, got %d", n)
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	sheet = wb2.Sheets()[0]
+	for _, tc := range []struct {
+		ref string
+		typ sml.ST_CellType
+		exp string
+	}{
+		{"A1", sml.ST_CellTypeS, "shared"},
+		{"A2", sml.ST_CellTypeInlineStr, "inline"},
+		{"A3", sml.ST_CellTypeInlineStr, "default inline"},
+		{"A4", sml.ST_CellTypeS, "shared again"},
+	} {
+		c := sheet.Cell(tc.ref)
+		if c.X().TAttr != tc.typ {
+			t.Errorf("expected %s to have type %s, got %s", tc.ref, tc.typ, c.X().TAttr)
+		}
+		if got := c.GetString(); got != tc.exp {
+			t.Errorf("expected %s = %s, got %s", tc.ref, tc.exp, got)
+		}
+	}
+}
+
+func TestCellSetNumberFormatLocalized(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	bold := wb.StyleSheet.AddCellStyle()
+	bold.SetFont(wb.StyleSheet.AddFont())
+
+	cell := sheet.Cell("A1")
+	cell.SetNumber(1234567.891)
+	cell.SetStyle(bold)
+	if err := cell.SetNumberFormatLocalized(`#,##0.00 "€"`, language.German); err != nil {
+		t.Fatalf("error setting number format: %s", err)
+	}
+	if got := cell.GetFormattedValueLocalized(); got != "1.234.567,89 €" {
+		t.Errorf("expected 1.234.567,89 €, got %s", got)
+	}
+	if got := cell.GetFormattedValue(); got != "1,234,567.89 €" {
+		t.Errorf("expected US separators unless localized, got %s", got)
+	}
+	cs := wb.StyleSheet.GetCellStyle(*cell.X().SAttr)
+	if got := wb.StyleSheet.GetNumberFormat(cs.NumberFormat()).GetFormat(); got != `[$-407]#,##0.00 "€"` {
+		t.Errorf("expected the format code to have a German locale prefix, got %s", got)
+	}
+	if cs.X().FontIdAttr == nil || *cs.X().FontIdAttr != *bold.X().FontIdAttr {
+		t.Errorf("expected the cell to keep its font")
+	}
+	if bold.HasNumberFormat() {
+		t.Errorf("expected the original style to be unchanged")
+	}
+
+	other := sheet.Cell("A2")
+	other.SetNumber(1)
+	other.SetStyle(bold)
+	other.SetNumberFormatLocalized(`#,##0.00 "€"`, language.MustParse("de-DE"))
+	if *other.X().SAttr != *cell.X().SAttr {
+		t.Errorf("expected identical styles to be shared")
+	}
+	if err := other.SetNumberFormatLocalized("0.00", language.Korean); err == nil {
+		t.Errorf("expected an error for an unknown locale")
+	}
+}
+
 func TestCellRichTextString(t *testing.T) {
 	wb := spreadsheet.New()
 	sheet := wb.AddSheet()
@@ -246,3 +504,440 @@ func TestCellDateFormat(t *testing.T) {
 	}
 	wb.SaveToFile("/tmp/future.xlsx")
 }
+
+func TestCellSetFormula(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Cell("A1").SetNumber(1)
+	sheet.Cell("A2").SetNumber(2)
+
+	cell := sheet.Cell("A3")
+	cell.SetString("literal")
+	cell.SetFormula("=SUM(A1:A2)")
+	if cell.X().V != nil || cell.X().Is != nil {
+		t.Errorf("expected setting a formula to clear the existing value")
+	}
+	if cell.X().TAttr != sml.ST_CellTypeUnset {
+		t.Errorf("expected unset cell type, got %s", cell.X().TAttr)
+	}
+	if got := cell.GetFormula(); got != "SUM(A1:A2)" {
+		t.Errorf("expected formula without leading '=', got %s", got)
+	}
+
+	cell.SetFormulaResultNumber(3)
+	if cell.X().TAttr != sml.ST_CellTypeN {
+		t.Errorf("expected number cell type, got %s", cell.X().TAttr)
+	}
+	if got := cell.GetCachedFormulaResult(); got != "3" {
+		t.Errorf("expected cached result 3, got %s", got)
+	}
+
+	str := sheet.Cell("B1")
+	str.SetFormula(`"a"&"b"`)
+	str.SetFormulaResult("ab")
+	if str.X().TAttr != sml.ST_CellTypeStr {
+		t.Errorf("expected str cell type, got %s", str.X().TAttr)
+	}
+
+	// a result can only be cached for a formula
+	num := sheet.Cell("C1")
+	num.SetNumber(5)
+	num.SetFormulaResult("text")
+	num.SetFormulaResultNumber(6)
+	if num.X().TAttr != sml.ST_CellTypeN || num.GetString() != "5" {
+		t.Errorf("expected a cell without a formula to be unchanged, got %s %s", num.X().TAttr, num.GetString())
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	sheet2 := wb2.Sheets()[0]
+	if got := sheet2.Cell("A3").GetFormula(); got != "SUM(A1:A2)" {
+		t.Errorf("expected formula to round trip, got %s", got)
+	}
+	if got := sheet2.Cell("A3").GetFormattedValue(); got != "3" {
+		t.Errorf("expected cached result to round trip, got %s", got)
+	}
+	if got := sheet2.Cell("B1").GetString(); got != "ab" {
+		t.Errorf("expected cached string result to round trip, got %s", got)
+	}
+}
+
+func TestCellGetValuesFromFile(t *testing.T) {
+	wb, err := spreadsheet.Open("testdata/mixed.xlsx")
+	if err != nil {
+		t.Fatalf("error opening workbook: %s", err)
+	}
+	defer wb.Close()
+	sheet := wb.Sheets()[0]
+
+	td := []struct {
+		ref       string
+		str       string
+		formatted string
+		num       float64
+		numErr    bool
+	}{
+		{"A1", "hello", "hello", 0, true},         // shared string
+		{"A2", "inline", "inline", 0, true},       // inline string
+		{"A3", "3.14159", "3.14", 3.14159, false}, // number with 0.00 format
+		{"A4", "1", "TRUE", 1, false},             // boolean
+		{"A5", "#DIV/0!", "#DIV/0!", 0, true},     // error
+		{"A6", "ab", "ab", 0, true},               // formula with string result
+		{"A7", "3", "3", 3, false},                // formula with number result
+		{"A8", "", "", 0, false},                  // empty
+	}
+	for _, tc := range td {
+		cell := sheet.Cell(tc.ref)
+		if got := cell.GetString(); got != tc.str {
+			t.Errorf("expected string %s in %s, got %s", tc.str, tc.ref, got)
+		}
+		if got := cell.GetFormattedValue(); got != tc.formatted {
+			t.Errorf("expected formatted value %s in %s, got %s", tc.formatted, tc.ref, got)
+		}
+		got, err := cell.GetValueAsNumber()
+		if tc.numErr {
+			if err == nil {
+				t.Errorf("expected an error reading %s as a number", tc.ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expected no error reading %s as a number, got %s", tc.ref, err)
+		} else if got != tc.num {
+			t.Errorf("expected %f in %s, got %f", tc.num, tc.ref, got)
+		}
+	}
+}
+
+func TestCellHyperlinks(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.SetName("My Sheet")
+	sheet.Cell("A1").SetString("external")
+	sheet.Cell("A1").AddHyperlink("https://example.com", "Example")
+	sheet.Cell("A2").SetString("internal")
+	sheet.Cell("A2").SetHyperlinkToCell("My Sheet", "B10")
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/_rels/sheet1.xml.rels" {
+			continue
+		}
+		rc, _ := f.Open()
+		rels, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if !bytes.Contains(rels, []byte(`TargetMode="External" Target="https://example.com"`)) {
+			t.Errorf("expected an external hyperlink relationship, got %s", rels)
+		}
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	hls := wb2.Sheets()[0].X().Hyperlinks
+	if hls == nil || len(hls.Hyperlink) != 2 {
+		t.Fatalf("expected two hyperlinks to round trip")
+	}
+	ext := hls.Hyperlink[0]
+	if ext.RefAttr != "A1" || ext.IdAttr == nil || ext.LocationAttr != nil {
+		t.Errorf("expected an external hyperlink on A1")
+	}
+	if ext.TooltipAttr == nil || *ext.TooltipAttr != "Example" {
+		t.Errorf("expected tooltip to round trip")
+	}
+	in := hls.Hyperlink[1]
+	if in.RefAttr != "A2" || in.IdAttr != nil {
+		t.Errorf("expected an internal hyperlink on A2")
+	}
+	if in.LocationAttr == nil || *in.LocationAttr != "'My Sheet'!B10" {
+		t.Errorf("expected location 'My Sheet'!B10, got %v", in.LocationAttr)
+	}
+}
+
+func TestCellHyperlinksShareRelationship(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	for i := 1; i <= 50; i++ {
+		sheet.Cell(fmt.Sprintf("A%d", i)).AddHyperlink("https://example.com")
+	}
+	sheet.Cell("B1").AddHyperlink("https://example.org")
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/_rels/sheet1.xml.rels" {
+			continue
+		}
+		rc, _ := f.Open()
+		rels, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if n := bytes.Count(rels, []byte(`Target="https://example.com"`)); n != 1 {
+			t.Errorf("expected a single relationship for the repeated URL, got %d", n)
+		}
+		if n := bytes.Count(rels, []byte(`Target="https://example.org"`)); n != 1 {
+			t.Errorf("expected a relationship for the second URL, got %d", n)
+		}
+	}
+
+	hls := sheet.X().Hyperlinks.Hyperlink
+	if len(hls) != 51 {
+		t.Fatalf("expected 51 hyperlinks, got %d", len(hls))
+	}
+	if *hls[0].IdAttr != *hls[49].IdAttr || *hls[0].IdAttr == *hls[50].IdAttr {
+		t.Errorf("expected hyperlinks to the same URL to share a relationship")
+	}
+}
+
+func TestCellKnownSerialDates(t *testing.T) {
+	td := []struct {
+		t        time.Time
+		date1904 bool
+		exp      string
+	}{
+		{time.Date(1900, 1, 1, 0, 0, 0, 0, time.Local), false, "1"},
+		{time.Date(1900, 2, 28, 0, 0, 0, 0, time.Local), false, "59"},
+		{time.Date(1900, 3, 1, 0, 0, 0, 0, time.Local), false, "61"},
+		{time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local), false, "43101"},
+		{time.Date(2018, 1, 1, 12, 0, 0, 0, time.Local), false, "43101.5"},
+		{time.Date(2018, 1, 1, 18, 0, 0, 0, time.Local), false, "43101.75"},
+		{time.Date(1900, 1, 1, 6, 0, 0, 0, time.Local), false, "1.25"},
+		{time.Date(1904, 1, 1, 0, 0, 0, 0, time.Local), true, "0"},
+		{time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local), true, "41639"},
+	}
+	for _, tc := range td {
+		wb := spreadsheet.New()
+		if tc.date1904 {
+			wb.X().WorkbookPr = sml.NewCT_WorkbookPr()
+			wb.X().WorkbookPr.Date1904Attr = unioffice.Bool(true)
+		}
+		cell := wb.AddSheet().Cell("A1")
+		cell.SetTime(tc.t)
+		if got := *cell.X().V; got != tc.exp {
+			t.Errorf("expected serial %s for %s (1904 = %v), got %s", tc.exp, tc.t, tc.date1904, got)
+		}
+		got, err := cell.GetValueAsTime()
+		if err != nil {
+			t.Errorf("error reading %s: %s", tc.t, err)
+		} else if !got.Equal(tc.t) {
+			t.Errorf("expected %s to round trip, got %s", tc.t, got)
+		}
+	}
+
+	wb := spreadsheet.New()
+	cell := wb.AddSheet().Cell("A1")
+	cell.SetTime(time.Date(1899, 12, 31, 0, 0, 0, 0, time.Local))
+	if cell.X().V != nil {
+		t.Errorf("expected dates before 1900 to not be set, got %s", *cell.X().V)
+	}
+	cell.SetNumber(43101)
+	if got, err := cell.GetValueAsTime(); err != nil || !got.Equal(time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("expected number cells to be read as times, got %s (%v)", got, err)
+	}
+	cell.SetTimeWithStyle(time.Now())
+	if cs := wb.StyleSheet.GetCellStyle(*cell.X().SAttr); cs.NumberFormat() != uint32(spreadsheet.StandardFormatDateTime) {
+		t.Errorf("expected date time format, got %d", cs.NumberFormat())
+	}
+}
+
+func TestCellRichText(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	rt := sheet.Cell("A1").SetRichTextString()
+	run := rt.AddRun()
+	run.SetText("Total: ")
+	run.SetBold(true)
+	run = rt.AddRun()
+	run.SetText("$5")
+	run.SetColor(color.Red)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+
+	cell := wb2.Sheets()[0].Cell("A1")
+	if got := cell.GetString(); got != "Total: $5" {
+		t.Errorf("expected Total: $5, got %s", got)
+	}
+	rt, ok := cell.GetRichText()
+	if !ok {
+		t.Fatalf("expected a rich text cell")
+	}
+	runs := rt.Runs()
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+	if runs[0].Text() != "Total: " || !runs[0].IsBold() {
+		t.Errorf("expected bold 'Total: ', got %q bold=%v", runs[0].Text(), runs[0].IsBold())
+	}
+	if runs[1].Text() != "$5" || runs[1].IsBold() {
+		t.Errorf("expected plain '$5', got %q bold=%v", runs[1].Text(), runs[1].IsBold())
+	}
+	if c, ok := runs[1].Color(); !ok || *c.AsRGBString() != *color.Red.AsRGBString() {
+		t.Errorf("expected $5 to be red")
+	}
+
+	sheet.Cell("A2").SetString("plain")
+	if _, ok := sheet.Cell("A2").GetRichText(); !ok {
+		t.Errorf("expected shared strings to be readable as rich text")
+	}
+	sheet.Cell("A3").SetNumber(1)
+	if _, ok := sheet.Cell("A3").GetRichText(); ok {
+		t.Errorf("expected a number cell not to be rich text")
+	}
+}
+
+func TestRowCellIndexConsistency(t *testing.T) {
+	wb := spreadsheet.New()
+	row := wb.AddSheet().AddRow()
+	// add every other column in reverse so cells are inserted before others
+	for i := 98; i >= 0; i -= 2 {
+		row.Cell(reference.IndexToColumn(uint32(i))).SetNumber(float64(i))
+	}
+	for i := 1; i < 100; i += 2 {
+		row.Cell(reference.IndexToColumn(uint32(i))).SetNumber(float64(i))
+	}
+	row.AddCell().SetNumber(100)
+	row.AddNamedCell("DA").SetNumber(104)
+
+	cells := row.X().C
+	if len(cells) != 102 {
+		t.Fatalf("expected 102 cells, got %d", len(cells))
+	}
+	for i := 0; i <= 100; i++ {
+		col := reference.IndexToColumn(uint32(i))
+		if exp := fmt.Sprintf("%s1", col); *cells[i].RAttr != exp {
+			t.Fatalf("expected cell %d to be %s, got %s", i, exp, *cells[i].RAttr)
+		}
+		if v, _ := row.Cell(col).GetValueAsNumber(); v != float64(i) {
+			t.Errorf("expected %s1 to be %d, got %f", col, i, v)
+		}
+	}
+	if v, _ := row.Cell("DA").GetValueAsNumber(); v != 104 {
+		t.Errorf("expected DA1 to be 104, got %f", v)
+	}
+
+	// clearing cells keeps them in place
+	row.Cell("B").Clear()
+	if row.Cell("B").X() != cells[1] || len(row.X().C) != 102 {
+		t.Errorf("expected the cleared cell to be reused")
+	}
+
+	// renaming through the XML is picked up
+	renamed := cells[5]
+	renamed.RAttr = unioffice.String("ZZ1")
+	if row.Cell("F").X() == renamed {
+		t.Errorf("expected a new cell for the renamed reference")
+	}
+	if row.Cell("ZZ").X() != renamed {
+		t.Errorf("expected the renamed cell to be found")
+	}
+	if len(row.X().C) != 103 {
+		t.Errorf("expected 103 cells, got %d", len(row.X().C))
+	}
+}
+
+func TestRowCellIndexManyRows(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	// more wide rows than there are cached cell indexes
+	for r := 1; r <= 10; r++ {
+		row := sheet.Row(uint32(r))
+		for i := 0; i < 20; i++ {
+			row.Cell(reference.IndexToColumn(uint32(i))).SetNumber(float64(r*100 + i))
+		}
+	}
+	for pass := 0; pass < 2; pass++ {
+		for r := 10; r >= 1; r-- {
+			row := sheet.Row(uint32(r))
+			for _, i := range []int{19, 0, 7} {
+				col := reference.IndexToColumn(uint32(i))
+				if v, _ := row.Cell(col).GetValueAsNumber(); v != float64(r*100+i) {
+					t.Errorf("expected %s%d to be %d, got %f", col, r, r*100+i, v)
+				}
+			}
+			if len(row.X().C) != 20 {
+				t.Errorf("expected 20 cells in row %d, got %d", r, len(row.X().C))
+			}
+		}
+	}
+}
+
+func TestCellDate1904(t *testing.T) {
+	for _, tc := range []struct {
+		date1904 bool
+		exp      time.Time
+		expFmt   string
+	}{
+		{false, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC), "2018-01-01"},
+		{true, time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), "2022-01-02"},
+	} {
+		wb := spreadsheet.New()
+		wb.SetDate1904(tc.date1904)
+		cell := wb.AddSheet().Cell("A1")
+		cell.SetNumber(43101)
+		cs := wb.StyleSheet.AddCellStyle()
+		cs.SetNumberFormat("yyyy-mm-dd")
+		cell.SetStyle(cs)
+
+		buf := bytes.Buffer{}
+		if err := wb.Save(&buf); err != nil {
+			t.Fatalf("error saving: %s", err)
+		}
+		wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("error reading: %s", err)
+		}
+		if wb2.Uses1904Dates() != tc.date1904 {
+			t.Errorf("expected 1904 dates = %v", tc.date1904)
+		}
+		cell = wb2.Sheets()[0].Cell("A1")
+		got, err := cell.GetValueAsTime()
+		if err != nil {
+			t.Fatalf("error reading time: %s", err)
+		}
+		if !got.Equal(tc.exp) {
+			t.Errorf("expected %s for 1904 dates = %v, got %s", tc.exp, tc.date1904, got)
+		}
+		if got := cell.GetFormattedValue(); got != tc.expFmt {
+			t.Errorf("expected %s for 1904 dates = %v, got %s", tc.expFmt, tc.date1904, got)
+		}
+
+		// setting a date stores the serial for the workbook's epoch
+		cell.SetDate(tc.exp)
+		if v := *cell.X().V; v != "43101" {
+			t.Errorf("expected serial 43101 for 1904 dates = %v, got %s", tc.date1904, v)
+		}
+	}
+
+	wb := spreadsheet.New()
+	wb.SetDate1904(true)
+	wb.SetDate1904(false)
+	if wb.Uses1904Dates() {
+		t.Errorf("expected 1900 dates")
+	}
+}
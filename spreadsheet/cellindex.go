@@ -0,0 +1,169 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+)
+
+// cellIndexMinCells is the number of cells a row must have before its cells
+// are indexed, searching narrower rows is faster than maintaining a map.
+const cellIndexMinCells = 16
+
+// cellIndexCacheSize is the number of rows whose cell indexes are kept.  Rows
+// are normally filled one at a time, so only the most recently used indexes
+// are kept rather than one for every wide row in the workbook.
+const cellIndexCacheSize = 4
+
+// cellIndex maps the references of the cells in a wide row to the cells.  As
+// with rowIndex, it's only trusted while the number of cells and the last cell
+// are unchanged since it was updated, otherwise it is rebuilt.
+type cellIndex struct {
+	count   int
+	last    *sml.CT_Cell
+	lastRef string
+	maxCol  uint32
+	cells   map[string]*sml.CT_Cell
+}
+
+// cellIndex returns the up to date cell index for the row, or nil if the row
+// is too narrow to be indexed.
+func (r Row) cellIndex() *cellIndex {
+	if ci := r.currentCellIndex(); ci != nil {
+		return ci
+	}
+	if len(r.x.C) < cellIndexMinCells {
+		return nil
+	}
+	ci := &cellIndex{cells: make(map[string]*sml.CT_Cell, len(r.x.C))}
+	for _, c := range r.x.C {
+		if c.RAttr == nil {
+			continue
+		}
+		if cref, err := reference.ParseCellReference(*c.RAttr); err == nil {
+			ci.add(c, cref.ColumnIdx)
+		}
+	}
+	ci.update(r.x.C)
+	r.w.cellIndexes.put(r.x, ci)
+	return ci
+}
+
+// currentCellIndex returns the row's cell index if it has one that is up to
+// date, without building it.
+func (r Row) currentCellIndex() *cellIndex {
+	ci := r.w.cellIndexes.get(r.x)
+	if ci == nil || !ci.isCurrent(r.x.C) {
+		return nil
+	}
+	return ci
+}
+
+// cellIndexCache holds the cell indexes of the most recently used rows, most
+// recent first.
+type cellIndexCache struct {
+	rows    [cellIndexCacheSize]*sml.CT_Row
+	indexes [cellIndexCacheSize]*cellIndex
+}
+
+// get returns the cell index of a row, or nil if it isn't cached.
+func (c *cellIndexCache) get(row *sml.CT_Row) *cellIndex {
+	for i, r := range c.rows {
+		if r == row {
+			ci := c.indexes[i]
+			c.moveToFront(i, row, ci)
+			return ci
+		}
+	}
+	return nil
+}
+
+// put caches the cell index of a row, evicting the least recently used index
+// if the cache is full.
+func (c *cellIndexCache) put(row *sml.CT_Row, ci *cellIndex) {
+	i := len(c.rows) - 1
+	for j, r := range c.rows {
+		if r == row {
+			i = j
+			break
+		}
+	}
+	c.moveToFront(i, row, ci)
+}
+
+// remove drops the cell index of a row.
+func (c *cellIndexCache) remove(row *sml.CT_Row) {
+	for i, r := range c.rows {
+		if r == row {
+			copy(c.rows[i:], c.rows[i+1:])
+			copy(c.indexes[i:], c.indexes[i+1:])
+			c.rows[len(c.rows)-1] = nil
+			c.indexes[len(c.indexes)-1] = nil
+			return
+		}
+	}
+}
+
+// moveToFront shifts the entries before i back by one, overwriting entry i,
+// and stores the row and its index first.
+func (c *cellIndexCache) moveToFront(i int, row *sml.CT_Row, ci *cellIndex) {
+	copy(c.rows[1:i+1], c.rows[:i])
+	copy(c.indexes[1:i+1], c.indexes[:i])
+	c.rows[0] = row
+	c.indexes[0] = ci
+}
+
+func (ci *cellIndex) isCurrent(cells []*sml.CT_Cell) bool {
+	if len(cells) != ci.count {
+		return false
+	}
+	if ci.count == 0 {
+		return true
+	}
+	last := cells[ci.count-1]
+	return last == ci.last && last.RAttr != nil && *last.RAttr == ci.lastRef
+}
+
+// add records a cell in column colIdx that has been added to the row.
+func (ci *cellIndex) add(c *sml.CT_Cell, colIdx uint32) {
+	if colIdx > ci.maxCol {
+		ci.maxCol = colIdx
+	}
+	// keep the first of any cells that reuse a reference
+	if _, ok := ci.cells[*c.RAttr]; !ok {
+		ci.cells[*c.RAttr] = c
+	}
+}
+
+// update records the current cells after cells have been added to the row.
+func (ci *cellIndex) update(cells []*sml.CT_Cell) {
+	ci.count = len(cells)
+	ci.last = nil
+	ci.lastRef = ""
+	if ci.count > 0 {
+		ci.last = cells[ci.count-1]
+		if ci.last.RAttr != nil {
+			ci.lastRef = *ci.last.RAttr
+		}
+	}
+}
+
+// cell returns the cell with a given reference, or nil if there is no such
+// cell.  It returns false if the index is out of date as a cell has been
+// renamed through X().
+func (ci *cellIndex) cell(ref string) (*sml.CT_Cell, bool) {
+	c, ok := ci.cells[ref]
+	if !ok {
+		return nil, true
+	}
+	if c.RAttr == nil || *c.RAttr != ref {
+		return nil, false
+	}
+	return c, true
+}
@@ -0,0 +1,113 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+)
+
+// CellRange is a rectangular region of cells on a sheet.
+type CellRange struct {
+	s                  Sheet
+	fromRow, toRow     uint32
+	fromCol, toCol     uint32
+	createMissingCells bool
+	valid              bool
+}
+
+// Range returns the rectangular region of cells given a range reference of the
+// form 'A1:C10'.  A single cell reference of the form 'A1' is also accepted.
+// Reversed ranges such as 'C10:A1' are normalized.  By default the range
+// creates any cells that don't exist yet when they are iterated over, see
+// SetCreateMissingCells.
+func (s Sheet) Range(ref string) CellRange {
+	from, to, err := reference.ParseRangeReference(ref)
+	if err != nil {
+		from, err = reference.ParseCellReference(ref)
+		to = from
+	}
+	if err != nil {
+		unioffice.Log("error parsing range reference %s: %s", ref, err)
+		return CellRange{s: s}
+	}
+	r := CellRange{s: s, fromRow: from.RowIdx, toRow: to.RowIdx,
+		fromCol: from.ColumnIdx, toCol: to.ColumnIdx, createMissingCells: true, valid: true}
+	if r.fromRow > r.toRow {
+		r.fromRow, r.toRow = r.toRow, r.fromRow
+	}
+	if r.fromCol > r.toCol {
+		r.fromCol, r.toCol = r.toCol, r.fromCol
+	}
+	return r
+}
+
+// ForEachCellInRange calls fn for each existing cell within the range
+// reference, e.g. 'A1:C10', in row major order.  Cells that don't exist are
+// skipped and aren't created.
+func (s Sheet) ForEachCellInRange(ref string, fn func(c Cell)) {
+	r := s.Range(ref)
+	r.SetCreateMissingCells(false)
+	for _, c := range r.Cells() {
+		fn(c)
+	}
+}
+
+// SetCreateMissingCells controls whether Cells creates the cells within the
+// range that don't exist yet, or only returns the existing cells.
+func (r *CellRange) SetCreateMissingCells(b bool) {
+	r.createMissingCells = b
+}
+
+// Reference returns the normalized range reference, e.g. 'A1:C10'.
+func (r CellRange) Reference() string {
+	if !r.valid {
+		return ""
+	}
+	return fmt.Sprintf("%s%d:%s%d", reference.IndexToColumn(r.fromCol), r.fromRow,
+		reference.IndexToColumn(r.toCol), r.toRow)
+}
+
+// Cells returns the cells within the range in row major order.
+func (r CellRange) Cells() []Cell {
+	ret := []Cell{}
+	if !r.valid {
+		return ret
+	}
+	if r.createMissingCells {
+		for row := r.fromRow; row <= r.toRow; row++ {
+			sr := r.s.Row(row)
+			for col := r.fromCol; col <= r.toCol; col++ {
+				ret = append(ret, sr.Cell(reference.IndexToColumn(col)))
+			}
+		}
+		return ret
+	}
+
+	// only visit the cells that already exist
+	for _, row := range r.s.x.SheetData.Row {
+		if row.RAttr == nil || *row.RAttr < r.fromRow || *row.RAttr > r.toRow {
+			continue
+		}
+		for _, c := range row.C {
+			if c.RAttr == nil {
+				continue
+			}
+			cref, err := reference.ParseCellReference(*c.RAttr)
+			if err != nil {
+				continue
+			}
+			if cref.ColumnIdx >= r.fromCol && cref.ColumnIdx <= r.toCol {
+				ret = append(ret, Cell{r.s.w, r.s.x, row, c})
+			}
+		}
+	}
+	return ret
+}
@@ -9,17 +9,27 @@ package spreadsheet
 
 import (
 	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/color"
+	"github.com/unidoc/unioffice/common"
 	"github.com/unidoc/unioffice/schema/soo/sml"
 )
 
 // CellStyle is a formatting style for a cell.  CellStyles are spreadsheet global
-// and can be applied to cells across sheets.
+// and can be applied to cells across sheets.  The setters return the style so
+// that calls can be chained:
+//
+//	cs := ss.AddCellStyle().SetFillColor(color.Yellow).SetWrapped(true)
 type CellStyle struct {
 	wb  *Workbook
 	xf  *sml.CT_Xf
 	xfs *sml.CT_CellXfs
 }
 
+// X returns the inner wrapped XML type.
+func (cs CellStyle) X() *sml.CT_Xf {
+	return cs.xf
+}
+
 // IsEmpty checks if the cell style contains nothing.
 func (cs CellStyle) IsEmpty() bool {
 	return cs.wb == nil || cs.xf == nil || cs.xfs == nil || cs.xfs.Xf == nil
@@ -48,16 +58,24 @@ func (cs CellStyle) ClearNumberFormat() {
 
 // SetNumberFormatStandard sets the format based off of the ECMA 376 standard formats.  These
 // formats are standardized and don't need to be defined in the styles.
-func (cs CellStyle) SetNumberFormatStandard(s StandardFormat) {
+func (cs CellStyle) SetNumberFormatStandard(s StandardFormat) CellStyle {
 	cs.xf.NumFmtIdAttr = unioffice.Uint32(uint32(s))
 	cs.xf.ApplyNumberFormatAttr = unioffice.Bool(true)
+	return cs
 }
 
-func (cs CellStyle) SetNumberFormat(s string) {
-	nf := cs.wb.StyleSheet.AddNumberFormat()
-	nf.SetFormat(s)
+// SetNumberFormat sets the number format to a custom format code, e.g.
+// `"$"#,##0.00`.  Codes matching a built-in format use the standard format ID
+// and styles using the same custom format code share a single number format
+// in the stylesheet.
+func (cs CellStyle) SetNumberFormat(s string) CellStyle {
+	if sf, ok := standardFormatForCode(s); ok {
+		return cs.SetNumberFormatStandard(sf)
+	}
+	nf := cs.wb.StyleSheet.GetOrCreateNumberFormat(s)
 	cs.xf.ApplyNumberFormatAttr = unioffice.Bool(true)
 	cs.xf.NumFmtIdAttr = unioffice.Uint32(nf.ID())
+	return cs
 }
 
 // Wrapped returns true if the cell will wrap text.
@@ -72,7 +90,7 @@ func (cs CellStyle) Wrapped() bool {
 }
 
 // SetWrapped configures the cell to wrap text.
-func (cs CellStyle) SetWrapped(b bool) {
+func (cs CellStyle) SetWrapped(b bool) CellStyle {
 	if cs.xf.Alignment == nil {
 		cs.xf.Alignment = sml.NewCT_CellAlignment()
 	}
@@ -82,36 +100,71 @@ func (cs CellStyle) SetWrapped(b bool) {
 		cs.xf.Alignment.WrapTextAttr = unioffice.Bool(true)
 		cs.xf.ApplyAlignmentAttr = unioffice.Bool(true)
 	}
+	return cs
 }
 
 // SetHorizontalAlignment sets the horizontal alignment of a cell style.
-func (cs CellStyle) SetHorizontalAlignment(a sml.ST_HorizontalAlignment) {
+func (cs CellStyle) SetHorizontalAlignment(a sml.ST_HorizontalAlignment) CellStyle {
 	if cs.xf.Alignment == nil {
 		cs.xf.Alignment = sml.NewCT_CellAlignment()
 	}
 	cs.xf.Alignment.HorizontalAttr = a
 	cs.xf.ApplyAlignmentAttr = unioffice.Bool(true)
+	return cs
 }
 
-// SetRotation configures the cell to be rotated.
-func (cs CellStyle) SetRotation(deg uint8) {
+// SetRotation configures the cell to be rotated by a number of degrees
+// counterclockwise, from -90 to 90.  Negative degrees rotate the text
+// clockwise, and are converted to the file format's encoding of 91 to 180,
+// which may also be used directly.  255 stacks the text vertically.
+func (cs CellStyle) SetRotation(deg int) CellStyle {
+	switch {
+	case deg == 255, deg >= 0 && deg <= 180:
+	case deg < 0 && deg >= -90:
+		deg = 90 - deg
+	default:
+		unioffice.Log("invalid text rotation %d, must be from -90 to 180 or 255", deg)
+		return cs
+	}
 	if cs.xf.Alignment == nil {
 		cs.xf.Alignment = sml.NewCT_CellAlignment()
 	}
 	cs.xf.ApplyAlignmentAttr = unioffice.Bool(true)
-	cs.xf.Alignment.TextRotationAttr = unioffice.Uint8(deg)
+	cs.xf.Alignment.TextRotationAttr = unioffice.Uint8(uint8(deg))
+	return cs
+}
+
+// SetIndent sets the indentation level of the cell's text, where each level
+// is the width of three characters.  Indentation requires left, right or
+// distributed horizontal alignment, so the cell is left aligned if its
+// horizontal alignment is unset.  A level of zero removes the indentation.
+func (cs CellStyle) SetIndent(level uint8) CellStyle {
+	if cs.xf.Alignment == nil {
+		cs.xf.Alignment = sml.NewCT_CellAlignment()
+	}
+	cs.xf.ApplyAlignmentAttr = unioffice.Bool(true)
+	if level == 0 {
+		cs.xf.Alignment.IndentAttr = nil
+		return cs
+	}
+	if cs.xf.Alignment.HorizontalAttr == sml.ST_HorizontalAlignmentUnset {
+		cs.xf.Alignment.HorizontalAttr = sml.ST_HorizontalAlignmentLeft
+	}
+	cs.xf.Alignment.IndentAttr = unioffice.Uint32(uint32(level))
+	return cs
 }
 
 // SetVerticalAlignment sets the vertical alignment of a cell style.
-func (cs CellStyle) SetVerticalAlignment(a sml.ST_VerticalAlignment) {
+func (cs CellStyle) SetVerticalAlignment(a sml.ST_VerticalAlignment) CellStyle {
 	if cs.xf.Alignment == nil {
 		cs.xf.Alignment = sml.NewCT_CellAlignment()
 	}
 	cs.xf.ApplyAlignmentAttr = unioffice.Bool(true)
 	cs.xf.Alignment.VerticalAttr = a
+	return cs
 }
 
-func (cs CellStyle) SetShrinkToFit(b bool) {
+func (cs CellStyle) SetShrinkToFit(b bool) CellStyle {
 	if cs.xf.Alignment == nil {
 		cs.xf.Alignment = sml.NewCT_CellAlignment()
 	}
@@ -121,6 +174,38 @@ func (cs CellStyle) SetShrinkToFit(b bool) {
 	} else {
 		cs.xf.Alignment.ShrinkToFitAttr = unioffice.Bool(b)
 	}
+	return cs
+}
+
+// Locked returns true if cells using the style are locked when the sheet is
+// protected.  Cells are locked by default.
+func (cs CellStyle) Locked() bool {
+	if cs.xf.Protection == nil || cs.xf.Protection.LockedAttr == nil {
+		return true
+	}
+	return *cs.xf.Protection.LockedAttr
+}
+
+// SetLocked controls whether cells using the style are locked when the sheet
+// is protected.  Unlocked cells remain editable on a protected sheet.
+func (cs CellStyle) SetLocked(b bool) CellStyle {
+	if cs.xf.Protection == nil {
+		cs.xf.Protection = sml.NewCT_CellProtection()
+	}
+	cs.xf.ApplyProtectionAttr = unioffice.Bool(true)
+	cs.xf.Protection.LockedAttr = unioffice.Bool(b)
+	return cs
+}
+
+// SetHidden controls whether formulas in cells using the style are hidden when
+// the sheet is protected.
+func (cs CellStyle) SetHidden(b bool) CellStyle {
+	if cs.xf.Protection == nil {
+		cs.xf.Protection = sml.NewCT_CellProtection()
+	}
+	cs.xf.ApplyProtectionAttr = unioffice.Bool(true)
+	cs.xf.Protection.HiddenAttr = unioffice.Bool(b)
+	return cs
 }
 
 // ClearFont clears any font configuration from the cell style.
@@ -132,17 +217,19 @@ func (cs CellStyle) ClearFont() {
 // SetFont applies a font to a cell style.  The font is referenced by its
 // index so modifying the font afterward will affect all styles that reference
 // it.
-func (cs CellStyle) SetFont(f Font) {
+func (cs CellStyle) SetFont(f Font) CellStyle {
 	cs.xf.FontIdAttr = unioffice.Uint32(f.Index())
 	cs.xf.ApplyFontAttr = unioffice.Bool(true)
+	return cs
 }
 
 // SetBorder applies a border to a cell style.  The border is referenced by its
 // index so modifying the border afterward will affect all styles that reference
 // it.
-func (cs CellStyle) SetBorder(b Border) {
+func (cs CellStyle) SetBorder(b Border) CellStyle {
 	cs.xf.BorderIdAttr = unioffice.Uint32(b.Index())
 	cs.xf.ApplyBorderAttr = unioffice.Bool(true)
+	return cs
 }
 
 // ClearBorder clears any border configuration from the cell style.
@@ -153,9 +240,36 @@ func (cs CellStyle) ClearBorder() {
 
 // SetFill applies a fill to a cell style.  The fill is referenced by its index
 // so modifying the fill afterward will affect all styles that reference it.
-func (cs CellStyle) SetFill(f Fill) {
+func (cs CellStyle) SetFill(f Fill) CellStyle {
 	cs.xf.FillIdAttr = unioffice.Uint32(f.Index())
 	cs.xf.ApplyFillAttr = unioffice.Bool(true)
+	return cs
+}
+
+// SetFillColor applies a solid fill of the given color to a cell style.  Styles
+// using the same fill color share a single fill in the stylesheet.
+func (cs CellStyle) SetFillColor(c color.Color) CellStyle {
+	return cs.SetFill(cs.wb.StyleSheet.GetOrCreateSolidFill(c))
+}
+
+// SetFillThemeColor applies a solid fill using a theme color to a cell style.
+// The tint ranges from -1.0 (darkest) to 1.0 (lightest), e.g. 0.4 for the
+// "lighter 40%" variant of the color.
+func (cs CellStyle) SetFillThemeColor(slot common.ThemeColorSlot, tint float64) CellStyle {
+	return cs.SetFill(cs.wb.StyleSheet.GetOrCreateSolidThemeFill(slot, tint))
+}
+
+// SetBorderAll applies a border with the same style and color on every side
+// of the cell.  Styles using the same border share a single border in the
+// stylesheet.
+func (cs CellStyle) SetBorderAll(style sml.ST_BorderStyle, c color.Color) CellStyle {
+	return cs.SetBorder(cs.wb.StyleSheet.GetOrCreateBorder(style, c))
+}
+
+// SetFontAttributes applies a font with the given attributes to a cell style.
+// Styles using the same font attributes share a single font in the stylesheet.
+func (cs CellStyle) SetFontAttributes(name string, size float64, bold bool, c color.Color) CellStyle {
+	return cs.SetFont(cs.wb.StyleSheet.GetOrCreateFont(name, size, bold, c))
 }
 
 // ClearFill clears any fill configuration from the cell style.
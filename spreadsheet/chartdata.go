@@ -0,0 +1,75 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"strings"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/chart"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+)
+
+// AddChart adds a chart to the sheet's drawing, creating the drawing if the
+// sheet doesn't have one yet.  Series added to the chart from cell ranges
+// cache the current contents of the cells.
+func (s Sheet) AddChart() (chart.Chart, Anchor) {
+	c, anc := s.drawing().AddChart(AnchorTypeTwoCell)
+	return chart.MakeChartWithRangeData(c.X(), sheetRangeData{s}), anc
+}
+
+// sheetRangeData provides cell values to charts.  References without a sheet
+// name refer to the sheet the chart was added to.
+type sheetRangeData struct {
+	s Sheet
+}
+
+func (d sheetRangeData) CellValues(ref string) []string {
+	from, to, err := reference.ParseRangeReference(ref)
+	if err != nil {
+		// single cell
+		from, err = reference.ParseCellReference(ref)
+		if err != nil {
+			unioffice.Log("invalid chart data reference %s: %s", ref, err)
+			return nil
+		}
+		to = from
+	}
+
+	sheet := d.s
+	if name := strings.Trim(from.SheetName, "'"); name != "" {
+		var ok bool
+		if sheet, ok = d.s.w.SheetByName(name); !ok {
+			unioffice.Log("chart data references unknown sheet %s", name)
+			return nil
+		}
+	}
+	if from.RowIdx > to.RowIdx {
+		from.RowIdx, to.RowIdx = to.RowIdx, from.RowIdx
+	}
+	if from.ColumnIdx > to.ColumnIdx {
+		from.ColumnIdx, to.ColumnIdx = to.ColumnIdx, from.ColumnIdx
+	}
+
+	cols := to.ColumnIdx - from.ColumnIdx + 1
+	ret := make([]string, (to.RowIdx-from.RowIdx+1)*cols)
+	for _, r := range sheet.Rows() {
+		rn := r.RowNumber()
+		if rn < from.RowIdx || rn > to.RowIdx {
+			continue
+		}
+		for _, c := range r.Cells() {
+			cref, err := reference.ParseCellReference(c.Reference())
+			if err != nil || cref.ColumnIdx < from.ColumnIdx || cref.ColumnIdx > to.ColumnIdx {
+				continue
+			}
+			ret[(rn-from.RowIdx)*cols+cref.ColumnIdx-from.ColumnIdx] = c.GetString()
+		}
+	}
+	return ret
+}
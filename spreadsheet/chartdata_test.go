@@ -0,0 +1,84 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/spreadsheet"
+)
+
+func TestSheetAddChart(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Cell("A1").SetString("Month")
+	sheet.Cell("B1").SetString("Sales")
+	for i, m := range []string{"Jan", "Feb", "Mar", "Apr"} {
+		row := sheet.Row(uint32(i + 2))
+		row.Cell("A").SetString(m)
+		row.Cell("B").SetNumber(float64(10 * (i + 1)))
+	}
+	// leave a blank value that shouldn't be cached
+	sheet.Cell("B4").Clear()
+
+	c, anc := sheet.AddChart()
+	anc.SetWidthCells(6)
+	bc := c.AddBarChart()
+	ser := bc.AddSeriesFromRange("'Sheet 1'!$A$2:$A$5", "'Sheet 1'!$B$2:$B$5")
+
+	cat := ser.X().Cat.Choice.StrRef
+	if cat == nil || cat.F != "'Sheet 1'!$A$2:$A$5" {
+		t.Fatalf("expected a category string reference")
+	}
+	if cat.StrCache == nil || cat.StrCache.PtCount.ValAttr != 4 || len(cat.StrCache.Pt) != 4 ||
+		cat.StrCache.Pt[0].V != "Jan" || cat.StrCache.Pt[3].V != "Apr" {
+		t.Errorf("unexpected category cache")
+	}
+	val := ser.X().Val.Choice.NumRef
+	if val == nil || val.F != "'Sheet 1'!$B$2:$B$5" {
+		t.Fatalf("expected a value number reference")
+	}
+	if val.NumCache == nil || val.NumCache.PtCount.ValAttr != 4 || len(val.NumCache.Pt) != 3 {
+		t.Fatalf("expected 3 of 4 cached values")
+	}
+	if val.NumCache.Pt[2].IdxAttr != 3 || val.NumCache.Pt[2].V != "40" {
+		t.Errorf("unexpected value cache point %d = %s", val.NumCache.Pt[2].IdxAttr, val.NumCache.Pt[2].V)
+	}
+
+	if err := wb.Validate(); err != nil {
+		t.Errorf("created an invalid workbook: %s", err)
+	}
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	if !strings.Contains(files["xl/worksheets/_rels/sheet1.xml.rels"], "drawing1.xml") {
+		t.Errorf("expected the sheet to reference the drawing")
+	}
+	if !strings.Contains(files["xl/drawings/_rels/drawing1.xml.rels"], "chart1.xml") {
+		t.Errorf("expected the drawing to reference the chart")
+	}
+	if !strings.Contains(files["xl/charts/chart1.xml"], "<c:f>&#39;Sheet 1&#39;!$B$2:$B$5</c:f>") {
+		t.Errorf("expected the chart to reference the sheet values")
+	}
+}
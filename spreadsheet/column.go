@@ -24,12 +24,15 @@ func (c Column) X() *sml.CT_Col {
 	return c.x
 }
 
-// SetWidth controls the width of a column.
+// SetWidth controls the width of a column. The width is stored in the file as a
+// number of characters.
 func (c Column) SetWidth(w measurement.Distance) {
 	c.x.WidthAttr = unioffice.Float64(float64(w / measurement.Character))
+	c.x.CustomWidthAttr = unioffice.Bool(true)
 }
 
-// SetStyle sets the cell style for an entire column.
+// SetStyle sets the cell style for an entire column.  Row and cell styles
+// take precedence over the column style.
 func (c Column) SetStyle(cs CellStyle) {
 	c.x.StyleAttr = unioffice.Uint32(cs.Index())
 }
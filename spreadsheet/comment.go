@@ -7,7 +7,12 @@
 
 package spreadsheet
 
-import "github.com/unidoc/unioffice/schema/soo/sml"
+import (
+	"strings"
+
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+)
 
 // Comment is a single comment within a sheet.
 type Comment struct {
@@ -48,3 +53,51 @@ func (c Comment) Author() string {
 func (c Comment) SetAuthor(author string) {
 	c.x.AuthorIdAttr = Comments{c.w, c.cmts}.getOrCreateAuthor(author)
 }
+
+// Text returns the plain text of the comment, including the author prefix
+// that Excel and Comments.AddCommentWithStyle add to the comment body.
+func (c Comment) Text() string {
+	if c.x.Text == nil {
+		return ""
+	}
+	if c.x.Text.T != nil {
+		return *c.x.Text.T
+	}
+	sb := strings.Builder{}
+	for _, r := range c.x.Text.R {
+		sb.WriteString(r.T)
+	}
+	return sb.String()
+}
+
+// Remove removes the comment from the sheet along with the shape used to
+// display it.
+func (c Comment) Remove() {
+	cmts := Comments{c.w, c.cmts}
+	for i, cmt := range c.cmts.CommentList.Comment {
+		if cmt == c.x {
+			copy(c.cmts.CommentList.Comment[i:], c.cmts.CommentList.Comment[i+1:])
+			c.cmts.CommentList.Comment = c.cmts.CommentList.Comment[:len(c.cmts.CommentList.Comment)-1]
+			break
+		}
+	}
+
+	vd := cmts.vmlDrawing()
+	cref, err := reference.ParseCellReference(c.x.RefAttr)
+	if vd == nil || err != nil {
+		return
+	}
+	for i, shp := range vd.Shape {
+		for _, se := range shp.EG_ShapeElements {
+			cd := se.ClientData
+			if cd == nil || cd.Row == nil || cd.Column == nil {
+				continue
+			}
+			if *cd.Row == int64(cref.RowIdx-1) && *cd.Column == int64(cref.ColumnIdx) {
+				copy(vd.Shape[i:], vd.Shape[i+1:])
+				vd.Shape = vd.Shape[:len(vd.Shape)-1]
+				return
+			}
+		}
+	}
+}
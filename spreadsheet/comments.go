@@ -84,6 +84,18 @@ func (c Comments) AddCommentWithStyle(cellRef string, author string, comment str
 	if err != nil {
 		return err
 	}
-	c.w.vmlDrawings[0].Shape = append(c.w.vmlDrawings[0].Shape, vmldrawing.NewCommentShape(int64(cref.ColumnIdx), int64(cref.RowIdx-1)))
+	if vd := c.vmlDrawing(); vd != nil {
+		vd.Shape = append(vd.Shape, vmldrawing.NewCommentShape(int64(cref.ColumnIdx), int64(cref.RowIdx-1)))
+	}
+	return nil
+}
+
+// vmlDrawing returns the VML drawing that displays the comments of the sheet.
+func (c Comments) vmlDrawing() *vmldrawing.Container {
+	for i, cmts := range c.w.comments {
+		if cmts == c.x {
+			return c.w.vmlDrawings[i]
+		}
+	}
 	return nil
 }
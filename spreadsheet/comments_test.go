@@ -8,6 +8,9 @@
 package spreadsheet_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/unidoc/unioffice/spreadsheet"
@@ -77,3 +80,70 @@ func TestCommentsReusesAuthorIDs(t *testing.T) {
 	}
 
 }
+
+func TestCommentsRoundTrip(t *testing.T) {
+	wb := spreadsheet.New()
+	first := wb.AddSheet()
+	second := wb.AddSheet()
+	if err := first.AddComment("B2", "foo", "first comment"); err != nil {
+		t.Fatalf("error adding comment: %s", err)
+	}
+	if err := second.AddComment("C3", "bar", "second comment"); err != nil {
+		t.Fatalf("error adding comment: %s", err)
+	}
+	if err := second.AddComment("D4", "bar", "removed comment"); err != nil {
+		t.Fatalf("error adding comment: %s", err)
+	}
+	second.Comments().Comments()[1].Remove()
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	files := map[string]bool{}
+	for _, f := range zr.File {
+		files[f.Name] = true
+	}
+	for _, fn := range []string{"xl/comments1.xml", "xl/comments2.xml",
+		"xl/drawings/vmlDrawing1.vml", "xl/drawings/vmlDrawing2.vml"} {
+		if !files[fn] {
+			t.Errorf("expected %s to be written", fn)
+		}
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	td := []struct {
+		ref    string
+		author string
+		text   string
+	}{
+		{"B2", "foo", "first comment"},
+		{"C3", "bar", "second comment"},
+	}
+	for i, sheet := range wb2.Sheets() {
+		if sheet.X().LegacyDrawing == nil {
+			t.Errorf("expected sheet %d to have a legacy drawing", i)
+		}
+		cmts := sheet.Comments().Comments()
+		if len(cmts) != 1 {
+			t.Fatalf("expected one comment on sheet %d, got %d", i, len(cmts))
+		}
+		cmt := cmts[0]
+		if cmt.CellReference() != td[i].ref {
+			t.Errorf("expected comment on %s, got %s", td[i].ref, cmt.CellReference())
+		}
+		if cmt.Author() != td[i].author {
+			t.Errorf("expected author %s, got %s", td[i].author, cmt.Author())
+		}
+		if !strings.Contains(cmt.Text(), td[i].text) {
+			t.Errorf("expected text to contain %s, got %s", td[i].text, cmt.Text())
+		}
+	}
+}
@@ -23,6 +23,12 @@ const (
 	SortOrderDescending
 )
 
+// SortKey is a column to sort by and the order to sort it in.
+type SortKey struct {
+	Column string
+	Order  SortOrder
+}
+
 // Comparer is used to compare rows based off a column and cells based off of
 // their value.
 type Comparer struct {
@@ -12,7 +12,8 @@ import "github.com/unidoc/unioffice/schema/soo/sml"
 // ConditionalFormatting controls the formatting styles and rules for a range of
 // cells with the same conditional formatting.
 type ConditionalFormatting struct {
-	x *sml.CT_ConditionalFormatting
+	x  *sml.CT_ConditionalFormatting
+	ws *sml.Worksheet
 }
 
 // X returns the inner wrapped XML type.
@@ -22,10 +23,30 @@ func (c ConditionalFormatting) X() *sml.CT_ConditionalFormatting {
 
 // AddRule adds and returns a new rule that can be configured.
 func (c ConditionalFormatting) AddRule() ConditionalFormattingRule {
+	priority := c.nextPriority()
 	rule := sml.NewCT_CfRule()
 	c.x.CfRule = append(c.x.CfRule, rule)
 	r := ConditionalFormattingRule{rule}
 	r.InitializeDefaults()
-	r.SetPriority(int32(len(c.x.CfRule) + 1))
+	r.SetPriority(priority)
 	return r
 }
+
+// nextPriority returns a priority one greater than that of any existing rule
+// on the sheet.  Priorities must be unique across all of the conditional
+// formatting on a sheet, not just within a single conditional formatting.
+func (c ConditionalFormatting) nextPriority() int32 {
+	cfmts := []*sml.CT_ConditionalFormatting{c.x}
+	if c.ws != nil {
+		cfmts = c.ws.ConditionalFormatting
+	}
+	max := int32(0)
+	for _, cf := range cfmts {
+		for _, rule := range cf.CfRule {
+			if rule.PriorityAttr > max {
+				max = rule.PriorityAttr
+			}
+		}
+	}
+	return max + 1
+}
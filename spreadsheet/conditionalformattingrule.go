@@ -33,6 +33,12 @@ func (c ConditionalFormattingRule) SetConditionValue(v string) {
 	c.x.Formula = []string{v}
 }
 
+// SetConditionValues sets the formulas that the cell value is compared
+// against.  Operators such as between and notBetween require two values.
+func (c ConditionalFormattingRule) SetConditionValues(v ...string) {
+	c.x.Formula = append([]string{}, v...)
+}
+
 // Priority returns the rule priority
 func (c ConditionalFormattingRule) Priority() int32 {
 	return c.x.PriorityAttr
@@ -72,6 +78,7 @@ func (c ConditionalFormattingRule) clear() {
 	c.x.OperatorAttr = sml.ST_ConditionalFormattingOperatorUnset
 	c.x.ColorScale = nil
 	c.x.IconSet = nil
+	c.x.DataBar = nil
 	c.x.Formula = nil
 }
 
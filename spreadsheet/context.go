@@ -55,13 +55,15 @@ func (e *evalContext) Cell(ref string, ev formula.Evaluator) formula.Result {
 
 	// if we have a formula, evaluate it
 	if c.HasFormula() {
-		if _, ok := e.evaluating[ref]; ok {
-			// recursively evaluating, so bail out
-			return formula.MakeErrorResult("recursion detected during evaluation of " + ref)
+		key := fmt.Sprintf("%s!%s%d", e.s.Name(), cr.Column, cr.RowIdx)
+		if _, ok := e.evaluating[key]; ok {
+			// circular reference, so bail out with a #REF! error as the cell
+			// can't be evaluated
+			return formula.MakeErrorResultType(formula.ErrorTypeRef, "circular reference detected during evaluation of "+ref)
 		}
-		e.evaluating[ref] = struct{}{}
+		e.evaluating[key] = struct{}{}
 		res := ev.Eval(e, c.GetFormula())
-		delete(e.evaluating, ref)
+		delete(e.evaluating, key)
 		ev.SetCache(fullRef, res)
 		return res
 	}
@@ -98,7 +100,11 @@ func (e *evalContext) Cell(ref string, ev formula.Evaluator) formula.Result {
 func (e *evalContext) Sheet(name string) formula.Context {
 	for _, sheet := range e.s.w.Sheets() {
 		if sheet.Name() == name {
-			return sheet.FormulaContext()
+			// share the cells currently being evaluated so that circular
+			// references spanning several sheets are detected as well
+			ctx := newEvalContext(&sheet)
+			ctx.evaluating = e.evaluating
+			return ctx
 		}
 	}
 	return formula.InvalidReferenceContext
@@ -161,7 +167,7 @@ func (e *evalContext) SetLocked(cellRef string, locked bool) {
 // GetWidth returns a worksheet's column width.
 func (e *evalContext) GetWidth(colIdx int) float64 {
 	colIdx++
-	for _, c := range e.s.X().Cols[0].Col {
+	for _, c := range e.s.x.Cols[0].Col {
 		if int(c.MinAttr) <= colIdx && colIdx <= int(c.MaxAttr) {
 			return float64(int(*c.WidthAttr))
 		}
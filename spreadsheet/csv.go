@@ -0,0 +1,227 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+)
+
+// CSVExportOptions controls how a sheet is written as CSV.
+type CSVExportOptions struct {
+	// Delimiter is the field delimiter, defaulting to a comma.  Use '\t' to
+	// write TSV.
+	Delimiter rune
+	// Formatted writes cell values with their number formats applied, rather
+	// than the raw stored values.
+	Formatted bool
+	// IncludeHidden includes hidden rows and columns in the output.
+	IncludeHidden bool
+}
+
+// CSVImportOptions controls how CSV data is imported into a sheet.
+type CSVImportOptions struct {
+	// Delimiter is the field delimiter, defaulting to a comma.
+	Delimiter rune
+	// Header indicates the first record is a header row, which is always
+	// stored as text.
+	Header bool
+	// InferTypes stores fields that look like numbers or dates as numeric
+	// cells rather than text.
+	InferTypes bool
+	// DateLayouts are the time.Parse layouts used to recognize dates when
+	// inferring types, defaulting to ISO 8601 dates and times.
+	DateLayouts []string
+}
+
+var defaultCSVDateLayouts = []string{"2006-01-02", "2006-01-02 15:04:05", time.RFC3339}
+
+// ImportCSV reads CSV records from r and writes them to the sheet starting at
+// cell A1, one record per row.  Empty fields don't create cells.
+func (s Sheet) ImportCSV(r io.Reader, opts CSVImportOptions) error {
+	cr := csv.NewReader(r)
+	if opts.Delimiter != 0 {
+		cr.Comma = opts.Delimiter
+	}
+	// allow ragged rows
+	cr.FieldsPerRecord = -1
+	layouts := opts.DateLayouts
+	if len(layouts) == 0 {
+		layouts = defaultCSVDateLayouts
+	}
+
+	for rowNum := uint32(1); ; rowNum++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		row := s.Row(rowNum)
+		for i, field := range rec {
+			if field == "" {
+				continue
+			}
+			cell := row.Cell(reference.IndexToColumn(uint32(i)))
+			if !opts.InferTypes || (opts.Header && rowNum == 1) {
+				cell.SetString(field)
+				continue
+			}
+			setInferredValue(cell, field, layouts)
+		}
+	}
+}
+
+// setInferredValue sets the cell to a number or date if the field can be
+// parsed as one, otherwise to a string.
+func setInferredValue(c Cell, field string, layouts []string) {
+	if isCSVNumber(field) {
+		if v, err := strconv.ParseFloat(field, 64); err == nil && !math.IsInf(v, 0) && !math.IsNaN(v) {
+			c.SetNumber(v)
+			return
+		}
+	}
+	for _, l := range layouts {
+		t, err := time.Parse(l, field)
+		if err != nil {
+			continue
+		}
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+			c.SetDateWithStyle(t)
+		} else {
+			c.SetTimeWithStyle(t)
+		}
+		return
+	}
+	c.SetString(field)
+}
+
+// isCSVNumber returns true if s is a plain decimal number.  Numbers with
+// leading zeros such as zip codes are kept as text so they aren't changed.
+func isCSVNumber(s string) bool {
+	digits := s
+	if digits[0] == '-' || digits[0] == '+' {
+		digits = digits[1:]
+	}
+	if len(digits) == 0 || digits[0] < '0' || digits[0] > '9' {
+		return false
+	}
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return false
+	}
+	return true
+}
+
+// ExportCSV writes the sheet to w as CSV, one record per row from the first
+// row and column through the last used cell.  Missing rows and cells are
+// written as empty records and fields.  Merged cells write their value in the
+// top left cell of the merged range and leave the remaining cells empty.
+func (s Sheet) ExportCSV(w io.Writer, opts CSVExportOptions) error {
+	cw := csv.NewWriter(w)
+	if opts.Delimiter != 0 {
+		cw.Comma = opts.Delimiter
+	}
+
+	hiddenCols := map[uint32]struct{}{}
+	if !opts.IncludeHidden {
+		for _, cols := range s.x.Cols {
+			for _, col := range cols.Col {
+				if col.HiddenAttr == nil || !*col.HiddenAttr {
+					continue
+				}
+				for i := col.MinAttr; i <= col.MaxAttr && i > 0; i++ {
+					hiddenCols[i-1] = struct{}{}
+				}
+			}
+		}
+	}
+
+	// cells covered by a merged range other than its top left cell
+	covered := map[reference.CellReference]struct{}{}
+	for _, mc := range s.MergedCells() {
+		from, to, err := reference.ParseRangeReference(mc.Reference())
+		if err != nil {
+			continue
+		}
+		for r := from.RowIdx; r <= to.RowIdx; r++ {
+			for c := from.ColumnIdx; c <= to.ColumnIdx; c++ {
+				if r != from.RowIdx || c != from.ColumnIdx {
+					covered[reference.CellReference{RowIdx: r, ColumnIdx: c}] = struct{}{}
+				}
+			}
+		}
+	}
+
+	rows := map[uint32][]string{}
+	hiddenRows := map[uint32]struct{}{}
+	var maxRow, numCols uint32
+	for _, r := range s.Rows() {
+		rn := r.RowNumber()
+		if !opts.IncludeHidden && r.IsHidden() {
+			hiddenRows[rn] = struct{}{}
+			continue
+		}
+		rec := []string{}
+		for _, c := range r.Cells() {
+			cref, err := reference.ParseCellReference(c.Reference())
+			if err != nil {
+				continue
+			}
+			if _, ok := covered[reference.CellReference{RowIdx: rn, ColumnIdx: cref.ColumnIdx}]; ok {
+				continue
+			}
+			v := c.GetString()
+			if opts.Formatted {
+				v = c.GetFormattedValue()
+			}
+			if v == "" {
+				continue
+			}
+			for uint32(len(rec)) <= cref.ColumnIdx {
+				rec = append(rec, "")
+			}
+			rec[cref.ColumnIdx] = v
+		}
+		rows[rn] = rec
+		if rn > maxRow {
+			maxRow = rn
+		}
+		if uint32(len(rec)) > numCols {
+			numCols = uint32(len(rec))
+		}
+	}
+
+	for rn := uint32(1); rn <= maxRow; rn++ {
+		if _, ok := hiddenRows[rn]; ok {
+			continue
+		}
+		rec := rows[rn]
+		out := make([]string, 0, numCols)
+		for i := uint32(0); i < numCols; i++ {
+			if _, ok := hiddenCols[i]; ok {
+				continue
+			}
+			v := ""
+			if i < uint32(len(rec)) {
+				v = rec[i]
+			}
+			out = append(out, v)
+		}
+		if err := cw.Write(out); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,117 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet"
+)
+
+func TestImportCSV(t *testing.T) {
+	data := `Name,Amount,Date,Zip
+"Smith, John",12.5,2019-03-04,00501
+"Multi
+Line",-3,not a date,
+`
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	if err := sheet.ImportCSV(strings.NewReader(data), spreadsheet.CSVImportOptions{
+		Header: true, InferTypes: true}); err != nil {
+		t.Fatalf("error importing CSV: %s", err)
+	}
+
+	if got := sheet.Cell("B1").GetString(); got != "Amount" || sheet.Cell("B1").X().TAttr != sml.ST_CellTypeS {
+		t.Errorf("expected the header to be stored as text, got %s", got)
+	}
+	if got := sheet.Cell("A2").GetString(); got != "Smith, John" {
+		t.Errorf("expected quoted field to be unquoted, got %q", got)
+	}
+	if got := sheet.Cell("A3").GetString(); got != "Multi\nLine" {
+		t.Errorf("expected embedded newline to be kept, got %q", got)
+	}
+	if !sheet.Cell("B2").IsNumber() || !sheet.Cell("B3").IsNumber() {
+		t.Errorf("expected amounts to be numeric cells")
+	}
+	if v, _ := sheet.Cell("B3").GetValueAsNumber(); v != -3 {
+		t.Errorf("expected -3, got %v", v)
+	}
+	dt, err := sheet.Cell("C2").GetValueAsTime()
+	if err != nil || !dt.Equal(time.Date(2019, 3, 4, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("expected a date cell, got %v (%v)", dt, err)
+	}
+	if sheet.Cell("C3").IsNumber() {
+		t.Errorf("expected text that isn't a date to stay text")
+	}
+	if got := sheet.Cell("D2").GetString(); got != "00501" || sheet.Cell("D2").IsNumber() {
+		t.Errorf("expected leading zeros to be kept as text, got %s", got)
+	}
+	if len(sheet.Row(3).Cells()) != 3 {
+		t.Errorf("expected empty fields not to create cells")
+	}
+	if err := wb.Validate(); err != nil {
+		t.Errorf("created an invalid workbook: %s", err)
+	}
+
+	other := wb.AddSheet()
+	if err := other.ImportCSV(strings.NewReader("1;2\n"), spreadsheet.CSVImportOptions{
+		Delimiter: ';'}); err != nil {
+		t.Fatalf("error importing CSV: %s", err)
+	}
+	if other.Cell("B1").GetString() != "2" || other.Cell("B1").IsNumber() {
+		t.Errorf("expected text cells without type inference")
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	data := "Name,Amount\n\"Smith, John\",12.5\n\"Multi\nLine\",-3\n,\nLast,7\n"
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	if err := sheet.ImportCSV(strings.NewReader(data), spreadsheet.CSVImportOptions{
+		Header: true, InferTypes: true}); err != nil {
+		t.Fatalf("error importing CSV: %s", err)
+	}
+	buf := bytes.Buffer{}
+	if err := sheet.ExportCSV(&buf, spreadsheet.CSVExportOptions{}); err != nil {
+		t.Fatalf("error exporting CSV: %s", err)
+	}
+	if buf.String() != data {
+		t.Errorf("expected round trip to give\n%q, got\n%q", data, buf.String())
+	}
+
+	sheet = wb.AddSheet()
+	sheet.Cell("A1").SetString("merged")
+	sheet.Cell("B1").SetString("stale")
+	sheet.AddMergedCells("A1", "B2")
+	sheet.Cell("D1").SetNumberWithStyle(0.25, spreadsheet.StandardFormatPercent)
+	sheet.Cell("C2").SetString("hidden column")
+	sheet.Cell("A3").SetString("hidden")
+	sheet.Row(3).SetHidden(true)
+	sheet.Cell("D4").SetString("last")
+	sheet.ColumnByName("C").SetHidden(true)
+
+	buf.Reset()
+	if err := sheet.ExportCSV(&buf, spreadsheet.CSVExportOptions{Delimiter: '\t', Formatted: true}); err != nil {
+		t.Fatalf("error exporting CSV: %s", err)
+	}
+	if exp := "merged\t\t25%\n\t\t\n\t\tlast\n"; buf.String() != exp {
+		t.Errorf("expected %q, got %q", exp, buf.String())
+	}
+
+	buf.Reset()
+	if err := sheet.ExportCSV(&buf, spreadsheet.CSVExportOptions{IncludeHidden: true}); err != nil {
+		t.Fatalf("error exporting CSV: %s", err)
+	}
+	if exp := "merged,,,0.25\n,,hidden column,\nhidden,,,\n,,,last\n"; buf.String() != exp {
+		t.Errorf("expected %q, got %q", exp, buf.String())
+	}
+}
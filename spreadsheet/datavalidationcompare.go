@@ -20,7 +20,11 @@ const (
 	DVCompareTypeDecimal     = DVCompareType(sml.ST_DataValidationTypeDecimal)
 	DVCompareTypeDate        = DVCompareType(sml.ST_DataValidationTypeDate)
 	DVCompareTypeTime        = DVCompareType(sml.ST_DataValidationTypeTime)
-	DVompareTypeTextLength   = DVCompareType(sml.ST_DataValidationTypeTextLength)
+	DVCompareTypeTextLength  = DVCompareType(sml.ST_DataValidationTypeTextLength)
+
+	// DVompareTypeTextLength is a misspelling of DVCompareTypeTextLength that is
+	// kept for compatibility.
+	DVompareTypeTextLength = DVCompareTypeTextLength
 )
 
 // DVCompareOp is a comparison operator for a data validation rule.
@@ -53,6 +57,9 @@ type DataValidationCompare struct {
 func (d DataValidationCompare) SetValue(v string) {
 	d.x.Formula1 = &v
 }
+
+// SetValue2 sets the second value to be used in comparisons like 'between' that
+// require two values.
 func (d DataValidationCompare) SetValue2(v string) {
 	d.x.Formula2 = &v
 }
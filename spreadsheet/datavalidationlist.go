@@ -28,8 +28,19 @@ func (d DataValidationList) SetRange(cellRange string) {
 	d.x.Formula2 = unioffice.String("0")
 }
 
-// SetValues sets the possible values. This is incompatible with SetRange.
+// maxListLength is the maximum length of an explicit list of values that Excel
+// will accept.
+const maxListLength = 255
+
+// SetValues sets the possible values. This is incompatible with SetRange. The
+// values are stored as a single quoted, comma separated formula (e.g.
+// "a,b,c"). Excel refuses to open files where the joined values exceed 255
+// characters, in which case SetRange should be used instead.
 func (d DataValidationList) SetValues(values []string) {
-	d.x.Formula1 = unioffice.String("\"" + strings.Join(values, ",") + "\"")
+	list := strings.Join(values, ",")
+	if len(list) > maxListLength {
+		unioffice.Log("data validation list of %d characters exceeds the maximum of %d", len(list), maxListLength)
+	}
+	d.x.Formula1 = unioffice.String("\"" + strings.Replace(list, "\"", "\"\"", -1) + "\"")
 	d.x.Formula2 = unioffice.String("0")
 }
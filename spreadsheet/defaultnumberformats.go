@@ -116,3 +116,18 @@ func CreateDefaultNumberFormat(id StandardFormat) NumberFormat {
 	}
 	return nf
 }
+
+// standardFormatForCode returns the built-in format that uses the given
+// format code, if there is one.
+func standardFormatForCode(code string) (StandardFormat, bool) {
+	if code == "General" {
+		return StandardFormatGeneral, true
+	}
+	for id := StandardFormat(1); id <= StandardFormat49; id++ {
+		// undefined IDs are reported as General
+		if nf := CreateDefaultNumberFormat(id); nf.GetFormat() == code {
+			return id, true
+		}
+	}
+	return 0, false
+}
@@ -7,12 +7,21 @@
 
 package spreadsheet
 
-import "github.com/unidoc/unioffice/schema/soo/sml"
-import "github.com/unidoc/unioffice"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+)
 
 // DefinedName is a named range, formula, etc.
 type DefinedName struct {
-	x *sml.CT_DefinedName
+	x  *sml.CT_DefinedName
+	wb *Workbook
 }
 
 // X returns the inner wrapped XML type.
@@ -40,7 +49,62 @@ func (d DefinedName) SetHidden(b bool) {
 	d.x.HiddenAttr = unioffice.Bool(b)
 }
 
-// SetHidden marks the defined name as hidden.
+// SetLocalSheetID scopes the defined name to the sheet with the given zero
+// based index.
 func (d DefinedName) SetLocalSheetID(id uint32) {
 	d.x.LocalSheetIdAttr = unioffice.Uint32(id)
 }
+
+// LocalSheetID returns the zero based index of the sheet the defined name is
+// scoped to.  If the name has workbook scope, false is returned.
+func (d DefinedName) LocalSheetID() (uint32, bool) {
+	if d.x.LocalSheetIdAttr == nil {
+		return 0, false
+	}
+	return *d.x.LocalSheetIdAttr, true
+}
+
+// Remove removes the defined name from the workbook.
+func (d DefinedName) Remove() error {
+	if d.wb == nil {
+		return errors.New("defined name is not part of a workbook")
+	}
+	return d.wb.RemoveDefinedName(d)
+}
+
+// maxDefinedNameLength is the maximum length of a defined name.
+const maxDefinedNameLength = 255
+
+var (
+	a1NameRe   = regexp.MustCompile(`^([A-Za-z]{1,3})([0-9]+)$`)
+	r1c1NameRe = regexp.MustCompile(`^([Rr][0-9]*)?([Cc][0-9]*)?$`)
+)
+
+// ValidateDefinedName returns an error if the name is not a valid defined
+// name. Names must begin with a letter, underscore or backslash, can only
+// contain letters, numbers, periods, underscores and backslashes, and can't
+// be the same as a cell reference.
+func ValidateDefinedName(name string) error {
+	if name == "" {
+		return errors.New("defined name must not be empty")
+	}
+	if len(name) > maxDefinedNameLength {
+		return fmt.Errorf("defined name must not exceed %d characters", maxDefinedNameLength)
+	}
+	for i, c := range name {
+		switch {
+		case unicode.IsLetter(c), c == '_', c == '\\':
+		case i > 0 && (unicode.IsDigit(c) || c == '.'):
+		default:
+			return fmt.Errorf("invalid character %q in defined name %s", c, name)
+		}
+	}
+	if m := a1NameRe.FindStringSubmatch(name); m != nil &&
+		reference.ColumnToIndex(m[1]) < 16384 {
+		return fmt.Errorf("defined name %s conflicts with a cell reference", name)
+	}
+	if r1c1NameRe.MatchString(name) {
+		return fmt.Errorf("defined name %s conflicts with an R1C1 reference", name)
+	}
+	return nil
+}
@@ -36,3 +36,12 @@ func (d DifferentialStyle) Fill() Fill {
 	}
 	return Fill{d.x.Fill, nil}
 }
+
+// Font returns the font used by the differential style, creating it if
+// necessary.
+func (d DifferentialStyle) Font() Font {
+	if d.x.Font == nil {
+		d.x.Font = sml.NewCT_Font()
+	}
+	return Font{d.x.Font, nil}
+}
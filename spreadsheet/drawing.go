@@ -84,7 +84,7 @@ func (d Drawing) AddChart(at AnchorType) (chart.Chart, Anchor) {
 
 	// required by Mac Excel
 	gf.NvGraphicFramePr = sd.NewCT_GraphicalObjectFrameNonVisual()
-	gf.NvGraphicFramePr.CNvPr.IdAttr = 2
+	gf.NvGraphicFramePr.CNvPr.IdAttr = uint32(len(d.x.EG_Anchor) + 1)
 	gf.NvGraphicFramePr.CNvPr.NameAttr = "Chart"
 
 	gf.Graphic = dml.NewGraphic()
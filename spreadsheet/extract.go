@@ -0,0 +1,164 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/schema/soo/dml"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/vmldrawing"
+)
+
+// ExtractSheet returns a new workbook containing a copy of the sheet.  Only
+// the parts of the workbook that the sheet uses are copied, i.e. its drawings,
+// charts, images, tables and comments, the shared strings and cell styles of
+// its cells and the defined names that are scoped to it or that don't refer to
+// other sheets.  Pivot tables aren't copied, and formulas that refer to other
+// sheets will no longer be valid.
+func (wb *Workbook) ExtractSheet(s Sheet) (*Workbook, error) {
+	idx := -1
+	for i, ws := range wb.xws {
+		if ws == s.x {
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return nil, ErrorNotFound
+	}
+	if _, ok := wb.streams[s.x]; ok {
+		return nil, errors.New("can't extract a sheet with streamed rows")
+	}
+
+	dt := unioffice.DocTypeSpreadsheet
+	nw := New()
+	if err := copyXML(wb.CoreProperties.X(), nw.CoreProperties.X()); err != nil {
+		return nil, err
+	}
+	if wb.x.WorkbookPr != nil {
+		nw.x.WorkbookPr = sml.NewCT_WorkbookPr()
+		if err := copyXML(wb.x.WorkbookPr, nw.x.WorkbookPr); err != nil {
+			return nil, err
+		}
+	}
+	// theme colors and fonts may be used by the styles
+	if len(wb.themes) > 0 {
+		thm := dml.NewTheme()
+		if err := copyXML(wb.themes[0], thm); err != nil {
+			return nil, err
+		}
+		nw.themes = append(nw.themes, thm)
+		nw.wbRels.AddAutoRelationship(dt, unioffice.OfficeDocumentType, 1, unioffice.ThemeType)
+		nw.ContentTypes.AddOverride(unioffice.AbsoluteFilename(dt, unioffice.ThemeType, 1), unioffice.ThemeContentType)
+	}
+
+	// the whole style sheet and string table are copied so the cells can keep
+	// their indices, and then pruned
+	nw.StyleSheet = StyleSheet{nw, sml.NewStyleSheet()}
+	if err := copyXML(wb.StyleSheet.X(), nw.StyleSheet.X()); err != nil {
+		return nil, err
+	}
+	nw.SharedStrings = NewSharedStrings()
+	if err := copyXML(wb.SharedStrings.X(), nw.SharedStrings.X()); err != nil {
+		return nil, err
+	}
+
+	sheet := nw.AddSheet()
+	sheet.cts.NameAttr = s.cts.NameAttr
+	sheet.x = sml.NewWorksheet()
+	if err := copyXML(s.x, sheet.x); err != nil {
+		return nil, err
+	}
+	nw.xws[0] = sheet.x
+	// the only sheet of a workbook must be selected
+	if sheet.x.SheetViews != nil {
+		for _, sv := range sheet.x.SheetViews.SheetView {
+			sv.TabSelectedAttr = unioffice.Bool(true)
+		}
+	}
+
+	rels := nw.xwsRels[0]
+	for _, r := range wb.xwsRels[idx].X().Relationship {
+		rel := *r
+		var err error
+		switch r.TypeAttr {
+		case unioffice.DrawingType:
+			err = wb.copyDrawing(nw, r, &rel)
+		case unioffice.TableType:
+			rel.TargetAttr, err = wb.copyTable(nw, r.TargetAttr, s.Name())
+		case unioffice.CommentsType:
+			if wb.comments[idx] != nil {
+				nw.comments[0] = sml.NewComments()
+				err = copyXML(wb.comments[idx], nw.comments[0])
+				nw.ContentTypes.AddOverride(unioffice.AbsoluteFilename(dt, unioffice.CommentsType, 1), unioffice.CommentsContentType)
+			}
+			rel.TargetAttr = unioffice.RelativeFilename(dt, unioffice.WorksheetType, unioffice.CommentsType, 1)
+		case unioffice.VMLDrawingType:
+			if wb.vmlDrawings[idx] != nil {
+				nw.vmlDrawings[0] = vmldrawing.NewContainer()
+				err = copyXML(wb.vmlDrawings[idx], nw.vmlDrawings[0])
+			}
+			rel.TargetAttr = unioffice.RelativeFilename(dt, unioffice.WorksheetType, unioffice.VMLDrawingType, 1)
+		case unioffice.HyperLinkType:
+		default:
+			unioffice.Log("relationship %s of type %s isn't copied", r.IdAttr, r.TypeAttr)
+			if sheet.x.PageSetup != nil && sheet.x.PageSetup.IdAttr != nil && *sheet.x.PageSetup.IdAttr == r.IdAttr {
+				sheet.x.PageSetup.IdAttr = nil
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error copying %s: %s", r.TargetAttr, err)
+		}
+		rels.X().Relationship = append(rels.X().Relationship, &rel)
+	}
+
+	others := []string{}
+	for i, cts := range wb.x.Sheets.Sheet {
+		if i != idx {
+			others = append(others, cts.NameAttr)
+		}
+	}
+	for _, dn := range wb.DefinedNames() {
+		if id, ok := dn.LocalSheetID(); ok {
+			if int(id) != idx {
+				continue
+			}
+		} else if referencesSheets(dn.Content(), others) {
+			continue
+		}
+		cdn := sml.NewCT_DefinedName()
+		if err := copyXML(dn.X(), cdn); err != nil {
+			return nil, err
+		}
+		if cdn.LocalSheetIdAttr != nil {
+			cdn.LocalSheetIdAttr = unioffice.Uint32(0)
+		}
+		if nw.x.DefinedNames == nil {
+			nw.x.DefinedNames = sml.NewCT_DefinedNames()
+		}
+		nw.x.DefinedNames.DefinedName = append(nw.x.DefinedNames.DefinedName, cdn)
+	}
+
+	nw.pruneSharedStrings()
+	nw.pruneStyles()
+	return nw, nil
+}
+
+// referencesSheets returns true if the formula refers to any of the named
+// sheets.
+func referencesSheets(content string, names []string) bool {
+	for _, name := range names {
+		if renameSheetReferences(content, name, "") != content {
+			return true
+		}
+	}
+	return false
+}
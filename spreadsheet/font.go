@@ -9,6 +9,7 @@ package spreadsheet
 
 import (
 	"github.com/unidoc/unioffice/color"
+	"github.com/unidoc/unioffice/common"
 	"github.com/unidoc/unioffice/schema/soo/sml"
 )
 
@@ -24,6 +25,11 @@ func (f Font) X() *sml.CT_Font {
 }
 
 func (f Font) Index() uint32 {
+	// fonts within differential formats aren't held in the stylesheet font
+	// list
+	if f.styles == nil || f.styles.Fonts == nil {
+		return 0
+	}
 	for i, sf := range f.styles.Fonts.Font {
 		if f.font == sf {
 			return uint32(i)
@@ -60,3 +66,9 @@ func (f Font) SetColor(c color.Color) {
 	clr.RgbAttr = c.AsRGBString()
 	f.font.Color = []*sml.CT_Color{clr}
 }
+
+// SetThemeColor sets the font color to a theme color, lightened or darkened by
+// tint which ranges from -1.0 to 1.0.
+func (f Font) SetThemeColor(slot common.ThemeColorSlot, tint float64) {
+	f.font.Color = []*sml.CT_Color{newThemeColor(slot, tint)}
+}
@@ -38,6 +38,9 @@ type Format struct {
 
 	denom       int64
 	denomDigits int
+
+	locale   Locale
+	date1904 bool
 }
 
 // FmtType is the type of a format token.
@@ -129,10 +132,37 @@ func (f *Format) AddToken(t FmtType, l []byte) {
 // string is empty, then General number formatting is used which attempts to mimic
 // Excel's general formatting.
 func Number(v float64, f string) string {
+	return numberWith(v, f, Locale{}, false)
+}
+
+// NumberLocalized formats a number with a format string in the same way as
+// Number, but using the decimal and thousands separators of locale l.
+func NumberLocalized(v float64, f string, l Locale) string {
+	return numberWith(v, f, l, false)
+}
+
+// NumberDate1904 formats a number with a format string in the same way as
+// Number, but with dates and times relative to 1 Jan 1904 as used by workbooks
+// with the 1904 date system.
+func NumberDate1904(v float64, f string) string {
+	return numberWith(v, f, Locale{}, true)
+}
+
+// NumberDate1904Localized formats a number in the same way as NumberDate1904,
+// but using the decimal and thousands separators of locale l.
+func NumberDate1904Localized(v float64, f string, l Locale) string {
+	return numberWith(v, f, l, true)
+}
+
+func numberWith(v float64, f string, l Locale, date1904 bool) string {
 	if f == "" || f == "General" || f == "@" {
-		return NumberGeneric(v)
+		return strings.Replace(NumberGeneric(v), ".", l.decimal(), 1)
 	}
 	fmts := Parse(f)
+	for i := range fmts {
+		fmts[i].locale = l
+		fmts[i].date1904 = date1904
+	}
 	if len(fmts) == 1 {
 		return number(v, fmts[0], false)
 	} else if len(fmts) > 1 && v < 0 {
@@ -304,6 +334,9 @@ func formatWholeNumber(pre, vOrig float64, f Format) []byte {
 		return nil
 	}
 	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if f.date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
 	t := epoch.Add(time.Duration(vOrig * float64(24*time.Hour)))
 	t = asLocal(t)
 
@@ -388,7 +421,7 @@ lfor:
 		for i := 0; i < len(buf); i++ {
 			idx := (len(buf) - i - nonTerm)
 			if idx%3 == 0 && idx != 0 && i != 0 {
-				b.WriteByte(',')
+				b.WriteString(f.locale.group())
 			}
 			b.WriteByte(buf[i])
 
@@ -411,7 +444,7 @@ func formatFractional(post, vOrig float64, f Format) []byte {
 	}
 
 	op := make([]byte, 0, len(raw))
-	op = append(op, '.')
+	op = append(op, f.locale.decimal()...)
 	consumed := 0
 lforPost:
 	for i := 0; i < len(f.Fractional); i++ {
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/unidoc/unioffice/spreadsheet/format"
+	"golang.org/x/text/language"
 )
 
 func TestCellFormattingNumber(t *testing.T) {
@@ -180,3 +181,66 @@ func TestCellFormattingValue(t *testing.T) {
 		}
 	}
 }
+
+func TestNumberLocalized(t *testing.T) {
+	de, ok := format.LookupLocale(language.German)
+	if !ok {
+		t.Fatalf("expected to find the German locale")
+	}
+	if de.LCID != 0x407 {
+		t.Errorf("expected the German locale to be de-DE, got %s", de.Tag)
+	}
+	if at, _ := format.LookupLocale(language.MustParse("de-AT")); at.LCID != 0xC07 {
+		t.Errorf("expected the Austrian locale, got %s", at.Tag)
+	}
+	if _, ok := format.LookupLocale(language.Korean); ok {
+		t.Errorf("expected no locale for a language without separators defined")
+	}
+	fr, _ := format.LookupLocale(language.French)
+	td := []struct {
+		Inp float64
+		Fmt string
+		Loc format.Locale
+		Exp string
+	}{
+		{1234567.891, `#,##0.00 "€"`, de, "1.234.567,89 €"},
+		{1234567.891, `#,##0.00`, fr, "1 234 567,89"},
+		{0.5, "General", de, "0,5"},
+		{1234.5, `#,##0.00`, format.Locale{}, "1,234.50"},
+	}
+	for _, tc := range td {
+		if got := format.NumberLocalized(tc.Inp, tc.Fmt, tc.Loc); got != tc.Exp {
+			t.Errorf("expected %s formatted for %q = %q, got %q", tc.Fmt, tc.Loc.Tag, tc.Exp, got)
+		}
+	}
+
+	// the locale of the format code is only used when asked for
+	l, _ := format.CodeLocale(`#,##0.00 [$€-407]`)
+	if got := format.NumberLocalized(1234567.891, `#,##0.00 [$€-407]`, l); got != "1.234.567,89 €" {
+		t.Errorf("expected 1.234.567,89 €, got %s", got)
+	}
+	if got := format.Number(1234567.891, `#,##0.00 [$€-407]`); got != "1,234,567.89 €" {
+		t.Errorf("expected US separators, got %s", got)
+	}
+	if got := format.Number(1234.5, `[$$-409]#,##0.00`); got != "$1,234.50" {
+		t.Errorf("expected $1,234.50, got %s", got)
+	}
+	if got := format.WithLocale(`#,##0.00 "€"`, de); got != `[$-407]#,##0.00 "€"` {
+		t.Errorf("expected a locale prefix, got %s", got)
+	}
+	if got := format.WithLocale(`#,##0.00 [$€-409]`, de); got != `#,##0.00 [$€-407]` {
+		t.Errorf("expected the locale ID to be replaced, got %s", got)
+	}
+}
+
+func TestNumberDate1904(t *testing.T) {
+	if got := format.Number(43101.5, "yyyy-mm-dd hh:mm"); got != "2018-01-01 12:00" {
+		t.Errorf("expected 2018-01-01 12:00, got %s", got)
+	}
+	if got := format.NumberDate1904(43101.5, "yyyy-mm-dd hh:mm"); got != "2022-01-02 12:00" {
+		t.Errorf("expected 2022-01-02 12:00, got %s", got)
+	}
+	if got := format.NumberDate1904(1234.5, "#,##0.00"); got != "1,234.50" {
+		t.Errorf("expected 1,234.50, got %s", got)
+	}
+}
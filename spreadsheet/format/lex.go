@@ -16,7 +16,7 @@ func (l *Lexer) nextFmt() {
 
 func Parse(s string) []Format {
 	l := Lexer{}
-	l.Lex(strings.NewReader(s))
+	l.Lex(strings.NewReader(stripLocaleIDs(s)))
 	l.formats = append(l.formats, l.fmt)
 	return l.formats
 }
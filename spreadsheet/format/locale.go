@@ -0,0 +1,143 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package format
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Locale describes the separators used when displaying numbers in a language
+// and region.
+type Locale struct {
+	Tag     language.Tag // language and region, e.g. language.German
+	LCID    uint32       // Windows locale ID used in format codes, e.g. 0x407
+	Decimal string       // decimal separator
+	Group   string       // thousands separator
+}
+
+var locales = []Locale{
+	{language.MustParse("en-US"), 0x409, ".", ","},
+	{language.MustParse("en-GB"), 0x809, ".", ","},
+	{language.MustParse("de-DE"), 0x407, ",", "."},
+	{language.MustParse("de-AT"), 0xC07, ",", "."},
+	{language.MustParse("de-CH"), 0x807, ".", "’"},
+	{language.MustParse("fr-FR"), 0x40C, ",", " "},
+	{language.MustParse("es-ES"), 0xC0A, ",", "."},
+	{language.MustParse("it-IT"), 0x410, ",", "."},
+	{language.MustParse("nl-NL"), 0x413, ",", "."},
+	{language.MustParse("pt-BR"), 0x416, ",", "."},
+	{language.MustParse("pt-PT"), 0x816, ",", " "},
+	{language.MustParse("pl-PL"), 0x415, ",", " "},
+	{language.MustParse("sv-SE"), 0x41D, ",", " "},
+	{language.MustParse("ja-JP"), 0x411, ".", ","},
+	{language.MustParse("zh-CN"), 0x804, ".", ","},
+}
+
+var localeMatcher = newLocaleMatcher()
+
+func newLocaleMatcher() language.Matcher {
+	tags := make([]language.Tag, len(locales))
+	for i, l := range locales {
+		tags[i] = l.Tag
+	}
+	return language.NewMatcher(tags)
+}
+
+// localeIDRe matches a locale and currency prefix such as [$-407] or
+// [$€-407], capturing the currency symbol and the hex locale ID.
+var localeIDRe = regexp.MustCompile(`\[\$([^\]-]*)(?:-([0-9A-Fa-f]+))?\]`)
+
+// LookupLocale returns the locale that best matches a language tag, e.g. the
+// German locale for language.German.  Tags for a language without a known
+// locale aren't matched.
+func LookupLocale(tag language.Tag) (Locale, bool) {
+	_, idx, conf := localeMatcher.Match(tag)
+	if conf == language.No {
+		return Locale{}, false
+	}
+	base, _ := tag.Base()
+	if lbase, _ := locales[idx].Tag.Base(); lbase != base {
+		return Locale{}, false
+	}
+	return locales[idx], true
+}
+
+// CodeLocale returns the locale given by the locale ID in a format code, e.g.
+// the German locale for "[$-407]#,##0.00".
+func CodeLocale(f string) (Locale, bool) {
+	for _, m := range localeIDRe.FindAllStringSubmatch(f, -1) {
+		if m[2] == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(m[2], 16, 32)
+		if err != nil {
+			continue
+		}
+		// the upper bytes select the calendar and number system
+		id &= 0xFFFF
+		for _, l := range locales {
+			if l.LCID == uint32(id) {
+				return l, true
+			}
+		}
+	}
+	return Locale{}, false
+}
+
+// WithLocale returns the format code with its locale ID set to l, adding a
+// prefix such as [$-407] if it doesn't already have one.
+func WithLocale(f string, l Locale) string {
+	id := fmt.Sprintf("%X", l.LCID)
+	replaced := false
+	f = localeIDRe.ReplaceAllStringFunc(f, func(s string) string {
+		m := localeIDRe.FindStringSubmatch(s)
+		if m[2] == "" {
+			return s
+		}
+		replaced = true
+		return "[$" + m[1] + "-" + id + "]"
+	})
+	if replaced {
+		return f
+	}
+	return "[$-" + id + "]" + f
+}
+
+// stripLocaleIDs replaces locale and currency prefixes in a format code with
+// their currency symbol as a literal.
+func stripLocaleIDs(f string) string {
+	if !strings.Contains(f, "[$") {
+		return f
+	}
+	return localeIDRe.ReplaceAllStringFunc(f, func(s string) string {
+		sym := localeIDRe.FindStringSubmatch(s)[1]
+		if sym == "" {
+			return ""
+		}
+		return `"` + strings.Replace(sym, `"`, `""`, -1) + `"`
+	})
+}
+
+func (l Locale) decimal() string {
+	if l.Decimal == "" {
+		return "."
+	}
+	return l.Decimal
+}
+
+func (l Locale) group() string {
+	if l.Group == "" {
+		return ","
+	}
+	return l.Group
+}
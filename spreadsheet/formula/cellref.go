@@ -61,6 +61,40 @@ func updateRefStr(refStr string, q *update.UpdateQuery) string {
 		} else {
 			return ref.Update(update.UpdateActionRemoveColumn).String()
 		}
+	} else if q.UpdateType == update.UpdateActionInsertRow {
+		if ref.RowIdx >= q.RowIdx {
+			ref.RowIdx++
+			return ref.String()
+		}
+	} else if q.UpdateType == update.UpdateActionRemoveRow {
+		if ref.RowIdx == q.RowIdx {
+			return "#REF!"
+		} else if ref.RowIdx > q.RowIdx {
+			ref.RowIdx--
+			return ref.String()
+		}
 	}
 	return refStr
 }
+
+// updateRangeRefs updates the ends of a range.  When a row is removed, a range
+// shrinks rather than losing an end that was in the removed row, and becomes
+// invalid only if every row it contained was removed.
+func updateRangeRefs(from, to Expression, q *update.UpdateQuery) (Expression, Expression, bool) {
+	fc, fok := from.(CellRef)
+	tc, tok := to.(CellRef)
+	if q.UpdateType != update.UpdateActionRemoveRow || !fok || !tok {
+		return from.Update(q), to.Update(q), true
+	}
+	fr, ferr := reference.ParseCellReference(fc.s)
+	tr, terr := reference.ParseCellReference(tc.s)
+	if ferr != nil || terr != nil {
+		return from.Update(q), to.Update(q), true
+	}
+	rowFrom, rowTo, ok := updateRowsUp(int(fr.RowIdx), int(tr.RowIdx), q.RowIdx)
+	if !ok {
+		return from, to, false
+	}
+	fr.RowIdx, tr.RowIdx = uint32(rowFrom), uint32(rowTo)
+	return CellRef{fr.String()}, CellRef{tr.String()}, true
+}
@@ -19,13 +19,17 @@ type defEval struct {
 func (d *defEval) Eval(ctx Context, formula string) Result {
 	expr := ParseString(formula)
 	if expr != nil {
-		d.checkLastEvalIsRef(ctx, expr)
-		result := expr.Eval(ctx, d)
-		return result
+		return d.evalExpression(ctx, expr)
 	}
 	return MakeErrorResult(fmt.Sprintf("unable to parse formula %s", formula))
 }
 
+// evalExpression evaluates an already parsed formula.
+func (d *defEval) evalExpression(ctx Context, expr Expression) Result {
+	d.checkLastEvalIsRef(ctx, expr)
+	return expr.Eval(ctx, d)
+}
+
 // LastEvalIsRef returns if last evaluation with the evaluator was a reference.
 func (d *defEval) LastEvalIsRef() bool {
 	return d.lastEvalIsRef
@@ -107,6 +107,11 @@ func testSheet(fn string, t *testing.T) {
 					// so evaluating the formula in the context of the sheet,
 					// should return the same results that Excel computed
 					result := cellFormula.Eval(sheet.FormulaContext(), formula.NewEvaluator())
+					// LibreOffice, which created the reference sheet, caches
+					// #VALUE! for circular references that evaluate to #REF!
+					if strings.HasPrefix(result.ErrorMessage, "circular reference") && cachedValue == "#VALUE!" {
+						cachedValue = "#REF!"
+					}
 					if got := result.Value(); !cmpValue(got, cachedValue) {
 						t.Errorf("expected '%s', got '%s' for %s cell %s (%s) %s", cachedValue, got, sheet.Name(), cell.Reference(), cell.GetFormula(), result.ErrorMessage)
 					} else {
@@ -178,3 +183,84 @@ func TestArrayFormula(t *testing.T) {
 		t.Errorf("expected 8 in F2, got %s", got)
 	}
 }
+
+func TestEvaluate(t *testing.T) {
+	ss := spreadsheet.New()
+	sheet := ss.AddSheet()
+	for i := 1; i <= 4; i++ {
+		sheet.Cell(fmt.Sprintf("A%d", i)).SetNumber(float64(i))
+	}
+	sheet.Cell("B1").SetString("text")
+	ctx := sheet.FormulaContext()
+
+	td := []struct {
+		Inp string
+		Exp string
+	}{
+		{"=SUM(A1:A4)", "10 ResultTypeNumber"},
+		{"AVERAGE(A1:A4)", "2.5 ResultTypeNumber"},
+		{"MIN(A1:A4)", "1 ResultTypeNumber"},
+		{"MAX($A$1:$A$4)", "4 ResultTypeNumber"},
+		{"COUNT(A1:B4)", "4 ResultTypeNumber"},
+		{"IF(A2>1,B1,\"no\")", "text ResultTypeString"},
+		{"$A$2*(A3+1)", "8 ResultTypeNumber"},
+	}
+	for _, tc := range td {
+		result, err := formula.Evaluate(ctx, tc.Inp)
+		if err != nil {
+			t.Errorf("error evaluating %s: %s", tc.Inp, err)
+			continue
+		}
+		got := fmt.Sprintf("%s %s", result.Value(), result.Type)
+		if got != tc.Exp {
+			t.Errorf("expected %s = %s, got %s", tc.Inp, tc.Exp, got)
+		}
+	}
+
+	if _, err := formula.Evaluate(ctx, "SUM(A1:"); err == nil {
+		t.Errorf("expected an error evaluating an unparseable formula")
+	}
+}
+
+func TestEvaluateCircularReference(t *testing.T) {
+	ss := spreadsheet.New()
+	sheet := ss.AddSheet()
+	other := ss.AddSheet()
+	other.SetName("Other")
+	sheet.Cell("A1").SetFormulaRaw("A2+1")
+	sheet.Cell("A2").SetFormulaRaw("$A$1+1")
+	sheet.Cell("B1").SetFormulaRaw("Other!A1")
+	other.Cell("A1").SetFormulaRaw("'Sheet 1'!B1")
+
+	for _, inp := range []string{"A1", "B1"} {
+		result, err := formula.Evaluate(sheet.FormulaContext(), inp)
+		if err != nil {
+			t.Fatalf("error evaluating %s: %s", inp, err)
+		}
+		if result.Type != formula.ResultTypeError || result.Value() != "#REF!" {
+			t.Errorf("expected #REF! for a circular reference from %s, got %s %s", inp, result.Value(), result.Type)
+		}
+	}
+}
+
+func TestSheetPrefixString(t *testing.T) {
+	for _, tc := range []struct {
+		Name, Exp string
+	}{
+		{"Sheet1", "Sheet1"},
+		{"my_data.2", "my_data.2"},
+		{"Sheet 1", "'Sheet 1'"},
+		{"O'Brien", "'O''Brien'"},
+		{"2024", "'2024'"},
+		{"1st", "'1st'"},
+		{"A1", "'A1'"},
+		{"xfd1048576", "'xfd1048576'"},
+		{"R1C1", "'R1C1'"},
+		{"C", "'C'"},
+		{"ABCD1", "ABCD1"},
+	} {
+		if got := formula.NewSheetPrefixExpr(tc.Name).String(); got != tc.Exp {
+			t.Errorf("expected %s to be written as %s, got %s", tc.Name, tc.Exp, got)
+		}
+	}
+}
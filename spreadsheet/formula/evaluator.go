@@ -7,6 +7,8 @@
 
 package formula
 
+import "fmt"
+
 // Evaluator is the interface for a formula evaluator.  This is needed so we can
 // pass it to the spreadsheet to let it evaluate formula cells before returning
 // the results.
@@ -24,3 +26,19 @@ func NewEvaluator() Evaluator {
 	ev.evCache = newEvCache()
 	return ev
 }
+
+// Evaluate parses and evaluates a formula within a context, such as the one
+// returned by Sheet.FormulaContext(). A leading '=' is optional. An error is
+// returned only if the formula can't be parsed, errors that occur during
+// evaluation (e.g. #DIV/0! or a #REF! due to a circular reference) are
+// returned as a Result of type ResultTypeError as they would be displayed in a
+// cell.
+func Evaluate(ctx Context, formula string) (Result, error) {
+	expr := ParseString(formula)
+	if expr == nil {
+		return MakeErrorResultType(ErrorTypeName, ""), fmt.Errorf("unable to parse formula %s", formula)
+	}
+	ev := &defEval{}
+	ev.evCache = newEvCache()
+	return ev.evalExpression(ctx, expr), nil
+}
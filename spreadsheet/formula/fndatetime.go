@@ -164,9 +164,6 @@ func Days(args []Result) Result {
 	switch args[1].Type {
 	case ResultTypeNumber:
 		sd = args[1].ValueNumber
-		if sd < 62 && ed >= 62 {
-			sd--
-		}
 	case ResultTypeString:
 		sdResult := DateValue([]Result{args[1]})
 		if sdResult.Type == ResultTypeError {
@@ -204,6 +201,11 @@ func Date(args []Result) Result {
 const nsPerDay = 86400000000000
 
 func dateFromDays(days float64) time.Time {
+	// serial dates before 1 Mar 1900 are offset by one day as Excel treats
+	// 1900 as a leap year
+	if days < 60 {
+		days++
+	}
 	unix := int64((days - daysTo1970) * nsPerDay)
 	return time.Unix(0, unix)
 }
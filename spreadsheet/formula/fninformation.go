@@ -278,6 +278,12 @@ func getValueAsTime(value string, epoch time.Time) (time.Time, error) {
 		return time.Time{}, err
 	}
 
+	// serial dates before 1 Mar 1900 are offset by one day as Excel treats
+	// 1900 as a leap year
+	if epoch.Year() < 1900 && f.Cmp(big.NewFloat(60)) < 0 {
+		f.Add(f, big.NewFloat(1))
+	}
+
 	day := new(big.Float)
 	day.SetUint64(uint64(24 * time.Hour))
 	f.Mul(f, day)
@@ -66,5 +66,47 @@ func (r HorizontalRange) String() string {
 
 // Update updates the horizontal range references after removing a row/column.
 func (r HorizontalRange) Update(q *update.UpdateQuery) Expression {
+	if q.UpdateType == update.UpdateActionInsertRow {
+		new := r
+		if q.UpdateCurrentSheet {
+			new.rowFrom = updateRowDown(r.rowFrom, q.RowIdx)
+			new.rowTo = updateRowDown(r.rowTo, q.RowIdx)
+		}
+		return new
+	}
+	if q.UpdateType == update.UpdateActionRemoveRow && q.UpdateCurrentSheet {
+		new := r
+		var ok bool
+		new.rowFrom, new.rowTo, ok = updateRowsUp(r.rowFrom, r.rowTo, q.RowIdx)
+		if !ok {
+			return CellRef{"#REF!"}
+		}
+		return new
+	}
 	return r
 }
+
+// updateRowDown returns the row number that a row will have after a row is
+// inserted at rowIdx.
+func updateRowDown(row int, rowIdx uint32) int {
+	if row >= int(rowIdx) {
+		return row + 1
+	}
+	return row
+}
+
+// updateRowsUp returns the rows that a range of rows will span after the row
+// rowIdx is removed, or false if it was the only row in the range.
+func updateRowsUp(rowFrom, rowTo int, rowIdx uint32) (int, int, bool) {
+	row := int(rowIdx)
+	if rowFrom == row && rowTo == row {
+		return rowFrom, rowTo, false
+	}
+	if rowFrom > row {
+		rowFrom--
+	}
+	if rowTo >= row {
+		rowTo--
+	}
+	return rowFrom, rowTo, true
+}
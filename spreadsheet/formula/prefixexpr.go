@@ -54,7 +54,7 @@ func (p PrefixExpr) String() string {
 // Update updates references in the PrefixExpr after removing a row/column.
 func (p PrefixExpr) Update(q *update.UpdateQuery) Expression {
 	new := p
-	sheetName := p.pfx.String()
+	sheetName := prefixSheetName(p.pfx)
 	if sheetName == q.SheetToUpdate {
 		newQ := *q
 		newQ.UpdateCurrentSheet = true
@@ -68,5 +68,23 @@ func (r PrefixHorizontalRange) String() string {
 
 // Update updates references in the PrefixHorizontalRange after removing a row/column.
 func (r PrefixHorizontalRange) Update(q *update.UpdateQuery) Expression {
+	if q.UpdateType == update.UpdateActionInsertRow {
+		new := r
+		sheetName := prefixSheetName(r.pfx)
+		if sheetName == q.SheetToUpdate {
+			new.rowFrom = updateRowDown(r.rowFrom, q.RowIdx)
+			new.rowTo = updateRowDown(r.rowTo, q.RowIdx)
+		}
+		return new
+	}
+	if q.UpdateType == update.UpdateActionRemoveRow && prefixSheetName(r.pfx) == q.SheetToUpdate {
+		new := r
+		var ok bool
+		new.rowFrom, new.rowTo, ok = updateRowsUp(r.rowFrom, r.rowTo, q.RowIdx)
+		if !ok {
+			return NewPrefixExpr(r.pfx, CellRef{"#REF!"})
+		}
+		return new
+	}
 	return r
 }
@@ -69,12 +69,15 @@ func (r PrefixRangeExpr) String() string {
 // Update updates references in the PrefixRangeExpr after removing a row/column.
 func (r PrefixRangeExpr) Update(q *update.UpdateQuery) Expression {
 	new := r
-	sheetName := r.pfx.String()
+	sheetName := prefixSheetName(r.pfx)
 	if sheetName == q.SheetToUpdate {
 		newQ := *q
 		newQ.UpdateCurrentSheet = true
-		new.from = r.from.Update(&newQ)
-		new.to = r.to.Update(&newQ)
+		var ok bool
+		new.from, new.to, ok = updateRangeRefs(r.from, r.to, &newQ)
+		if !ok {
+			return NewPrefixExpr(r.pfx, CellRef{"#REF!"})
+		}
 	}
 	return new
 }
@@ -67,7 +67,7 @@ func (r PrefixVerticalRange) String() string {
 func (r PrefixVerticalRange) Update(q *update.UpdateQuery) Expression {
 	if q.UpdateType == update.UpdateActionRemoveColumn {
 		new := r
-		sheetName := r.pfx.String()
+		sheetName := prefixSheetName(r.pfx)
 		if sheetName == q.SheetToUpdate {
 			columnIdx := q.ColumnIdx
 			new.colFrom = updateColumnToLeft(r.colFrom, columnIdx)
@@ -98,8 +98,11 @@ func (r Range) String() string {
 func (r Range) Update(q *update.UpdateQuery) Expression {
 	new := r
 	if q.UpdateCurrentSheet {
-		new.from = r.from.Update(q)
-		new.to = r.to.Update(q)
+		var ok bool
+		new.from, new.to, ok = updateRangeRefs(r.from, r.to, q)
+		if !ok {
+			return CellRef{"#REF!"}
+		}
 	}
 	return new
 }
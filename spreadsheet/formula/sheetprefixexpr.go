@@ -7,7 +7,13 @@
 
 package formula
 
-import "github.com/unidoc/unioffice/spreadsheet/update"
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/unidoc/unioffice/spreadsheet/update"
+)
 
 // SheetPrefixExpr is a reference to a sheet like Sheet1! (reference to sheet 'Sheet1').
 type SheetPrefixExpr struct {
@@ -29,12 +35,43 @@ func (s SheetPrefixExpr) Reference(ctx Context, ev Evaluator) Reference {
 	return Reference{Type: ReferenceTypeSheet, Value: s.sheet}
 }
 
-// String returns a string representation of SheetPrefixExpr.
+// String returns a string representation of SheetPrefixExpr. Sheet names
+// containing anything other than letters, digits, underscores or periods, or
+// that start with a digit or could be read as a cell reference are quoted,
+// e.g. 'Sheet 1' or 'A1', and any quotes within them are doubled.
 func (s SheetPrefixExpr) String() string {
+	if sheetNameNeedsQuotes(s.sheet) {
+		return "'" + strings.Replace(s.sheet, "'", "''", -1) + "'"
+	}
 	return s.sheet
 }
 
+// cellRefName matches sheet names that could be read as an A1 or R1C1 cell
+// reference.
+var cellRefName = regexp.MustCompile(`^(?i)(\$?[a-z]{1,3}\$?[0-9]+|r[0-9]*(c[0-9]*)?|c[0-9]*)$`)
+
+func sheetNameNeedsQuotes(name string) bool {
+	if name == "" || unicode.IsDigit([]rune(name)[0]) || cellRefName.MatchString(name) {
+		return true
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' {
+			return true
+		}
+	}
+	return false
+}
+
 // Update returns the same object as updating sheet references does not affect SheetPrefixExpr.
 func (s SheetPrefixExpr) Update(q *update.UpdateQuery) Expression {
 	return s
 }
+
+// prefixSheetName returns the unquoted name of the sheet that a prefix
+// expression refers to.
+func prefixSheetName(pfx Expression) string {
+	if sp, ok := pfx.(*SheetPrefixExpr); ok {
+		return sp.sheet
+	}
+	return pfx.String()
+}
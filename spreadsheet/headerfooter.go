@@ -0,0 +1,129 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"strings"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+)
+
+// Codes that can be used within header and footer text and are replaced when
+// the sheet is printed.  A literal ampersand must be written as "&&".
+const (
+	HeaderFooterPageNumber = "&P"
+	HeaderFooterPageCount  = "&N"
+	HeaderFooterDate       = "&D"
+	HeaderFooterTime       = "&T"
+	HeaderFooterSheetName  = "&A"
+	HeaderFooterFileName   = "&F"
+	HeaderFooterFilePath   = "&Z"
+)
+
+// HeaderFooter controls the headers and footers printed on each page of a
+// sheet.  Each header and footer has left, center and right sections.
+type HeaderFooter struct {
+	x *sml.CT_HeaderFooter
+}
+
+// X returns the inner wrapped XML type.
+func (h HeaderFooter) X() *sml.CT_HeaderFooter {
+	return h.x
+}
+
+// HeaderFooter returns the headers and footers of the sheet, creating them if
+// necessary.
+func (s Sheet) HeaderFooter() HeaderFooter {
+	if s.x.HeaderFooter == nil {
+		s.x.HeaderFooter = sml.NewCT_HeaderFooter()
+	}
+	return HeaderFooter{s.x.HeaderFooter}
+}
+
+// SetOddHeader sets the header of odd pages, or of all pages if different odd
+// and even pages are not enabled.
+func (h HeaderFooter) SetOddHeader(left, center, right string) {
+	h.x.OddHeader = headerFooterText(left, center, right)
+}
+
+// SetOddFooter sets the footer of odd pages, or of all pages if different odd
+// and even pages are not enabled.
+func (h HeaderFooter) SetOddFooter(left, center, right string) {
+	h.x.OddFooter = headerFooterText(left, center, right)
+}
+
+// SetEvenHeader sets the header of even pages and enables different odd and
+// even pages.
+func (h HeaderFooter) SetEvenHeader(left, center, right string) {
+	h.x.EvenHeader = headerFooterText(left, center, right)
+	h.x.DifferentOddEvenAttr = unioffice.Bool(true)
+}
+
+// SetEvenFooter sets the footer of even pages and enables different odd and
+// even pages.
+func (h HeaderFooter) SetEvenFooter(left, center, right string) {
+	h.x.EvenFooter = headerFooterText(left, center, right)
+	h.x.DifferentOddEvenAttr = unioffice.Bool(true)
+}
+
+// SetFirstHeader sets the header of the first page and enables a different
+// first page.
+func (h HeaderFooter) SetFirstHeader(left, center, right string) {
+	h.x.FirstHeader = headerFooterText(left, center, right)
+	h.x.DifferentFirstAttr = unioffice.Bool(true)
+}
+
+// SetFirstFooter sets the footer of the first page and enables a different
+// first page.
+func (h HeaderFooter) SetFirstFooter(left, center, right string) {
+	h.x.FirstFooter = headerFooterText(left, center, right)
+	h.x.DifferentFirstAttr = unioffice.Bool(true)
+}
+
+// SetDifferentOddEven controls whether even pages use the even header and
+// footer.
+func (h HeaderFooter) SetDifferentOddEven(b bool) {
+	if b {
+		h.x.DifferentOddEvenAttr = unioffice.Bool(true)
+	} else {
+		h.x.DifferentOddEvenAttr = nil
+	}
+}
+
+// SetDifferentFirst controls whether the first page uses the first page
+// header and footer.
+func (h HeaderFooter) SetDifferentFirst(b bool) {
+	if b {
+		h.x.DifferentFirstAttr = unioffice.Bool(true)
+	} else {
+		h.x.DifferentFirstAttr = nil
+	}
+}
+
+// headerFooterText encodes the three sections of a header or footer, returning
+// nil if all of the sections are empty.
+func headerFooterText(left, center, right string) *string {
+	sb := strings.Builder{}
+	if left != "" {
+		sb.WriteString("&L")
+		sb.WriteString(left)
+	}
+	if center != "" {
+		sb.WriteString("&C")
+		sb.WriteString(center)
+	}
+	if right != "" {
+		sb.WriteString("&R")
+		sb.WriteString(right)
+	}
+	if sb.Len() == 0 {
+		return nil
+	}
+	return unioffice.String(sb.String())
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/unidoc/unioffice/schema/soo/sml"
 )
 
+// MergedCell is a region of cells that have been merged.
 type MergedCell struct {
 	wb *Workbook
 	ws *sml.Worksheet
@@ -44,3 +45,9 @@ func (s MergedCell) Cell() Cell {
 	// couldn't find it, log an error?
 	return Cell{}
 }
+
+// Remove removes the merging from the region of cells. The cells that made up
+// the merged region remain, but are no longer merged.
+func (s MergedCell) Remove() {
+	Sheet{w: s.wb, x: s.ws}.RemoveMergedCell(s)
+}
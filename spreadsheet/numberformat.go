@@ -9,6 +9,23 @@ package spreadsheet
 
 import "github.com/unidoc/unioffice/schema/soo/sml"
 
+// firstCustomNumberFormatID is the lowest ID that can be used for a custom
+// number format, IDs below this are reserved for built-in formats.
+const firstCustomNumberFormatID = 164
+
+// Common number format codes that can be passed to CellStyle.SetNumberFormat.
+const (
+	NumberFormatCurrency   = `"$"#,##0.00`
+	NumberFormatAccounting = `_("$"* #,##0.00_);_("$"* \(#,##0.00\);_("$"* "-"??_);_(@_)`
+	NumberFormatPercent    = `0.00%`
+	NumberFormatScientific = `0.00E+00`
+	NumberFormatDateShort  = `m/d/yyyy`
+	NumberFormatDateLong   = `dddd, mmmm d, yyyy`
+	NumberFormatDateISO    = `yyyy-mm-dd`
+	NumberFormatTime       = `h:mm:ss AM/PM`
+	NumberFormatDateTime   = `yyyy-mm-dd hh:mm:ss`
+)
+
 // NumberFormat is a number formatting string that can be applied to a cell
 // style.
 type NumberFormat struct {
@@ -0,0 +1,115 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+)
+
+// PaperSize is the paper size used when printing a sheet.
+type PaperSize uint32
+
+// PaperSize constants, the values are those used by the file format.
+const (
+	PaperSizeLetter    PaperSize = 1
+	PaperSizeTabloid   PaperSize = 3
+	PaperSizeLegal     PaperSize = 5
+	PaperSizeExecutive PaperSize = 7
+	PaperSizeA3        PaperSize = 8
+	PaperSizeA4        PaperSize = 9
+	PaperSizeA5        PaperSize = 11
+	PaperSizeB4        PaperSize = 12
+	PaperSizeB5        PaperSize = 13
+)
+
+// PageSetup controls how a sheet is printed.
+type PageSetup struct {
+	ws *sml.Worksheet
+}
+
+// X returns the inner wrapped XML type.
+func (p PageSetup) X() *sml.CT_PageSetup {
+	return p.ws.PageSetup
+}
+
+// SetOrientation sets the page orientation to portrait or landscape.
+func (p PageSetup) SetOrientation(o sml.ST_Orientation) {
+	p.ws.PageSetup.OrientationAttr = o
+}
+
+// SetPaperSize sets the paper size.
+func (p PageSetup) SetPaperSize(sz PaperSize) {
+	p.ws.PageSetup.PaperSizeAttr = unioffice.Uint32(uint32(sz))
+}
+
+// SetScale sets the print scale as a percentage, from 10 to 400.  It is
+// ignored if the sheet is fit to a number of pages.
+func (p PageSetup) SetScale(pct uint32) {
+	p.ws.PageSetup.ScaleAttr = unioffice.Uint32(pct)
+}
+
+// SetFitToWidth fits the printed sheet to n pages wide, or if n is zero, as
+// many pages wide as needed.
+func (p PageSetup) SetFitToWidth(n uint32) {
+	p.fitToPage()
+	p.ws.PageSetup.FitToWidthAttr = unioffice.Uint32(n)
+}
+
+// SetFitToHeight fits the printed sheet to n pages tall, or if n is zero, as
+// many pages tall as needed.
+func (p PageSetup) SetFitToHeight(n uint32) {
+	p.fitToPage()
+	p.ws.PageSetup.FitToHeightAttr = unioffice.Uint32(n)
+}
+
+// ClearFitToPage removes any fit to page settings, printing the sheet at its
+// scale instead.
+func (p PageSetup) ClearFitToPage() {
+	p.ws.PageSetup.FitToWidthAttr = nil
+	p.ws.PageSetup.FitToHeightAttr = nil
+	if p.ws.SheetPr != nil && p.ws.SheetPr.PageSetUpPr != nil {
+		p.ws.SheetPr.PageSetUpPr.FitToPageAttr = nil
+	}
+}
+
+func (p PageSetup) fitToPage() {
+	if p.ws.SheetPr == nil {
+		p.ws.SheetPr = sml.NewCT_SheetPr()
+	}
+	if p.ws.SheetPr.PageSetUpPr == nil {
+		p.ws.SheetPr.PageSetUpPr = sml.NewCT_PageSetUpPr()
+	}
+	p.ws.SheetPr.PageSetUpPr.FitToPageAttr = unioffice.Bool(true)
+}
+
+// SetMargins sets the page margins along with the distance of the header and
+// footer from the top and bottom of the page.
+func (p PageSetup) SetMargins(top, bottom, left, right, header, footer measurement.Distance) {
+	if p.ws.PageMargins == nil {
+		p.ws.PageMargins = sml.NewCT_PageMargins()
+	}
+	// margins are stored in inches
+	pm := p.ws.PageMargins
+	pm.TopAttr = float64(top / measurement.Inch)
+	pm.BottomAttr = float64(bottom / measurement.Inch)
+	pm.LeftAttr = float64(left / measurement.Inch)
+	pm.RightAttr = float64(right / measurement.Inch)
+	pm.HeaderAttr = float64(header / measurement.Inch)
+	pm.FooterAttr = float64(footer / measurement.Inch)
+}
+
+// PageSetup returns the print settings of the sheet, creating them if
+// necessary.
+func (s Sheet) PageSetup() PageSetup {
+	if s.x.PageSetup == nil {
+		s.x.PageSetup = sml.NewCT_PageSetup()
+	}
+	return PageSetup{s.x}
+}
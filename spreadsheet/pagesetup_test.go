@@ -0,0 +1,161 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet"
+)
+
+func TestPageSetup(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	wb.AddSheet()
+	sheet := wb.AddSheet()
+	sheet.SetName("Report")
+	ps := sheet.PageSetup()
+	ps.SetOrientation(sml.ST_OrientationLandscape)
+	ps.SetPaperSize(spreadsheet.PaperSizeA4)
+	ps.SetFitToWidth(1)
+	ps.SetFitToHeight(0)
+	ps.SetMargins(measurement.Inch, measurement.Inch, 0.5*measurement.Inch, 0.5*measurement.Inch,
+		0.25*measurement.Inch, 0.25*measurement.Inch)
+	sheet.SetPrintArea("A1:F20")
+	sheet.SetPrintArea("$A$1:$D$10, F1:G10")
+	sheet.SetPrintTitleRows(1, 2)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+
+	ws := wb2.Sheets()[1].X()
+	if ws.PageSetup.OrientationAttr != sml.ST_OrientationLandscape {
+		t.Errorf("expected landscape orientation")
+	}
+	if ws.PageSetup.PaperSizeAttr == nil || *ws.PageSetup.PaperSizeAttr != 9 {
+		t.Errorf("expected A4 paper")
+	}
+	if ws.PageSetup.FitToWidthAttr == nil || *ws.PageSetup.FitToWidthAttr != 1 ||
+		ws.PageSetup.FitToHeightAttr == nil || *ws.PageSetup.FitToHeightAttr != 0 {
+		t.Errorf("expected the sheet to fit one page wide")
+	}
+	if ws.SheetPr == nil || ws.SheetPr.PageSetUpPr == nil || ws.SheetPr.PageSetUpPr.FitToPageAttr == nil ||
+		!*ws.SheetPr.PageSetUpPr.FitToPageAttr {
+		t.Errorf("expected fit to page to be enabled")
+	}
+	if pm := ws.PageMargins; pm == nil || pm.TopAttr != 1 || pm.LeftAttr != 0.5 || pm.FooterAttr != 0.25 {
+		t.Errorf("expected margins to be stored in inches, got %+v", pm)
+	}
+
+	exp := map[string]string{
+		"_xlnm.Print_Area":   "'Report'!$A$1:$D$10,'Report'!$F$1:$G$10",
+		"_xlnm.Print_Titles": "'Report'!$1:$2",
+	}
+	dns := wb2.DefinedNames()
+	if len(dns) != len(exp) {
+		t.Fatalf("expected %d defined names, got %d", len(exp), len(dns))
+	}
+	for _, dn := range dns {
+		if dn.Content() != exp[dn.Name()] {
+			t.Errorf("expected %s = %s, got %s", dn.Name(), exp[dn.Name()], dn.Content())
+		}
+		if id, ok := dn.LocalSheetID(); !ok || id != 1 {
+			t.Errorf("expected %s to be scoped to the sheet", dn.Name())
+		}
+	}
+
+	sheet2 := wb2.Sheets()[1]
+	sheet2.ClearPrintArea()
+	sheet2.ClearPrintTitleRows()
+	if len(wb2.DefinedNames()) != 0 {
+		t.Errorf("expected the print settings to be removed")
+	}
+}
+
+func TestPrintTitleRowsQuotedSheetName(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	sheet := wb.AddSheet()
+	sheet.SetName("Bob's")
+	sheet.SetPrintTitleRows(1, 1)
+
+	dns := wb.DefinedNames()
+	if len(dns) != 1 {
+		t.Fatalf("expected 1 defined name, got %d", len(dns))
+	}
+	if exp := "'Bob''s'!$1:$1"; dns[0].Content() != exp {
+		t.Errorf("expected %s, got %s", exp, dns[0].Content())
+	}
+}
+
+func TestHeaderFooter(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	sheet := wb.AddSheet()
+	hf := sheet.HeaderFooter()
+	hf.SetOddHeader("", "Quarterly Report", "")
+	hf.SetOddFooter(spreadsheet.HeaderFooterDate, "",
+		"Page "+spreadsheet.HeaderFooterPageNumber+" of "+spreadsheet.HeaderFooterPageCount)
+	hf.SetFirstHeader("", "", "")
+	hf.SetFirstFooter("Confidential", "", "")
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, _ := f.Open()
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		exp := `<ma:headerFooter differentFirst="1"><ma:oddHeader>&amp;CQuarterly Report</ma:oddHeader>` +
+			`<ma:oddFooter>&amp;L&amp;D&amp;RPage &amp;P of &amp;N</ma:oddFooter>` +
+			`<ma:firstFooter>&amp;LConfidential</ma:firstFooter></ma:headerFooter>`
+		if !strings.Contains(string(b), exp) {
+			t.Errorf("expected header and footer %s, got %s", exp, string(b))
+		}
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	hx := wb2.Sheets()[0].X().HeaderFooter
+	if hx == nil || hx.OddFooter == nil || *hx.OddFooter != "&L&D&RPage &P of &N" {
+		t.Errorf("expected the footer to round trip")
+	}
+	if hx.DifferentOddEvenAttr != nil {
+		t.Errorf("expected odd and even pages to be the same")
+	}
+}
@@ -8,7 +8,11 @@
 package spreadsheet
 
 import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
 	"fmt"
+	"unicode/utf16"
 )
 
 // PasswordHash returns the password hash for a workbook using the modified
@@ -27,3 +31,28 @@ func PasswordHash(s string) string {
 	}
 	return fmt.Sprintf("%04X", uint64(hash))
 }
+
+// defaultSpinCount is the number of hash iterations used by Excel when
+// writing SHA-512 password hashes.
+const defaultSpinCount = 100000
+
+// PasswordHashSHA512 returns the base64 encoded SHA-512 hash of a password as
+// used by the algorithmName, hashValue, saltValue and spinCount attributes of
+// modern sheet and workbook protection. The salt is prepended to the UTF-16LE
+// encoded password, and the result is then rehashed spinCount times with the
+// little-endian iteration number appended.
+func PasswordHashSHA512(pw string, salt []byte, spinCount uint32) string {
+	buf := make([]byte, 0, len(salt)+2*len(pw))
+	buf = append(buf, salt...)
+	for _, c := range utf16.Encode([]rune(pw)) {
+		buf = append(buf, byte(c), byte(c>>8))
+	}
+	h := sha512.Sum512(buf)
+	iter := make([]byte, sha512.Size+4)
+	for i := uint32(0); i < spinCount; i++ {
+		copy(iter, h[:])
+		binary.LittleEndian.PutUint32(iter[sha512.Size:], i)
+		h = sha512.Sum512(iter)
+	}
+	return base64.StdEncoding.EncodeToString(h[:])
+}
@@ -8,6 +8,7 @@
 package spreadsheet_test
 
 import (
+	"encoding/base64"
 	"testing"
 
 	"github.com/unidoc/unioffice/spreadsheet"
@@ -19,6 +20,8 @@ func TestKnownHashes(t *testing.T) {
 		Exp string
 	}{
 		{"gooxml", "DD67"},
+		{"password", "83AF"},
+		{"test", "CBEB"},
 		{"", "0000"},
 	}
 	for _, tc := range td {
@@ -27,3 +30,14 @@ func TestKnownHashes(t *testing.T) {
 		}
 	}
 }
+
+func TestKnownSHA512Hashes(t *testing.T) {
+	// hash and salt of the password "password" with the default spin count
+	// from the test vectors of excelize's independent implementation, as no
+	// workbook protected by Excel is available to take them from
+	salt, _ := base64.StdEncoding.DecodeString("p5s/bybHBPtusI7EydTIrg==")
+	exp := "YZ6jrGOFQgVKK3rDK/0SHGGgxEmFJglQIIRamZc2PkxVtUBp54fQn96+jVXEOqo6dtCSanqksXGcm/h3KaiR4Q=="
+	if got := spreadsheet.PasswordHashSHA512("password", salt, 100000); got != exp {
+		t.Errorf("expected hash of password = %s, got %s", exp, got)
+	}
+}
@@ -9,6 +9,7 @@ package spreadsheet
 
 import (
 	"github.com/unidoc/unioffice/color"
+	"github.com/unidoc/unioffice/common"
 
 	"github.com/unidoc/unioffice/schema/soo/sml"
 )
@@ -50,3 +51,15 @@ func (f PatternFill) SetFgColor(c color.Color) {
 	f.x.FgColor = sml.NewCT_Color()
 	f.x.FgColor.RgbAttr = c.AsRGBAString()
 }
+
+// SetFgThemeColor sets the fill foreground color to a theme color, lightened
+// or darkened by tint which ranges from -1.0 to 1.0.
+func (f PatternFill) SetFgThemeColor(slot common.ThemeColorSlot, tint float64) {
+	f.x.FgColor = newThemeColor(slot, tint)
+}
+
+// SetBgThemeColor sets the fill background color to a theme color, lightened
+// or darkened by tint which ranges from -1.0 to 1.0.
+func (f PatternFill) SetBgThemeColor(slot common.ThemeColorSlot, tint float64) {
+	f.x.BgColor = newThemeColor(slot, tint)
+}
@@ -0,0 +1,486 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+)
+
+// PivotTable is a pivot table summarizing a range of cells.  Pivot tables are
+// written with refreshOnLoad set so that Excel recomputes the layout and
+// values when the workbook is opened.
+type PivotTable struct {
+	wb    *Workbook
+	x     *sml.PivotTableDefinition
+	cache *sml.PivotCacheDefinition
+	recs  *sml.PivotCacheRecords
+}
+
+// X returns the inner wrapped XML type.
+func (p PivotTable) X() *sml.PivotTableDefinition {
+	return p.x
+}
+
+// CacheDefinition returns the pivot cache definition that describes the
+// source fields of the pivot table.
+func (p PivotTable) CacheDefinition() *sml.PivotCacheDefinition {
+	return p.cache
+}
+
+// AddPivotTable adds a pivot table to dst with its top left corner at ref,
+// summarizing sourceRange, e.g. "'Sales Data'!A1:C100".  The first row of the
+// source range contains the field names.  If sourceRange doesn't include a
+// sheet name, it refers to dst.
+func (wb *Workbook) AddPivotTable(sourceRange string, dst Sheet, ref string) (PivotTable, error) {
+	from, to, err := reference.ParseRangeReference(sourceRange)
+	if err != nil {
+		return PivotTable{}, fmt.Errorf("invalid pivot source %s: %s", sourceRange, err)
+	}
+	topLeft, err := reference.ParseCellReference(ref)
+	if err != nil {
+		return PivotTable{}, fmt.Errorf("invalid pivot location %s: %s", ref, err)
+	}
+	src := dst
+	if name := strings.Trim(from.SheetName, "'"); name != "" {
+		var ok bool
+		if src, ok = wb.SheetByName(name); !ok {
+			return PivotTable{}, fmt.Errorf("pivot source references unknown sheet %s", name)
+		}
+	}
+	if from.RowIdx > to.RowIdx {
+		from.RowIdx, to.RowIdx = to.RowIdx, from.RowIdx
+	}
+	if from.ColumnIdx > to.ColumnIdx {
+		from.ColumnIdx, to.ColumnIdx = to.ColumnIdx, from.ColumnIdx
+	}
+	if from.RowIdx == to.RowIdx {
+		return PivotTable{}, fmt.Errorf("pivot source %s must contain a header row and data", sourceRange)
+	}
+
+	cache, recs := buildPivotCache(src, from, to)
+	cacheID := uint32(1)
+	if wb.x.PivotCaches == nil {
+		wb.x.PivotCaches = sml.NewCT_PivotCaches()
+	}
+	for _, pc := range wb.x.PivotCaches.PivotCache {
+		if pc.CacheIdAttr >= cacheID {
+			cacheID = pc.CacheIdAttr + 1
+		}
+	}
+
+	pt := sml.NewPivotTableDefinition()
+	pt.NameAttr = fmt.Sprintf("PivotTable%d", cacheID)
+	pt.CacheIdAttr = cacheID
+	pt.DataCaptionAttr = "Values"
+	pt.ApplyNumberFormatsAttr = unioffice.Bool(false)
+	pt.ApplyBorderFormatsAttr = unioffice.Bool(false)
+	pt.ApplyFontFormatsAttr = unioffice.Bool(false)
+	pt.ApplyPatternFormatsAttr = unioffice.Bool(false)
+	pt.ApplyAlignmentFormatsAttr = unioffice.Bool(false)
+	pt.ApplyWidthHeightFormatsAttr = unioffice.Bool(true)
+	pt.UpdatedVersionAttr = unioffice.Uint8(pivotVersion)
+	pt.MinRefreshableVersionAttr = unioffice.Uint8(pivotMinRefreshableVersion)
+	pt.CreatedVersionAttr = unioffice.Uint8(pivotVersion)
+	pt.UseAutoFormattingAttr = unioffice.Bool(true)
+	pt.ItemPrintTitlesAttr = unioffice.Bool(true)
+	pt.IndentAttr = unioffice.Uint32(0)
+	pt.OutlineAttr = unioffice.Bool(true)
+	pt.OutlineDataAttr = unioffice.Bool(true)
+	pt.Location.RefAttr = topLeft.String()
+	pt.PivotFields = sml.NewCT_PivotFields()
+	for range cache.CacheFields.CacheField {
+		pf := sml.NewCT_PivotField()
+		pf.ShowAllAttr = unioffice.Bool(false)
+		pt.PivotFields.PivotField = append(pt.PivotFields.PivotField, pf)
+	}
+	pt.PivotFields.CountAttr = unioffice.Uint32(uint32(len(pt.PivotFields.PivotField)))
+	pt.PivotTableStyleInfo = sml.NewCT_PivotTableStyle()
+	pt.PivotTableStyleInfo.NameAttr = unioffice.String("PivotStyleLight16")
+	pt.PivotTableStyleInfo.ShowRowHeadersAttr = unioffice.Bool(true)
+	pt.PivotTableStyleInfo.ShowColHeadersAttr = unioffice.Bool(true)
+	pt.PivotTableStyleInfo.ShowRowStripesAttr = unioffice.Bool(false)
+	pt.PivotTableStyleInfo.ShowColStripesAttr = unioffice.Bool(false)
+	pt.PivotTableStyleInfo.ShowLastColumnAttr = unioffice.Bool(true)
+
+	// pivot parts read from an existing file are preserved as extra files, so
+	// the parts we add are numbered after them
+	if len(wb.pivotTables) == 0 {
+		wb.pivotOffset = 0
+		for _, ef := range wb.ExtraFiles {
+			for _, prefix := range []string{"xl/pivotTables/pivotTable", "xl/pivotCache/pivotCacheDefinition", "xl/pivotCache/pivotCacheRecords"} {
+				if !strings.HasPrefix(ef.ZipPath, prefix) {
+					continue
+				}
+				if idx, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(ef.ZipPath, prefix), ".xml")); err == nil && idx > wb.pivotOffset {
+					wb.pivotOffset = idx
+				}
+			}
+		}
+	}
+	wb.pivotTables = append(wb.pivotTables, pt)
+	wb.pivotCaches = append(wb.pivotCaches, cache)
+	wb.pivotRecords = append(wb.pivotRecords, recs)
+	idx := wb.pivotOffset + len(wb.pivotTables)
+
+	dt := unioffice.DocTypeSpreadsheet
+	wb.ContentTypes.AddOverride(unioffice.AbsoluteFilename(dt, unioffice.PivotTableType, idx), unioffice.PivotTableContentType)
+	wb.ContentTypes.AddOverride(unioffice.AbsoluteFilename(dt, unioffice.PivotCacheDefinitionType, idx), unioffice.PivotCacheDefinitionContentType)
+	wb.ContentTypes.AddOverride(unioffice.AbsoluteFilename(dt, unioffice.PivotCacheRecordsType, idx), unioffice.PivotCacheRecordsContentType)
+
+	// workbook -> cache definition -> records
+	rel := wb.wbRels.AddAutoRelationship(dt, unioffice.OfficeDocumentType, idx, unioffice.PivotCacheDefinitionType)
+	pc := sml.NewCT_PivotCache()
+	pc.CacheIdAttr = cacheID
+	pc.IdAttr = rel.ID()
+	wb.x.PivotCaches.PivotCache = append(wb.x.PivotCaches.PivotCache, pc)
+
+	cacheRels := common.NewRelationships()
+	rel = cacheRels.AddAutoRelationship(dt, unioffice.PivotCacheDefinitionType, idx, unioffice.PivotCacheRecordsType)
+	cache.IdAttr = unioffice.String(rel.ID())
+	wb.pivotCacheRels = append(wb.pivotCacheRels, cacheRels)
+
+	// sheet -> pivot table -> cache definition
+	tableRels := common.NewRelationships()
+	tableRels.AddAutoRelationship(dt, unioffice.PivotTableType, idx, unioffice.PivotCacheDefinitionType)
+	wb.pivotTableRels = append(wb.pivotTableRels, tableRels)
+	for i, ws := range wb.xws {
+		if ws == dst.x {
+			wb.xwsRels[i].AddAutoRelationship(dt, unioffice.WorksheetType, idx, unioffice.PivotTableType)
+			break
+		}
+	}
+
+	p := PivotTable{wb, pt, cache, recs}
+	p.updateLocation()
+	return p, nil
+}
+
+// PivotTables returns the pivot tables that have been added to the workbook.
+func (wb *Workbook) PivotTables() []PivotTable {
+	ret := []PivotTable{}
+	for i, pt := range wb.pivotTables {
+		ret = append(ret, PivotTable{wb, pt, wb.pivotCaches[i], wb.pivotRecords[i]})
+	}
+	return ret
+}
+
+const (
+	pivotVersion               = 6
+	pivotMinRefreshableVersion = 3
+	// pivotValuesField is the field index used to place the data fields on an
+	// axis when there is more than one
+	pivotValuesField = -2
+)
+
+// buildPivotCache builds the cache definition and records for the source
+// range.  Every field enumerates its shared items and each record refers to
+// them by index.
+func buildPivotCache(src Sheet, from, to reference.CellReference) (*sml.PivotCacheDefinition, *sml.PivotCacheRecords) {
+	cache := sml.NewPivotCacheDefinition()
+	cache.RefreshOnLoadAttr = unioffice.Bool(true)
+	cache.CreatedVersionAttr = unioffice.Uint8(pivotVersion)
+	cache.RefreshedVersionAttr = unioffice.Uint8(pivotVersion)
+	cache.MinRefreshableVersionAttr = unioffice.Uint8(pivotMinRefreshableVersion)
+	cache.RecordCountAttr = unioffice.Uint32(to.RowIdx - from.RowIdx)
+	cache.CacheSource.TypeAttr = sml.ST_SourceTypeWorksheet
+	cache.CacheSource.WorksheetSource = sml.NewCT_WorksheetSource()
+	cache.CacheSource.WorksheetSource.RefAttr = unioffice.String(fmt.Sprintf("%s%d:%s%d", from.Column, from.RowIdx, to.Column, to.RowIdx))
+	cache.CacheSource.WorksheetSource.SheetAttr = unioffice.String(src.Name())
+	cache.CacheFields = sml.NewCT_CacheFields()
+
+	recs := sml.NewPivotCacheRecords()
+	for r := from.RowIdx + 1; r <= to.RowIdx; r++ {
+		recs.R = append(recs.R, sml.NewCT_Record())
+	}
+	recs.CountAttr = unioffice.Uint32(uint32(len(recs.R)))
+
+	for c := from.ColumnIdx; c <= to.ColumnIdx; c++ {
+		col := reference.IndexToColumn(c)
+		cf := sml.NewCT_CacheField()
+		cf.NameAttr = src.Cell(fmt.Sprintf("%s%d", col, from.RowIdx)).GetString()
+		if cf.NameAttr == "" {
+			cf.NameAttr = col
+		}
+		cf.NumFmtIdAttr = unioffice.Uint32(0)
+		cf.SharedItems = sml.NewCT_SharedItems()
+
+		cells := []Cell{}
+		numeric := true
+		for r := from.RowIdx + 1; r <= to.RowIdx; r++ {
+			cell := src.Cell(fmt.Sprintf("%s%d", col, r))
+			cells = append(cells, cell)
+			if !cell.IsNumber() {
+				numeric = false
+			}
+		}
+
+		// shared items of a field must all be of the same type as the
+		// schema types don't preserve the order of mixed items
+		si := cf.SharedItems
+		ids := map[string]uint32{}
+		if numeric {
+			si.ContainsSemiMixedTypesAttr = unioffice.Bool(false)
+			si.ContainsStringAttr = unioffice.Bool(false)
+			si.ContainsNumberAttr = unioffice.Bool(true)
+			integers := true
+			min, max := math.Inf(1), math.Inf(-1)
+			for i, cell := range cells {
+				v, _ := cell.GetValueAsNumber()
+				min = math.Min(min, v)
+				max = math.Max(max, v)
+				if v != math.Trunc(v) {
+					integers = false
+				}
+				key := strconv.FormatFloat(v, 'g', -1, 64)
+				id, ok := ids[key]
+				if !ok {
+					id = uint32(len(si.N))
+					ids[key] = id
+					si.N = append(si.N, &sml.CT_Number{VAttr: v})
+				}
+				recs.R[i].X = append(recs.R[i].X, &sml.CT_Index{VAttr: id})
+			}
+			if integers {
+				si.ContainsIntegerAttr = unioffice.Bool(true)
+			}
+			si.MinValueAttr = unioffice.Float64(min)
+			si.MaxValueAttr = unioffice.Float64(max)
+			si.CountAttr = unioffice.Uint32(uint32(len(si.N)))
+		} else {
+			for i, cell := range cells {
+				v := cell.GetString()
+				id, ok := ids[v]
+				if !ok {
+					id = uint32(len(si.S))
+					ids[v] = id
+					si.S = append(si.S, &sml.CT_String{VAttr: v})
+				}
+				recs.R[i].X = append(recs.R[i].X, &sml.CT_Index{VAttr: id})
+			}
+			si.CountAttr = unioffice.Uint32(uint32(len(si.S)))
+		}
+		cache.CacheFields.CacheField = append(cache.CacheFields.CacheField, cf)
+	}
+	cache.CacheFields.CountAttr = unioffice.Uint32(uint32(len(cache.CacheFields.CacheField)))
+	return cache, recs
+}
+
+// fieldIndex returns the index of the source field with the given name.
+func (p PivotTable) fieldIndex(name string) (int, error) {
+	for i, cf := range p.cache.CacheFields.CacheField {
+		if cf.NameAttr == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("pivot source has no field named %s", name)
+}
+
+// itemCount returns the number of shared items of a source field.
+func (p PivotTable) itemCount(idx int) int {
+	si := p.cache.CacheFields.CacheField[idx].SharedItems
+	return len(si.N) + len(si.S)
+}
+
+// setAxis places a field on the row or column axis, listing each of its items
+// followed by the default subtotal.
+func (p PivotTable) setAxis(name string, axis sml.ST_Axis) (int, error) {
+	idx, err := p.fieldIndex(name)
+	if err != nil {
+		return 0, err
+	}
+	pf := p.x.PivotFields.PivotField[idx]
+	if pf.AxisAttr != sml.ST_AxisUnset {
+		return 0, fmt.Errorf("pivot field %s is already on an axis", name)
+	}
+	pf.AxisAttr = axis
+	pf.Items = sml.NewCT_Items()
+	for i := 0; i < p.itemCount(idx); i++ {
+		pf.Items.Item = append(pf.Items.Item, &sml.CT_Item{XAttr: unioffice.Uint32(uint32(i))})
+	}
+	pf.Items.Item = append(pf.Items.Item, &sml.CT_Item{TAttr: sml.ST_ItemTypeDefault})
+	pf.Items.CountAttr = unioffice.Uint32(uint32(len(pf.Items.Item)))
+	return idx, nil
+}
+
+// AddRowField groups the pivot table rows by the values of a source field.
+func (p PivotTable) AddRowField(name string) error {
+	idx, err := p.setAxis(name, sml.ST_AxisAxisRow)
+	if err != nil {
+		return err
+	}
+	if p.x.RowFields == nil {
+		p.x.RowFields = sml.NewCT_RowFields()
+	}
+	p.x.RowFields.Field = append(p.x.RowFields.Field, &sml.CT_Field{XAttr: int32(idx)})
+	p.x.RowFields.CountAttr = unioffice.Uint32(uint32(len(p.x.RowFields.Field)))
+	p.updateLocation()
+	return nil
+}
+
+// AddColumnField groups the pivot table columns by the values of a source
+// field.
+func (p PivotTable) AddColumnField(name string) error {
+	idx, err := p.setAxis(name, sml.ST_AxisAxisCol)
+	if err != nil {
+		return err
+	}
+	if p.x.ColFields == nil {
+		p.x.ColFields = sml.NewCT_ColFields()
+	}
+	// the values field stays last
+	fields := []*sml.CT_Field{}
+	var values *sml.CT_Field
+	for _, f := range p.x.ColFields.Field {
+		if f.XAttr == pivotValuesField {
+			values = f
+			continue
+		}
+		fields = append(fields, f)
+	}
+	fields = append(fields, &sml.CT_Field{XAttr: int32(idx)})
+	if values != nil {
+		fields = append(fields, values)
+	}
+	p.x.ColFields.Field = fields
+	p.x.ColFields.CountAttr = unioffice.Uint32(uint32(len(p.x.ColFields.Field)))
+	p.updateLocation()
+	return nil
+}
+
+// AddDataField summarizes a source field in the body of the pivot table using
+// the given function, e.g. sml.ST_DataConsolidateFunctionSum.
+func (p PivotTable) AddDataField(name string, fn sml.ST_DataConsolidateFunction) error {
+	idx, err := p.fieldIndex(name)
+	if err != nil {
+		return err
+	}
+	if fn == sml.ST_DataConsolidateFunctionUnset {
+		fn = sml.ST_DataConsolidateFunctionSum
+	}
+	p.x.PivotFields.PivotField[idx].DataFieldAttr = unioffice.Bool(true)
+	if p.x.DataFields == nil {
+		p.x.DataFields = sml.NewCT_DataFields()
+	}
+	df := sml.NewCT_DataField()
+	df.NameAttr = unioffice.String(fmt.Sprintf("%s of %s", dataFieldCaption(fn), name))
+	df.FldAttr = uint32(idx)
+	df.SubtotalAttr = fn
+	df.BaseFieldAttr = unioffice.Int32(0)
+	df.BaseItemAttr = unioffice.Uint32(0)
+	p.x.DataFields.DataField = append(p.x.DataFields.DataField, df)
+	p.x.DataFields.CountAttr = unioffice.Uint32(uint32(len(p.x.DataFields.DataField)))
+
+	// multiple data fields are laid out across the columns
+	if len(p.x.DataFields.DataField) == 2 {
+		if p.x.ColFields == nil {
+			p.x.ColFields = sml.NewCT_ColFields()
+		}
+		p.x.ColFields.Field = append(p.x.ColFields.Field, &sml.CT_Field{XAttr: pivotValuesField})
+		p.x.ColFields.CountAttr = unioffice.Uint32(uint32(len(p.x.ColFields.Field)))
+	}
+	p.updateLocation()
+	return nil
+}
+
+func dataFieldCaption(fn sml.ST_DataConsolidateFunction) string {
+	switch fn {
+	case sml.ST_DataConsolidateFunctionAverage:
+		return "Average"
+	case sml.ST_DataConsolidateFunctionCount, sml.ST_DataConsolidateFunctionCountNums:
+		return "Count"
+	case sml.ST_DataConsolidateFunctionMax:
+		return "Max"
+	case sml.ST_DataConsolidateFunctionMin:
+		return "Min"
+	case sml.ST_DataConsolidateFunctionProduct:
+		return "Product"
+	case sml.ST_DataConsolidateFunctionStdDev:
+		return "StdDev"
+	case sml.ST_DataConsolidateFunctionStdDevp:
+		return "StdDevp"
+	case sml.ST_DataConsolidateFunctionVar:
+		return "Var"
+	case sml.ST_DataConsolidateFunctionVarp:
+		return "Varp"
+	}
+	return "Sum"
+}
+
+// uniqueTuples returns the number of distinct combinations of item values of
+// the given fields across the cached records.
+func (p PivotTable) uniqueTuples(fields []int) int {
+	seen := map[string]struct{}{}
+	for _, r := range p.recs.R {
+		key := []string{}
+		for _, f := range fields {
+			key = append(key, strconv.Itoa(int(r.X[f].VAttr)))
+		}
+		seen[strings.Join(key, ",")] = struct{}{}
+	}
+	return len(seen)
+}
+
+// updateLocation sizes the pivot table's location for a compact layout.  Excel
+// recomputes the layout when the pivot is refreshed on load, but the location
+// must still cover the table.
+func (p PivotTable) updateLocation() {
+	rowFields := []int{}
+	if p.x.RowFields != nil {
+		for _, f := range p.x.RowFields.Field {
+			rowFields = append(rowFields, int(f.XAttr))
+		}
+	}
+	colFields := []int{}
+	hasValuesField := false
+	if p.x.ColFields != nil {
+		for _, f := range p.x.ColFields.Field {
+			if f.XAttr == pivotValuesField {
+				hasValuesField = true
+				continue
+			}
+			colFields = append(colFields, int(f.XAttr))
+		}
+	}
+	nData := 1
+	if p.x.DataFields != nil && len(p.x.DataFields.DataField) > 1 {
+		nData = len(p.x.DataFields.DataField)
+	}
+
+	headerRows := 1
+	if len(colFields) > 0 || hasValuesField {
+		headerRows++
+	}
+	rows := headerRows + 1
+	for i := range rowFields {
+		rows += p.uniqueTuples(rowFields[:i+1])
+	}
+	cols := 1 + nData
+	if len(colFields) > 0 {
+		// one column per item combination plus the grand total
+		cols = 1 + nData*(p.uniqueTuples(colFields)+1)
+	}
+
+	from, err := reference.ParseCellReference(strings.Split(p.x.Location.RefAttr, ":")[0])
+	if err != nil {
+		unioffice.Log("invalid pivot table location %s", p.x.Location.RefAttr)
+		return
+	}
+	to := reference.IndexToColumn(from.ColumnIdx + uint32(cols) - 1)
+	p.x.Location.RefAttr = fmt.Sprintf("%s%d:%s%d", from.Column, from.RowIdx, to, from.RowIdx+uint32(rows)-1)
+	p.x.Location.FirstHeaderRowAttr = 1
+	p.x.Location.FirstDataRowAttr = uint32(headerRows)
+	p.x.Location.FirstDataColAttr = 1
+}
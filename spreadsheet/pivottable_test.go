@@ -0,0 +1,139 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet"
+)
+
+func zipContents(t *testing.T, buf []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	ret := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("error opening %s: %s", f.Name, err)
+		}
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		ret[f.Name] = string(b)
+	}
+	return ret
+}
+
+func TestPivotTable(t *testing.T) {
+	wb := spreadsheet.New()
+	data := wb.AddSheet()
+	data.SetName("Sales")
+	rows := [][]interface{}{
+		{"Region", "Product", "Amount"},
+		{"East", "Apples", 10.0},
+		{"West", "Apples", 20.0},
+		{"East", "Pears", 5.0},
+		{"West", "Pears", 7.5},
+	}
+	for _, r := range rows {
+		row := data.AddRow()
+		for _, v := range r {
+			switch v := v.(type) {
+			case string:
+				row.AddCell().SetString(v)
+			case float64:
+				row.AddCell().SetNumber(v)
+			}
+		}
+	}
+	summary := wb.AddSheet()
+
+	pt, err := wb.AddPivotTable("Sales!A1:C5", summary, "A3")
+	if err != nil {
+		t.Fatalf("error adding pivot table: %s", err)
+	}
+	if err := pt.AddRowField("Region"); err != nil {
+		t.Fatalf("error adding row field: %s", err)
+	}
+	if err := pt.AddDataField("Amount", sml.ST_DataConsolidateFunctionSum); err != nil {
+		t.Fatalf("error adding data field: %s", err)
+	}
+	if err := pt.AddRowField("Missing"); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+	if err := pt.AddColumnField("Region"); err == nil {
+		t.Errorf("expected an error placing a field on two axes")
+	}
+
+	// header, East, West and the grand total
+	if got := pt.X().Location.RefAttr; got != "A3:B6" {
+		t.Errorf("expected location A3:B6, got %s", got)
+	}
+	cf := pt.CacheDefinition().CacheFields.CacheField
+	if len(cf) != 3 || cf[0].NameAttr != "Region" || len(cf[0].SharedItems.S) != 2 {
+		t.Errorf("expected 3 cache fields with Region having 2 items")
+	}
+	if si := cf[2].SharedItems; si.ContainsNumberAttr == nil || !*si.ContainsNumberAttr || *si.MaxValueAttr != 20 {
+		t.Errorf("expected Amount to be a numeric field with a max of 20")
+	}
+
+	if _, err := wb.AddPivotTable("Nope!A1:C5", summary, "A3"); err == nil {
+		t.Errorf("expected an error for an unknown source sheet")
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	files := zipContents(t, buf.Bytes())
+	for fn, exp := range map[string]string{
+		"xl/pivotTables/pivotTable1.xml":                     `<ma:dataField name="Sum of Amount" fld="2" subtotal="sum" baseField="0" baseItem="0"/>`,
+		"xl/pivotTables/_rels/pivotTable1.xml.rels":          `Target="../pivotCache/pivotCacheDefinition1.xml"`,
+		"xl/pivotCache/pivotCacheDefinition1.xml":            `<ma:worksheetSource ref="A1:C5" sheet="Sales"/>`,
+		"xl/pivotCache/_rels/pivotCacheDefinition1.xml.rels": `Target="pivotCacheRecords1.xml"`,
+		"xl/pivotCache/pivotCacheRecords1.xml":               `<ma:r><ma:x v="1"/><ma:x v="0"/><ma:x v="1"/></ma:r>`,
+		"xl/workbook.xml":                                    `<ma:pivotCache cacheId="1"`,
+		"xl/worksheets/_rels/sheet2.xml.rels":                `Target="../pivotTables/pivotTable1.xml"`,
+		"xl/_rels/workbook.xml.rels":                         `Target="pivotCache/pivotCacheDefinition1.xml"`,
+		"[Content_Types].xml":                                `spreadsheetml.pivotCacheRecords+xml`,
+	} {
+		if !strings.Contains(files[fn], exp) {
+			t.Errorf("expected %s to contain %s, got %s", fn, exp, files[fn])
+		}
+	}
+
+	// pivots read from a file are preserved and new ones numbered after them
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading: %s", err)
+	}
+	pt2, err := wb2.AddPivotTable("Sales!A1:C5", wb2.Sheets()[1], "E3")
+	if err != nil {
+		t.Fatalf("error adding pivot table: %s", err)
+	}
+	if pt2.X().CacheIdAttr != 2 {
+		t.Errorf("expected a new cache ID of 2, got %d", pt2.X().CacheIdAttr)
+	}
+	buf.Reset()
+	if err := wb2.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	files = zipContents(t, buf.Bytes())
+	for _, fn := range []string{"xl/pivotTables/pivotTable1.xml", "xl/pivotTables/pivotTable2.xml", "xl/pivotCache/pivotCacheRecords2.xml"} {
+		if _, ok := files[fn]; !ok {
+			t.Errorf("expected %s in the saved workbook", fn)
+		}
+	}
+}
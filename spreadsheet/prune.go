@@ -0,0 +1,252 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+)
+
+// Optimize reduces the size of the workbook by removing the shared strings and
+// cell styles that are no longer used by any cell, along with the fonts, fills,
+// borders and number formats that only the removed styles used.  Strings that
+// were added to the shared strings table more than once are merged.  The
+// indices of the remaining strings and styles change, so CellStyle values
+// obtained before optimizing must not be used afterwards.  Sheets with
+// streamed rows can't be optimized as the streamed cells can't be updated.
+func (wb *Workbook) Optimize() error {
+	if len(wb.streams) > 0 {
+		return errors.New("can't optimize a workbook with streamed rows")
+	}
+	wb.pruneSharedStrings()
+	wb.pruneStyles()
+	return nil
+}
+
+// pruneSharedStrings removes the strings that aren't referenced by any cell
+// from the shared strings table, and merges duplicate strings, updating the
+// cells to the new indices.  Streamed rows aren't visited, so it must not be
+// used while rows are being streamed.
+func (wb *Workbook) pruneSharedStrings() {
+	ss := wb.SharedStrings
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	cells := []*sml.CT_Cell{}
+	used := make([]bool, len(ss.x.Si))
+	for _, ws := range wb.xws {
+		for _, r := range ws.SheetData.Row {
+			for _, c := range r.C {
+				if c.TAttr != sml.ST_CellTypeS || c.V == nil {
+					continue
+				}
+				id, err := strconv.Atoi(*c.V)
+				if err != nil || id < 0 || id >= len(used) {
+					continue
+				}
+				used[id] = true
+				cells = append(cells, c)
+			}
+		}
+	}
+
+	// keep the strings in their original order
+	ids := make([]int, len(ss.x.Si))
+	si := []*sml.CT_Rst{}
+	plain := map[string]int{}
+	for id, rst := range ss.x.Si {
+		if !used[id] {
+			continue
+		}
+		// strings with formatted runs are kept as they are
+		if rst.T != nil && len(rst.R) == 0 {
+			if dup, ok := plain[*rst.T]; ok {
+				ids[id] = dup
+				continue
+			}
+			plain[*rst.T] = len(si)
+		}
+		ids[id] = len(si)
+		si = append(si, rst)
+	}
+	for _, c := range cells {
+		id, _ := strconv.Atoi(*c.V)
+		*c.V = strconv.Itoa(ids[id])
+	}
+
+	ss.x.Si = si
+	ss.x.CountAttr = unioffice.Uint32(uint32(len(si)))
+	ss.x.UniqueCountAttr = ss.x.CountAttr
+	for k := range ss.cachedIDs {
+		delete(ss.cachedIDs, k)
+	}
+	for k, id := range plain {
+		ss.cachedIDs[k] = id
+	}
+}
+
+// pruneStyles removes the cell formats that aren't used by any cell, row or
+// column, along with the fonts, fills, borders and number formats that are
+// only used by them.  The default records that Excel expects at the start of
+// each list are always kept.  Cell style formats and differential formats are
+// left as they are.
+func (wb *Workbook) pruneStyles() {
+	x := wb.StyleSheet.X()
+	if x.CellXfs == nil {
+		return
+	}
+
+	// cell formats
+	usedXfs := make([]bool, len(x.CellXfs.Xf))
+	use := func(id *uint32) {
+		if id != nil && int(*id) < len(usedXfs) {
+			usedXfs[*id] = true
+		}
+	}
+	if len(usedXfs) > 0 {
+		usedXfs[0] = true
+	}
+	for _, ws := range wb.xws {
+		for _, cols := range ws.Cols {
+			for _, col := range cols.Col {
+				use(col.StyleAttr)
+			}
+		}
+		for _, r := range ws.SheetData.Row {
+			use(r.SAttr)
+			for _, c := range r.C {
+				use(c.SAttr)
+			}
+		}
+	}
+	xfIDs := make([]uint32, len(x.CellXfs.Xf))
+	xfs := []*sml.CT_Xf{}
+	for i, xf := range x.CellXfs.Xf {
+		if usedXfs[i] {
+			xfIDs[i] = uint32(len(xfs))
+			xfs = append(xfs, xf)
+		}
+	}
+	remap := func(id *uint32) {
+		if id != nil && int(*id) < len(xfIDs) {
+			*id = xfIDs[*id]
+		}
+	}
+	for _, ws := range wb.xws {
+		for _, cols := range ws.Cols {
+			for _, col := range cols.Col {
+				remap(col.StyleAttr)
+			}
+		}
+		for _, r := range ws.SheetData.Row {
+			remap(r.SAttr)
+			for _, c := range r.C {
+				remap(c.SAttr)
+			}
+		}
+	}
+	x.CellXfs.Xf = xfs
+	x.CellXfs.CountAttr = unioffice.Uint32(uint32(len(xfs)))
+
+	// the records the remaining formats refer to
+	allXfs := append([]*sml.CT_Xf{}, xfs...)
+	if x.CellStyleXfs != nil {
+		allXfs = append(allXfs, x.CellStyleXfs.Xf...)
+	}
+	if x.Fonts != nil {
+		ids := pruneRecords(len(x.Fonts.Font), 1, allXfs, func(xf *sml.CT_Xf) *uint32 { return xf.FontIdAttr })
+		fonts := []*sml.CT_Font{}
+		for i, f := range x.Fonts.Font {
+			if ids[i] >= 0 {
+				fonts = append(fonts, f)
+			}
+		}
+		x.Fonts.Font = fonts
+		x.Fonts.CountAttr = unioffice.Uint32(uint32(len(fonts)))
+	}
+	if x.Fills != nil {
+		// Excel reserves the first two fills
+		ids := pruneRecords(len(x.Fills.Fill), 2, allXfs, func(xf *sml.CT_Xf) *uint32 { return xf.FillIdAttr })
+		fills := []*sml.CT_Fill{}
+		for i, f := range x.Fills.Fill {
+			if ids[i] >= 0 {
+				fills = append(fills, f)
+			}
+		}
+		x.Fills.Fill = fills
+		x.Fills.CountAttr = unioffice.Uint32(uint32(len(fills)))
+	}
+	if x.Borders != nil {
+		ids := pruneRecords(len(x.Borders.Border), 1, allXfs, func(xf *sml.CT_Xf) *uint32 { return xf.BorderIdAttr })
+		borders := []*sml.CT_Border{}
+		for i, b := range x.Borders.Border {
+			if ids[i] >= 0 {
+				borders = append(borders, b)
+			}
+		}
+		x.Borders.Border = borders
+		x.Borders.CountAttr = unioffice.Uint32(uint32(len(borders)))
+	}
+
+	// custom number formats are referred to by ID rather than by index, so
+	// unused ones are removed without renumbering
+	if x.NumFmts != nil {
+		used := map[uint32]struct{}{}
+		for _, xf := range allXfs {
+			if xf.NumFmtIdAttr != nil {
+				used[*xf.NumFmtIdAttr] = struct{}{}
+			}
+		}
+		fmts := []*sml.CT_NumFmt{}
+		for _, nf := range x.NumFmts.NumFmt {
+			if _, ok := used[nf.NumFmtIdAttr]; ok {
+				fmts = append(fmts, nf)
+			}
+		}
+		x.NumFmts.NumFmt = fmts
+		x.NumFmts.CountAttr = unioffice.Uint32(uint32(len(fmts)))
+		if len(fmts) == 0 {
+			x.NumFmts = nil
+		}
+	}
+}
+
+// pruneRecords determines which of n records are referred to by the formats,
+// always keeping the first keep records, and updates the references to the new
+// indices.  It returns the new index of each record, or -1 if the record isn't
+// used.
+func pruneRecords(n, keep int, xfs []*sml.CT_Xf, ref func(xf *sml.CT_Xf) *uint32) []int {
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = -1
+		if i < keep {
+			ids[i] = 0
+		}
+	}
+	for _, xf := range xfs {
+		if id := ref(xf); id != nil && int(*id) < n {
+			ids[*id] = 0
+		}
+	}
+	next := 0
+	for i := range ids {
+		if ids[i] >= 0 {
+			ids[i] = next
+			next++
+		}
+	}
+	for _, xf := range xfs {
+		if id := ref(xf); id != nil && int(*id) < n {
+			*id = uint32(ids[*id])
+		}
+	}
+	return ids
+}
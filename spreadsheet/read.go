@@ -9,6 +9,8 @@ package spreadsheet
 
 import (
 	"archive/zip"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -16,11 +18,25 @@ import (
 	"os"
 
 	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/common/encryption"
 	"github.com/unidoc/unioffice/zippkg"
 )
 
+// ErrInvalidPassword is returned by ReadEncrypted when the password is wrong.
+var ErrInvalidPassword = encryption.ErrInvalidPassword
+
+// ErrEncrypted is returned when reading a password protected workbook without
+// a password.
+var ErrEncrypted = errors.New("workbook is encrypted, use ReadEncrypted")
+
 // Read reads a workbook from an io.Reader(.xlsx).
 func Read(r io.ReaderAt, size int64) (*Workbook, error) {
+	return ReadWithOptions(r, size, zippkg.ReadOptions{})
+}
+
+// ReadWithOptions reads a workbook from an io.Reader, applying the limits in
+// opts before any of its content is decompressed.
+func ReadWithOptions(r io.ReaderAt, size int64, opts zippkg.ReadOptions) (*Workbook, error) {
 	wb := New()
 	td, err := ioutil.TempDir("", "gooxml-xlsx")
 	if err != nil {
@@ -30,11 +46,19 @@ func Read(r io.ReaderAt, size int64) (*Workbook, error) {
 
 	zr, err := zip.NewReader(r, size)
 	if err != nil {
+		hdr := make([]byte, 8)
+		if n, _ := r.ReadAt(hdr, 0); encryption.IsEncrypted(hdr[:n]) {
+			return nil, ErrEncrypted
+		}
 		return nil, fmt.Errorf("parsing zip: %s", err)
 	}
 
 	files := []*zip.File{}
 	files = append(files, zr.File...)
+	if err := opts.CheckSize(files); err != nil {
+		return nil, err
+	}
+
 	decMap := zippkg.DecodeMap{}
 	decMap.SetOnNewRelationshipFunc(wb.onNewRelationship)
 	// we should discover all contents by starting with these two files
@@ -57,6 +81,26 @@ func Read(r io.ReaderAt, size int64) (*Workbook, error) {
 	return wb, nil
 }
 
+// ReadEncrypted reads a password protected workbook from an io.Reader(.xlsx),
+// decrypting it with password.  Workbooks encrypted with either the agile
+// encryption of Office 2010 and later or the standard encryption of Office
+// 2007 can be read.  ErrInvalidPassword is returned if the password is wrong.
+// Unencrypted workbooks are read as is.
+func ReadEncrypted(r io.ReaderAt, size int64, password string) (*Workbook, error) {
+	data, err := ioutil.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	if !encryption.IsEncrypted(data) {
+		return Read(bytes.NewReader(data), size)
+	}
+	pkg, err := encryption.Decrypt(data, password)
+	if err != nil {
+		return nil, err
+	}
+	return Read(bytes.NewReader(pkg), int64(len(pkg)))
+}
+
 // Open opens and reads a workbook from a file (.xlsx).
 func Open(filename string) (*Workbook, error) {
 	f, err := os.Open(filename)
@@ -17,14 +17,19 @@ import (
 )
 
 // CellReference is a parsed reference to a cell.  Input is of the form 'A1',
-// '$C$2', etc.
+// '$C$2', 'Sheet1!B3', etc.
 type CellReference struct {
-	RowIdx         uint32
-	ColumnIdx      uint32
+	// RowIdx is the one based row number as it appears in the reference, so
+	// 'A1' has a RowIdx of 1.
+	RowIdx uint32
+	// ColumnIdx is the zero based column index, so 'A1' has a ColumnIdx of 0.
+	ColumnIdx uint32
+	// Column is the column name as it appears in the reference, e.g. 'A'.
 	Column         string
 	AbsoluteColumn bool
 	AbsoluteRow    bool
-	SheetName      string
+	// SheetName is the sheet name prefix, if any, including any quotes.
+	SheetName string
 }
 
 // String returns a string representation of CellReference.
@@ -42,7 +47,8 @@ func (c CellReference) String() string {
 }
 
 // ParseCellReference parses a cell reference of the form 'A10' and splits it
-// into column/row segments.
+// into column/row segments.  Absolute markers ('$A$10') and a sheet name
+// prefix ('Sheet1!A10' or 'My Sheet'!A10) are recognized.
 func ParseCellReference(s string) (CellReference, error) {
 	s = strings.TrimSpace(s)
 	if len(s) < 2 {
@@ -50,10 +56,12 @@ func ParseCellReference(s string) (CellReference, error) {
 	}
 
 	r := CellReference{}
-	sl := strings.Split(s, "!")
-	if len(sl) == 2 {
-		r.SheetName = sl[0]
-		s = sl[1]
+	if idx := strings.LastIndex(s, "!"); idx != -1 {
+		r.SheetName = s[:idx]
+		s = s[idx+1:]
+		if r.SheetName == "" || s == "" {
+			return CellReference{}, fmt.Errorf("invalid sheet reference %s", r.SheetName+"!"+s)
+		}
 	}
 	// check for absolute column
 	if s[0] == '$' {
@@ -78,6 +86,9 @@ lfor:
 	}
 
 	r.Column = s[0:split]
+	if !isColumn(r.Column) {
+		return CellReference{}, fmt.Errorf("invalid column %s", r.Column)
+	}
 
 	if s[split] == '$' {
 		r.AbsoluteRow = true
@@ -89,6 +100,9 @@ lfor:
 	if err != nil {
 		return CellReference{}, fmt.Errorf("error parsing row: %s", err)
 	}
+	if r64 == 0 {
+		return CellReference{}, errors.New("row numbers start at 1")
+	}
 	r.RowIdx = uint32(r64)
 
 	return r, nil
@@ -0,0 +1,14 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+// Package reference is used to parse and build A1 style cell, column and range
+// references.
+//
+// Row numbers are one based and kept as they appear in a reference, so 'B3'
+// parses to a RowIdx of 3.  Column indices are zero based, so 'B3' parses to a
+// ColumnIdx of 1 and IndexToColumn(1) is "B".
+package reference
@@ -11,7 +11,9 @@ import (
 	"strings"
 )
 
-// ColumnToIndex maps a column to a zero based index (e.g. A = 0, B = 1, AA = 26)
+// ColumnToIndex maps a column to a zero based index (e.g. A = 0, B = 1, AA =
+// 26, XFD = 16383).  The column name is case insensitive and must consist only
+// of letters, see ParseColumnReference for a validating alternative.
 func ColumnToIndex(col string) uint32 {
 	col = strings.ToUpper(col)
 	res := uint32(0)
@@ -22,7 +24,8 @@ func ColumnToIndex(col string) uint32 {
 	return res - 1
 }
 
-// IndexToColumn maps a column number to a column name (e.g. 0 = A, 1 = B, 26 = AA)
+// IndexToColumn maps a zero based column index to a column name (e.g. 0 = A, 1
+// = B, 26 = AA)
 func IndexToColumn(col uint32) string {
 	var a [64 + 1]byte
 	i := len(a)
@@ -39,3 +42,17 @@ func IndexToColumn(col uint32) string {
 
 	return string(a[i:])
 }
+
+// isColumn returns true if col is a non-empty sequence of letters.
+func isColumn(col string) bool {
+	if col == "" {
+		return false
+	}
+	for i := 0; i < len(col); i++ {
+		c := col[i]
+		if (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+	return true
+}
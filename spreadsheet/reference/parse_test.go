@@ -34,6 +34,18 @@ func TestParseCellReference(t *testing.T) {
 		{"A$1", reference.CellReference{RowIdx: 1, ColumnIdx: 0, Column: "A", AbsoluteRow: true}, false},
 		{"$A$1", reference.CellReference{RowIdx: 1, ColumnIdx: 0, Column: "A", AbsoluteRow: true, AbsoluteColumn: true}, false},
 		{"$D$15", reference.CellReference{RowIdx: 15, ColumnIdx: 3, Column: "D", AbsoluteRow: true, AbsoluteColumn: true}, false},
+		{"AA1", reference.CellReference{RowIdx: 1, ColumnIdx: 26, Column: "AA"}, false},
+		{"ZZ20", reference.CellReference{RowIdx: 20, ColumnIdx: 701, Column: "ZZ"}, false},
+		{"XFD1048576", reference.CellReference{RowIdx: 1048576, ColumnIdx: 16383, Column: "XFD"}, false},
+		{"Sheet1!B2", reference.CellReference{RowIdx: 2, ColumnIdx: 1, Column: "B", SheetName: "Sheet1"}, false},
+		{"'A!B'!$C$3", reference.CellReference{RowIdx: 3, ColumnIdx: 2, Column: "C", SheetName: "'A!B'", AbsoluteColumn: true, AbsoluteRow: true}, false},
+		{"A0", reference.CellReference{}, true},
+		{"A-1", reference.CellReference{}, true},
+		{"A 1", reference.CellReference{}, true},
+		{"Ä1", reference.CellReference{}, true},
+		{"!A1", reference.CellReference{}, true},
+		{"Sheet1!", reference.CellReference{}, true},
+		{"A99999999999", reference.CellReference{}, true},
 	}
 	for _, tc := range td {
 		cref, err := reference.ParseCellReference(tc.Inp)
@@ -62,6 +74,9 @@ func TestParseCellReference(t *testing.T) {
 		if cref.AbsoluteColumn != tc.Exp.AbsoluteColumn {
 			t.Errorf("expected absolute-column = %v for %s, got %v", tc.Exp.AbsoluteColumn, tc.Inp, cref.AbsoluteColumn)
 		}
+		if cref.SheetName != tc.Exp.SheetName {
+			t.Errorf("expected sheet name = %s for %s, got %s", tc.Exp.SheetName, tc.Inp, cref.SheetName)
+		}
 	}
 }
 
@@ -96,6 +111,18 @@ func TestParseCellRangeReference(t *testing.T) {
 			reference.CellReference{},
 			reference.CellReference{},
 			true},
+		{"Sheet1!B5:A1",
+			reference.CellReference{RowIdx: 5, ColumnIdx: 1, Column: "B", SheetName: "Sheet1"},
+			reference.CellReference{RowIdx: 1, ColumnIdx: 0, Column: "A", SheetName: "Sheet1"},
+			false},
+		{"A1:XFD1048576",
+			reference.CellReference{RowIdx: 1, ColumnIdx: 0, Column: "A"},
+			reference.CellReference{RowIdx: 1048576, ColumnIdx: 16383, Column: "XFD"},
+			false},
+		{"A1:B0",
+			reference.CellReference{},
+			reference.CellReference{},
+			true},
 	}
 
 	for _, tc := range td {
@@ -131,6 +158,9 @@ func TestColumnToIndex(t *testing.T) {
 		{"AC", 28},
 		{"BZ", 77},
 		{"CA", 78},
+		{"ZZ", 701},
+		{"AAA", 702},
+		{"XFD", 16383},
 		{"GOOXML", 90304485},
 	}
 	for _, tc := range td {
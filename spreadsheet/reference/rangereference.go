@@ -12,14 +12,15 @@ import (
 	"strings"
 )
 
-// ParseRangeReference splits a range reference of the form "A1:B5" into its
-// components.
+// ParseRangeReference splits a range reference of the form "A1:B5" or
+// "Sheet1!A1:B5" into its components.  The sheet name, if present, is copied
+// to both references.  The references are returned in the order they appear
+// and are not normalized.
 func ParseRangeReference(s string) (from, to CellReference, err error) {
 	sheetName := ""
-	sp0 := strings.Split(s, "!")
-	if len(sp0) == 2 {
-		sheetName = sp0[0]
-		s = sp0[1]
+	if idx := strings.LastIndex(s, "!"); idx != -1 {
+		sheetName = s[:idx]
+		s = s[idx+1:]
 	}
 	sp := strings.Split(s, ":")
 	if len(sp) != 2 {
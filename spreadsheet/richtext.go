@@ -7,7 +7,11 @@
 
 package spreadsheet
 
-import "github.com/unidoc/unioffice/schema/soo/sml"
+import (
+	"strings"
+
+	"github.com/unidoc/unioffice/schema/soo/sml"
+)
 
 // RichText is a container for the rich text within a cell. It's similar to a
 // paragaraph for a document, except a cell can only contain one rich text item.
@@ -26,3 +30,29 @@ func (r RichText) AddRun() RichTextRun {
 	r.x.R = append(r.x.R, elt)
 	return RichTextRun{elt}
 }
+
+// Runs returns the formatted runs of the rich text.
+func (r RichText) Runs() []RichTextRun {
+	ret := []RichTextRun{}
+	for _, elt := range r.x.R {
+		ret = append(ret, RichTextRun{elt})
+	}
+	return ret
+}
+
+// Text returns the unformatted text, concatenating the text of each run.
+func (r RichText) Text() string {
+	return rstText(r.x)
+}
+
+// rstText returns the plain text of a possibly rich string.
+func rstText(x *sml.CT_Rst) string {
+	if x.T != nil {
+		return *x.T
+	}
+	sb := strings.Builder{}
+	for _, elt := range x.R {
+		sb.WriteString(elt.T)
+	}
+	return sb.String()
+}
@@ -29,6 +29,41 @@ func (r RichTextRun) SetText(s string) {
 	r.x.T = s
 }
 
+// Text returns the text of the run.
+func (r RichTextRun) Text() string {
+	return r.x.T
+}
+
+// IsBold returns true if the run is bold.
+func (r RichTextRun) IsBold() bool {
+	return r.x.RPr != nil && r.x.RPr.B != nil &&
+		(r.x.RPr.B.ValAttr == nil || *r.x.RPr.B.ValAttr)
+}
+
+// IsItalic returns true if the run is italic.
+func (r RichTextRun) IsItalic() bool {
+	return r.x.RPr != nil && r.x.RPr.I != nil &&
+		(r.x.RPr.I.ValAttr == nil || *r.x.RPr.I.ValAttr)
+}
+
+// Color returns the RGB color of the run and true, or false if the run has no
+// RGB color set.
+func (r RichTextRun) Color() (color.Color, bool) {
+	if r.x.RPr == nil || r.x.RPr.Color == nil || r.x.RPr.Color.RgbAttr == nil {
+		return color.Color{}, false
+	}
+	rgb := *r.x.RPr.Color.RgbAttr
+	// colors read from files are usually ARGB
+	if len(rgb) == 8 {
+		rgb = rgb[2:]
+	}
+	c, err := color.FromHex(rgb)
+	if err != nil {
+		return color.Color{}, false
+	}
+	return c, true
+}
+
 func (r RichTextRun) ensureRpr() {
 	if r.x.RPr == nil {
 		r.x.RPr = sml.NewCT_RPrElt()
@@ -16,6 +16,9 @@ import (
 	"github.com/unidoc/unioffice/spreadsheet/reference"
 )
 
+// maxOutlineLevel is the maximum outline level supported for rows and columns.
+const maxOutlineLevel = 7
+
 // Row is a row within a spreadsheet.
 type Row struct {
 	w *Workbook
@@ -25,6 +28,7 @@ type Row struct {
 
 // X returns the inner wrapped XML type.
 func (r Row) X() *sml.CT_Row {
+	r.w.exposeRows(r.s)
 	return r.x
 }
 
@@ -36,18 +40,28 @@ func (r Row) RowNumber() uint32 {
 	return 0
 }
 
-// SetHeight sets the row height in points.
+// SetHeight sets the row height.  The height is stored in points.
 func (r Row) SetHeight(d measurement.Distance) {
-	r.x.HtAttr = unioffice.Float64(float64(d))
+	r.x.HtAttr = unioffice.Float64(float64(d / measurement.Point))
 	r.x.CustomHeightAttr = unioffice.Bool(true)
 }
 
-// SetHeightAuto sets the row height to be automatically determined.
+// SetHeightAuto sets the row height to be automatically determined, clearing
+// any custom height.
 func (r Row) SetHeightAuto() {
 	r.x.HtAttr = nil
 	r.x.CustomHeightAttr = nil
 }
 
+// Height returns the custom height of the row and whether a custom height is
+// set.
+func (r Row) Height() (measurement.Distance, bool) {
+	if r.x.HtAttr == nil || r.x.CustomHeightAttr == nil || !*r.x.CustomHeightAttr {
+		return 0, false
+	}
+	return measurement.Distance(*r.x.HtAttr) * measurement.Point, true
+}
+
 // IsHidden returns whether the row is hidden or not.
 func (r Row) IsHidden() bool {
 	return r.x.HiddenAttr != nil && *r.x.HiddenAttr
@@ -62,10 +76,49 @@ func (r Row) SetHidden(hidden bool) {
 	}
 }
 
+// SetStyle sets the default style for the row.  Excel applies it to cells
+// subsequently entered in the row; existing cells keep their own style.  A
+// cell's own style takes precedence over the row style, which in turn takes
+// precedence over a column style.
+func (r Row) SetStyle(cs CellStyle) {
+	r.x.SAttr = unioffice.Uint32(cs.Index())
+	r.x.CustomFormatAttr = unioffice.Bool(true)
+}
+
+// OutlineLevel returns the outline (grouping) level of the row.
+func (r Row) OutlineLevel() uint8 {
+	if r.x.OutlineLevelAttr == nil {
+		return 0
+	}
+	return *r.x.OutlineLevelAttr
+}
+
+// SetOutlineLevel sets the outline (grouping) level of the row, from 0 for an
+// ungrouped row up to 7.
+func (r Row) SetOutlineLevel(lvl uint8) {
+	if lvl > maxOutlineLevel {
+		unioffice.Log("outline level %d exceeds the maximum of %d", lvl, maxOutlineLevel)
+		lvl = maxOutlineLevel
+	}
+	if lvl == 0 {
+		r.x.OutlineLevelAttr = nil
+		return
+	}
+	r.x.OutlineLevelAttr = unioffice.Uint8(lvl)
+
+	// the outline bar is sized to the maximum level used on the sheet
+	fp := sheetFormatPr(r.s)
+	if cur := fp.OutlineLevelRowAttr; cur == nil || *cur < lvl {
+		fp.OutlineLevelRowAttr = unioffice.Uint8(lvl)
+	}
+}
+
 // AddCell adds a cell to a spreadsheet.
 func (r Row) AddCell() Cell {
+	ci := r.currentCellIndex()
 	numCells := uint32(len(r.x.C))
 	var nextCellID *string
+	nextIdx := numCells
 	if numCells > 0 {
 		prevCellName := unioffice.Stringf("%s%d", reference.IndexToColumn(numCells-1), r.RowNumber())
 		// previous cell has an expected name
@@ -79,7 +132,7 @@ func (r Row) AddCell() Cell {
 
 	// fast path failed, so find the last cell and add another
 	if nextCellID == nil {
-		nextIdx := uint32(0)
+		nextIdx = 0
 		for _, c := range r.x.C {
 			if c.RAttr != nil {
 				cref, _ := reference.ParseCellReference(*c.RAttr)
@@ -91,6 +144,10 @@ func (r Row) AddCell() Cell {
 		nextCellID = unioffice.Stringf("%s%d", reference.IndexToColumn(nextIdx), r.RowNumber())
 	}
 	c.RAttr = nextCellID
+	if ci != nil {
+		ci.add(c, nextIdx)
+		ci.update(r.x.C)
+	}
 	return Cell{r.w, r.s, r.x, c}
 }
 
@@ -121,6 +178,14 @@ func (r Row) Cells() []Cell {
 	return ret
 }
 
+// Clear clears the values and formulas of all cells in the row, keeping the
+// cells, their styles and the row's formatting.
+func (r Row) Clear() {
+	for _, c := range r.x.C {
+		Cell{r.w, r.s, r.x, c}.Clear()
+	}
+}
+
 // AddNamedCell adds a new named cell to a row and returns it. You should
 // normally prefer Cell() as it will return the existing cell if the cell
 // already exists, while AddNamedCell will duplicate the cell creating an
@@ -131,6 +196,14 @@ func (r Row) AddNamedCell(col string) Cell {
 
 	indexToInsert := -1
 	colIdx := reference.ColumnToIndex(col)
+	ci := r.cellIndex()
+	if ci != nil && colIdx > ci.maxCol {
+		// follows every existing cell
+		r.x.C = append(r.x.C, c)
+		ci.add(c, colIdx)
+		ci.update(r.x.C)
+		return Cell{r.w, r.s, r.x, c}
+	}
 	for i, cell := range r.x.C {
 		cr, err := reference.ParseCellReference(*cell.RAttr)
 		if err != nil {
@@ -146,6 +219,10 @@ func (r Row) AddNamedCell(col string) Cell {
 	} else {
 		r.x.C = append(r.x.C[:indexToInsert], append([]*sml.CT_Cell{c}, r.x.C[indexToInsert:]...)...)
 	}
+	if ci != nil {
+		ci.add(c, colIdx)
+		ci.update(r.x.C)
+	}
 
 	return Cell{r.w, r.s, r.x, c}
 }
@@ -153,6 +230,16 @@ func (r Row) AddNamedCell(col string) Cell {
 // Cell retrieves or adds a new cell to a row. Col is the column (e.g. 'A', 'B')
 func (r Row) Cell(col string) Cell {
 	name := fmt.Sprintf("%s%d", col, r.RowNumber())
+	if ci := r.cellIndex(); ci != nil {
+		if c, ok := ci.cell(name); ok {
+			if c != nil {
+				return Cell{r.w, r.s, r.x, c}
+			}
+			return r.AddNamedCell(col)
+		}
+		// renamed through X(), so fall back to searching the row
+		r.w.cellIndexes.remove(r.x)
+	}
 	for _, c := range r.x.C {
 		if c.RAttr != nil && *c.RAttr == name {
 			return Cell{r.w, r.s, r.x, c}
@@ -166,11 +253,15 @@ func (r Row) Cell(col string) Cell {
 // moved rows.
 func (r Row) renumberAs(rowNumber uint32) {
 	r.x.RAttr = unioffice.Uint32(rowNumber)
-	for _, c := range r.Cells() {
-		cref, err := reference.ParseCellReference(c.Reference())
+	r.w.invalidateRowIndex(r.s)
+	for _, c := range r.x.C {
+		if c.RAttr == nil {
+			continue
+		}
+		cref, err := reference.ParseCellReference(*c.RAttr)
 		if err == nil {
 			newRef := fmt.Sprintf("%s%d", cref.Column, rowNumber)
-			c.x.RAttr = unioffice.String(newRef)
+			c.RAttr = unioffice.String(newRef)
 		}
 	}
 }
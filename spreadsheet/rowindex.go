@@ -0,0 +1,148 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import "github.com/unidoc/unioffice/schema/soo/sml"
+
+// rowIndex caches the highest row number of a worksheet and, once a row is
+// looked up by number, the rows by number so rows can be added and found
+// without scanning every existing row.  As rows can also be modified through
+// X(), the cache is only trusted while the number of rows and the last row are
+// unchanged since it was updated, otherwise it is rebuilt.  Rows renumbered
+// through X() are found by rebuilding the rows by number when a lookup misses
+// after X() was called.
+type rowIndex struct {
+	maxRow  uint32
+	count   int
+	last    *sml.CT_Row
+	lastNum uint32
+	rows    map[uint32]*sml.CT_Row
+
+	// exposed is true if the rows may have been modified through X() since
+	// the rows by number were built
+	exposed bool
+}
+
+// rowIndex returns the up to date row index for the sheet.
+func (s Sheet) rowIndex() *rowIndex {
+	if s.w.rowIndexes == nil {
+		s.w.rowIndexes = map[*sml.Worksheet]*rowIndex{}
+	}
+	rows := s.x.SheetData.Row
+	if ri, ok := s.w.rowIndexes[s.x]; ok && ri.isCurrent(rows) {
+		return ri
+	}
+	ri := &rowIndex{}
+	for _, r := range rows {
+		ri.add(r)
+	}
+	ri.update(rows)
+	s.w.rowIndexes[s.x] = ri
+	return ri
+}
+
+// invalidateRowIndex discards the row index of a worksheet, it must be called
+// whenever existing rows are renumbered.
+func (wb *Workbook) invalidateRowIndex(ws *sml.Worksheet) {
+	delete(wb.rowIndexes, ws)
+}
+
+// exposeRows records that the rows of a worksheet may be modified through X().
+func (wb *Workbook) exposeRows(ws *sml.Worksheet) {
+	if wb == nil {
+		return
+	}
+	if ri, ok := wb.rowIndexes[ws]; ok {
+		ri.exposed = true
+	}
+}
+
+func (ri *rowIndex) isCurrent(rows []*sml.CT_Row) bool {
+	if len(rows) != ri.count {
+		return false
+	}
+	if ri.count == 0 {
+		return true
+	}
+	last := rows[ri.count-1]
+	return last == ri.last && last.RAttr != nil && *last.RAttr == ri.lastNum
+}
+
+// add records a row that has been added to the sheet.
+func (ri *rowIndex) add(r *sml.CT_Row) {
+	if r.RAttr == nil {
+		return
+	}
+	if *r.RAttr > ri.maxRow {
+		ri.maxRow = *r.RAttr
+	}
+	if ri.rows != nil {
+		// keep the first of any rows that reuse a number
+		if _, ok := ri.rows[*r.RAttr]; !ok {
+			ri.rows[*r.RAttr] = r
+		}
+	}
+}
+
+// remove records a row that has been removed from the sheet, leaving rows.
+func (ri *rowIndex) remove(r *sml.CT_Row, rows []*sml.CT_Row) {
+	if r.RAttr != nil {
+		if ri.rows != nil && ri.rows[*r.RAttr] == r {
+			delete(ri.rows, *r.RAttr)
+		}
+		if *r.RAttr == ri.maxRow {
+			ri.maxRow = 0
+			for _, r := range rows {
+				if r.RAttr != nil && *r.RAttr > ri.maxRow {
+					ri.maxRow = *r.RAttr
+				}
+			}
+		}
+	}
+	ri.update(rows)
+}
+
+// update records the current rows after rows have been added or removed by
+// the sheet.
+func (ri *rowIndex) update(rows []*sml.CT_Row) {
+	ri.count = len(rows)
+	ri.last = nil
+	ri.lastNum = 0
+	if ri.count > 0 {
+		ri.last = rows[ri.count-1]
+		if ri.last.RAttr != nil {
+			ri.lastNum = *ri.last.RAttr
+		}
+	}
+}
+
+// row returns the row with a given number, or nil if there is no such row.
+func (ri *rowIndex) row(rows []*sml.CT_Row, rowNum uint32) *sml.CT_Row {
+	if ri.rows == nil {
+		ri.buildRows(rows)
+	}
+	r, ok := ri.rows[rowNum]
+	if ok && (r.RAttr == nil || *r.RAttr != rowNum) || !ok && ri.exposed {
+		// possibly renumbered through X(), so rebuild and try again
+		ri.buildRows(rows)
+		r, ok = ri.rows[rowNum]
+	}
+	if !ok {
+		return nil
+	}
+	return r
+}
+
+func (ri *rowIndex) buildRows(rows []*sml.CT_Row) {
+	ri.rows = make(map[uint32]*sml.CT_Row, len(rows))
+	ri.maxRow = 0
+	ri.exposed = false
+	for _, r := range rows {
+		ri.add(r)
+	}
+}
@@ -8,22 +8,26 @@ package spreadsheet
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/unidoc/unioffice"
 	"github.com/unidoc/unioffice/schema/soo/sml"
 )
 
 // SharedStrings is a shared strings table, where string data can be placed
-// outside of the sheet contents and referenced from a sheet.
+// outside of the sheet contents and referenced from a sheet.  Strings can be
+// added and retrieved concurrently.
 type SharedStrings struct {
 	x         *sml.Sst
 	cachedIDs map[string]int
+	mu        *sync.RWMutex
 }
 
 // NewSharedStrings constructs a new Shared Strings table.
 func NewSharedStrings() SharedStrings {
 	return SharedStrings{x: sml.NewSst(),
-		cachedIDs: make(map[string]int)}
+		cachedIDs: make(map[string]int),
+		mu:        &sync.RWMutex{}}
 }
 
 // X returns the inner wrapped XML type.
@@ -33,6 +37,8 @@ func (s SharedStrings) X() *sml.Sst {
 
 // AddString adds a string to the shared string cache.
 func (s SharedStrings) AddString(v string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if id, ok := s.cachedIDs[v]; ok {
 		return id
 	}
@@ -48,15 +54,24 @@ func (s SharedStrings) AddString(v string) int {
 
 // GetString retrieves a string from the shared strings table by index.
 func (s SharedStrings) GetString(id int) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	if id < 0 {
 		return "", fmt.Errorf("invalid string index %d, must be > 0", id)
 	}
-	if id > len(s.x.Si) {
+	if id >= len(s.x.Si) {
 		return "", fmt.Errorf("invalid string index %d, table only has %d values", id, len(s.x.Si))
 	}
-	si := s.x.Si[id]
-	if si.T != nil {
-		return *si.T, nil
+	return rstText(s.x.Si[id]), nil
+}
+
+// getRst retrieves a string, which may contain formatted runs, from the shared
+// strings table by index.
+func (s SharedStrings) getRst(id int) (*sml.CT_Rst, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if id < 0 || id >= len(s.x.Si) {
+		return nil, false
 	}
-	return "", nil
+	return s.x.Si[id], true
 }
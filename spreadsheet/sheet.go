@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"log"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/unidoc/unioffice/spreadsheet/formula"
@@ -19,7 +20,9 @@ import (
 	"github.com/unidoc/unioffice/spreadsheet/update"
 
 	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/color"
 	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/measurement"
 	"github.com/unidoc/unioffice/schema/soo/sml"
 	"github.com/unidoc/unioffice/vmldrawing"
 )
@@ -37,6 +40,7 @@ func (s Sheet) IsValid() bool {
 
 // X returns the inner wrapped XML type.
 func (s Sheet) X() *sml.Worksheet {
+	s.w.exposeRows(s.x)
 	return s.x
 }
 
@@ -44,10 +48,8 @@ func (s Sheet) X() *sml.Worksheet {
 // necessary.
 func (s Sheet) Row(rowNum uint32) Row {
 	// see if the row exists
-	for _, r := range s.x.SheetData.Row {
-		if r.RAttr != nil && *r.RAttr == rowNum {
-			return Row{s.w, s.x, r}
-		}
+	if r := s.rowIndex().row(s.x.SheetData.Row, rowNum); r != nil {
+		return Row{s.w, s.x, r}
 	}
 	// create a new row
 	return s.AddNumberedRow(rowNum)
@@ -67,22 +69,28 @@ func (s Sheet) Cell(cellRef string) Cell {
 // the resulting file will fail validation and fail to open in Office programs. Use
 // Row instead which creates a new row or returns an existing row.
 func (s Sheet) AddNumberedRow(rowNum uint32) Row {
+	ri := s.rowIndex()
 	r := sml.NewCT_Row()
 	r.RAttr = unioffice.Uint32(rowNum)
 	s.x.SheetData.Row = append(s.x.SheetData.Row, r)
 
-	// Excel wants the rows to be sorted
-	sort.Slice(s.x.SheetData.Row, func(i, j int) bool {
-		l := s.x.SheetData.Row[i].RAttr
-		r := s.x.SheetData.Row[j].RAttr
-		if l == nil {
-			return true
-		}
-		if r == nil {
-			return true
-		}
-		return *l < *r
-	})
+	// Excel wants the rows to be sorted, which they already are if the new row
+	// follows every existing row
+	if rowNum <= ri.maxRow {
+		sort.Slice(s.x.SheetData.Row, func(i, j int) bool {
+			l := s.x.SheetData.Row[i].RAttr
+			r := s.x.SheetData.Row[j].RAttr
+			if l == nil {
+				return true
+			}
+			if r == nil {
+				return true
+			}
+			return *l < *r
+		})
+	}
+	ri.add(r)
+	ri.update(s.x.SheetData.Row)
 
 	return Row{s.w, s.x, r}
 }
@@ -100,64 +108,244 @@ func (s Sheet) addNumberedRowFast(rowNum uint32) Row {
 // however it will get confusing. You should prefer to use either automatically
 // numbered rows with AddRow or manually numbered rows with Row/AddNumberedRow
 func (s Sheet) AddRow() Row {
-	maxRowID := uint32(0)
+	ri := s.rowIndex()
+	// the new row follows every existing row, so the rows remain sorted
+	r := s.addNumberedRowFast(ri.maxRow + 1)
+	ri.add(r.x)
+	ri.update(s.x.SheetData.Row)
+	return r
+}
 
-	numRows := uint32(len(s.x.SheetData.Row))
-	// fast path, adding consecutive rows
-	if numRows > 0 && s.x.SheetData.Row[numRows-1].RAttr != nil && *s.x.SheetData.Row[numRows-1].RAttr == numRows {
-		return s.addNumberedRowFast(numRows + 1)
+// AddRows adds n new rows to the end of a sheet in the same way as calling
+// AddRow n times, but growing the sheet's rows only once.
+func (s Sheet) AddRows(n int) []Row {
+	if n <= 0 {
+		return nil
 	}
-
-	// find the max row number
-	for _, r := range s.x.SheetData.Row {
-		if r.RAttr != nil && *r.RAttr > maxRowID {
-			maxRowID = *r.RAttr
-		}
+	s.Reserve(n)
+	ri := s.rowIndex()
+	first := ri.maxRow + 1
+	ret := make([]Row, n)
+	for i := range ret {
+		ret[i] = s.addNumberedRowFast(first + uint32(i))
+		ri.add(ret[i].x)
 	}
+	ri.update(s.x.SheetData.Row)
+	return ret
+}
 
-	return s.AddNumberedRow(maxRowID + 1)
+// Reserve ensures there is capacity for n more rows to be added to the sheet
+// without reallocating, which avoids repeated copying when adding many rows.
+func (s Sheet) Reserve(n int) {
+	rows := s.x.SheetData.Row
+	if n <= 0 || cap(rows)-len(rows) >= n {
+		return
+	}
+	grown := make([]*sml.CT_Row, len(rows), len(rows)+n)
+	copy(grown, rows)
+	s.x.SheetData.Row = grown
 }
 
 // InsertRow inserts a new row into a spreadsheet at a particular row number.  This
 // row will now be the row number specified, and any rows after it will be renumbed.
+// Formulas throughout the workbook, merged cells, defined names, conditional
+// formatting and data validations that refer to the renumbered rows of this
+// sheet are updated to point at the new row numbers.
 func (s Sheet) InsertRow(rowNum int) Row {
 	rIdx := uint32(rowNum)
 
-	// Renumber every row after the row we're inserting
+	// Renumber every row after the row we're inserting, which also recomputes
+	// the cell references
 	for _, r := range s.Rows() {
 		if r.x.RAttr != nil && *r.x.RAttr >= rIdx {
-			*r.x.RAttr++
-			// and we also need to recompute cell references
-			for _, c := range r.Cells() {
-				cref, err := reference.ParseCellReference(c.Reference())
-				if err != nil {
+			r.renumberAs(*r.x.RAttr + 1)
+		}
+	}
+	s.updateAfterRowChange(&update.UpdateQuery{
+		UpdateType:    update.UpdateActionInsertRow,
+		RowIdx:        rIdx,
+		SheetToUpdate: s.Name(),
+	})
+
+	// finally AddNumberedRow will add and re-sort rows
+	return s.AddNumberedRow(rIdx)
+}
+
+// updateAfterRowChange rewrites the references to rows of this sheet that have
+// been renumbered by inserting or removing a row.  This covers formulas
+// throughout the workbook, defined names, and the merged cells, conditional
+// formatting and data validations of the sheet.  References to a removed row
+// become #REF!, and ranges that only covered it are removed.  Anything that
+// isn't affected is left untouched.
+func (s Sheet) updateAfterRowChange(q *update.UpdateQuery) {
+	ownSheetName := s.Name()
+	for _, sheet := range s.w.Sheets() {
+		q.UpdateCurrentSheet = ownSheetName == sheet.Name()
+		for _, r := range sheet.x.SheetData.Row {
+			for _, c := range r.C {
+				if c.F == nil {
 					continue
 				}
-				cref.RowIdx++
-				c.x.RAttr = unioffice.String(cref.String())
+				// shared and array formulas also record the range they apply to
+				if q.UpdateCurrentSheet && c.F.RefAttr != nil {
+					if ref, ok := updateRowsInRef(*c.F.RefAttr, q); ok {
+						c.F.RefAttr = unioffice.String(ref)
+					}
+				}
+				c.F.Content = updateRowsInFormula(c.F.Content, q)
 			}
 		}
 	}
 
-	// check for merged cells, and try to intelligently adjust them
-	// issue #212
-	for _, mc := range s.MergedCells() {
-		from, to, err := reference.ParseRangeReference(mc.Reference())
-		if err != nil {
-			continue
+	// defined names always qualify references with the sheet name
+	q.UpdateCurrentSheet = false
+	if s.w.x.DefinedNames != nil {
+		for _, dn := range s.w.x.DefinedNames.DefinedName {
+			dn.Content = updateRowsInFormula(dn.Content, q)
 		}
-		if int(from.RowIdx) >= rowNum {
-			from.RowIdx++
+	}
+	q.UpdateCurrentSheet = true
+
+	if s.x.MergeCells != nil {
+		merged := s.x.MergeCells.MergeCell[:0]
+		for _, mc := range s.x.MergeCells.MergeCell {
+			if ref, ok := updateRowsInRef(mc.RefAttr, q); ok {
+				mc.RefAttr = ref
+				merged = append(merged, mc)
+			}
 		}
-		if int(to.RowIdx) >= rowNum {
-			to.RowIdx++
+		s.x.MergeCells.MergeCell = merged
+		s.updateMergedCellsCount()
+	}
+
+	cfs := s.x.ConditionalFormatting[:0]
+	for _, cf := range s.x.ConditionalFormatting {
+		if cf.SqrefAttr != nil {
+			*cf.SqrefAttr = updateRowsInSqref(*cf.SqrefAttr, q)
+			if len(*cf.SqrefAttr) == 0 {
+				continue
+			}
+		}
+		for _, rule := range cf.CfRule {
+			for i, f := range rule.Formula {
+				rule.Formula[i] = updateRowsInFormula(f, q)
+			}
 		}
-		ref := fmt.Sprintf("%s:%s", from, to)
-		mc.SetReference(ref)
+		cfs = append(cfs, cf)
 	}
+	s.x.ConditionalFormatting = cfs
 
-	// finally AddNumberedRow will add and re-sort rows
-	return s.AddNumberedRow(rIdx)
+	if s.x.DataValidations != nil {
+		dvs := s.x.DataValidations.DataValidation[:0]
+		for _, dv := range s.x.DataValidations.DataValidation {
+			dv.SqrefAttr = updateRowsInSqref(dv.SqrefAttr, q)
+			if len(dv.SqrefAttr) == 0 {
+				continue
+			}
+			for _, f := range []*string{dv.Formula1, dv.Formula2} {
+				if f != nil {
+					*f = updateRowsInFormula(*f, q)
+				}
+			}
+			dvs = append(dvs, dv)
+		}
+		s.x.DataValidations.DataValidation = dvs
+		s.x.DataValidations.CountAttr = unioffice.Uint32(uint32(len(dvs)))
+	}
+}
+
+// updateRowsInFormula returns a formula with its references updated for an
+// inserted or removed row.  Formulas that can't be parsed or aren't affected
+// are returned unchanged.
+func updateRowsInFormula(f string, q *update.UpdateQuery) string {
+	if f == "" {
+		return f
+	}
+	expr := formula.ParseString(f)
+	if expr == nil {
+		return f
+	}
+	if updated := expr.Update(q).String(); updated != expr.String() {
+		return updated
+	}
+	return f
+}
+
+// updateRowsInRef returns a cell or range reference of the sheet, e.g. "A1:B4",
+// updated for an inserted or removed row, or false if every row of it was
+// removed.
+func updateRowsInRef(ref string, q *update.UpdateQuery) (string, bool) {
+	updated := updateRowsInFormula(ref, q)
+	if strings.Contains(updated, "#REF!") {
+		return "", false
+	}
+	return updated, true
+}
+
+// updateRowsInSqref updates each range of a sequence of references, dropping
+// those that were removed.
+func updateRowsInSqref(sqref sml.ST_Sqref, q *update.UpdateQuery) sml.ST_Sqref {
+	ret := sml.ST_Sqref{}
+	for _, ref := range sqref {
+		if updated, ok := updateRowsInRef(ref, q); ok {
+			ret = append(ret, updated)
+		}
+	}
+	return ret
+}
+
+// RemoveRow removes a row from the sheet. The row numbers of any following rows
+// are left unchanged, use RemoveRowShifting to move them up instead.
+func (s Sheet) RemoveRow(r Row) {
+	for i, xr := range s.x.SheetData.Row {
+		if xr == r.x {
+			s.removeRowAt(i)
+			return
+		}
+	}
+}
+
+// RemoveRowByNumber removes the row with a given row number (1-N). The row
+// numbers of any following rows are left unchanged. Removing a row that does
+// not exist is a no-op.
+func (s Sheet) RemoveRowByNumber(rowNum uint32) {
+	for i, r := range s.x.SheetData.Row {
+		if r.RAttr != nil && *r.RAttr == rowNum {
+			s.removeRowAt(i)
+			return
+		}
+	}
+}
+
+// RemoveRowShifting removes the row with a given row number (1-N) and moves
+// every row below it up by one, renumbering the rows and the references of the
+// cells they contain.  As with InsertRow, formulas throughout the workbook,
+// merged cells, defined names, conditional formatting and data validations
+// that refer to the moved rows are updated, and references to the removed row
+// become #REF!.
+func (s Sheet) RemoveRowShifting(rowNum uint32) {
+	s.RemoveRowByNumber(rowNum)
+	for _, r := range s.Rows() {
+		if r.x.RAttr != nil && *r.x.RAttr > rowNum {
+			r.renumberAs(*r.x.RAttr - 1)
+		}
+	}
+	s.updateAfterRowChange(&update.UpdateQuery{
+		UpdateType:    update.UpdateActionRemoveRow,
+		RowIdx:        rowNum,
+		SheetToUpdate: s.Name(),
+	})
+}
+
+func (s Sheet) removeRowAt(i int) {
+	ri := s.rowIndex()
+	rows := s.x.SheetData.Row
+	removed := rows[i]
+	copy(rows[i:], rows[i+1:])
+	rows[len(rows)-1] = nil
+	s.x.SheetData.Row = rows[:len(rows)-1]
+	ri.remove(removed, s.x.SheetData.Row)
+	s.w.cellIndexes.remove(removed)
 }
 
 // Name returns the sheet name
@@ -170,12 +358,41 @@ func (s Sheet) SetName(name string) {
 	s.cts.NameAttr = name
 }
 
+// IsVisible returns true if the sheet is neither hidden nor very hidden.
+func (s Sheet) IsVisible() bool {
+	return isSheetVisible(s.cts)
+}
+
+// SetVisible controls whether the sheet is visible or hidden.  Hidden sheets
+// can be unhidden by the user from within Excel.  A workbook must have at
+// least one visible sheet, which is checked by Workbook.Validate.
+func (s Sheet) SetVisible(b bool) {
+	if b {
+		s.cts.StateAttr = sml.ST_SheetStateUnset
+	} else {
+		s.cts.StateAttr = sml.ST_SheetStateHidden
+	}
+}
+
+// SetVeryHidden controls whether the sheet is very hidden.  Very hidden sheets
+// aren't listed in Excel's unhide dialog and can only be made visible again
+// programmatically, e.g. via VBA.
+func (s Sheet) SetVeryHidden(b bool) {
+	if b {
+		s.cts.StateAttr = sml.ST_SheetStateVeryHidden
+	} else {
+		s.cts.StateAttr = sml.ST_SheetStateUnset
+	}
+}
+
 // Validate validates the sheet, returning an error if it is found to be invalid.
 func (s Sheet) Validate() error {
 	validators := []func() error{
 		s.validateRowCellNumbers,
+		s.validateCellReferences,
 		s.validateMergedCells,
 		s.validateSheetNames,
+		s.validateTables,
 	}
 	for _, v := range validators {
 		if err := v(); err != nil {
@@ -224,6 +441,52 @@ func (s Sheet) validateRowCellNumbers() error {
 	return nil
 }
 
+// validateCellReferences returns an error if any row or cell references are
+// outside of the bounds of a sheet, are malformed or if a cell reference doesn't
+// match the number of the row containing it
+func (s Sheet) validateCellReferences() error {
+	for _, r := range s.x.SheetData.Row {
+		if r.RAttr != nil && (*r.RAttr == 0 || *r.RAttr > maxRows) {
+			return fmt.Errorf("'%s' row %d is out of range, must be between 1 and %d", s.Name(), *r.RAttr, maxRows)
+		}
+		for _, c := range r.C {
+			if c.RAttr == nil {
+				continue
+			}
+			ref, err := reference.ParseCellReference(*c.RAttr)
+			if err != nil {
+				return fmt.Errorf("'%s' cell %s has an invalid reference: %s", s.Name(), *c.RAttr, err)
+			}
+			if ref.SheetName != "" || ref.AbsoluteColumn || ref.AbsoluteRow || !isColumnName(ref.Column) {
+				return fmt.Errorf("'%s' cell %s has an invalid reference", s.Name(), *c.RAttr)
+			}
+			if ref.ColumnIdx >= maxColumns {
+				return fmt.Errorf("'%s' cell %s is out of range, the last column is %s", s.Name(), *c.RAttr, reference.IndexToColumn(maxColumns-1))
+			}
+			if ref.RowIdx == 0 || ref.RowIdx > maxRows {
+				return fmt.Errorf("'%s' cell %s is out of range, rows must be between 1 and %d", s.Name(), *c.RAttr, maxRows)
+			}
+			if r.RAttr != nil && ref.RowIdx != *r.RAttr {
+				return fmt.Errorf("'%s' cell %s is in row %d", s.Name(), *c.RAttr, *r.RAttr)
+			}
+		}
+	}
+	return nil
+}
+
+// isColumnName returns true if col consists only of upper case letters.
+func isColumnName(col string) bool {
+	if len(col) == 0 {
+		return false
+	}
+	for i := 0; i < len(col); i++ {
+		if col[i] < 'A' || col[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
 // validateMergedCells returns an error if merged cells overlap
 func (s Sheet) validateMergedCells() error {
 	mergedCells := map[uint64]struct{}{}
@@ -246,6 +509,70 @@ func (s Sheet) validateMergedCells() error {
 	return nil
 }
 
+// validateTables returns an error if tables overlap each other, the sheet's
+// auto filter or merged cells, all of which cause Excel to repair the file.
+func (s Sheet) validateTables() error {
+	type namedRange struct {
+		desc     string
+		from, to reference.CellReference
+	}
+	parse := func(desc, ref string) (namedRange, error) {
+		from, to, err := reference.ParseRangeReference(ref)
+		if err != nil {
+			return namedRange{}, fmt.Errorf("'%s' %s has an invalid reference %s", s.Name(), desc, ref)
+		}
+		return namedRange{desc, from, to}, nil
+	}
+	overlaps := func(a, b namedRange) bool {
+		return a.from.RowIdx <= b.to.RowIdx && a.to.RowIdx >= b.from.RowIdx &&
+			a.from.ColumnIdx <= b.to.ColumnIdx && a.to.ColumnIdx >= b.from.ColumnIdx
+	}
+
+	tables := []namedRange{}
+	for _, t := range s.Tables() {
+		tr, err := parse(fmt.Sprintf("table %s", t.Name()), t.Reference())
+		if err != nil {
+			return err
+		}
+		for _, o := range tables {
+			if overlaps(tr, o) {
+				return fmt.Errorf("'%s' %s overlaps %s", s.Name(), tr.desc, o.desc)
+			}
+		}
+		tables = append(tables, tr)
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+
+	others := []namedRange{}
+	if s.x.AutoFilter != nil && s.x.AutoFilter.RefAttr != nil {
+		af, err := parse("auto filter", *s.x.AutoFilter.RefAttr)
+		if err != nil {
+			return err
+		}
+		af.desc = fmt.Sprintf("auto filter %s", *s.x.AutoFilter.RefAttr)
+		others = append(others, af)
+	}
+	for _, mc := range s.MergedCells() {
+		mr, err := parse("merged cell", mc.Reference())
+		if err != nil {
+			return err
+		}
+		mr.desc = fmt.Sprintf("merged cell %s", mc.Reference())
+		others = append(others, mr)
+	}
+	for _, t := range tables {
+		for _, o := range others {
+			if overlaps(t, o) {
+				return fmt.Errorf("'%s' %s overlaps %s, remove it or move the table as tables have their own filter and can't contain merged cells",
+					s.Name(), t.desc, o.desc)
+			}
+		}
+	}
+	return nil
+}
+
 // ValidateWithPath validates the sheet passing path informaton for a better
 // error message
 func (s Sheet) ValidateWithPath(path string) error {
@@ -278,6 +605,7 @@ func (s Sheet) SetDrawing(d Drawing) {
 		if dr == d.x {
 			rel := rel.AddAutoRelationship(unioffice.DocTypeSpreadsheet, unioffice.WorksheetType, i+1, unioffice.DrawingType)
 			drawingID = rel.ID()
+			s.w.addSheetDrawing(rel.X(), dr)
 			break
 		}
 	}
@@ -285,6 +613,120 @@ func (s Sheet) SetDrawing(d Drawing) {
 	s.x.Drawing.IdAttr = drawingID
 }
 
+// AddTable adds an Excel table, as created with Format as Table, over a range
+// of cells such as "A1:C10".  The first row of the range is the header row and
+// provides the column names, with empty or repeated names replaced by unique
+// ones as Excel requires.  The name must be unique within the workbook and
+// follows the same rules as a defined name.  The table is given a filter button on
+// each column and the default banded table style.
+func (s Sheet) AddTable(ref, name string) (Table, error) {
+	from, to, err := reference.ParseRangeReference(ref)
+	if err != nil {
+		return Table{}, err
+	}
+	if from.RowIdx >= to.RowIdx {
+		return Table{}, fmt.Errorf("table %s must have a header row and at least one data row", ref)
+	}
+	// tables share the rules and namespace of defined names
+	if err := ValidateDefinedName(name); err != nil {
+		return Table{}, fmt.Errorf("invalid table name: %s", err)
+	}
+	idx, ok := s.index()
+	if !ok {
+		return Table{}, errors.New("sheet not found in workbook")
+	}
+	for _, dn := range s.w.DefinedNames() {
+		if strings.EqualFold(dn.Name(), name) {
+			return Table{}, fmt.Errorf("a defined name %s already exists", name)
+		}
+	}
+	id := uint32(1)
+	for _, t := range s.w.tables {
+		if t.NameAttr != nil && strings.EqualFold(*t.NameAttr, name) ||
+			strings.EqualFold(t.DisplayNameAttr, name) {
+			return Table{}, fmt.Errorf("a table named %s already exists", name)
+		}
+		if t.IdAttr >= id {
+			id = t.IdAttr + 1
+		}
+	}
+
+	tbl := sml.NewTable()
+	tbl.IdAttr = id
+	tbl.NameAttr = unioffice.String(name)
+	tbl.DisplayNameAttr = name
+	tbl.RefAttr = ref
+	tbl.AutoFilter = sml.NewCT_AutoFilter()
+	tbl.AutoFilter.RefAttr = unioffice.String(ref)
+	tbl.TableColumns = sml.NewCT_TableColumns()
+	used := map[string]bool{}
+	for col := from.ColumnIdx; col <= to.ColumnIdx; col++ {
+		n := col - from.ColumnIdx + 1
+		cell := s.Cell(fmt.Sprintf("%s%d", reference.IndexToColumn(col), from.RowIdx))
+		colName := cell.GetFormattedValue()
+		if colName == "" {
+			colName = fmt.Sprintf("Column%d", n)
+		}
+		for i := 2; used[strings.ToLower(colName)]; i++ {
+			colName = fmt.Sprintf("%s%d", strings.TrimRight(colName, "0123456789"), i)
+		}
+		used[strings.ToLower(colName)] = true
+		// the header cells must contain the column names
+		if cell.GetString() != colName {
+			cell.SetString(colName)
+		}
+		tc := sml.NewCT_TableColumn()
+		tc.IdAttr = n
+		tc.NameAttr = colName
+		tbl.TableColumns.TableColumn = append(tbl.TableColumns.TableColumn, tc)
+	}
+	tbl.TableColumns.CountAttr = unioffice.Uint32(uint32(len(tbl.TableColumns.TableColumn)))
+
+	s.w.tables = append(s.w.tables, tbl)
+	dt := unioffice.DocTypeSpreadsheet
+	s.w.ContentTypes.AddOverride(unioffice.AbsoluteFilename(dt, unioffice.TableType, len(s.w.tables)), unioffice.TableContentType)
+	rel := s.w.xwsRels[idx].AddAutoRelationship(dt, unioffice.WorksheetType, len(s.w.tables), unioffice.TableType)
+	if s.x.TableParts == nil {
+		s.x.TableParts = sml.NewCT_TableParts()
+	}
+	tp := sml.NewCT_TablePart()
+	tp.IdAttr = rel.ID()
+	s.x.TableParts.TablePart = append(s.x.TableParts.TablePart, tp)
+	s.x.TableParts.CountAttr = unioffice.Uint32(uint32(len(s.x.TableParts.TablePart)))
+
+	t := Table{tbl}
+	t.SetStyle("TableStyleMedium2")
+	t.SetBandedRows(true)
+	t.SetBandedColumns(false)
+	t.SetShowFirstColumn(false)
+	t.SetShowLastColumn(false)
+	return t, nil
+}
+
+// Tables returns the tables on the sheet.
+func (s Sheet) Tables() []Table {
+	if s.x.TableParts == nil {
+		return nil
+	}
+	idx, ok := s.index()
+	if !ok {
+		return nil
+	}
+	ret := []Table{}
+	targets := map[string]string{}
+	for _, rel := range s.w.xwsRels[idx].Relationships() {
+		targets[rel.ID()] = rel.Target()
+	}
+	for _, tp := range s.x.TableParts.TablePart {
+		for i, tbl := range s.w.tables {
+			if unioffice.RelativeFilename(unioffice.DocTypeSpreadsheet, unioffice.WorksheetType, unioffice.TableType, i+1) == targets[tp.IdAttr] {
+				ret = append(ret, Table{tbl})
+			}
+		}
+	}
+	return ret
+}
+
 // AddHyperlink adds a hyperlink to a sheet. Adding the hyperlink to the sheet
 // and setting it on a cell is more efficient than setting hyperlinks directly
 // on a cell.
@@ -300,6 +742,26 @@ func (s Sheet) AddHyperlink(url string) common.Hyperlink {
 	return common.Hyperlink{}
 }
 
+// AddDefinedName adds a name for a cell or range reference that is scoped to
+// the sheet.  Sheet scoped names take precedence over workbook names of the
+// same name in formulas on the sheet.  Names that aren't valid are logged but
+// still added, as with Workbook.AddDefinedName.
+func (s Sheet) AddDefinedName(name, ref string) DefinedName {
+	if err := ValidateDefinedName(name); err != nil {
+		unioffice.Log("%s", err)
+	}
+	return s.addDefinedName(name, ref)
+}
+
+// addDefinedName adds a name scoped to the sheet without validating it.
+func (s Sheet) addDefinedName(name, ref string) DefinedName {
+	dn := s.w.addDefinedName(name, ref)
+	if idx, ok := s.index(); ok {
+		dn.SetLocalSheetID(uint32(idx))
+	}
+	return dn
+}
+
 // RangeReference converts a range reference of the form 'A1:A5' to 'Sheet
 // 1'!$A$1:$A$5 . Renaming a sheet after calculating a range reference will
 // invalidate the reference.
@@ -320,15 +782,8 @@ const autoFilterName = "_xlnm._FilterDatabase"
 // ClearAutoFilter removes the autofilters from the sheet.
 func (s Sheet) ClearAutoFilter() {
 	s.x.AutoFilter = nil
-	sn := "'" + s.Name() + "'!"
-	// see if we have a defined auto filter name for the sheet
-	for _, dn := range s.w.DefinedNames() {
-		if dn.Name() == autoFilterName {
-			if strings.HasPrefix(dn.Content(), sn) {
-				s.w.RemoveDefinedName(dn)
-				break
-			}
-		}
+	if dn, ok := s.autoFilterDefinedName(); ok {
+		s.w.RemoveDefinedName(dn)
 	}
 }
 
@@ -342,30 +797,109 @@ func (s Sheet) SetAutoFilter(rangeRef string) {
 
 	s.x.AutoFilter = sml.NewCT_AutoFilter()
 	s.x.AutoFilter.RefAttr = unioffice.String(rangeRef)
-	sn := "'" + s.Name() + "'!"
-	var sdn DefinedName
 
-	// see if we already have a defined auto filter name for the sheet
+	// see if we already have a defined auto filter name for the sheet, if so
+	// the name must match, but make sure rangeRef matches as well
+	sdn, ok := s.autoFilterDefinedName()
+	if ok {
+		sdn.SetContent(s.RangeReference(rangeRef))
+	} else {
+		sdn = s.w.addDefinedName(autoFilterName, s.RangeReference(rangeRef))
+	}
+	// Excel always hides the filter database name
+	sdn.SetHidden(true)
+
+	if idx, ok := s.index(); ok {
+		sdn.SetLocalSheetID(uint32(idx))
+	}
+}
+
+// autoFilterDefinedName returns the defined name that Excel uses to record the
+// auto filter range of the sheet, if there is one.
+func (s Sheet) autoFilterDefinedName() (DefinedName, bool) {
+	return s.builtinDefinedName(autoFilterName)
+}
+
+const (
+	printAreaName   = "_xlnm.Print_Area"
+	printTitlesName = "_xlnm.Print_Titles"
+)
+
+// SetPrintArea sets the range of cells that are printed, e.g. "A1:F20".
+// Multiple ranges can be separated by commas.
+func (s Sheet) SetPrintArea(rangeRef string) {
+	refs := []string{}
+	for _, r := range strings.Split(rangeRef, ",") {
+		refs = append(refs, s.RangeReference(strings.Replace(strings.TrimSpace(r), "$", "", -1)))
+	}
+	s.setBuiltinDefinedName(printAreaName, strings.Join(refs, ","))
+}
+
+// ClearPrintArea removes the print area so the entire sheet is printed.
+func (s Sheet) ClearPrintArea() {
+	if dn, ok := s.builtinDefinedName(printAreaName); ok {
+		s.w.RemoveDefinedName(dn)
+	}
+}
+
+// SetPrintTitleRows sets the rows that are repeated at the top of each printed
+// page, from row number first to last inclusively.
+func (s Sheet) SetPrintTitleRows(first, last uint32) {
+	if first > last {
+		first, last = last, first
+	}
+	s.setBuiltinDefinedName(printTitlesName, fmt.Sprintf("'%s'!$%d:$%d",
+		strings.Replace(s.Name(), "'", "''", -1), first, last))
+}
+
+// ClearPrintTitleRows removes the rows repeated at the top of each printed
+// page.
+func (s Sheet) ClearPrintTitleRows() {
+	if dn, ok := s.builtinDefinedName(printTitlesName); ok {
+		s.w.RemoveDefinedName(dn)
+	}
+}
+
+// setBuiltinDefinedName sets the content of a defined name scoped to the
+// sheet, creating it if necessary.
+func (s Sheet) setBuiltinDefinedName(name, content string) {
+	if dn, ok := s.builtinDefinedName(name); ok {
+		dn.SetContent(content)
+		return
+	}
+	s.addDefinedName(name, content)
+}
+
+// builtinDefinedName returns one of the defined names that Excel uses to
+// record per sheet settings such as the print area, if there is one.
+func (s Sheet) builtinDefinedName(name string) (DefinedName, bool) {
+	idx, hasIdx := s.index()
+	sn := "'" + s.Name() + "'!"
 	for _, dn := range s.w.DefinedNames() {
-		if dn.Name() == autoFilterName {
-			if strings.HasPrefix(dn.Content(), sn) {
-				sdn = dn
-				// name must match, but make sure rangeRef matches as well
-				sdn.SetContent(s.RangeReference(rangeRef))
-				break
+		if dn.Name() != name {
+			continue
+		}
+		if lsid := dn.X().LocalSheetIdAttr; lsid != nil && hasIdx {
+			if int(*lsid) == idx {
+				return dn, true
 			}
+			continue
+		}
+		if strings.HasPrefix(dn.Content(), sn) {
+			return dn, true
 		}
 	}
-	// no existing name found, so add a new one
-	if sdn.X() == nil {
-		sdn = s.w.AddDefinedName(autoFilterName, s.RangeReference(rangeRef))
-	}
+	return DefinedName{}, false
+}
 
+// index returns the zero based index of the sheet within the workbook.
+func (s Sheet) index() (int, bool) {
 	for i, ws := range s.w.xws {
 		if ws == s.x {
-			sdn.SetLocalSheetID(uint32(i))
+			return i, true
 		}
 	}
+	return 0, false
 }
 
 // AddMergedCells merges cells within a sheet.
@@ -398,17 +932,57 @@ func (s Sheet) MergedCells() []MergedCell {
 	return ret
 }
 
+// MergeCells merges cells within a sheet in the same way as AddMergedCells,
+// but returns an error instead of merging if the region overlaps an existing
+// merged region as Excel refuses to open such files.
+func (s Sheet) MergeCells(fromRef, toRef string) (MergedCell, error) {
+	ref := fmt.Sprintf("%s:%s", fromRef, toRef)
+	from, to, err := reference.ParseRangeReference(ref)
+	if err != nil {
+		return MergedCell{}, err
+	}
+	for _, mc := range s.MergedCells() {
+		mf, mt, err := reference.ParseRangeReference(mc.Reference())
+		if err != nil {
+			continue
+		}
+		if from.RowIdx <= mt.RowIdx && to.RowIdx >= mf.RowIdx &&
+			from.ColumnIdx <= mt.ColumnIdx && to.ColumnIdx >= mf.ColumnIdx {
+			return MergedCell{}, fmt.Errorf("merged region %s overlaps existing merged region %s", ref, mc.Reference())
+		}
+	}
+	return s.AddMergedCells(fromRef, toRef), nil
+}
+
 // RemoveMergedCell removes merging from a cell range within a sheet.  The cells
 // that made up the merged cell remain, but are no lon merged.
 func (s Sheet) RemoveMergedCell(mc MergedCell) {
+	if s.x.MergeCells == nil {
+		return
+	}
 	for i, c := range s.x.MergeCells.MergeCell {
 		if c == mc.X() {
 			copy(s.x.MergeCells.MergeCell[i:], s.x.MergeCells.MergeCell[i+1:])
 			s.x.MergeCells.MergeCell[len(s.x.MergeCells.MergeCell)-1] = nil
 			s.x.MergeCells.MergeCell = s.x.MergeCells.MergeCell[:len(s.x.MergeCells.MergeCell)-1]
+			break
 		}
 	}
+	s.updateMergedCellsCount()
+}
 
+// updateMergedCellsCount keeps the merged cells count in sync, removing the
+// container entirely once there are no merged cells left as an empty container
+// is invalid.
+func (s Sheet) updateMergedCellsCount() {
+	if s.x.MergeCells == nil {
+		return
+	}
+	if len(s.x.MergeCells.MergeCell) == 0 {
+		s.x.MergeCells = nil
+		return
+	}
+	s.x.MergeCells.CountAttr = unioffice.Uint32(uint32(len(s.x.MergeCells.MergeCell)))
 }
 
 func (s Sheet) ExtentsIndex() (string, uint32, string, uint32) {
@@ -453,7 +1027,7 @@ func (s Sheet) AddConditionalFormatting(cellRanges []string) ConditionalFormatti
 	for _, r := range cellRanges {
 		*cfmt.SqrefAttr = append(*cfmt.SqrefAttr, r)
 	}
-	return ConditionalFormatting{cfmt}
+	return ConditionalFormatting{cfmt, s.x}
 }
 
 // Column returns or creates a column that with a given index (1-N).  Columns
@@ -486,6 +1060,326 @@ func (s Sheet) Column(idx uint32) Column {
 	return Column{col}
 }
 
+// ColumnByName returns or creates a column given a column name of the form 'C'.
+// It behaves identically to Column, but avoids the need to convert the column
+// name to a 1-N index.
+func (s Sheet) ColumnByName(col string) Column {
+	return s.Column(reference.ColumnToIndex(col) + 1)
+}
+
+// SetColumnWidthRange sets the width of every column from the column named
+// 'from' to the column named 'to' inclusively, e.g. SetColumnWidthRange("B",
+// "D", w).  The range is stored as a single column definition, and any existing
+// column definitions that overlap the range are trimmed or split so that they
+// no longer overlap it.
+func (s Sheet) SetColumnWidthRange(from, to string, w measurement.Distance) Column {
+	min := reference.ColumnToIndex(from) + 1
+	max := reference.ColumnToIndex(to) + 1
+	if min > max {
+		min, max = max, min
+	}
+
+	var colSet *sml.CT_Cols
+	if len(s.x.Cols) == 0 {
+		colSet = sml.NewCT_Cols()
+		s.x.Cols = append(s.x.Cols, colSet)
+	} else {
+		colSet = s.x.Cols[0]
+	}
+
+	// remove the range from any existing columns
+	cols := []*sml.CT_Col{}
+	for _, cs := range s.x.Cols {
+		for _, col := range cs.Col {
+			if col.MaxAttr < min || col.MinAttr > max {
+				cols = append(cols, col)
+				continue
+			}
+			if col.MinAttr < min {
+				left := *col
+				left.MaxAttr = min - 1
+				cols = append(cols, &left)
+			}
+			if col.MaxAttr > max {
+				right := *col
+				right.MinAttr = max + 1
+				cols = append(cols, &right)
+			}
+		}
+		cs.Col = nil
+	}
+
+	col := sml.NewCT_Col()
+	col.MinAttr = min
+	col.MaxAttr = max
+	cols = append(cols, col)
+
+	// Excel wants the columns to be sorted
+	sort.Slice(cols, func(i, j int) bool {
+		return cols[i].MinAttr < cols[j].MinAttr
+	})
+	colSet.Col = cols
+	s.x.Cols = s.x.Cols[:1]
+
+	c := Column{col}
+	c.SetWidth(w)
+	return c
+}
+
+// GroupRows groups the rows from start to end inclusively, increasing their
+// outline level by one so that nested groups can be created by grouping a
+// range within an existing group.
+func (s Sheet) GroupRows(start, end uint32) {
+	if start < 1 || start > end {
+		unioffice.Log("invalid row range %d-%d to group", start, end)
+		return
+	}
+	for i := start; i <= end; i++ {
+		r := s.Row(i)
+		r.SetOutlineLevel(r.OutlineLevel() + 1)
+	}
+}
+
+// SetRowsCollapsed collapses or expands a group of rows previously created
+// with GroupRows.  Collapsed rows are hidden and the collapsed state is stored
+// on the summary row next to the group.
+func (s Sheet) SetRowsCollapsed(start, end uint32, collapsed bool) {
+	if start < 1 || start > end {
+		unioffice.Log("invalid row range %d-%d to collapse", start, end)
+		return
+	}
+	for i := start; i <= end; i++ {
+		s.Row(i).SetHidden(collapsed)
+	}
+	summary := end + 1
+	if !s.outlineSummaryBelow() {
+		if start <= 1 {
+			return
+		}
+		summary = start - 1
+	}
+	if collapsed {
+		s.Row(summary).x.CollapsedAttr = unioffice.Bool(true)
+	} else {
+		s.Row(summary).x.CollapsedAttr = nil
+	}
+}
+
+// GroupColumns groups the columns from startCol to endCol inclusively (e.g.
+// GroupColumns("B", "D")), increasing their outline level by one so that
+// nested groups can be created by grouping a range within an existing group.
+func (s Sheet) GroupColumns(startCol, endCol string) {
+	lvl := uint8(0)
+	for _, col := range s.columnRange(startCol, endCol) {
+		next := uint8(1)
+		if col.OutlineLevelAttr != nil {
+			next = *col.OutlineLevelAttr + 1
+		}
+		if next > maxOutlineLevel {
+			unioffice.Log("outline level %d exceeds the maximum of %d", next, maxOutlineLevel)
+			next = maxOutlineLevel
+		}
+		col.OutlineLevelAttr = unioffice.Uint8(next)
+		if next > lvl {
+			lvl = next
+		}
+	}
+	fp := sheetFormatPr(s.x)
+	if cur := fp.OutlineLevelColAttr; cur == nil || *cur < lvl {
+		fp.OutlineLevelColAttr = unioffice.Uint8(lvl)
+	}
+}
+
+// sheetFormatPr returns the sheet format properties of a worksheet, creating
+// them with the default row height if they don't exist yet.
+func sheetFormatPr(ws *sml.Worksheet) *sml.CT_SheetFormatPr {
+	if ws.SheetFormatPr == nil {
+		ws.SheetFormatPr = sml.NewCT_SheetFormatPr()
+		ws.SheetFormatPr.DefaultRowHeightAttr = 15
+	}
+	return ws.SheetFormatPr
+}
+
+// SetColumnsCollapsed collapses or expands a group of columns previously
+// created with GroupColumns.  Collapsed columns are hidden and the collapsed
+// state is stored on the summary column next to the group.
+func (s Sheet) SetColumnsCollapsed(startCol, endCol string, collapsed bool) {
+	for _, col := range s.columnRange(startCol, endCol) {
+		Column{col}.SetHidden(collapsed)
+	}
+	min := reference.ColumnToIndex(startCol) + 1
+	max := reference.ColumnToIndex(endCol) + 1
+	if min > max {
+		min, max = max, min
+	}
+	summaryIdx := max + 1
+	if !s.outlineSummaryRight() {
+		if min <= 1 {
+			return
+		}
+		summaryIdx = min - 1
+	}
+	summary := s.Column(summaryIdx)
+	if collapsed {
+		summary.X().CollapsedAttr = unioffice.Bool(true)
+	} else {
+		summary.X().CollapsedAttr = nil
+	}
+}
+
+// SetOutlineSummaryBelow controls whether the summary rows of row groups are
+// below (the default) or above the grouped rows.
+func (s Sheet) SetOutlineSummaryBelow(b bool) {
+	if s.x.SheetPr == nil {
+		s.x.SheetPr = sml.NewCT_SheetPr()
+	}
+	if s.x.SheetPr.OutlinePr == nil {
+		s.x.SheetPr.OutlinePr = sml.NewCT_OutlinePr()
+	}
+	if b {
+		s.x.SheetPr.OutlinePr.SummaryBelowAttr = nil
+	} else {
+		s.x.SheetPr.OutlinePr.SummaryBelowAttr = unioffice.Bool(false)
+	}
+}
+
+// SetOutlineSummaryRight controls whether the summary columns of column groups
+// are to the right (the default) or to the left of the grouped columns.
+func (s Sheet) SetOutlineSummaryRight(b bool) {
+	if s.x.SheetPr == nil {
+		s.x.SheetPr = sml.NewCT_SheetPr()
+	}
+	if s.x.SheetPr.OutlinePr == nil {
+		s.x.SheetPr.OutlinePr = sml.NewCT_OutlinePr()
+	}
+	if b {
+		s.x.SheetPr.OutlinePr.SummaryRightAttr = nil
+	} else {
+		s.x.SheetPr.OutlinePr.SummaryRightAttr = unioffice.Bool(false)
+	}
+}
+
+// SetTabColor sets the color of the sheet's tab.  Passing color.Auto removes
+// the tab color.
+func (s Sheet) SetTabColor(c color.Color) {
+	if c.IsAuto() {
+		s.ClearTabColor()
+		return
+	}
+	if s.x.SheetPr == nil {
+		s.x.SheetPr = sml.NewCT_SheetPr()
+	}
+	clr := sml.NewCT_Color()
+	clr.RgbAttr = c.AsRGBAString()
+	s.x.SheetPr.TabColor = clr
+}
+
+// SetTabThemeColor sets the color of the sheet's tab to a theme color,
+// lightened or darkened by tint which ranges from -1 to 1.
+func (s Sheet) SetTabThemeColor(slot common.ThemeColorSlot, tint float64) {
+	if s.x.SheetPr == nil {
+		s.x.SheetPr = sml.NewCT_SheetPr()
+	}
+	s.x.SheetPr.TabColor = newThemeColor(slot, tint)
+}
+
+// ClearTabColor removes the color of the sheet's tab.
+func (s Sheet) ClearTabColor() {
+	if s.x.SheetPr != nil {
+		s.x.SheetPr.TabColor = nil
+	}
+}
+
+func (s Sheet) outlineSummaryBelow() bool {
+	if s.x.SheetPr == nil || s.x.SheetPr.OutlinePr == nil || s.x.SheetPr.OutlinePr.SummaryBelowAttr == nil {
+		return true
+	}
+	return *s.x.SheetPr.OutlinePr.SummaryBelowAttr
+}
+
+func (s Sheet) outlineSummaryRight() bool {
+	if s.x.SheetPr == nil || s.x.SheetPr.OutlinePr == nil || s.x.SheetPr.OutlinePr.SummaryRightAttr == nil {
+		return true
+	}
+	return *s.x.SheetPr.OutlinePr.SummaryRightAttr
+}
+
+// columnRange returns a column definition for each column from startCol to
+// endCol inclusively, splitting any existing column definitions that span the
+// range boundaries so that the returned columns can be modified individually.
+func (s Sheet) columnRange(startCol, endCol string) []*sml.CT_Col {
+	min := reference.ColumnToIndex(startCol) + 1
+	max := reference.ColumnToIndex(endCol) + 1
+	if min > max {
+		min, max = max, min
+	}
+
+	if len(s.x.Cols) == 0 {
+		s.x.Cols = append(s.x.Cols, sml.NewCT_Cols())
+	}
+	covered := map[uint32]*sml.CT_Col{}
+	cols := []*sml.CT_Col{}
+	for _, cs := range s.x.Cols {
+		for _, col := range cs.Col {
+			if col.MaxAttr < min || col.MinAttr > max {
+				cols = append(cols, col)
+				continue
+			}
+			if col.MinAttr < min {
+				left := *col
+				left.MaxAttr = min - 1
+				cols = append(cols, &left)
+			}
+			if col.MaxAttr > max {
+				right := *col
+				right.MinAttr = max + 1
+				cols = append(cols, &right)
+			}
+			lo, hi := col.MinAttr, col.MaxAttr
+			if lo < min {
+				lo = min
+			}
+			if hi > max {
+				hi = max
+			}
+			for i := lo; i <= hi; i++ {
+				c := *col
+				c.MinAttr = i
+				c.MaxAttr = i
+				covered[i] = &c
+			}
+		}
+		cs.Col = nil
+	}
+
+	ret := []*sml.CT_Col{}
+	for i := min; i <= max; i++ {
+		col, ok := covered[i]
+		if !ok {
+			col = sml.NewCT_Col()
+			col.MinAttr = i
+			col.MaxAttr = i
+		}
+		ret = append(ret, col)
+	}
+	cols = append(cols, ret...)
+
+	// Excel wants the columns to be sorted
+	sort.Slice(cols, func(i, j int) bool {
+		return cols[i].MinAttr < cols[j].MinAttr
+	})
+	s.x.Cols[0].Col = cols
+	s.x.Cols = s.x.Cols[:1]
+	return ret
+}
+
+// AddComment adds a comment with the given author and text to a cell,
+// creating the comments part and the VML drawing used to display it if
+// necessary.
+func (s Sheet) AddComment(cellRef, author, text string) error {
+	return s.Comments().AddCommentWithStyle(cellRef, author, text)
+}
+
 // Comments returns the comments for a sheet.
 func (s Sheet) Comments() Comments {
 	for i, wks := range s.w.xws {
@@ -495,9 +1389,10 @@ func (s Sheet) Comments() Comments {
 				s.w.xwsRels[i].AddAutoRelationship(unioffice.DocTypeSpreadsheet, unioffice.WorksheetType, i+1, unioffice.CommentsType)
 				s.w.ContentTypes.AddOverride(unioffice.AbsoluteFilename(unioffice.DocTypeSpreadsheet, unioffice.CommentsType, i+1), unioffice.CommentsContentType)
 			}
-			if len(s.w.vmlDrawings) == 0 {
-				s.w.vmlDrawings = append(s.w.vmlDrawings, vmldrawing.NewCommentDrawing())
-				vmlID := s.w.xwsRels[i].AddAutoRelationship(unioffice.DocTypeSpreadsheet, unioffice.WorksheetType, 1, unioffice.VMLDrawingType)
+			if s.w.vmlDrawings[i] == nil {
+				s.w.vmlDrawings[i] = vmldrawing.NewCommentDrawing()
+				s.w.vmlDrawings[i].Layout.Idmap.DataAttr = unioffice.String(strconv.Itoa(i + 1))
+				vmlID := s.w.xwsRels[i].AddAutoRelationship(unioffice.DocTypeSpreadsheet, unioffice.WorksheetType, i+1, unioffice.VMLDrawingType)
 				if s.x.LegacyDrawing == nil {
 					s.x.LegacyDrawing = sml.NewCT_LegacyDrawing()
 				}
@@ -512,6 +1407,89 @@ func (s Sheet) Comments() Comments {
 	return Comments{}
 }
 
+// SetStyleRange applies a style to every cell in the range from fromRef to
+// toRef inclusive, e.g. "B2" and "D10", creating empty cells where none exist.
+func (s Sheet) SetStyleRange(fromRef, toRef string, cs CellStyle) error {
+	from, err := reference.ParseCellReference(fromRef)
+	if err != nil {
+		return err
+	}
+	to, err := reference.ParseCellReference(toRef)
+	if err != nil {
+		return err
+	}
+	if from.RowIdx > to.RowIdx {
+		from.RowIdx, to.RowIdx = to.RowIdx, from.RowIdx
+	}
+	if from.ColumnIdx > to.ColumnIdx {
+		from.ColumnIdx, to.ColumnIdx = to.ColumnIdx, from.ColumnIdx
+	}
+	idx := cs.Index()
+	for r := from.RowIdx; r <= to.RowIdx; r++ {
+		row := s.Row(r)
+		for c := from.ColumnIdx; c <= to.ColumnIdx; c++ {
+			row.Cell(reference.IndexToColumn(c)).SetStyleIndex(idx)
+		}
+	}
+	return nil
+}
+
+// ApplyBanding styles alternating rows of a range such as "A1:D20" without
+// adding a table, using evenStyle for even numbered rows and oddStyle for odd
+// numbered rows.  The range is limited to the last row of the sheet and the
+// last column containing cells in the range's rows, so banding whole columns
+// doesn't create empty rows and cells beyond the sheet's content.  Within that,
+// missing cells are created so that the banding is shown.
+func (s Sheet) ApplyBanding(ref string, evenStyle, oddStyle CellStyle) error {
+	from, to, err := reference.ParseRangeReference(ref)
+	if err != nil {
+		return err
+	}
+	if from.RowIdx > to.RowIdx {
+		from.RowIdx, to.RowIdx = to.RowIdx, from.RowIdx
+	}
+	if from.ColumnIdx > to.ColumnIdx {
+		from.ColumnIdx, to.ColumnIdx = to.ColumnIdx, from.ColumnIdx
+	}
+	if maxRow := s.rowIndex().maxRow; to.RowIdx > maxRow {
+		to.RowIdx = maxRow
+	}
+	maxCol, ok := uint32(0), false
+	for _, r := range s.x.SheetData.Row {
+		if r.RAttr == nil || *r.RAttr < from.RowIdx || *r.RAttr > to.RowIdx || len(r.C) == 0 {
+			continue
+		}
+		if last := r.C[len(r.C)-1]; last.RAttr != nil {
+			if cref, err := reference.ParseCellReference(*last.RAttr); err == nil && (!ok || cref.ColumnIdx > maxCol) {
+				maxCol, ok = cref.ColumnIdx, true
+			}
+		}
+	}
+	if !ok || maxCol < from.ColumnIdx {
+		// no content to band
+		return nil
+	}
+	if to.ColumnIdx > maxCol {
+		to.ColumnIdx = maxCol
+	}
+
+	even, odd := evenStyle.Index(), oddStyle.Index()
+	for r := from.RowIdx; r <= to.RowIdx; r++ {
+		idx := odd
+		if r%2 == 0 {
+			idx = even
+		}
+		row := s.Row(r)
+		for c := from.ColumnIdx; c <= to.ColumnIdx; c++ {
+			cell := row.Cell(reference.IndexToColumn(c))
+			if cell.x.SAttr == nil || *cell.x.SAttr != idx {
+				cell.SetStyleIndex(idx)
+			}
+		}
+	}
+	return nil
+}
+
 // SetBorder is a helper function for creating borders across multiple cells. In
 // the OOXML spreadsheet format, a border applies to a single cell.  To draw a
 // 'boxed' border around multiple cells, you need to apply different styles to
@@ -802,20 +1780,48 @@ func (s *Sheet) InitialView() SheetView {
 func (s *Sheet) SetFrozen(firstRow, firstCol bool) {
 	s.x.SheetViews = nil
 	v := s.AddView()
-	v.SetState(sml.ST_PaneStateFrozen)
-	switch {
-	case firstRow && firstCol:
-		v.SetYSplit(1)
-		v.SetXSplit(1)
-		v.SetTopLeft("B2")
-	case firstRow:
-		v.SetYSplit(1)
-		v.SetTopLeft("A2")
-	case firstCol:
-		v.SetXSplit(1)
-		v.SetTopLeft("B1")
+	v.freeze(uint32(b2i(firstRow)), uint32(b2i(firstCol)))
+}
+
+// SetFrozenRange freezes the given number of rows at the top and columns at
+// the left of the sheet.  Unlike SetFrozen, other settings of the initial
+// sheet view such as zoom are preserved. Any existing pane is replaced, and
+// passing zero for both rows and cols unfreezes the sheet.
+func (s *Sheet) SetFrozenRange(rows, cols uint32) {
+	s.InitialView().freeze(rows, cols)
+}
+
+// SetShowGridlines controls whether gridlines are displayed on the sheet.
+func (s *Sheet) SetShowGridlines(b bool) {
+	s.InitialView().SetShowGridlines(b)
+}
+
+// SetShowRowColHeaders controls whether the row and column headings are
+// displayed on the sheet.
+func (s *Sheet) SetShowRowColHeaders(b bool) {
+	s.InitialView().SetShowRowColHeaders(b)
+}
+
+// SetZoom sets the zoom level of the sheet as a percentage, from 10 to 400.
+func (s *Sheet) SetZoom(pct uint32) {
+	if pct < 10 || pct > 400 {
+		unioffice.Log("zoom must be between 10 and 400, got %d", pct)
+		return
 	}
+	s.InitialView().SetZoom(pct)
+}
+
+// SetActiveCell sets the cell that is active when the sheet is opened, e.g.
+// SetActiveCell("B3").
+func (s *Sheet) SetActiveCell(cellRef string) {
+	s.InitialView().SetActiveCell(cellRef)
+}
 
+// SetSelection sets the cells or ranges that are selected when the sheet is
+// opened, e.g. SetSelection("A1:B5 D3").  The top left cell of the first range
+// becomes the active cell.
+func (s *Sheet) SetSelection(ref string) {
+	s.InitialView().SetSelection(ref)
 }
 
 // FormulaContext returns a formula evaluation context that can be used to
@@ -874,6 +1880,117 @@ func (s *Sheet) Sort(column string, firstRow uint32, order SortOrder) {
 	}
 }
 
+// SortRange sorts the rows of a range, e.g. "A2:C10", by the values of one or
+// more columns within it, with later keys breaking ties in earlier ones.  The
+// sort is stable and only moves the contents and styles of the cells within
+// the range, cells outside of it along with any hyperlinks and comments are
+// left in place.  Numbers sort before text and empty cells are always placed
+// last.  As with Sort, formulas are moved as is so relative references within
+// them aren't adjusted.  An error is returned if the range contains merged
+// cells.
+func (s *Sheet) SortRange(ref string, by []SortKey) error {
+	from, to, err := reference.ParseRangeReference(ref)
+	if err != nil {
+		return err
+	}
+	if len(by) == 0 {
+		return errors.New("no sort keys specified")
+	}
+	keys := make([]uint32, len(by))
+	for i, k := range by {
+		idx := reference.ColumnToIndex(k.Column)
+		if idx < from.ColumnIdx || idx > to.ColumnIdx {
+			return fmt.Errorf("sort column %s is outside of %s", k.Column, ref)
+		}
+		keys[i] = idx - from.ColumnIdx
+	}
+	for _, mc := range s.MergedCells() {
+		mf, mt, err := reference.ParseRangeReference(mc.Reference())
+		if err != nil {
+			continue
+		}
+		if from.RowIdx <= mt.RowIdx && to.RowIdx >= mf.RowIdx &&
+			from.ColumnIdx <= mt.ColumnIdx && to.ColumnIdx >= mf.ColumnIdx {
+			return fmt.Errorf("can't sort %s as it contains merged cells %s", ref, mc.Reference())
+		}
+	}
+
+	// take a copy of the cells within the range, remembering where they came
+	// from so they can be overwritten in sorted order
+	nRows := to.RowIdx - from.RowIdx + 1
+	nCols := to.ColumnIdx - from.ColumnIdx + 1
+	orig := make([][]*sml.CT_Cell, nRows)
+	rows := make([][]*sml.CT_Cell, nRows)
+	for i := range rows {
+		orig[i] = make([]*sml.CT_Cell, nCols)
+		rows[i] = make([]*sml.CT_Cell, nCols)
+	}
+	for _, r := range s.x.SheetData.Row {
+		if r.RAttr == nil || *r.RAttr < from.RowIdx || *r.RAttr > to.RowIdx {
+			continue
+		}
+		for _, c := range r.C {
+			if c.RAttr == nil {
+				continue
+			}
+			cref, err := reference.ParseCellReference(*c.RAttr)
+			if err != nil || cref.ColumnIdx < from.ColumnIdx || cref.ColumnIdx > to.ColumnIdx {
+				continue
+			}
+			i, j := *r.RAttr-from.RowIdx, cref.ColumnIdx-from.ColumnIdx
+			cp := *c
+			orig[i][j] = c
+			rows[i][j] = &cp
+		}
+	}
+
+	isBlank := func(c *sml.CT_Cell) bool {
+		return c == nil || (c.V == nil && c.F == nil && c.Is == nil)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for n, k := range keys {
+			lhs, rhs := rows[i][k], rows[j][k]
+			switch {
+			case isBlank(lhs) && isBlank(rhs):
+				continue
+			case isBlank(lhs):
+				return false
+			case isBlank(rhs):
+				return true
+			}
+			cmp := Comparer{Order: by[n].Order}
+			lc, rc := Cell{s.w, s.x, nil, lhs}, Cell{s.w, s.x, nil, rhs}
+			if cmp.LessCells(lc, rc) {
+				return true
+			}
+			if cmp.LessCells(rc, lc) {
+				return false
+			}
+		}
+		return false
+	})
+
+	for i, cells := range rows {
+		for j, c := range cells {
+			dst := orig[i][j]
+			switch {
+			case c == nil && dst == nil:
+				continue
+			case c == nil:
+				*dst = sml.CT_Cell{RAttr: dst.RAttr}
+				continue
+			case dst == nil:
+				cellRef := fmt.Sprintf("%s%d", reference.IndexToColumn(from.ColumnIdx+uint32(j)), from.RowIdx+uint32(i))
+				dst = s.Cell(cellRef).x
+			}
+			ref := dst.RAttr
+			*dst = *c
+			dst.RAttr = ref
+		}
+	}
+	return nil
+}
+
 // RemoveColumn removes column from the sheet and moves all columns to the right of the removed column one step left.
 func (s *Sheet) RemoveColumn(column string) error {
 	cellsInFormulaArrays, err := s.getAllCellsInFormulaArraysForColumn()
@@ -882,7 +1999,7 @@ func (s *Sheet) RemoveColumn(column string) error {
 	}
 	columnIdx := reference.ColumnToIndex(column)
 	for _, row := range s.Rows() {
-		ref := fmt.Sprintf("%s%d", column, *row.X().RAttr)
+		ref := fmt.Sprintf("%s%d", column, *row.x.RAttr)
 		if _, ok := cellsInFormulaArrays[ref]; ok {
 			return nil
 		}
@@ -978,6 +2095,7 @@ func (s *Sheet) removeColumnFromMergedCells(columnIdx uint32) error {
 		}
 	}
 	s.x.MergeCells.MergeCell = newMergedCells
+	s.updateMergedCellsCount()
 	return nil
 }
 
@@ -998,7 +2116,7 @@ func (s *Sheet) removeColumnFromNamedRanges(columnIdx uint32) error {
 			newRefStr := moveRangeLeft(sp[1], columnIdx, true)
 			if newRefStr != "" {
 				newContent := sheetName + "!" + newRefStr
-				s.w.AddDefinedName(name, newContent)
+				s.w.addDefinedName(name, newContent)
 			}
 		}
 	}
@@ -1,9 +1,11 @@
 package spreadsheet_test
 
 import (
+	"io/ioutil"
 	"testing"
 
 	"github.com/unidoc/unioffice/spreadsheet"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
 )
 
 func BenchmarkAddRow(b *testing.B) {
@@ -23,3 +25,102 @@ func BenchmarkAddCell(b *testing.B) {
 		row.AddCell()
 	}
 }
+
+func BenchmarkAddRowValues(b *testing.B) {
+	ss := spreadsheet.New()
+	sheet := ss.AddSheet()
+	for r := 0; r < b.N; r++ {
+		row := sheet.AddRow()
+		row.AddCell().SetString("label")
+		row.AddCell().SetNumber(float64(r))
+		row.AddCell().SetBool(true)
+	}
+	ss.Save(ioutil.Discard)
+}
+
+func BenchmarkStreamRow(b *testing.B) {
+	ss := spreadsheet.New()
+	defer ss.Close()
+	sheet := ss.AddSheet()
+	stream, err := sheet.StreamRows()
+	if err != nil {
+		b.Fatalf("error streaming rows: %s", err)
+	}
+	for r := 0; r < b.N; r++ {
+		stream.AddRow([]interface{}{"label", r, true})
+	}
+	ss.Save(ioutil.Discard)
+}
+
+func BenchmarkAddRow100k(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ss := spreadsheet.New()
+		sheet := ss.AddSheet()
+		for r := 0; r < 100000; r++ {
+			sheet.AddRow()
+		}
+	}
+}
+
+func BenchmarkAddRowReserve100k(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ss := spreadsheet.New()
+		sheet := ss.AddSheet()
+		sheet.Reserve(100000)
+		for r := 0; r < 100000; r++ {
+			sheet.AddRow()
+		}
+	}
+}
+
+func BenchmarkAddRows100k(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ss := spreadsheet.New()
+		sheet := ss.AddSheet()
+		sheet.AddRows(100000)
+	}
+}
+
+// BenchmarkAddRowSparse adds rows after a sheet with gaps in its row numbers,
+// which previously required scanning every row to find the next row number.
+func BenchmarkAddRowSparse(b *testing.B) {
+	ss := spreadsheet.New()
+	sheet := ss.AddSheet()
+	for r := uint32(1); r <= 10000; r++ {
+		sheet.AddNumberedRow(2 * r)
+	}
+	b.ResetTimer()
+	for r := 0; r < b.N; r++ {
+		sheet.AddRow()
+	}
+}
+
+func BenchmarkRow50k(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ss := spreadsheet.New()
+		sheet := ss.AddSheet()
+		for r := uint32(1); r <= 50000; r++ {
+			sheet.Row(2 * r).AddCell()
+		}
+		for r := uint32(1); r <= 50000; r++ {
+			sheet.Row(2 * r).AddCell()
+		}
+	}
+}
+
+func BenchmarkRowCell1000Columns(b *testing.B) {
+	cols := make([]string, 1000)
+	for i := range cols {
+		cols[i] = reference.IndexToColumn(uint32(i))
+	}
+	for i := 0; i < b.N; i++ {
+		ss := spreadsheet.New()
+		row := ss.AddSheet().AddRow()
+		for _, col := range cols {
+			row.Cell(col).SetNumber(1)
+		}
+		for _, col := range cols {
+			row.Cell(col).SetNumber(2)
+		}
+	}
+}
@@ -8,12 +8,20 @@
 package spreadsheet_test
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
 	"fmt"
 	"math"
 	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/color"
+	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/sml"
 	"github.com/unidoc/unioffice/spreadsheet"
 	"github.com/unidoc/unioffice/spreadsheet/reference"
 )
@@ -88,6 +96,171 @@ func TestRowNumberValidation(t *testing.T) {
 	}
 }
 
+func TestCellReferenceValidation(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.AddNumberedRow(1048576).AddCell().SetString("last row")
+	sheet.Row(1).Cell("XFD").SetString("last column")
+	if err := sheet.Validate(); err != nil {
+		t.Errorf("expected no validation error, got %s", err)
+	}
+
+	sheet.AddNumberedRow(1048577)
+	if err := sheet.Validate(); err == nil {
+		t.Errorf("expected validation error with a row beyond 1048576")
+	}
+
+	wb = spreadsheet.New()
+	sheet = wb.AddSheet()
+	sheet.Row(1).Cell("A").X().RAttr = unioffice.String("XFE1")
+	if err := sheet.Validate(); err == nil {
+		t.Errorf("expected validation error with a column beyond XFD")
+	}
+
+	for _, ref := range []string{"A-1", "1A", "A2", "A0", "A$1", "Sheet1!A1", "a1"} {
+		wb = spreadsheet.New()
+		sheet = wb.AddSheet()
+		sheet.Row(1).Cell("A").X().RAttr = unioffice.String(ref)
+		err := sheet.Validate()
+		if err == nil {
+			t.Errorf("expected validation error with cell reference %s", ref)
+		} else if !strings.Contains(err.Error(), ref) {
+			t.Errorf("expected validation error to name cell %s, got %s", ref, err)
+		}
+	}
+}
+
+func TestForEachCellInRange(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	for r := 1; r <= 5; r++ {
+		row := sheet.Row(uint32(r))
+		row.Cell("A").SetNumber(float64(r))
+		row.Cell("B").SetNumber(float64(10 * r))
+	}
+	sheet.Cell("D1").SetNumber(1000)
+
+	sum := 0.0
+	sheet.ForEachCellInRange("B5:A2", func(c spreadsheet.Cell) {
+		v, err := c.GetValueAsNumber()
+		if err != nil {
+			t.Errorf("expected a number in %s, got %s", c.Reference(), err)
+		}
+		sum += v
+	})
+	if exp := 154.0; sum != exp {
+		t.Errorf("expected sum = %v, got %v", exp, sum)
+	}
+
+	// only existing cells are visited
+	n := 0
+	sheet.ForEachCellInRange("C1:C10", func(c spreadsheet.Cell) { n++ })
+	if n != 0 {
+		t.Errorf("expected no cells in C1:C10, got %d", n)
+	}
+	if len(sheet.Rows()) != 5 {
+		t.Errorf("expected 5 rows, got %d", len(sheet.Rows()))
+	}
+
+	rng := sheet.Range("C3:A1")
+	if rng.Reference() != "A1:C3" {
+		t.Errorf("expected normalized range A1:C3, got %s", rng.Reference())
+	}
+	cells := rng.Cells()
+	if len(cells) != 9 {
+		t.Fatalf("expected 9 cells, got %d", len(cells))
+	}
+	if cells[0].Reference() != "A1" || cells[8].Reference() != "C3" {
+		t.Errorf("expected cells in row major order, got %s to %s", cells[0].Reference(), cells[8].Reference())
+	}
+	if len(sheet.Row(1).Cells()) != 4 {
+		t.Errorf("expected missing cells to be created, got %d cells in row 1", len(sheet.Row(1).Cells()))
+	}
+
+	rng = sheet.Range("A1:D5")
+	rng.SetCreateMissingCells(false)
+	if got := len(rng.Cells()); got != 14 {
+		t.Errorf("expected 14 existing cells, got %d", got)
+	}
+}
+
+func TestAutoFitColumn(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	for r := 1; r <= 3; r++ {
+		sheet.Row(uint32(r)).Cell("A").SetString("ab")
+		sheet.Row(uint32(r)).Cell("B").SetString("a much longer string value")
+	}
+	sheet.Cell("C1").SetNumber(1234567.5)
+	sheet.Cell("D1").SetNumber(1234567.5)
+	cs := wb.StyleSheet.AddCellStyle()
+	cs.SetNumberFormat("#,##0.00")
+	sheet.Cell("D1").SetStyle(cs)
+	sheet.SetColumnWidthRange("A", "D", 30*measurement.Character)
+
+	sheet.AutoFitAllColumns()
+
+	width := func(idx uint32) float64 {
+		w := sheet.Column(idx).X().WidthAttr
+		if w == nil {
+			t.Fatalf("expected column %d to have a width", idx)
+		}
+		return *w
+	}
+	if width(1) >= width(2) {
+		t.Errorf("expected column with long strings to be wider, got %v and %v", width(1), width(2))
+	}
+	if width(3) >= width(4) {
+		t.Errorf("expected number format to be considered, got %v and %v", width(3), width(4))
+	}
+	if len(sheet.X().Cols[0].Col) != 4 {
+		t.Errorf("expected the column range to be split into 4 columns, got %d", len(sheet.X().Cols[0].Col))
+	}
+	if len(sheet.Row(1).Cells()) != 4 {
+		t.Errorf("expected auto fit not to create cells, got %d", len(sheet.Row(1).Cells()))
+	}
+
+	before := width(1)
+	sheet.Cell("A4").SetString("a string that is longer than the others")
+	sheet.AutoFitColumn("A")
+	if width(1) <= before || width(1) <= width(2) {
+		t.Errorf("expected column A to widen, got %v", width(1))
+	}
+}
+
+func TestTabColor(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.SetTabColor(color.Red)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	exp := `<ma:sheetPr><ma:tabColor rgb="ffff0000"/></ma:sheetPr>`
+	if got := zipContents(t, buf.Bytes())["xl/worksheets/sheet1.xml"]; !strings.Contains(got, exp) {
+		t.Errorf("expected %s in worksheet, got %s", exp, got)
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	pr := wb2.Sheets()[0].X().SheetPr
+	if pr == nil || pr.TabColor == nil || pr.TabColor.RgbAttr == nil || *pr.TabColor.RgbAttr != "ffff0000" {
+		t.Errorf("expected tab color to round trip")
+	}
+
+	sheet.SetTabThemeColor(common.ThemeColorAccent1, 0)
+	if tc := sheet.X().SheetPr.TabColor; tc.ThemeAttr == nil || *tc.ThemeAttr != 4 || tc.RgbAttr != nil {
+		t.Errorf("expected theme tab color")
+	}
+	sheet.ClearTabColor()
+	if sheet.X().SheetPr.TabColor != nil {
+		t.Errorf("expected tab color to be removed")
+	}
+}
+
 func TestAutoFilter(t *testing.T) {
 	wb := spreadsheet.New()
 	sheet := wb.AddSheet()
@@ -346,6 +519,123 @@ func TestSortStrings(t *testing.T) {
 	}
 }
 
+func TestSheetValidateTables(t *testing.T) {
+	newSheet := func() (*spreadsheet.Workbook, spreadsheet.Sheet) {
+		wb := spreadsheet.New()
+		sheet := wb.AddSheet()
+		for r := 1; r <= 10; r++ {
+			for _, c := range []string{"A", "B", "C", "E", "F"} {
+				sheet.Cell(fmt.Sprintf("%s%d", c, r)).SetString(fmt.Sprintf("%s%d", c, r))
+			}
+		}
+		if _, err := sheet.AddTable("A1:C10", "Data"); err != nil {
+			t.Fatalf("error adding table: %s", err)
+		}
+		return wb, sheet
+	}
+
+	wb, sheet := newSheet()
+	sheet.SetFrozen(true, false)
+	sheet.SetAutoFilter("E1:F10")
+	sheet.AddMergedCells("E12", "F12")
+	if err := sheet.Validate(); err != nil {
+		t.Errorf("expected no error for separate ranges, got %s", err)
+	}
+	if len(sheet.Tables()) != 1 || sheet.Tables()[0].Name() != "Data" {
+		t.Errorf("expected the sheet to have the Data table")
+	}
+
+	_, sheet = newSheet()
+	sheet.SetAutoFilter("A1:C10")
+	if err := sheet.Validate(); err == nil || !strings.Contains(err.Error(), "auto filter") {
+		t.Errorf("expected an auto filter conflict, got %v", err)
+	}
+
+	_, sheet = newSheet()
+	sheet.AddMergedCells("C5", "D5")
+	if err := sheet.Validate(); err == nil || !strings.Contains(err.Error(), "merged cell C5:D5") {
+		t.Errorf("expected a merged cell conflict, got %v", err)
+	}
+
+	_, sheet = newSheet()
+	if _, err := sheet.AddTable("B5:E10", "Overlap"); err != nil {
+		t.Fatalf("error adding table: %s", err)
+	}
+	if err := sheet.Validate(); err == nil || !strings.Contains(err.Error(), "table Overlap overlaps table Data") {
+		t.Errorf("expected a table conflict, got %v", err)
+	}
+	if err := wb.Validate(); err != nil {
+		t.Errorf("expected the first workbook to remain valid, got %s", err)
+	}
+}
+
+func TestSortRange(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Cell("A1").SetString("Name")
+	sheet.Cell("B1").SetString("Dept")
+	sheet.Cell("C1").SetString("Salary")
+	for i, r := range []struct {
+		name, dept string
+		salary     float64
+	}{
+		{"Alice", "Sales", 50},
+		{"Bob", "IT", 70},
+		{"Carol", "Sales", 65},
+		{"Dan", "IT", 70},
+		{"Eve", "", 40},
+	} {
+		row := sheet.Row(uint32(i + 2))
+		row.Cell("A").SetString(r.name)
+		if r.dept != "" {
+			row.Cell("B").SetString(r.dept)
+		}
+		row.Cell("C").SetNumber(r.salary)
+		row.Cell("D").SetNumber(float64(i + 1))
+	}
+
+	err := sheet.SortRange("A2:C6", []spreadsheet.SortKey{
+		{Column: "B", Order: spreadsheet.SortOrderAscending},
+		{Column: "C", Order: spreadsheet.SortOrderDescending},
+	})
+	if err != nil {
+		t.Fatalf("error sorting: %s", err)
+	}
+	expNames := []string{"Bob", "Dan", "Carol", "Alice", "Eve"}
+	expDepts := []string{"IT", "IT", "Sales", "Sales", ""}
+	expSalaries := []float64{70, 70, 65, 50, 40}
+	for i := range expNames {
+		row := uint32(i + 2)
+		if got := sheet.Cell(fmt.Sprintf("A%d", row)).GetString(); got != expNames[i] {
+			t.Errorf("expected %s in A%d, got %s", expNames[i], row, got)
+		}
+		if got := sheet.Cell(fmt.Sprintf("B%d", row)).GetString(); got != expDepts[i] {
+			t.Errorf("expected %s in B%d, got %s", expDepts[i], row, got)
+		}
+		if got, _ := sheet.Cell(fmt.Sprintf("C%d", row)).GetValueAsNumber(); got != expSalaries[i] {
+			t.Errorf("expected %f in C%d, got %f", expSalaries[i], row, got)
+		}
+		// cells outside of the range aren't moved
+		if got, _ := sheet.Cell(fmt.Sprintf("D%d", row)).GetValueAsNumber(); got != float64(i+1) {
+			t.Errorf("expected %d in D%d, got %f", i+1, row, got)
+		}
+	}
+	if got := sheet.Cell("A1").GetString(); got != "Name" {
+		t.Errorf("expected the header to be unchanged, got %s", got)
+	}
+	if err := sheet.Validate(); err != nil {
+		t.Errorf("expected a valid sheet, got %s", err)
+	}
+
+	if err := sheet.SortRange("A2:C6", []spreadsheet.SortKey{{Column: "D"}}); err == nil {
+		t.Errorf("expected an error sorting by a column outside of the range")
+	}
+	sheet.AddMergedCells("B8", "C8")
+	if err := sheet.SortRange("A7:C9", []spreadsheet.SortKey{{Column: "A"}}); err == nil {
+		t.Errorf("expected an error sorting a range containing merged cells")
+	}
+}
+
 func TestRemoveColumn(t *testing.T) {
 	wb := spreadsheet.New()
 	sheet := wb.AddSheet()
@@ -369,3 +659,1054 @@ func TestRemoveColumn(t *testing.T) {
 		}
 	}
 }
+
+func TestRemoveRow(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	for i := 1; i <= 5; i++ {
+		sheet.Cell(fmt.Sprintf("A%d", i)).SetNumber(float64(i))
+	}
+
+	sheet.RemoveRow(sheet.Row(2))
+	sheet.RemoveRowByNumber(4)
+	// removing a row that doesn't exist is a no-op
+	sheet.RemoveRowByNumber(4)
+	sheet.RemoveRowByNumber(100)
+
+	rows := sheet.Rows()
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+	// remaining rows are not renumbered
+	for i, exp := range []uint32{1, 3, 5} {
+		if got := rows[i].RowNumber(); got != exp {
+			t.Errorf("expected row number %d, got %d", exp, got)
+		}
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook after removing rows, got %s", err)
+	}
+}
+
+func TestRemoveRowShifting(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	for i := 1; i <= 5; i++ {
+		sheet.Cell(fmt.Sprintf("A%d", i)).SetNumber(float64(i))
+		sheet.Cell(fmt.Sprintf("C%d", i)).SetNumber(float64(i * 10))
+	}
+
+	sheet.RemoveRowShifting(2)
+
+	rows := sheet.Rows()
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(rows))
+	}
+	for i, exp := range []float64{1, 3, 4, 5} {
+		rowNum := uint32(i + 1)
+		if got := rows[i].RowNumber(); got != rowNum {
+			t.Errorf("expected row number %d, got %d", rowNum, got)
+		}
+		for _, c := range rows[i].X().C {
+			cref, err := reference.ParseCellReference(*c.RAttr)
+			if err != nil {
+				t.Fatalf("error parsing cell reference: %s", err)
+			}
+			if cref.RowIdx != rowNum {
+				t.Errorf("expected cell %s to be in row %d", *c.RAttr, rowNum)
+			}
+		}
+		got, _ := sheet.Cell(fmt.Sprintf("A%d", rowNum)).GetValueAsNumber()
+		if got != exp {
+			t.Errorf("expected %f in A%d, got %f", exp, rowNum, got)
+		}
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook after removing rows, got %s", err)
+	}
+}
+
+func TestRemoveRowShiftingUpdatesReferences(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.SetName("Data")
+	other := wb.AddSheet()
+	for i := 1; i <= 6; i++ {
+		sheet.Cell(fmt.Sprintf("A%d", i)).SetNumber(float64(i))
+	}
+	sheet.Cell("B1").SetFormulaRaw("A5*2")
+	sheet.Cell("B2").SetFormulaRaw("SUM(A1:A6)")
+	sheet.Cell("E6").SetFormulaRaw("A3+A4")
+	other.Cell("A1").SetFormulaRaw("Data!A6")
+	sheet.AddMergedCells("C4", "D5")
+	sheet.AddMergedCells("C3", "D3")
+	wb.AddDefinedName("Values", "Data!$A$4:$A$6")
+	cf := sheet.AddConditionalFormatting([]string{"A5:A6", "A3"})
+	rule := cf.AddRule()
+	rule.SetType(sml.ST_CfTypeExpression)
+	rule.SetConditionValue("A5>4")
+	dv := sheet.AddDataValidation()
+	dv.SetRange("A6")
+
+	sheet.RemoveRowShifting(3)
+
+	for ref, exp := range map[string]string{
+		"B1": "A4*2",
+		"B2": "SUM(A1:A5)",
+		"E5": "#REF!+A3",
+	} {
+		if got := sheet.Cell(ref).GetFormula(); got != exp {
+			t.Errorf("expected %s to be %s, got %s", ref, exp, got)
+		}
+	}
+	if got := other.Cell("A1").GetFormula(); got != "Data!A5" {
+		t.Errorf("expected the other sheet's formula to be updated, got %s", got)
+	}
+	merged := sheet.MergedCells()
+	if len(merged) != 1 || merged[0].Reference() != "C3:D4" {
+		t.Errorf("expected the merged cells to move up and those in the removed row to be removed, got %v", merged)
+	}
+	if got := wb.DefinedNames()[0].Content(); got != "Data!$A$3:$A$5" {
+		t.Errorf("expected the defined name to be updated, got %s", got)
+	}
+	if got := *cf.X().SqrefAttr; len(got) != 1 || got[0] != "A4:A5" {
+		t.Errorf("expected the conditional formatting range to be updated, got %v", got)
+	}
+	if got := rule.X().Formula[0]; got != "A4>4" {
+		t.Errorf("expected the conditional formatting formula to be updated, got %s", got)
+	}
+	if got := dv.X().SqrefAttr; len(got) != 1 || got[0] != "A5" {
+		t.Errorf("expected the data validation range to be updated, got %v", got)
+	}
+
+	// values are still calculated from the moved rows
+	sheet.RecalculateFormulas()
+	if got := sheet.Cell("B2").GetFormattedValue(); got != "18" {
+		t.Errorf("expected SUM to exclude the removed row, got %s", got)
+	}
+	if err := wb.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+}
+
+func TestInsertRow(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	for i := 1; i <= 100; i++ {
+		sheet.Cell(fmt.Sprintf("A%d", i)).SetNumber(float64(i))
+		sheet.Cell(fmt.Sprintf("B%d", i)).SetFormulaRaw(fmt.Sprintf("A%d*2", i))
+	}
+	sheet.Cell("C1").SetFormulaRaw("SUM(A1:A100)")
+	other := wb.AddSheet()
+	other.Cell("A1").SetFormulaRaw("'Sheet 1'!A60+'Sheet 1'!A10")
+
+	r := sheet.InsertRow(50)
+	if r.RowNumber() != 50 {
+		t.Errorf("expected inserted row number 50, got %d", r.RowNumber())
+	}
+	if len(r.X().C) != 0 {
+		t.Errorf("expected inserted row to be empty, had %d cells", len(r.X().C))
+	}
+
+	rows := sheet.Rows()
+	if len(rows) != 101 {
+		t.Fatalf("expected 101 rows, got %d", len(rows))
+	}
+	for i, r := range rows {
+		if r.RowNumber() != uint32(i+1) {
+			t.Fatalf("expected row number %d, got %d", i+1, r.RowNumber())
+		}
+	}
+
+	for _, tc := range []struct {
+		ref string
+		exp float64
+	}{
+		{"A49", 49},
+		{"A51", 50},
+		{"A101", 100},
+	} {
+		got, _ := sheet.Cell(tc.ref).GetValueAsNumber()
+		if got != tc.exp {
+			t.Errorf("expected %f in %s, got %f", tc.exp, tc.ref, got)
+		}
+	}
+
+	for _, tc := range []struct {
+		sheet spreadsheet.Sheet
+		ref   string
+		exp   string
+	}{
+		{sheet, "B49", "A49*2"},
+		{sheet, "B51", "A51*2"},
+		{sheet, "C1", "SUM(A1:A101)"},
+		{other, "A1", "'Sheet 1'!A61+'Sheet 1'!A10"},
+	} {
+		if got := tc.sheet.Cell(tc.ref).GetFormula(); got != tc.exp {
+			t.Errorf("expected formula %s in %s, got %s", tc.exp, tc.ref, got)
+		}
+	}
+
+	if err := wb.Validate(); err != nil {
+		t.Errorf("expected a valid workbook after inserting a row, got %s", err)
+	}
+}
+
+func TestColumnByName(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	c := sheet.ColumnByName("C")
+	c.SetWidth(10 * measurement.Character)
+	if c.X().MinAttr != 3 || c.X().MaxAttr != 3 {
+		t.Errorf("expected column C to have min/max of 3, got %d/%d", c.X().MinAttr, c.X().MaxAttr)
+	}
+	if sheet.Column(3).X() != c.X() {
+		t.Errorf("expected ColumnByName and Column to return the same column")
+	}
+	if got := *c.X().WidthAttr; got != 10 {
+		t.Errorf("expected width of 10 characters, got %f", got)
+	}
+}
+
+func TestSetColumnWidthRange(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Column(1).SetHidden(true)
+	sheet.ColumnByName("C").SetWidth(5 * measurement.Character)
+	wide := sheet.SetColumnWidthRange("A", "F", 8*measurement.Character)
+	wide.SetHidden(true)
+	sheet.SetColumnWidthRange("C", "D", 20*measurement.Character)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+
+	cols := wb2.Sheets()[0].X().Cols
+	if len(cols) != 1 {
+		t.Fatalf("expected a single column set, got %d", len(cols))
+	}
+	exp := []struct {
+		min, max uint32
+		width    float64
+	}{
+		{1, 2, 8},
+		{3, 4, 20},
+		{5, 6, 8},
+	}
+	if len(cols[0].Col) != len(exp) {
+		t.Fatalf("expected %d columns, got %d", len(exp), len(cols[0].Col))
+	}
+	for i, e := range exp {
+		col := cols[0].Col[i]
+		if col.MinAttr != e.min || col.MaxAttr != e.max {
+			t.Errorf("expected column %d-%d, got %d-%d", e.min, e.max, col.MinAttr, col.MaxAttr)
+		}
+		if col.WidthAttr == nil || *col.WidthAttr != e.width {
+			t.Errorf("expected column %d-%d to have width %f", e.min, e.max, e.width)
+		}
+	}
+}
+
+func TestRowHeight(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	header := sheet.Row(1)
+	header.SetHeight(40 * measurement.Point)
+	sized := sheet.Row(2)
+	sized.SetHeight(1 * measurement.Inch)
+	sized.SetHeightAuto()
+	if sized.X().HtAttr != nil || sized.X().CustomHeightAttr != nil {
+		t.Errorf("expected clearing the height to remove the height attributes")
+	}
+	sheet.Row(3).SetHidden(true)
+	sheet.Row(4).SetOutlineLevel(2)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+
+	sheet2 := wb2.Sheets()[0]
+	if h, ok := sheet2.Row(1).Height(); !ok || h != 40*measurement.Point {
+		t.Errorf("expected a 40pt header row, got %v", h)
+	}
+	if _, ok := sheet2.Row(2).Height(); ok {
+		t.Errorf("expected no custom height on row 2")
+	}
+	if !sheet2.Row(3).IsHidden() {
+		t.Errorf("expected row 3 to be hidden")
+	}
+	if got := sheet2.Row(4).OutlineLevel(); got != 2 {
+		t.Errorf("expected outline level 2, got %d", got)
+	}
+	if fp := sheet2.X().SheetFormatPr; fp == nil || fp.OutlineLevelRowAttr == nil || *fp.OutlineLevelRowAttr != 2 {
+		t.Errorf("expected the sheet outline level to be 2")
+	}
+}
+
+func TestOutlineGrouping(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.ColumnByName("C").SetWidth(20 * measurement.Character)
+	sheet.GroupRows(2, 9)
+	sheet.GroupRows(3, 5)
+	sheet.GroupRows(0, 4)
+	sheet.GroupRows(8, 7)
+	sheet.SetRowsCollapsed(3, 5, true)
+	sheet.SetRowsCollapsed(0, 1, true)
+	sheet.GroupColumns("B", "E")
+	sheet.GroupColumns("C", "D")
+	sheet.SetOutlineSummaryBelow(false)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+
+	sheet2 := wb2.Sheets()[0]
+	for _, tc := range []struct {
+		row uint32
+		exp uint8
+	}{{1, 0}, {2, 1}, {3, 2}, {5, 2}, {6, 1}, {7, 1}, {8, 1}, {9, 1}, {10, 0}} {
+		if got := sheet2.Row(tc.row).OutlineLevel(); got != tc.exp {
+			t.Errorf("expected row %d to have outline level %d, got %d", tc.row, tc.exp, got)
+		}
+	}
+	for _, r := range sheet2.Rows() {
+		if r.RowNumber() == 0 {
+			t.Errorf("expected no row 0 to be created")
+		}
+	}
+	if sheet2.Row(1).IsHidden() {
+		t.Errorf("expected an invalid range to not be collapsed")
+	}
+	if !sheet2.Row(4).IsHidden() || sheet2.Row(6).IsHidden() {
+		t.Errorf("expected only the collapsed rows to be hidden")
+	}
+	if c := sheet2.Row(6).X().CollapsedAttr; c == nil || !*c {
+		t.Errorf("expected the summary row to be marked collapsed")
+	}
+
+	fp := sheet2.X().SheetFormatPr
+	if fp == nil || fp.OutlineLevelRowAttr == nil || *fp.OutlineLevelRowAttr != 2 ||
+		fp.OutlineLevelColAttr == nil || *fp.OutlineLevelColAttr != 2 {
+		t.Fatalf("expected the sheet to record two outline levels for rows and columns")
+	}
+	if op := sheet2.X().SheetPr.OutlinePr; op == nil || op.SummaryBelowAttr == nil || *op.SummaryBelowAttr {
+		t.Errorf("expected summary rows to be above the groups")
+	}
+
+	exp := map[uint32]uint8{2: 1, 3: 2, 4: 2, 5: 1}
+	for _, col := range sheet2.X().Cols[0].Col {
+		if col.MinAttr != col.MaxAttr {
+			continue
+		}
+		lvl := uint8(0)
+		if col.OutlineLevelAttr != nil {
+			lvl = *col.OutlineLevelAttr
+		}
+		if lvl != exp[col.MinAttr] {
+			t.Errorf("expected column %d to have outline level %d, got %d", col.MinAttr, exp[col.MinAttr], lvl)
+		}
+		delete(exp, col.MinAttr)
+	}
+	if len(exp) != 0 {
+		t.Errorf("expected a column definition for each grouped column, missing %v", exp)
+	}
+	if w := sheet2.ColumnByName("C").X().WidthAttr; w == nil || *w != 20 {
+		t.Errorf("expected grouping to preserve the column width")
+	}
+}
+
+func TestCollapseColumns(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.GroupColumns("B", "D")
+	sheet.SetColumnsCollapsed("B", "D", true)
+	if h := sheet.ColumnByName("C").X().HiddenAttr; h == nil || !*h {
+		t.Errorf("expected the collapsed columns to be hidden")
+	}
+	if h := sheet.ColumnByName("E").X().HiddenAttr; h != nil && *h {
+		t.Errorf("expected the summary column not to be hidden")
+	}
+	if c := sheet.ColumnByName("E").X().CollapsedAttr; c == nil || !*c {
+		t.Errorf("expected the summary column to the right to be marked collapsed")
+	}
+
+	// summary columns to the left of the groups
+	left := wb.AddSheet()
+	left.SetOutlineSummaryRight(false)
+	left.GroupColumns("B", "D")
+	left.SetColumnsCollapsed("B", "D", true)
+	if c := left.ColumnByName("A").X().CollapsedAttr; c == nil || !*c {
+		t.Errorf("expected the summary column to the left to be marked collapsed")
+	}
+	if c := left.ColumnByName("E").X().CollapsedAttr; c != nil {
+		t.Errorf("expected the column to the right not to be marked collapsed")
+	}
+	left.SetColumnsCollapsed("B", "D", false)
+	if c := left.ColumnByName("A").X().CollapsedAttr; c != nil {
+		t.Errorf("expected the summary column to be expanded")
+	}
+	if op := left.X().SheetPr.OutlinePr; op == nil || op.SummaryRightAttr == nil || *op.SummaryRightAttr {
+		t.Errorf("expected summary columns to be left of the groups")
+	}
+
+	if err := wb.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+}
+
+func TestSheetViewToggles(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.SetShowGridlines(false)
+	sheet.SetShowRowColHeaders(false)
+	sheet.SetZoom(125)
+	sheet.SetZoom(1000)
+	other := wb.AddSheet()
+	other.SetShowGridlines(false)
+	other.SetShowGridlines(true)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+
+	sv := wb2.Sheets()[0].X().SheetViews.SheetView[0]
+	if sv.ShowGridLinesAttr == nil || *sv.ShowGridLinesAttr {
+		t.Errorf("expected gridlines to be hidden")
+	}
+	if sv.ShowRowColHeadersAttr == nil || *sv.ShowRowColHeadersAttr {
+		t.Errorf("expected headings to be hidden")
+	}
+	if sv.ZoomScaleAttr == nil || *sv.ZoomScaleAttr != 125 {
+		t.Errorf("expected a zoom of 125%%")
+	}
+	sv = wb2.Sheets()[1].X().SheetViews.SheetView[0]
+	if sv.ShowGridLinesAttr != nil {
+		t.Errorf("expected gridlines to use the default")
+	}
+}
+
+func TestSetFrozenRange(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.InitialView().SetZoom(150)
+
+	for _, tc := range []struct {
+		rows, cols uint32
+		exp        string
+	}{
+		{1, 1, `<pane xSplit="1" ySplit="1" topLeftCell="B2" activePane="bottomRight" state="frozen"></pane>`},
+		{1, 0, `<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"></pane>`},
+		{0, 2, `<pane xSplit="2" topLeftCell="C1" activePane="topRight" state="frozen"></pane>`},
+		{3, 2, `<pane xSplit="2" ySplit="3" topLeftCell="C4" activePane="bottomRight" state="frozen"></pane>`},
+	} {
+		sheet.SetFrozenRange(tc.rows, tc.cols)
+		views := sheet.SheetViews()
+		if len(views) != 1 {
+			t.Fatalf("expected a single sheet view, got %d", len(views))
+		}
+		if zoom := views[0].X().ZoomScaleAttr; zoom == nil || *zoom != 150 {
+			t.Errorf("expected the zoom level to be preserved")
+		}
+		got := bytes.Buffer{}
+		enc := xml.NewEncoder(&got)
+		if err := enc.EncodeElement(views[0].X().Pane, xml.StartElement{Name: xml.Name{Local: "pane"}}); err != nil {
+			t.Fatalf("error marshaling pane: %s", err)
+		}
+		enc.Flush()
+		if got.String() != tc.exp {
+			t.Errorf("expected %s, got %s", tc.exp, got.String())
+		}
+	}
+
+	sheet.SetFrozenRange(0, 0)
+	if sheet.InitialView().X().Pane != nil {
+		t.Errorf("expected no pane after unfreezing")
+	}
+}
+
+func TestMergeCells(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Cell("A1").SetString("title")
+	if _, err := sheet.MergeCells("A1", "D1"); err != nil {
+		t.Fatalf("expected no error merging cells, got %s", err)
+	}
+	if _, err := sheet.MergeCells("C1", "E2"); err == nil {
+		t.Errorf("expected an error merging overlapping cells")
+	}
+	if _, err := sheet.MergeCells("A2", "B3"); err != nil {
+		t.Errorf("expected no error merging cells, got %s", err)
+	}
+	if got := *sheet.X().MergeCells.CountAttr; got != 2 {
+		t.Errorf("expected merged cell count of 2, got %d", got)
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	mcs := wb2.Sheets()[0].MergedCells()
+	if len(mcs) != 2 {
+		t.Fatalf("expected 2 merged cells, got %d", len(mcs))
+	}
+	if mcs[0].Reference() != "A1:D1" {
+		t.Errorf("expected merged cell A1:D1, got %s", mcs[0].Reference())
+	}
+	if mcs[0].Cell().GetString() != "title" {
+		t.Errorf("expected merged cell content to be 'title', got '%s'", mcs[0].Cell().GetString())
+	}
+
+	mcs[0].Remove()
+	mcs[1].Remove()
+	if len(wb2.Sheets()[0].MergedCells()) != 0 {
+		t.Errorf("after removal, sheet should have no merged cells")
+	}
+	if wb2.Sheets()[0].X().MergeCells != nil {
+		t.Errorf("expected the empty merged cells container to be removed")
+	}
+}
+
+func TestAutoFilterRoundTrip(t *testing.T) {
+	wb := spreadsheet.New()
+	wb.AddSheet()
+	sheet := wb.AddSheet()
+	sheet.SetAutoFilter("A1:C10")
+	// renaming the sheet shouldn't cause a second filter name to be created
+	sheet.SetName("Data")
+	sheet.SetAutoFilter("$A$1:$D$20")
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	sheet2 := wb2.Sheets()[1]
+	if af := sheet2.X().AutoFilter; af == nil || af.RefAttr == nil || *af.RefAttr != "A1:D20" {
+		t.Errorf("expected an auto filter on A1:D20")
+	}
+	dns := wb2.DefinedNames()
+	if len(dns) != 1 {
+		t.Fatalf("expected a single defined name, got %d", len(dns))
+	}
+	dn := dns[0]
+	if exp := "'Data'!$A$1:$D$20"; dn.Content() != exp {
+		t.Errorf("expected defined name content = '%s', got %s", exp, dn.Content())
+	}
+	if dn.X().HiddenAttr == nil || !*dn.X().HiddenAttr {
+		t.Errorf("expected the filter database name to be hidden")
+	}
+	if dn.X().LocalSheetIdAttr == nil || *dn.X().LocalSheetIdAttr != 1 {
+		t.Errorf("expected the filter database name to be scoped to the second sheet")
+	}
+
+	sheet2.ClearAutoFilter()
+	if len(wb2.DefinedNames()) != 0 {
+		t.Errorf("clearing the filter should have removed the defined name")
+	}
+}
+
+func TestDataValidation(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+
+	dvList := sheet.AddDataValidation()
+	dvList.SetRange("A1:A10")
+	dvList.SetList().SetValues([]string{"a", "b", `say "c"`})
+
+	dvRef := sheet.AddDataValidation()
+	dvRef.SetRange("B1:B10")
+	dvRef.SetList().SetRange("$D$1:$D$5")
+
+	dvNum := sheet.AddDataValidation()
+	dvNum.SetRange("C1:C10")
+	cmp := dvNum.SetComparison(spreadsheet.DVCompareTypeWholeNumber, spreadsheet.DVCompareOpBetween)
+	cmp.SetValue("1")
+	cmp.SetValue2("10")
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	dvs := wb2.Sheets()[0].X().DataValidations
+	if dvs == nil || len(dvs.DataValidation) != 3 || *dvs.CountAttr != 3 {
+		t.Fatalf("expected three data validations")
+	}
+	for i, exp := range []string{`"a,b,say ""c"""`, "$D$1:$D$5", "1"} {
+		if got := *dvs.DataValidation[i].Formula1; got != exp {
+			t.Errorf("expected formula %s, got %s", exp, got)
+		}
+	}
+	if got := dvs.DataValidation[2].TypeAttr; got != sml.ST_DataValidationTypeWhole {
+		t.Errorf("expected whole number validation, got %s", got)
+	}
+}
+
+func TestConditionalFormatting(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	for i := 1; i <= 10; i++ {
+		sheet.Cell(fmt.Sprintf("A%d", i)).SetNumber(float64(i))
+	}
+
+	dxf := wb.StyleSheet.AddDifferentialStyle()
+	dxf.Fill().SetPatternFill().SetBgColor(color.Red)
+	dxf.Font().SetBold(true)
+
+	cf := sheet.AddConditionalFormatting([]string{"A1:A10"})
+	gt := cf.AddRule()
+	gt.SetConditionValue("5")
+	gt.SetStyle(dxf)
+
+	bt := cf.AddRule()
+	bt.SetOperator(sml.ST_ConditionalFormattingOperatorBetween)
+	bt.SetConditionValues("2", "4")
+	bt.SetStyle(dxf)
+
+	cs := sheet.AddConditionalFormatting([]string{"B1:B10"}).AddRule()
+	scale := cs.SetColorScale()
+	scale.AddFormatValue(sml.ST_CfvoTypeMin, "0")
+	scale.AddGradientStop(color.Red)
+	scale.AddFormatValue(sml.ST_CfvoTypeMax, "0")
+	scale.AddGradientStop(color.Green)
+
+	db := sheet.AddConditionalFormatting([]string{"C1:C10"}).AddRule()
+	db.SetDataBar()
+	cs.SetColorScale()
+	if cs.X().DataBar != nil {
+		t.Errorf("expected switching rule type to clear the data bar")
+	}
+
+	for i, r := range []spreadsheet.ConditionalFormattingRule{gt, bt, cs, db} {
+		if r.Priority() != int32(i+1) {
+			t.Errorf("expected priority %d, got %d", i+1, r.Priority())
+		}
+	}
+	if len(bt.X().Formula) != 2 {
+		t.Errorf("expected two formulas for between, got %d", len(bt.X().Formula))
+	}
+	if gt.X().DxfIdAttr == nil || *gt.X().DxfIdAttr != dxf.Index() {
+		t.Errorf("expected rule to reference the differential style")
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	cfmts := wb2.Sheets()[0].X().ConditionalFormatting
+	if len(cfmts) != 3 {
+		t.Fatalf("expected 3 conditional formattings, got %d", len(cfmts))
+	}
+	if cfmts[0].CfRule[1].OperatorAttr != sml.ST_ConditionalFormattingOperatorBetween {
+		t.Errorf("expected between operator, got %s", cfmts[0].CfRule[1].OperatorAttr)
+	}
+	dxfs := wb2.StyleSheet.X().Dxfs
+	if dxfs == nil || len(dxfs.Dxf) != 1 || dxfs.Dxf[0].Font == nil {
+		t.Errorf("expected differential style with a font to round trip")
+	}
+}
+
+func TestSheetProtection(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	unlocked := wb.StyleSheet.AddCellStyle()
+	unlocked.SetLocked(false)
+	sheet.Cell("A1").SetStyle(unlocked)
+	if unlocked.Locked() {
+		t.Errorf("expected style to be unlocked")
+	}
+
+	prot := sheet.Protection()
+	prot.LockSheet(true)
+	prot.SetPassword("password")
+	if prot.PasswordHash() != "83AF" {
+		t.Errorf("expected legacy hash 83AF, got %s", prot.PasswordHash())
+	}
+	if err := prot.SetPasswordSHA512("password"); err != nil {
+		t.Fatalf("error setting password: %s", err)
+	}
+	if prot.X().PasswordAttr != nil {
+		t.Errorf("expected modern password to clear the legacy hash")
+	}
+	prot.SetSelectLockedCells(true)
+	prot.SetFormatCells(false)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	sp := wb2.Sheets()[0].X().SheetProtection
+	if sp == nil || sp.AlgorithmNameAttr == nil || *sp.AlgorithmNameAttr != "SHA-512" {
+		t.Fatalf("expected SHA-512 protection to round trip")
+	}
+	salt, err := base64.StdEncoding.DecodeString(*sp.SaltValueAttr)
+	if err != nil {
+		t.Fatalf("error decoding salt: %s", err)
+	}
+	if exp := spreadsheet.PasswordHashSHA512("password", salt, *sp.SpinCountAttr); *sp.HashValueAttr != exp {
+		t.Errorf("expected hash %s, got %s", exp, *sp.HashValueAttr)
+	}
+	if sp.SelectLockedCellsAttr == nil || !*sp.SelectLockedCellsAttr {
+		t.Errorf("expected selectLockedCells to round trip")
+	}
+	if sp.FormatCellsAttr == nil || *sp.FormatCellsAttr {
+		t.Errorf("expected formatCells to round trip")
+	}
+}
+
+func TestSetStyleRange(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Cell("C5").SetNumber(1.5)
+	cs := wb.StyleSheet.AddCellStyle().SetNumberFormat(`"$"#,##0.00`)
+
+	if err := sheet.SetStyleRange("B2", "D10", cs); err != nil {
+		t.Fatalf("error styling range: %s", err)
+	}
+	for r := 2; r <= 10; r++ {
+		for _, col := range []string{"B", "C", "D"} {
+			ref := fmt.Sprintf("%s%d", col, r)
+			s := sheet.Cell(ref).X().SAttr
+			if s == nil || *s != cs.Index() {
+				t.Errorf("expected %s to have style %d", ref, cs.Index())
+			}
+		}
+	}
+	if got := sheet.Cell("C5").GetString(); got != "1.5" {
+		t.Errorf("expected styling to preserve the value, got %s", got)
+	}
+	if s := sheet.Cell("E2").X().SAttr; s != nil {
+		t.Errorf("expected E2 to be unstyled")
+	}
+
+	if err := sheet.SetStyleRange("B2", "not a ref", cs); err == nil {
+		t.Errorf("expected an error for an invalid reference")
+	}
+
+	row := sheet.Row(12)
+	row.SetStyle(cs)
+	if row.X().SAttr == nil || *row.X().SAttr != cs.Index() || row.X().CustomFormatAttr == nil || !*row.X().CustomFormatAttr {
+		t.Errorf("expected row style to be set")
+	}
+	if err := wb.Validate(); err != nil {
+		t.Errorf("created an invalid spreadsheet: %s", err)
+	}
+}
+
+func TestApplyBanding(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	for r := 1; r <= 20; r++ {
+		row := sheet.AddRow()
+		row.AddCell().SetNumber(float64(r))
+		if r != 7 {
+			// leave a gap that banding must fill
+			row.AddCell().SetString("x")
+		}
+		row.AddCell().SetNumber(float64(r * 2))
+	}
+	even := wb.StyleSheet.AddCellStyle().SetFillColor(color.LightGray)
+	odd := wb.StyleSheet.AddCellStyle().SetFillColor(color.White)
+
+	// whole columns are limited to the sheet's content
+	if err := sheet.ApplyBanding("A1:E1048576", even, odd); err != nil {
+		t.Fatalf("error banding: %s", err)
+	}
+	if got := len(sheet.Rows()); got != 20 {
+		t.Errorf("expected banding to not add rows, got %d rows", got)
+	}
+	for r := uint32(1); r <= 20; r++ {
+		exp := odd.Index()
+		if r%2 == 0 {
+			exp = even.Index()
+		}
+		row := sheet.Row(r)
+		if got := len(row.Cells()); got != 3 {
+			t.Errorf("expected 3 cells in row %d, got %d", r, got)
+		}
+		for _, col := range []string{"A", "B", "C"} {
+			s := row.Cell(col).X().SAttr
+			if s == nil || *s != exp {
+				t.Errorf("expected %s%d to have style %d", col, r, exp)
+			}
+		}
+	}
+	if got := sheet.Cell("A20").GetString(); got != "20" {
+		t.Errorf("expected banding to preserve values, got %s", got)
+	}
+
+	if err := sheet.ApplyBanding("A1", even, odd); err == nil {
+		t.Errorf("expected an error for an invalid range")
+	}
+	if err := wb.Validate(); err != nil {
+		t.Errorf("created an invalid spreadsheet: %s", err)
+	}
+}
+
+func TestSparklines(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.SetName("Data")
+	for i, v := range []float64{1, 3, 2, 5} {
+		sheet.Cell(fmt.Sprintf("%s2", reference.IndexToColumn(uint32(i)))).SetNumber(v)
+	}
+	sg := sheet.AddSparklineGroup()
+	sg.SetType(spreadsheet.SparklineTypeLine)
+	sg.SetColor(color.Blue)
+	sg.SetMarkers(true)
+	sg.SetHighPointColor(color.Red)
+	sg.AddSparkline("E2", "Data!A2:D2")
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	files := zipContents(t, buf.Bytes())
+	exp := `<ma:extLst><ma:ext uri="{05C60535-1F16-4fd2-B633-F4F36F0B64E0}">` +
+		`<x14:sparklineGroups xmlns:x14="http://schemas.microsoft.com/office/spreadsheetml/2009/9/main" xmlns:xm="http://schemas.microsoft.com/office/excel/2006/main">` +
+		`<x14:sparklineGroup displayEmptyCellsAs="gap" markers="1" high="1">` +
+		`<x14:colorSeries rgb="ff0000ff"/><x14:colorHigh rgb="ffff0000"/>` +
+		`<x14:sparklines><x14:sparkline><xm:f>Data!A2:D2</xm:f><xm:sqref>E2</xm:sqref></x14:sparkline></x14:sparklines>` +
+		`</x14:sparklineGroup></x14:sparklineGroups></ma:ext></ma:extLst>`
+	if got := files["xl/worksheets/sheet1.xml"]; !strings.Contains(got, exp) {
+		t.Errorf("expected sheet to contain %s, got %s", exp, got)
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading: %s", err)
+	}
+	groups := wb2.Sheets()[0].SparklineGroups()
+	if len(groups) != 1 {
+		t.Fatalf("expected one sparkline group, got %d", len(groups))
+	}
+	locs, data := groups[0].Sparklines()
+	if len(locs) != 1 || locs[0] != "E2" || data[0] != "Data!A2:D2" {
+		t.Errorf("expected sparkline in E2 from Data!A2:D2, got %v %v", locs, data)
+	}
+}
+
+func TestSparklinesRoundTrip(t *testing.T) {
+	wb, err := spreadsheet.Open("testdata/sparklines.xlsx")
+	if err != nil {
+		t.Fatalf("error opening: %s", err)
+	}
+	groups := wb.Sheets()[0].SparklineGroups()
+	if len(groups) != 1 {
+		t.Fatalf("expected one sparkline group, got %d", len(groups))
+	}
+	if locs, _ := groups[0].Sparklines(); len(locs) != 2 {
+		t.Errorf("expected two sparklines, got %d", len(locs))
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	got := zipContents(t, buf.Bytes())["xl/worksheets/sheet1.xml"]
+	for _, exp := range []string{
+		`<x14:sparklineGroup type="column" displayEmptyCellsAs="gap" first="1" negative="1" displayXAxis="1" minAxisType="group"`,
+		`uid="{6A4D7C8E-2B1F-4E3A-9C5D-1F2E3A4B5C6D}"`,
+		`<x14:colorSeries theme="4" tint="-0.499984740745262"/>`,
+		`<x14:colorNegative theme="5"/>`,
+		`<x14:colorAxis rgb="FF000000"/>`,
+		`<x14:colorFirst theme="4" tint="0.3999755851924192"/>`,
+		`<x14:colorLow indexed="10"/>`,
+		`<x14:sparkline><xm:f>Data!A2:D2</xm:f><xm:sqref>E2</xm:sqref></x14:sparkline>`,
+	} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("expected sheet to contain %s, got %s", exp, got)
+		}
+	}
+	if strings.Contains(got, `rgb=""`) {
+		t.Errorf("expected no empty colors, got %s", got)
+	}
+}
+
+func TestAddRows(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Row(3)
+	sheet.Reserve(10)
+	rows := sheet.AddRows(3)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+	for i, r := range rows {
+		if exp := uint32(4 + i); r.RowNumber() != exp {
+			t.Errorf("expected row %d, got %d", exp, r.RowNumber())
+		}
+	}
+	if r := sheet.AddRow(); r.RowNumber() != 7 {
+		t.Errorf("expected row 7, got %d", r.RowNumber())
+	}
+	if len(sheet.Rows()) != 5 {
+		t.Errorf("expected 5 rows, got %d", len(sheet.Rows()))
+	}
+	if rows := sheet.AddRows(0); len(rows) != 0 {
+		t.Errorf("expected no rows, got %d", len(rows))
+	}
+}
+
+func TestAddRowAfterOutOfBandChanges(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.AddRow()
+	sheet.AddRow()
+
+	// rows appended directly to the XML aren't tracked by the sheet
+	xr := sml.NewCT_Row()
+	xr.RAttr = unioffice.Uint32(10)
+	sheet.X().SheetData.Row = append(sheet.X().SheetData.Row, xr)
+	if r := sheet.AddRow(); r.RowNumber() != 11 {
+		t.Errorf("expected row 11, got %d", r.RowNumber())
+	}
+
+	// renumbering the last row
+	last := sheet.Rows()[len(sheet.Rows())-1]
+	last.X().RAttr = unioffice.Uint32(20)
+	if r := sheet.AddRow(); r.RowNumber() != 21 {
+		t.Errorf("expected row 21, got %d", r.RowNumber())
+	}
+
+	// removing rows lowers the max row number
+	sheet.RemoveRowByNumber(21)
+	sheet.RemoveRowByNumber(20)
+	if r := sheet.AddRow(); r.RowNumber() != 11 {
+		t.Errorf("expected row 11, got %d", r.RowNumber())
+	}
+
+	sheet.AddNumberedRow(5)
+	if r := sheet.AddRow(); r.RowNumber() != 12 {
+		t.Errorf("expected row 12, got %d", r.RowNumber())
+	}
+}
+
+func TestRowIndexConsistency(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	for _, n := range []uint32{5, 1, 3} {
+		sheet.Row(n).AddCell().SetNumber(float64(n))
+	}
+	checkRow := func(n uint32, exp float64) {
+		t.Helper()
+		got, _ := sheet.Row(n).Cells()[0].GetValueAsNumber()
+		if got != exp {
+			t.Errorf("expected row %d to contain %f, got %f", n, exp, got)
+		}
+	}
+	checkRow(1, 1)
+	checkRow(3, 3)
+	checkRow(5, 5)
+
+	sheet.InsertRow(2).AddCell().SetNumber(2)
+	checkRow(2, 2)
+	checkRow(4, 3)
+	checkRow(6, 5)
+
+	sheet.RemoveRowShifting(2)
+	checkRow(3, 3)
+	checkRow(5, 5)
+
+	sheet.RemoveRowByNumber(3)
+	if cells := sheet.Row(3).Cells(); len(cells) != 0 {
+		t.Errorf("expected a new empty row 3, got %d cells", len(cells))
+	}
+
+	// renumbering through the XML is picked up
+	sheet.Row(5).X().RAttr = unioffice.Uint32(7)
+	checkRow(7, 5)
+	if len(sheet.Rows()) != 3 {
+		t.Errorf("expected 3 rows, got %d", len(sheet.Rows()))
+	}
+
+	// including rows other than the last
+	sheet.X().SheetData.Row[0].RAttr = unioffice.Uint32(10)
+	checkRow(10, 1)
+	sheet.X().SheetData.Row[0].RAttr = unioffice.Uint32(2)
+	checkRow(2, 1)
+	if len(sheet.Rows()) != 3 {
+		t.Errorf("expected 3 rows, got %d", len(sheet.Rows()))
+	}
+}
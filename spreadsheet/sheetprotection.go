@@ -8,10 +8,17 @@
 package spreadsheet
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+
 	"github.com/unidoc/unioffice"
 	"github.com/unidoc/unioffice/schema/soo/sml"
 )
 
+// SheetProtection controls the protection of a sheet.  Sheet protection only
+// prevents editing within a spreadsheet application, the password is stored as
+// a hash and the sheet contents are not encrypted.  It should be considered
+// obfuscation and not a security mechanism.
 type SheetProtection struct {
 	x *sml.CT_SheetProtection
 }
@@ -57,12 +64,97 @@ func (p SheetProtection) PasswordHash() string {
 	return *p.x.PasswordAttr
 }
 
-// SetPassword sets the password hash to a hash of the input password.
+// SetPassword sets the password hash to a hash of the input password using
+// the legacy 16 bit hash.
 func (p SheetProtection) SetPassword(pw string) {
 	p.SetPasswordHash(PasswordHash(pw))
 }
 
 // SetPasswordHash sets the password hash to the input.
 func (p SheetProtection) SetPasswordHash(pwHash string) {
+	p.clearPassword()
 	p.x.PasswordAttr = unioffice.String(pwHash)
 }
+
+// SetPasswordSHA512 sets the password using a salted SHA-512 hash as written
+// by modern versions of Excel.  A random salt is generated and the default
+// spin count of 100,000 is used.
+func (p SheetProtection) SetPasswordSHA512(pw string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	p.SetPasswordSHA512Salt(pw, salt, defaultSpinCount)
+	return nil
+}
+
+// SetPasswordSHA512Salt sets the password using a salted SHA-512 hash with the
+// given salt and spin count.
+func (p SheetProtection) SetPasswordSHA512Salt(pw string, salt []byte, spinCount uint32) {
+	p.clearPassword()
+	p.x.AlgorithmNameAttr = unioffice.String("SHA-512")
+	p.x.HashValueAttr = unioffice.String(PasswordHashSHA512(pw, salt, spinCount))
+	p.x.SaltValueAttr = unioffice.String(base64.StdEncoding.EncodeToString(salt))
+	p.x.SpinCountAttr = unioffice.Uint32(spinCount)
+}
+
+func (p SheetProtection) clearPassword() {
+	p.x.PasswordAttr = nil
+	p.x.AlgorithmNameAttr = nil
+	p.x.HashValueAttr = nil
+	p.x.SaltValueAttr = nil
+	p.x.SpinCountAttr = nil
+}
+
+// SetSelectLockedCells controls whether selecting locked cells is prevented
+// while the sheet is protected.
+func (p SheetProtection) SetSelectLockedCells(b bool) {
+	p.x.SelectLockedCellsAttr = unioffice.Bool(b)
+}
+
+// SetSelectUnlockedCells controls whether selecting unlocked cells is
+// prevented while the sheet is protected.
+func (p SheetProtection) SetSelectUnlockedCells(b bool) {
+	p.x.SelectUnlockedCellsAttr = unioffice.Bool(b)
+}
+
+// SetFormatCells controls whether formatting cells is prevented while the
+// sheet is protected.
+func (p SheetProtection) SetFormatCells(b bool) {
+	p.x.FormatCellsAttr = unioffice.Bool(b)
+}
+
+// SetFormatColumns controls whether formatting columns is prevented while the
+// sheet is protected.
+func (p SheetProtection) SetFormatColumns(b bool) {
+	p.x.FormatColumnsAttr = unioffice.Bool(b)
+}
+
+// SetFormatRows controls whether formatting rows is prevented while the sheet
+// is protected.
+func (p SheetProtection) SetFormatRows(b bool) {
+	p.x.FormatRowsAttr = unioffice.Bool(b)
+}
+
+// SetInsertRows controls whether inserting rows is prevented while the sheet
+// is protected.
+func (p SheetProtection) SetInsertRows(b bool) {
+	p.x.InsertRowsAttr = unioffice.Bool(b)
+}
+
+// SetDeleteRows controls whether deleting rows is prevented while the sheet
+// is protected.
+func (p SheetProtection) SetDeleteRows(b bool) {
+	p.x.DeleteRowsAttr = unioffice.Bool(b)
+}
+
+// SetSort controls whether sorting is prevented while the sheet is protected.
+func (p SheetProtection) SetSort(b bool) {
+	p.x.SortAttr = unioffice.Bool(b)
+}
+
+// SetAutoFilter controls whether using auto filters is prevented while the
+// sheet is protected.
+func (p SheetProtection) SetAutoFilter(b bool) {
+	p.x.AutoFilterAttr = unioffice.Bool(b)
+}
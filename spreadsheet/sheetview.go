@@ -8,8 +8,12 @@
 package spreadsheet
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/unidoc/unioffice"
 	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
 )
 
 // SheetView is a view of a sheet. There is typically one per sheet, though more
@@ -36,6 +40,31 @@ func (s SheetView) ensurePane() {
 	}
 }
 
+// freeze replaces any existing pane with one that freezes the given number of
+// rows and columns, activating the pane that Excel would.
+func (s SheetView) freeze(rows, cols uint32) {
+	s.x.Pane = nil
+	if rows == 0 && cols == 0 {
+		return
+	}
+	s.SetState(sml.ST_PaneStateFrozen)
+	if cols > 0 {
+		s.SetXSplit(float64(cols))
+	}
+	if rows > 0 {
+		s.SetYSplit(float64(rows))
+	}
+	s.SetTopLeft(fmt.Sprintf("%s%d", reference.IndexToColumn(cols), rows+1))
+	switch {
+	case rows > 0 && cols > 0:
+		s.x.Pane.ActivePaneAttr = sml.ST_PaneBottomRight
+	case cols > 0:
+		s.x.Pane.ActivePaneAttr = sml.ST_PaneTopRight
+	default:
+		s.x.Pane.ActivePaneAttr = sml.ST_PaneBottomLeft
+	}
+}
+
 // SetXSplit sets the column split point
 func (s SheetView) SetXSplit(v float64) {
 	s.ensurePane()
@@ -70,3 +99,86 @@ func (s SheetView) SetShowRuler(b bool) {
 		s.x.ShowRulerAttr = nil
 	}
 }
+
+// SetShowGridlines controls the visibility of the gridlines
+func (s SheetView) SetShowGridlines(b bool) {
+	// default is true
+	if !b {
+		s.x.ShowGridLinesAttr = unioffice.Bool(false)
+	} else {
+		s.x.ShowGridLinesAttr = nil
+	}
+}
+
+// SetShowRowColHeaders controls the visibility of the row and column headers
+func (s SheetView) SetShowRowColHeaders(b bool) {
+	// default is true
+	if !b {
+		s.x.ShowRowColHeadersAttr = unioffice.Bool(false)
+	} else {
+		s.x.ShowRowColHeadersAttr = nil
+	}
+}
+
+// SetActiveCell sets the active cell of the view, selecting only that cell.
+func (s SheetView) SetActiveCell(cellRef string) {
+	if _, err := reference.ParseCellReference(cellRef); err != nil {
+		unioffice.Log("invalid active cell %s: %s", cellRef, err)
+		return
+	}
+	sel := s.selection()
+	sel.ActiveCellAttr = unioffice.String(cellRef)
+	sel.SqrefAttr = &sml.ST_Sqref{cellRef}
+}
+
+// SetSelection selects the cells or ranges of the view, given as a space
+// separated list such as "A1:B5 D3".  The top left cell of the first range
+// becomes the active cell.
+func (s SheetView) SetSelection(ref string) {
+	refs := strings.Fields(ref)
+	if len(refs) == 0 {
+		unioffice.Log("empty selection")
+		return
+	}
+	var active reference.CellReference
+	for i, r := range refs {
+		var err error
+		var from reference.CellReference
+		if strings.Contains(r, ":") {
+			from, _, err = reference.ParseRangeReference(r)
+		} else {
+			from, err = reference.ParseCellReference(r)
+		}
+		if err != nil {
+			unioffice.Log("invalid selection %s: %s", r, err)
+			return
+		}
+		if i == 0 {
+			active = from
+		}
+	}
+	active.AbsoluteColumn = false
+	active.AbsoluteRow = false
+	sel := s.selection()
+	sel.ActiveCellAttr = unioffice.String(active.String())
+	sqref := sml.ST_Sqref(refs)
+	sel.SqrefAttr = &sqref
+}
+
+// selection returns the selection of the active pane of the view, creating it
+// if necessary.
+func (s SheetView) selection() *sml.CT_Selection {
+	pane := sml.ST_PaneUnset
+	if s.x.Pane != nil {
+		pane = s.x.Pane.ActivePaneAttr
+	}
+	for _, sel := range s.x.Selection {
+		if sel.PaneAttr == pane {
+			return sel
+		}
+	}
+	sel := sml.NewCT_Selection()
+	sel.PaneAttr = pane
+	s.x.Selection = append(s.x.Selection, sel)
+	return sel
+}
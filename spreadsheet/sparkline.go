@@ -0,0 +1,344 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/color"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+)
+
+// Sparklines are an Excel 2010 extension stored in the worksheet's extension
+// list, so they aren't part of the generated schema types.
+const (
+	x14Namespace        = "http://schemas.microsoft.com/office/spreadsheetml/2009/9/main"
+	xmNamespace         = "http://schemas.microsoft.com/office/excel/2006/main"
+	sparklineGroupsURI  = "{05C60535-1F16-4fd2-B633-F4F36F0B64E0}"
+	sparklineGroupsName = "sparklineGroups"
+)
+
+func init() {
+	unioffice.RegisterConstructor(x14Namespace, sparklineGroupsName, newSparklineGroups)
+}
+
+// SparklineType is the type of chart drawn by a sparkline.
+type SparklineType byte
+
+// SparklineType constants
+const (
+	SparklineTypeLine SparklineType = iota
+	SparklineTypeColumn
+	SparklineTypeWinLoss
+)
+
+// SparklineGroup is a group of sparklines sharing the same formatting.
+type SparklineGroup struct {
+	x *sparklineGroup
+}
+
+// AddSparklineGroup adds a new group of line sparklines to the sheet.
+func (s Sheet) AddSparklineGroup() SparklineGroup {
+	sg := s.sparklineGroups(true)
+	g := &sparklineGroup{DisplayEmptyCellsAs: "gap"}
+	sg.Groups = append(sg.Groups, g)
+	return SparklineGroup{g}
+}
+
+// SparklineGroups returns the sparkline groups of the sheet.
+func (s Sheet) SparklineGroups() []SparklineGroup {
+	ret := []SparklineGroup{}
+	if sg := s.sparklineGroups(false); sg != nil {
+		for _, g := range sg.Groups {
+			ret = append(ret, SparklineGroup{g})
+		}
+	}
+	return ret
+}
+
+// sparklineGroups returns the sparkline groups stored in the worksheet's
+// extension list, optionally creating them.
+func (s Sheet) sparklineGroups(create bool) *sparklineGroups {
+	if s.x.ExtLst != nil {
+		for _, ext := range s.x.ExtLst.Ext {
+			if sg, ok := ext.Any.(*sparklineGroups); ok {
+				return sg
+			}
+		}
+	}
+	if !create {
+		return nil
+	}
+	if s.x.ExtLst == nil {
+		s.x.ExtLst = sml.NewCT_ExtensionList()
+	}
+	ext := sml.NewCT_Extension()
+	ext.UriAttr = unioffice.String(sparklineGroupsURI)
+	sg := newSparklineGroups()
+	ext.Any = sg
+	s.x.ExtLst.Ext = append(s.x.ExtLst.Ext, ext)
+	return sg
+}
+
+// SetType sets the type of chart drawn by the sparklines.
+func (g SparklineGroup) SetType(t SparklineType) {
+	switch t {
+	case SparklineTypeColumn:
+		g.x.Type = "column"
+	case SparklineTypeWinLoss:
+		g.x.Type = "stacked"
+	default:
+		// line is the default
+		g.x.Type = ""
+	}
+}
+
+// SetColor sets the color of the sparkline series.
+func (g SparklineGroup) SetColor(c color.Color) {
+	g.x.ColorSeries = newSparklineColor(c)
+}
+
+// SetLineWeight sets the line weight of line sparklines.
+func (g SparklineGroup) SetLineWeight(w measurement.Distance) {
+	g.x.LineWeight = unioffice.Float64(float64(w / measurement.Point))
+}
+
+// SetMarkers controls whether line sparklines show a marker at each point.
+func (g SparklineGroup) SetMarkers(b bool) {
+	g.x.Markers = b
+}
+
+// SetMarkerColor sets the color of the markers.
+func (g SparklineGroup) SetMarkerColor(c color.Color) {
+	g.x.ColorMarkers = newSparklineColor(c)
+}
+
+// SetHighPointColor highlights the highest point of each sparkline.
+func (g SparklineGroup) SetHighPointColor(c color.Color) {
+	g.x.High = true
+	g.x.ColorHigh = newSparklineColor(c)
+}
+
+// SetLowPointColor highlights the lowest point of each sparkline.
+func (g SparklineGroup) SetLowPointColor(c color.Color) {
+	g.x.Low = true
+	g.x.ColorLow = newSparklineColor(c)
+}
+
+// SetNegativeColor highlights the negative points of each sparkline.
+func (g SparklineGroup) SetNegativeColor(c color.Color) {
+	g.x.Negative = true
+	g.x.ColorNegative = newSparklineColor(c)
+}
+
+// AddSparkline adds a sparkline drawn in the cell at locationRef, e.g. "E2",
+// from the values in dataRange, e.g. "Sheet1!A2:D2".  dataRange must include
+// the name of the sheet containing the data.
+func (g SparklineGroup) AddSparkline(locationRef, dataRange string) {
+	if !strings.Contains(dataRange, "!") {
+		unioffice.Log("sparkline data range %s should include a sheet name", dataRange)
+	}
+	g.x.Sparklines = append(g.x.Sparklines, &sparkline{F: dataRange, Sqref: locationRef})
+}
+
+// Sparklines returns the location and data range of each sparkline in the
+// group.
+func (g SparklineGroup) Sparklines() (locations, dataRanges []string) {
+	for _, sp := range g.x.Sparklines {
+		locations = append(locations, sp.Sqref)
+		dataRanges = append(dataRanges, sp.F)
+	}
+	return locations, dataRanges
+}
+
+type sparklineGroups struct {
+	Groups []*sparklineGroup `xml:"sparklineGroup"`
+}
+
+func newSparklineGroups() *sparklineGroups {
+	return &sparklineGroups{}
+}
+
+func newSparklineColor(c color.Color) *sml.CT_Color {
+	clr := sml.NewCT_Color()
+	clr.RgbAttr = c.AsRGBAString()
+	return clr
+}
+
+type sparkline struct {
+	F     string `xml:"f"`
+	Sqref string `xml:"sqref"`
+}
+
+// sparklineGroup is the x14 CT_SparklineGroup.  It's unmarshaled using the tags
+// but marshaled by hand as the elements must be written with the x14 and xm
+// prefixes.  Attributes that aren't part of the schema, such as revision IDs,
+// are kept in Other so that they survive a round trip.
+type sparklineGroup struct {
+	ManualMax           *float64   `xml:"manualMax,attr"`
+	ManualMin           *float64   `xml:"manualMin,attr"`
+	LineWeight          *float64   `xml:"lineWeight,attr"`
+	Type                string     `xml:"type,attr"`
+	DateAxis            bool       `xml:"dateAxis,attr"`
+	DisplayEmptyCellsAs string     `xml:"displayEmptyCellsAs,attr"`
+	Markers             bool       `xml:"markers,attr"`
+	High                bool       `xml:"high,attr"`
+	Low                 bool       `xml:"low,attr"`
+	First               bool       `xml:"first,attr"`
+	Last                bool       `xml:"last,attr"`
+	Negative            bool       `xml:"negative,attr"`
+	DisplayXAxis        bool       `xml:"displayXAxis,attr"`
+	DisplayHidden       bool       `xml:"displayHidden,attr"`
+	MinAxisType         string     `xml:"minAxisType,attr"`
+	MaxAxisType         string     `xml:"maxAxisType,attr"`
+	RightToLeft         bool       `xml:"rightToLeft,attr"`
+	Other               []xml.Attr `xml:",any,attr"`
+
+	ColorSeries   *sml.CT_Color     `xml:"colorSeries"`
+	ColorNegative *sml.CT_Color     `xml:"colorNegative"`
+	ColorAxis     *sml.CT_Color     `xml:"colorAxis"`
+	ColorMarkers  *sml.CT_Color     `xml:"colorMarkers"`
+	ColorFirst    *sml.CT_Color     `xml:"colorFirst"`
+	ColorLast     *sml.CT_Color     `xml:"colorLast"`
+	ColorHigh     *sml.CT_Color     `xml:"colorHigh"`
+	ColorLow      *sml.CT_Color     `xml:"colorLow"`
+	F             *string           `xml:"f"`
+	Sparklines    []*sparkline      `xml:"sparklines>sparkline"`
+	ExtLst        *unioffice.XSDAny `xml:"extLst"`
+}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (s *sparklineGroups) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "x14:" + sparklineGroupsName}}
+	start.Attr = append(start.Attr,
+		xml.Attr{Name: xml.Name{Local: "xmlns:x14"}, Value: x14Namespace},
+		xml.Attr{Name: xml.Name{Local: "xmlns:xm"}, Value: xmNamespace})
+	e.EncodeToken(start)
+	for _, g := range s.Groups {
+		if err := g.marshal(e); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (s *sparklineGroups) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain sparklineGroups
+	return d.DecodeElement((*plain)(s), &start)
+}
+
+func (g *sparklineGroup) marshal(e *xml.Encoder) error {
+	start := xml.StartElement{Name: xml.Name{Local: "x14:sparklineGroup"}}
+	attr := func(name, value string) {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	}
+	for _, f := range []struct {
+		name string
+		v    *float64
+	}{{"manualMax", g.ManualMax}, {"manualMin", g.ManualMin}, {"lineWeight", g.LineWeight}} {
+		if f.v != nil {
+			attr(f.name, fmt.Sprintf("%v", *f.v))
+		}
+	}
+	if g.Type != "" {
+		attr("type", g.Type)
+	}
+	if g.DateAxis {
+		attr("dateAxis", "1")
+	}
+	if g.DisplayEmptyCellsAs != "" {
+		attr("displayEmptyCellsAs", g.DisplayEmptyCellsAs)
+	}
+	for _, b := range []struct {
+		name string
+		v    bool
+	}{
+		{"markers", g.Markers},
+		{"high", g.High},
+		{"low", g.Low},
+		{"first", g.First},
+		{"last", g.Last},
+		{"negative", g.Negative},
+		{"displayXAxis", g.DisplayXAxis},
+		{"displayHidden", g.DisplayHidden},
+	} {
+		if b.v {
+			attr(b.name, "1")
+		}
+	}
+	if g.MinAxisType != "" {
+		attr("minAxisType", g.MinAxisType)
+	}
+	if g.MaxAxisType != "" {
+		attr("maxAxisType", g.MaxAxisType)
+	}
+	if g.RightToLeft {
+		attr("rightToLeft", "1")
+	}
+	for _, a := range g.Other {
+		// namespace declarations are recreated by the encoder as needed
+		if a.Name.Space != "xmlns" && a.Name.Local != "xmlns" {
+			start.Attr = append(start.Attr, a)
+		}
+	}
+	e.EncodeToken(start)
+
+	for _, c := range []struct {
+		name string
+		v    *sml.CT_Color
+	}{
+		{"colorSeries", g.ColorSeries},
+		{"colorNegative", g.ColorNegative},
+		{"colorAxis", g.ColorAxis},
+		{"colorMarkers", g.ColorMarkers},
+		{"colorFirst", g.ColorFirst},
+		{"colorLast", g.ColorLast},
+		{"colorHigh", g.ColorHigh},
+		{"colorLow", g.ColorLow},
+	} {
+		if c.v == nil {
+			continue
+		}
+		if err := e.EncodeElement(c.v, xml.StartElement{Name: xml.Name{Local: "x14:" + c.name}}); err != nil {
+			return err
+		}
+	}
+	if g.F != nil {
+		if err := e.EncodeElement(*g.F, xml.StartElement{Name: xml.Name{Local: "xm:f"}}); err != nil {
+			return err
+		}
+	}
+
+	sparklines := xml.StartElement{Name: xml.Name{Local: "x14:sparklines"}}
+	e.EncodeToken(sparklines)
+	for _, sp := range g.Sparklines {
+		el := xml.StartElement{Name: xml.Name{Local: "x14:sparkline"}}
+		e.EncodeToken(el)
+		if sp.F != "" {
+			if err := e.EncodeElement(sp.F, xml.StartElement{Name: xml.Name{Local: "xm:f"}}); err != nil {
+				return err
+			}
+		}
+		if err := e.EncodeElement(sp.Sqref, xml.StartElement{Name: xml.Name{Local: "xm:sqref"}}); err != nil {
+			return err
+		}
+		e.EncodeToken(el.End())
+	}
+	e.EncodeToken(sparklines.End())
+	if g.ExtLst != nil {
+		if err := e.EncodeElement(g.ExtLst, xml.StartElement{Name: xml.Name{Local: "x14:extLst"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
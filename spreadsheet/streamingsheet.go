@@ -0,0 +1,204 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"time"
+
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+	"github.com/unidoc/unioffice/zippkg"
+)
+
+// streamMarkerRow is the row number of a placeholder row that is written in
+// place of the streamed rows when marshaling the rest of the worksheet.
+const streamMarkerRow = math.MaxUint32
+
+// StreamingSheet writes rows of a sheet to a temporary file as they are added
+// instead of retaining them in memory, allowing very large sheets to be
+// written.  The tradeoff is that streamed rows can't be accessed or modified
+// after they are added, they are only read back from the temporary file when
+// the workbook is saved.  Shared strings and styles are still held in memory.
+type StreamingSheet struct {
+	s      Sheet
+	f      *os.File
+	bw     *bufio.Writer
+	enc    *xml.Encoder
+	rowNum uint32
+	maxCol uint32
+	err    error
+}
+
+// StreamRows returns a StreamingSheet that can be used to append rows to the
+// sheet without retaining them in memory.  Rows already in the sheet are
+// written before any streamed rows, and no further rows should be added to the
+// sheet through AddRow, Row or Cell after streaming begins.
+func (s Sheet) StreamRows() (*StreamingSheet, error) {
+	if ss, ok := s.w.streams[s.x]; ok {
+		return ss, nil
+	}
+	if s.w.TmpPath == "" {
+		td, err := ioutil.TempDir("", "gooxml-xlsx")
+		if err != nil {
+			return nil, err
+		}
+		s.w.TmpPath = td
+	}
+	f, err := ioutil.TempFile(s.w.TmpPath, "sheet")
+	if err != nil {
+		return nil, err
+	}
+	ss := &StreamingSheet{s: s, f: f}
+	ss.bw = bufio.NewWriter(f)
+	ss.enc = xml.NewEncoder(zippkg.SelfClosingWriter{W: ss.bw})
+	for _, r := range s.x.SheetData.Row {
+		if r.RAttr != nil && *r.RAttr > ss.rowNum {
+			ss.rowNum = *r.RAttr
+		}
+		for _, c := range r.C {
+			if c.RAttr == nil {
+				continue
+			}
+			if cref, err := reference.ParseCellReference(*c.RAttr); err == nil && cref.ColumnIdx+1 > ss.maxCol {
+				ss.maxCol = cref.ColumnIdx + 1
+			}
+		}
+	}
+	if s.w.streams == nil {
+		s.w.streams = map[*sml.Worksheet]*StreamingSheet{}
+	}
+	s.w.streams[s.x] = ss
+	return ss, nil
+}
+
+// AddRow writes a new row to the end of the sheet containing the values
+// given.  Values may be any type supported by Cell.SetValue, or nil to skip a
+// cell.  Strings are stored in the shared strings table and times, including
+// those at midnight, are formatted with a date/time number format.
+func (ss *StreamingSheet) AddRow(values []interface{}) error {
+	if ss.err != nil {
+		return ss.err
+	}
+	ss.rowNum++
+	row := sml.NewCT_Row()
+	row.RAttr = &ss.rowNum
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		cx := sml.NewCT_Cell()
+		ref := fmt.Sprintf("%s%d", reference.IndexToColumn(uint32(i)), ss.rowNum)
+		cx.RAttr = &ref
+		c := Cell{ss.s.w, ss.s.x, row, cx}
+		if t, ok := v.(time.Time); ok {
+			c.SetTimeWithStyle(t)
+		} else if err := c.SetValue(v); err != nil {
+			ss.rowNum--
+			return err
+		}
+		row.C = append(row.C, cx)
+		if uint32(i+1) > ss.maxCol {
+			ss.maxCol = uint32(i + 1)
+		}
+	}
+	if err := ss.enc.EncodeElement(row, xml.StartElement{Name: xml.Name{Local: "ma:row"}}); err != nil {
+		ss.err = err
+		return err
+	}
+	return nil
+}
+
+// Flush writes any buffered rows to the temporary file.
+func (ss *StreamingSheet) Flush() error {
+	if ss.err != nil {
+		return ss.err
+	}
+	if err := ss.enc.Flush(); err != nil {
+		ss.err = err
+		return err
+	}
+	if err := ss.bw.Flush(); err != nil {
+		ss.err = err
+		return err
+	}
+	return nil
+}
+
+// extents returns the range of cells covered by the sheet including the
+// streamed rows.
+func (ss *StreamingSheet) extents() string {
+	if ss.rowNum == 0 || ss.maxCol == 0 {
+		return "A1"
+	}
+	return fmt.Sprintf("A1:%s%d", reference.IndexToColumn(ss.maxCol-1), ss.rowNum)
+}
+
+// marshal writes the worksheet to the zip file, inserting the streamed rows
+// after any rows held in memory.
+func (ss *StreamingSheet) marshal(z *zip.Writer, fn string) error {
+	if err := ss.Flush(); err != nil {
+		return err
+	}
+	ws := ss.s.x
+	marker := sml.NewCT_Row()
+	marker.RAttr = new(uint32)
+	*marker.RAttr = streamMarkerRow
+	ws.SheetData.Row = append(ws.SheetData.Row, marker)
+	buf := bytes.Buffer{}
+	err := xml.NewEncoder(&buf).Encode(ws)
+	ws.SheetData.Row = ws.SheetData.Row[:len(ws.SheetData.Row)-1]
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %s", fn, err)
+	}
+
+	markerXML := []byte(fmt.Sprintf(`<ma:row r="%d"></ma:row>`, uint32(streamMarkerRow)))
+	idx := bytes.Index(buf.Bytes(), markerXML)
+	if idx == -1 {
+		return errors.New("unable to locate streamed rows in worksheet")
+	}
+
+	w, err := z.CreateHeader(&zip.FileHeader{Name: fn, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("creating %s in zip: %s", fn, err)
+	}
+	scw := zippkg.SelfClosingWriter{W: w}
+	if _, err := io.WriteString(w, zippkg.XMLHeader); err != nil {
+		return err
+	}
+	if _, err := scw.Write(buf.Bytes()[:idx]); err != nil {
+		return err
+	}
+	f, err := os.Open(ss.f.Name())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(w, f); err != nil {
+		return err
+	}
+	if _, err := scw.Write(buf.Bytes()[idx+len(markerXML):]); err != nil {
+		return err
+	}
+	_, err = w.Write([]byte{'\r', '\n'})
+	return err
+}
+
+// close closes the temporary file used to hold the streamed rows.
+func (ss *StreamingSheet) close() error {
+	return ss.f.Close()
+}
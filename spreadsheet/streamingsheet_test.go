@@ -0,0 +1,256 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/unidoc/unioffice/spreadsheet"
+)
+
+func TestStreamRows(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	sheet := wb.AddSheet()
+	sheet.Cell("A1").SetString("header")
+	sheet.SetFrozen(true, false)
+
+	ss, err := sheet.StreamRows()
+	if err != nil {
+		t.Fatalf("error streaming rows: %s", err)
+	}
+	tm := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := ss.AddRow([]interface{}{"foo", 1.5, 2, true, nil, tm}); err != nil {
+		t.Fatalf("error adding row: %s", err)
+	}
+	midnight := time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)
+	if err := ss.AddRow([]interface{}{"foo", midnight}); err != nil {
+		t.Fatalf("error adding row: %s", err)
+	}
+	if err := ss.AddRow([]interface{}{struct{}{}}); err == nil {
+		t.Errorf("expected an error adding an unsupported value")
+	}
+	if len(sheet.X().SheetData.Row) != 1 {
+		t.Errorf("expected streamed rows to not be retained, got %d rows", len(sheet.X().SheetData.Row))
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	sheet2 := wb2.Sheets()[0]
+	if sheet2.X().SheetViews == nil {
+		t.Errorf("expected the rest of the sheet to be written")
+	}
+	if got := sheet2.X().Dimension.RefAttr; got != "A1:F3" {
+		t.Errorf("expected dimension A1:F3, got %s", got)
+	}
+	if rows := sheet2.Rows(); len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+	td := []struct {
+		ref string
+		exp string
+	}{
+		{"A1", "header"},
+		{"A2", "foo"},
+		{"B2", "1.5"},
+		{"C2", "2"},
+		{"D2", "1"},
+		{"E2", ""},
+		{"A3", "foo"},
+	}
+	for _, tc := range td {
+		if got := sheet2.Cell(tc.ref).GetString(); got != tc.exp {
+			t.Errorf("expected %s in %s, got %s", tc.exp, tc.ref, got)
+		}
+	}
+	got, err := sheet2.Cell("F2").GetValueAsTime()
+	if err != nil || got.Sub(tm) > time.Millisecond || tm.Sub(got) > time.Millisecond {
+		t.Errorf("expected time %s in F2, got %s (%v)", tm, got, err)
+	}
+	for _, ref := range []string{"F2", "B3"} {
+		c := sheet2.Cell(ref)
+		if c.X().SAttr == nil {
+			t.Errorf("expected a style on %s", ref)
+			continue
+		}
+		if cs := wb2.StyleSheet.GetCellStyle(*c.X().SAttr); cs.NumberFormat() != uint32(spreadsheet.StandardFormatDateTime) {
+			t.Errorf("expected a date and time format on %s, got %d", ref, cs.NumberFormat())
+		}
+	}
+	if n := len(wb2.SharedStrings.X().Si); n != 2 {
+		t.Errorf("expected 2 shared strings, got %d", n)
+	}
+}
+
+func TestStreamRowsMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large streaming test in short mode")
+	}
+	const numRows = 100000
+	const ceiling = 32 << 20
+
+	wb := spreadsheet.New()
+	defer wb.Close()
+	sheet := wb.AddSheet()
+	ss, err := sheet.StreamRows()
+	if err != nil {
+		t.Fatalf("error streaming rows: %s", err)
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	values := []interface{}{"label", 0, 1.5, true, "another label"}
+	for i := 0; i < numRows; i++ {
+		values[1] = i
+		if err := ss.AddRow(values); err != nil {
+			t.Fatalf("error adding row: %s", err)
+		}
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	if after.HeapAlloc > before.HeapAlloc && after.HeapAlloc-before.HeapAlloc > ceiling {
+		t.Errorf("expected heap growth under %d bytes, got %d", ceiling, after.HeapAlloc-before.HeapAlloc)
+	}
+
+	if err := wb.Save(ioutil.Discard); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	if got := sheet.X().Dimension.RefAttr; got != "A1:E100000" {
+		t.Errorf("expected dimension A1:E100000, got %s", got)
+	}
+}
+
+func TestStreamRead(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	wb.AddSheet().SetName("Other")
+	sheet := wb.AddSheet()
+	sheet.SetName("Data")
+	sheet.Cell("A1").SetString("shared")
+	sheet.Cell("C1").SetInlineString("inline")
+	sheet.Cell("D1").SetBool(true)
+	sheet.Cell("A3").SetNumber(1.5)
+	sheet.Cell("B3").SetFormulaRaw("A3*2")
+	sheet.Cell("B3").SetCachedFormulaResult("3")
+	sheet.AddComment("A1", "author", "ignored")
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	r := bytes.NewReader(buf.Bytes())
+
+	got := map[string]spreadsheet.CellData{}
+	rowNums := []uint32{}
+	err := spreadsheet.StreamRead(r, r.Size(), "Data", func(rowNum uint32, cells []spreadsheet.CellData) error {
+		rowNums = append(rowNums, rowNum)
+		for _, c := range cells {
+			got[c.Reference] = c
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error reading sheet: %s", err)
+	}
+	if len(rowNums) != 2 || rowNums[0] != 1 || rowNums[1] != 3 {
+		t.Errorf("expected rows 1 and 3, got %v", rowNums)
+	}
+	for ref, exp := range map[string]string{"A1": "shared", "C1": "inline", "D1": "1", "A3": "1.5", "B3": "3"} {
+		if got[ref].Value != exp {
+			t.Errorf("expected %s in %s, got %s", exp, ref, got[ref].Value)
+		}
+	}
+	if got["B3"].Formula != "A3*2" {
+		t.Errorf("expected formula A3*2, got %s", got["B3"].Formula)
+	}
+	if got["C1"].Column != 2 {
+		t.Errorf("expected C1 to be in column 2, got %d", got["C1"].Column)
+	}
+	if v, err := got["A3"].Number(); err != nil || v != 1.5 {
+		t.Errorf("expected 1.5, got %f (%v)", v, err)
+	}
+	if _, err := got["A1"].Number(); err == nil {
+		t.Errorf("expected an error reading a string as a number")
+	}
+
+	if err := spreadsheet.StreamRead(r, r.Size(), "Missing", func(uint32, []spreadsheet.CellData) error { return nil }); err == nil {
+		t.Errorf("expected an error reading a missing sheet")
+	}
+	stop := errors.New("stop")
+	if err := spreadsheet.StreamRead(r, r.Size(), "Data", func(uint32, []spreadsheet.CellData) error { return stop }); err != stop {
+		t.Errorf("expected the callback error to be returned, got %v", err)
+	}
+}
+
+func TestStreamReadLarge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large streaming test in short mode")
+	}
+	const numRows = 50000
+	const ceiling = 16 << 20
+
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	ss, err := sheet.StreamRows()
+	if err != nil {
+		t.Fatalf("error streaming rows: %s", err)
+	}
+	values := []interface{}{"label", 0, 1.5, true}
+	exp := 0.0
+	for i := 0; i < numRows; i++ {
+		values[1] = i
+		exp += float64(i)
+		if err := ss.AddRow(values); err != nil {
+			t.Fatalf("error adding row: %s", err)
+		}
+	}
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb.Close()
+	r := bytes.NewReader(buf.Bytes())
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	sum := 0.0
+	rows := 0
+	err = spreadsheet.StreamRead(r, r.Size(), sheet.Name(), func(rowNum uint32, cells []spreadsheet.CellData) error {
+		rows++
+		v, err := cells[1].Number()
+		sum += v
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error reading sheet: %s", err)
+	}
+	runtime.ReadMemStats(&after)
+	if rows != numRows || sum != exp {
+		t.Errorf("expected %d rows summing to %f, got %d rows summing to %f", numRows, exp, rows, sum)
+	}
+	if after.HeapAlloc > before.HeapAlloc && after.HeapAlloc-before.HeapAlloc > ceiling {
+		t.Errorf("expected heap growth under %d bytes, got %d", ceiling, after.HeapAlloc-before.HeapAlloc)
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice/schema/soo/pkg/relationships"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
+	"github.com/unidoc/unioffice/zippkg"
+)
+
+// CellData is the content of a cell read by StreamRead.
+type CellData struct {
+	Reference string // e.g. "B2"
+	Column    uint32 // zero based column index
+	Type      sml.ST_CellType
+	Style     uint32
+	Formula   string
+
+	// Value is the cell's value with shared and inline strings resolved, or
+	// the raw value for other types, e.g. "1.5" or "1" for a boolean.
+	Value string
+}
+
+// IsNumber returns true if the cell contains a number.
+func (c CellData) IsNumber() bool {
+	switch c.Type {
+	case sml.ST_CellTypeN, sml.ST_CellTypeUnset:
+		_, err := strconv.ParseFloat(c.Value, 64)
+		return err == nil
+	}
+	return false
+}
+
+// Number returns the cell's value as a number.
+func (c CellData) Number() (float64, error) {
+	if !c.IsNumber() {
+		return 0, fmt.Errorf("cell %s is not a number", c.Reference)
+	}
+	return strconv.ParseFloat(c.Value, 64)
+}
+
+// StreamRead reads the rows of a single sheet of a workbook (.xlsx) without
+// loading the sheet into memory, calling fn with each row containing cells in
+// order.  Only the shared strings table is held in memory, so it is suitable
+// for very large sheets.  Drawings, comments and other parts of the workbook
+// are ignored.  The cells slice is reused between calls and must not be
+// retained by fn.  Reading stops at the first error returned by fn, which is
+// returned by StreamRead.
+func StreamRead(r io.ReaderAt, size int64, sheetName string, fn func(rowNum uint32, cells []CellData) error) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("parsing zip: %s", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	wbPath, err := streamFindRel(files, "", "/officeDocument")
+	if err != nil {
+		return err
+	}
+	wb := sml.NewWorkbook()
+	if f, ok := files[wbPath]; !ok {
+		return fmt.Errorf("workbook %s not found", wbPath)
+	} else if err := zippkg.Decode(f, wb); err != nil {
+		return err
+	}
+	rid := ""
+	for _, s := range wb.Sheets.Sheet {
+		if s.NameAttr == sheetName {
+			rid = s.IdAttr
+		}
+	}
+	if rid == "" {
+		return fmt.Errorf("sheet %s not found", sheetName)
+	}
+	wbRels, err := streamReadRels(files, wbPath)
+	if err != nil {
+		return err
+	}
+
+	sheetPath := ""
+	var sst []string
+	for _, rel := range wbRels.Relationship {
+		target := streamResolve(wbPath, rel.TargetAttr)
+		switch {
+		case rel.IdAttr == rid:
+			sheetPath = target
+		case strings.HasSuffix(rel.TypeAttr, "/sharedStrings"):
+			if sst, err = streamReadSharedStrings(files[target]); err != nil {
+				return err
+			}
+		}
+	}
+	f, ok := files[sheetPath]
+	if !ok {
+		return fmt.Errorf("worksheet for sheet %s not found", sheetName)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return streamRows(xml.NewDecoder(rc), sst, fn)
+}
+
+// streamRows decodes the rows of a worksheet, calling fn for each.
+func streamRows(dec *xml.Decoder, sst []string, fn func(rowNum uint32, cells []CellData) error) error {
+	cells := []CellData{}
+	rowNum := uint32(0)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch se.Name.Local {
+		case "worksheet", "sheetData":
+			// descend into the rows
+		case "row":
+			rowNum++
+			if v, ok := streamAttr(se, "r"); ok {
+				n, err := strconv.ParseUint(v, 10, 32)
+				if err != nil {
+					return fmt.Errorf("invalid row number %s", v)
+				}
+				rowNum = uint32(n)
+			}
+			cells, err = streamCells(dec, sst, rowNum, cells[:0])
+			if err != nil {
+				return err
+			}
+			if err := fn(rowNum, cells); err != nil {
+				return err
+			}
+		default:
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// streamCells decodes the cells of a row up to the end of the row.
+func streamCells(dec *xml.Decoder, sst []string, rowNum uint32, cells []CellData) ([]CellData, error) {
+	col := uint32(0)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			return cells, nil
+		case xml.StartElement:
+			if t.Name.Local != "c" {
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			cd := CellData{Column: col}
+			if v, ok := streamAttr(t, "r"); ok {
+				cref, err := reference.ParseCellReference(v)
+				if err != nil {
+					return nil, err
+				}
+				cd.Column = cref.ColumnIdx
+			}
+			cd.Reference = fmt.Sprintf("%s%d", reference.IndexToColumn(cd.Column), rowNum)
+			if v, ok := streamAttr(t, "t"); ok {
+				if err := cd.Type.UnmarshalXMLAttr(xml.Attr{Value: v}); err != nil {
+					return nil, err
+				}
+			}
+			if v, ok := streamAttr(t, "s"); ok {
+				s, _ := strconv.ParseUint(v, 10, 32)
+				cd.Style = uint32(s)
+			}
+			if err := streamCellContent(dec, &cd, sst); err != nil {
+				return nil, err
+			}
+			cells = append(cells, cd)
+			col = cd.Column + 1
+		}
+	}
+}
+
+// streamCellContent decodes the value, formula and inline string of a cell.
+func streamCellContent(dec *xml.Decoder, cd *CellData, sst []string) error {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			if cd.Type == sml.ST_CellTypeS {
+				id, err := strconv.Atoi(cd.Value)
+				if err != nil || id < 0 || id >= len(sst) {
+					return fmt.Errorf("cell %s has an invalid shared string index %s", cd.Reference, cd.Value)
+				}
+				cd.Value = sst[id]
+			}
+			return nil
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "v":
+				if err := dec.DecodeElement(&cd.Value, &t); err != nil {
+					return err
+				}
+			case "f":
+				if err := dec.DecodeElement(&cd.Formula, &t); err != nil {
+					return err
+				}
+			case "is":
+				is := sml.NewCT_Rst()
+				if err := dec.DecodeElement(is, &t); err != nil {
+					return err
+				}
+				cd.Value = rstText(is)
+			default:
+				if err := dec.Skip(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func streamAttr(se xml.StartElement, name string) (string, bool) {
+	for _, a := range se.Attr {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// streamReadRels reads the relationships of a part, or of the package if
+// partPath is empty.
+func streamReadRels(files map[string]*zip.File, partPath string) (*relationships.Relationships, error) {
+	relsPath := "_rels/.rels"
+	if partPath != "" {
+		relsPath = zippkg.RelationsPathFor(partPath)
+	}
+	rels := relationships.NewRelationships()
+	f, ok := files[relsPath]
+	if !ok {
+		return nil, fmt.Errorf("relationships %s not found", relsPath)
+	}
+	if err := zippkg.Decode(f, rels); err != nil {
+		return nil, err
+	}
+	return rels, nil
+}
+
+// streamFindRel returns the path of the first part related to partPath with a
+// relationship type ending in typeSuffix, so that strict types also match.
+func streamFindRel(files map[string]*zip.File, partPath, typeSuffix string) (string, error) {
+	rels, err := streamReadRels(files, partPath)
+	if err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationship {
+		if strings.HasSuffix(rel.TypeAttr, typeSuffix) {
+			return streamResolve(partPath, rel.TargetAttr), nil
+		}
+	}
+	return "", errors.New("workbook not found in package")
+}
+
+// streamResolve returns the path within the zip of a relationship target of
+// the part at partPath.
+func streamResolve(partPath, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return target[1:]
+	}
+	return path.Join(path.Dir(partPath), target)
+}
+
+// streamReadSharedStrings reads the text of each shared string.
+func streamReadSharedStrings(f *zip.File) ([]string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	sst := sml.NewSst()
+	if err := zippkg.Decode(f, sst); err != nil {
+		return nil, err
+	}
+	ret := make([]string, len(sst.Si))
+	for i, si := range sst.Si {
+		ret[i] = rstText(si)
+	}
+	return ret, nil
+}
@@ -8,9 +8,13 @@
 package spreadsheet
 
 import (
+	"bytes"
+	"encoding/xml"
 	"errors"
 
 	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/color"
+	"github.com/unidoc/unioffice/common"
 	"github.com/unidoc/unioffice/schema/soo/sml"
 )
 
@@ -122,6 +126,39 @@ func (s StyleSheet) AddCellStyle() CellStyle {
 	return CellStyle{s.wb, xf, s.x.CellXfs}
 }
 
+// DedupeCellStyle returns an earlier cell style that is identical to cs, or cs
+// itself if there is none.  If cs is a duplicate and the most recently added
+// style it is removed from the stylesheet, so this should be called once a new
+// style is fully configured and before it's applied to any cells.  The
+// returned style should be used in place of cs.
+func (s StyleSheet) DedupeCellStyle(cs CellStyle) CellStyle {
+	xfs := s.x.CellXfs.Xf
+	for _, xf := range xfs {
+		if xf == cs.xf {
+			break
+		}
+		if sameXML(xf, cs.xf) {
+			if last := len(xfs) - 1; xfs[last] == cs.xf {
+				s.x.CellXfs.Xf = xfs[:last]
+				s.x.CellXfs.CountAttr = unioffice.Uint32(uint32(last))
+			}
+			return CellStyle{s.wb, xf, s.x.CellXfs}
+		}
+	}
+	return cs
+}
+
+func (s StyleSheet) getOrCreateCellStyle(cand *sml.CT_Xf) CellStyle {
+	for _, xf := range s.x.CellXfs.Xf {
+		if sameXML(xf, cand) {
+			return CellStyle{s.wb, xf, s.x.CellXfs}
+		}
+	}
+	s.x.CellXfs.Xf = append(s.x.CellXfs.Xf, cand)
+	s.x.CellXfs.CountAttr = unioffice.Uint32(uint32(len(s.x.CellXfs.Xf)))
+	return CellStyle{s.wb, cand, s.x.CellXfs}
+}
+
 // CellStyles returns the list of defined cell styles
 func (s StyleSheet) CellStyles() []CellStyle {
 	ret := []CellStyle{}
@@ -165,14 +202,178 @@ func (s StyleSheet) AddNumberFormat() NumberFormat {
 		s.x.NumFmts = sml.NewCT_NumFmts()
 	}
 	nf := sml.NewCT_NumFmt()
-	// start our IDs at 200 so we can ensure we don't conflict with any
-	// pre-defined formats
-	nf.NumFmtIdAttr = uint32(200 + len(s.x.NumFmts.NumFmt))
+	// custom number formats must have an ID of at least 164 to avoid
+	// conflicting with the built-in formats
+	nf.NumFmtIdAttr = firstCustomNumberFormatID
+	for _, enf := range s.x.NumFmts.NumFmt {
+		if enf.NumFmtIdAttr >= nf.NumFmtIdAttr {
+			nf.NumFmtIdAttr = enf.NumFmtIdAttr + 1
+		}
+	}
 	s.x.NumFmts.NumFmt = append(s.x.NumFmts.NumFmt, nf)
 	s.x.NumFmts.CountAttr = unioffice.Uint32(uint32(len(s.x.NumFmts.NumFmt)))
 	return NumberFormat{s.wb, nf}
 }
 
+// GetOrCreateNumberFormat returns the number format with the given format
+// code, adding it to the stylesheet if it doesn't already exist.
+func (s StyleSheet) GetOrCreateNumberFormat(code string) NumberFormat {
+	if s.x.NumFmts != nil {
+		for _, nf := range s.x.NumFmts.NumFmt {
+			if nf.FormatCodeAttr == code {
+				return NumberFormat{s.wb, nf}
+			}
+		}
+	}
+	nf := s.AddNumberFormat()
+	nf.SetFormat(code)
+	return nf
+}
+
+// GetOrCreateSolidFill returns a solid fill of the given color, adding it to
+// the stylesheet if an identical fill doesn't already exist.
+func (s StyleSheet) GetOrCreateSolidFill(c color.Color) Fill {
+	cand := sml.NewCT_Fill()
+	pf := PatternFill{sml.NewCT_PatternFill(), cand}
+	cand.PatternFill = pf.X()
+	pf.SetPattern(sml.ST_PatternTypeSolid)
+	pf.SetFgColor(c)
+	return s.getOrCreateFill(cand)
+}
+
+// GetOrCreateSolidThemeFill returns a solid fill using a theme color, adding
+// it to the stylesheet if an identical fill doesn't already exist.  The tint
+// ranges from -1.0 (darkest) to 1.0 (lightest), with zero leaving the theme
+// color unchanged.
+func (s StyleSheet) GetOrCreateSolidThemeFill(slot common.ThemeColorSlot, tint float64) Fill {
+	cand := sml.NewCT_Fill()
+	pf := PatternFill{sml.NewCT_PatternFill(), cand}
+	cand.PatternFill = pf.X()
+	pf.SetPattern(sml.ST_PatternTypeSolid)
+	pf.SetFgThemeColor(slot, tint)
+	return s.getOrCreateFill(cand)
+}
+
+func (s StyleSheet) getOrCreateFill(cand *sml.CT_Fill) Fill {
+	for _, f := range s.x.Fills.Fill {
+		if sameXML(f, cand) {
+			return Fill{f, s.x.Fills}
+		}
+	}
+	s.x.Fills.Fill = append(s.x.Fills.Fill, cand)
+	s.x.Fills.CountAttr = unioffice.Uint32(uint32(len(s.x.Fills.Fill)))
+	return Fill{cand, s.x.Fills}
+}
+
+// GetOrCreateBorder returns a border with the same style and color on every
+// side, adding it to the stylesheet if an identical border doesn't already
+// exist.
+func (s StyleSheet) GetOrCreateBorder(style sml.ST_BorderStyle, c color.Color) Border {
+	cand := Border{sml.NewCT_Border(), s.x.Borders}
+	cand.InitializeDefaults()
+	cand.SetLeft(style, c)
+	cand.SetRight(style, c)
+	cand.SetTop(style, c)
+	cand.SetBottom(style, c)
+	for _, b := range s.x.Borders.Border {
+		if sameXML(b, cand.X()) {
+			return Border{b, s.x.Borders}
+		}
+	}
+	s.x.Borders.Border = append(s.x.Borders.Border, cand.X())
+	s.x.Borders.CountAttr = unioffice.Uint32(uint32(len(s.x.Borders.Border)))
+	return cand
+}
+
+// GetOrCreateFont returns a font with the given attributes, adding it to the
+// stylesheet if an identical font doesn't already exist.
+func (s StyleSheet) GetOrCreateFont(name string, size float64, bold bool, c color.Color) Font {
+	cand := Font{sml.NewCT_Font(), s.x}
+	cand.SetBold(bold)
+	cand.SetName(name)
+	cand.SetSize(size)
+	cand.SetColor(c)
+	for _, f := range s.x.Fonts.Font {
+		if sameXML(f, cand.X()) {
+			return Font{f, s.x}
+		}
+	}
+	s.x.Fonts.Font = append(s.x.Fonts.Font, cand.X())
+	s.x.Fonts.CountAttr = unioffice.Uint32(uint32(len(s.x.Fonts.Font)))
+	return cand
+}
+
+// SetDefaultFont sets the name and size of the default font (font index 0)
+// which is used by the Normal style and by any cells without a style.  Cell
+// styles that explicitly apply the default font keep the previous font.
+func (s StyleSheet) SetDefaultFont(name string, size float64) {
+	if len(s.x.Fonts.Font) == 0 {
+		s.AddFont()
+	}
+	def := s.x.Fonts.Font[0]
+
+	// preserve the previous default font for any styles that explicitly apply it
+	var prev *sml.CT_Font
+	keepFont := func(xfs []*sml.CT_Xf) {
+		for i, xf := range xfs {
+			if i == 0 || xf.FontIdAttr == nil || *xf.FontIdAttr != 0 ||
+				xf.ApplyFontAttr == nil || !*xf.ApplyFontAttr {
+				continue
+			}
+			if prev == nil {
+				prev = sml.NewCT_Font()
+				*prev = *def
+				s.x.Fonts.Font = append(s.x.Fonts.Font, prev)
+				s.x.Fonts.CountAttr = unioffice.Uint32(uint32(len(s.x.Fonts.Font)))
+			}
+			xf.FontIdAttr = unioffice.Uint32(uint32(len(s.x.Fonts.Font) - 1))
+		}
+	}
+	if s.x.CellXfs != nil {
+		keepFont(s.x.CellXfs.Xf)
+	}
+	if s.x.CellStyleXfs != nil {
+		keepFont(s.x.CellStyleXfs.Xf)
+	}
+
+	fnt := Font{def, s.x}
+	fnt.SetName(name)
+	fnt.SetSize(size)
+	// a font scheme would cause the theme font to be used instead of name
+	def.Scheme = nil
+
+	if s.x.CellStyleXfs != nil && len(s.x.CellStyleXfs.Xf) > 0 {
+		s.x.CellStyleXfs.Xf[0].FontIdAttr = unioffice.Uint32(0)
+	}
+	if s.x.CellXfs != nil && len(s.x.CellXfs.Xf) > 0 {
+		s.x.CellXfs.Xf[0].FontIdAttr = unioffice.Uint32(0)
+	}
+}
+
+// sameXML returns true if a and b marshal to identical XML.
+func sameXML(a, b interface{}) bool {
+	ax, err := xml.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bx, err := xml.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ax, bx)
+}
+
+// newThemeColor constructs a color referring to a theme color slot, lightened
+// or darkened by tint.
+func newThemeColor(slot common.ThemeColorSlot, tint float64) *sml.CT_Color {
+	clr := sml.NewCT_Color()
+	clr.ThemeAttr = unioffice.Uint32(uint32(slot))
+	if tint != 0 {
+		clr.TintAttr = unioffice.Float64(tint)
+	}
+	return clr
+}
+
 // Fills returns a Fills object that can be used to add/create/edit fills.
 func (s StyleSheet) Fills() Fills {
 	return Fills{s.x.Fills}
@@ -191,6 +392,9 @@ func (s StyleSheet) GetNumberFormat(id uint32) NumberFormat {
 	if id >= 0 && id < 50 {
 		return CreateDefaultNumberFormat(StandardFormat(id))
 	}
+	if s.x.NumFmts == nil {
+		return NumberFormat{}
+	}
 	for _, nf := range s.x.NumFmts.NumFmt {
 		if nf.NumFmtIdAttr == id {
 			return NumberFormat{s.wb, nf}
@@ -12,8 +12,12 @@ import (
 	"encoding/xml"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/unidoc/unioffice/color"
+	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/schema/soo/sml"
 	"github.com/unidoc/unioffice/spreadsheet"
 	"github.com/unidoc/unioffice/testhelper"
 	"github.com/unidoc/unioffice/zippkg"
@@ -58,3 +62,296 @@ func TestStyleSheetFonts(t *testing.T) {
 	}
 
 }
+
+func TestCustomNumberFormats(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+
+	cs := wb.StyleSheet.AddCellStyle()
+	cs.SetNumberFormat(spreadsheet.NumberFormatCurrency)
+	if cs.NumberFormat() < 164 {
+		t.Errorf("expected custom format ID >= 164, got %d", cs.NumberFormat())
+	}
+	other := wb.StyleSheet.AddCellStyle()
+	other.SetNumberFormat(spreadsheet.NumberFormatCurrency)
+	if other.NumberFormat() != cs.NumberFormat() {
+		t.Errorf("expected duplicate format codes to share an ID, got %d and %d",
+			cs.NumberFormat(), other.NumberFormat())
+	}
+	if n := len(wb.StyleSheet.X().NumFmts.NumFmt); n != 1 {
+		t.Errorf("expected a single number format, got %d", n)
+	}
+	date := wb.StyleSheet.AddCellStyle()
+	date.SetNumberFormat(spreadsheet.NumberFormatDateLong)
+	if date.NumberFormat() != cs.NumberFormat()+1 {
+		t.Errorf("expected next custom ID %d, got %d", cs.NumberFormat()+1, date.NumberFormat())
+	}
+	pct := wb.StyleSheet.AddCellStyle()
+	pct.SetNumberFormat(spreadsheet.NumberFormatPercent)
+	if pct.NumberFormat() != uint32(spreadsheet.StandardFormat10) {
+		t.Errorf("expected built-in format ID 10, got %d", pct.NumberFormat())
+	}
+
+	cell := sheet.Cell("A1")
+	cell.SetNumber(1234.5)
+	cell.SetStyle(cs)
+	if got := cell.GetFormattedValue(); got != "$1,234.50" {
+		t.Errorf("expected $1,234.50, got %s", got)
+	}
+}
+
+func TestCellStyleBuilderDedupes(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	ss := wb.StyleSheet
+	nXfs := len(ss.X().CellXfs.Xf)
+	nFills := len(ss.X().Fills.Fill)
+	nBorders := len(ss.X().Borders.Border)
+	nFonts := len(ss.X().Fonts.Font)
+
+	cs := ss.AddCellStyle().
+		SetFillColor(color.Red).
+		SetBorderAll(sml.ST_BorderStyleThin, color.Black).
+		SetFontAttributes("Arial", 12, true, color.Blue).
+		SetHorizontalAlignment(sml.ST_HorizontalAlignmentCenter).
+		SetVerticalAlignment(sml.ST_VerticalAlignmentTop).
+		SetWrapped(true).
+		SetRotation(45)
+	for r := 1; r <= 10000; r++ {
+		sheet.Cell(fmt.Sprintf("A%d", r)).SetStyle(cs)
+	}
+
+	// an identical second style reuses the format, fill, border and font
+	cs2 := ss.DedupeCellStyle(ss.AddCellStyle().
+		SetFillColor(color.Red).
+		SetBorderAll(sml.ST_BorderStyleThin, color.Black).
+		SetFontAttributes("Arial", 12, true, color.Blue).
+		SetHorizontalAlignment(sml.ST_HorizontalAlignmentCenter).
+		SetVerticalAlignment(sml.ST_VerticalAlignmentTop).
+		SetWrapped(true).
+		SetRotation(45))
+	sheet.Cell("B1").SetStyle(cs2)
+
+	if got := len(ss.X().CellXfs.Xf); got != nXfs+1 {
+		t.Errorf("expected %d xfs, got %d", nXfs+1, got)
+	}
+	if cs2.Index() != cs.Index() {
+		t.Errorf("expected identical styles to share an index, got %d and %d", cs.Index(), cs2.Index())
+	}
+	if got := sheet.Cell("B1").X().SAttr; got == nil || *got != cs.Index() {
+		t.Errorf("expected cell to reference the existing style")
+	}
+	if got := len(ss.X().Fills.Fill); got != nFills+1 {
+		t.Errorf("expected %d fills, got %d", nFills+1, got)
+	}
+	if got := len(ss.X().Borders.Border); got != nBorders+1 {
+		t.Errorf("expected %d borders, got %d", nBorders+1, got)
+	}
+	if got := len(ss.X().Fonts.Font); got != nFonts+1 {
+		t.Errorf("expected %d fonts, got %d", nFonts+1, got)
+	}
+	if *cs.X().FillIdAttr != *cs2.X().FillIdAttr {
+		t.Errorf("expected styles to share a fill")
+	}
+	if got := sheet.Cell("A9999").X().SAttr; got == nil || *got != cs.Index() {
+		t.Errorf("expected cell to reference the style")
+	}
+	if err := wb.Validate(); err != nil {
+		t.Errorf("created an invalid spreadsheet: %s", err)
+	}
+}
+
+func TestCellStylesAreIndependent(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	ss := wb.StyleSheet
+	nXfs := len(ss.X().CellXfs.Xf)
+
+	csA := ss.AddCellStyle()
+	csB := ss.AddCellStyle()
+	if csA.Index() == csB.Index() {
+		t.Fatalf("expected new styles to have distinct indexes, got %d", csA.Index())
+	}
+	sheet.Cell("A1").SetStyle(csA)
+	sheet.Cell("B1").SetStyle(csB)
+	csA.SetFillColor(color.Red)
+	csB.SetFillColor(color.Blue)
+	if got := len(ss.X().CellXfs.Xf); got != nXfs+2 {
+		t.Errorf("expected %d xfs, got %d", nXfs+2, got)
+	}
+	if got := *sheet.Cell("B1").X().SAttr; got != csB.Index() {
+		t.Errorf("expected B1 to reference its own style %d, got %d", csB.Index(), got)
+	}
+	if *csA.X().FillIdAttr == *csB.X().FillIdAttr {
+		t.Errorf("expected the styles to have different fills")
+	}
+
+	// a style with no identical earlier style is kept as is
+	csC := ss.AddCellStyle().SetWrapped(true)
+	if got := ss.DedupeCellStyle(csC); got.X() != csC.X() {
+		t.Errorf("expected a unique style to be returned unchanged")
+	}
+}
+
+func TestFillThemeColor(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	cs := wb.StyleSheet.AddCellStyle().SetFillThemeColor(common.ThemeColorAccent1, 0.4)
+	cell := sheet.Cell("A1")
+	cell.SetString("accent")
+	cell.SetStyle(cs)
+
+	fill := wb.StyleSheet.X().Fills.Fill[*cs.X().FillIdAttr]
+	fg := fill.PatternFill.FgColor
+	if fg.ThemeAttr == nil || *fg.ThemeAttr != 4 {
+		t.Errorf("expected theme color 4 (accent1), got %v", fg.ThemeAttr)
+	}
+	if fg.TintAttr == nil || *fg.TintAttr != 0.4 {
+		t.Errorf("expected tint of 0.4, got %v", fg.TintAttr)
+	}
+	if fg.RgbAttr != nil {
+		t.Errorf("expected no rgb color for a theme fill")
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	styles := zipContents(t, buf.Bytes())["xl/styles.xml"]
+	if !strings.Contains(styles, `<ma:fgColor theme="4" tint="0.4"/>`) {
+		t.Errorf("expected a theme fill color in the styles, got %s", styles)
+	}
+}
+
+func TestThemeColors(t *testing.T) {
+	wb, err := spreadsheet.Open("testdata/fmt.xlsx")
+	if err != nil {
+		t.Fatalf("error opening workbook: %s", err)
+	}
+	themes := wb.Themes()
+	if len(themes) != 1 {
+		t.Fatalf("expected 1 theme, got %d", len(themes))
+	}
+	if _, ok := themes[0].Color(common.ThemeColorAccent1); !ok {
+		t.Errorf("expected an accent1 color in the theme")
+	}
+	themes[0].SetColor(common.ThemeColorAccent1, color.Orange)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	got, ok := wb2.Themes()[0].Color(common.ThemeColorAccent1)
+	if !ok || *got.AsRGBString() != *color.Orange.AsRGBString() {
+		t.Errorf("expected accent1 to round trip as orange")
+	}
+}
+
+func TestSetDefaultFont(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Cell("A1").SetString("unstyled")
+
+	explicit := wb.StyleSheet.AddCellStyle()
+	explicit.SetFont(wb.StyleSheet.Fonts()[0])
+	sheet.Cell("A2").SetStyle(explicit)
+
+	wb.SetDefaultFont("Arial", 10)
+
+	fontOf := func(xf *sml.CT_Xf) *sml.CT_Font {
+		return wb.StyleSheet.X().Fonts.Font[*xf.FontIdAttr]
+	}
+	unstyled := fontOf(wb.StyleSheet.X().CellXfs.Xf[0])
+	if unstyled.Name[0].ValAttr != "Arial" || unstyled.Sz[0].ValAttr != 10 {
+		t.Errorf("expected unstyled cells to use Arial 10, got %s %v", unstyled.Name[0].ValAttr, unstyled.Sz[0].ValAttr)
+	}
+	normal := fontOf(wb.StyleSheet.X().CellStyleXfs.Xf[0])
+	if normal.Name[0].ValAttr != "Arial" {
+		t.Errorf("expected normal style to use Arial, got %s", normal.Name[0].ValAttr)
+	}
+	kept := fontOf(explicit.X())
+	if kept.Name[0].ValAttr != "Calibri" || kept.Sz[0].ValAttr != 11 {
+		t.Errorf("expected explicit style to keep Calibri 11, got %s %v", kept.Name[0].ValAttr, kept.Sz[0].ValAttr)
+	}
+	if err := wb.Validate(); err != nil {
+		t.Errorf("expected no validation error, got %s", err)
+	}
+}
+
+func TestCellStyleRotation(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	angled := wb.StyleSheet.AddCellStyle().SetRotation(45)
+	vertical := wb.StyleSheet.AddCellStyle().SetRotation(255)
+	sheet.Cell("A1").SetString("Angled")
+	sheet.Cell("A1").SetStyle(angled)
+	sheet.Cell("B1").SetString("Stacked")
+	sheet.Cell("B1").SetStyle(vertical)
+
+	td := []struct {
+		deg int
+		exp uint8
+	}{
+		{0, 0},
+		{90, 90},
+		{-45, 135},
+		{-90, 180},
+		{135, 135},
+	}
+	for _, tc := range td {
+		cs := wb.StyleSheet.AddCellStyle().SetRotation(tc.deg)
+		if got := *cs.X().Alignment.TextRotationAttr; got != tc.exp {
+			t.Errorf("expected rotation %d to be encoded as %d, got %d", tc.deg, tc.exp, got)
+		}
+	}
+	invalid := wb.StyleSheet.AddCellStyle().SetRotation(200)
+	if invalid.X().Alignment != nil {
+		t.Errorf("expected an invalid rotation to be ignored")
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	styles := zipContents(t, buf.Bytes())["xl/styles.xml"]
+	for _, exp := range []string{`textRotation="45"`, `textRotation="255"`} {
+		if !strings.Contains(styles, exp) {
+			t.Errorf("expected %s in styles, got %s", exp, styles)
+		}
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading: %s", err)
+	}
+	xf := wb2.StyleSheet.GetCellStyle(*wb2.Sheets()[0].Cell("B1").X().SAttr).X()
+	if xf.Alignment == nil || xf.Alignment.TextRotationAttr == nil || *xf.Alignment.TextRotationAttr != 255 {
+		t.Errorf("expected vertical text after reading")
+	}
+}
+
+func TestCellStyleIndent(t *testing.T) {
+	wb := spreadsheet.New()
+	cs := wb.StyleSheet.AddCellStyle().SetIndent(2)
+	al := cs.X().Alignment
+	if al.IndentAttr == nil || *al.IndentAttr != 2 {
+		t.Errorf("expected indent 2")
+	}
+	if al.HorizontalAttr != sml.ST_HorizontalAlignmentLeft {
+		t.Errorf("expected indented text to be left aligned, got %s", al.HorizontalAttr)
+	}
+
+	right := wb.StyleSheet.AddCellStyle().
+		SetHorizontalAlignment(sml.ST_HorizontalAlignmentRight).
+		SetIndent(1)
+	if right.X().Alignment.HorizontalAttr != sml.ST_HorizontalAlignmentRight {
+		t.Errorf("expected the alignment to be kept")
+	}
+	cs.SetIndent(0)
+	if al.IndentAttr != nil {
+		t.Errorf("expected the indent to be removed")
+	}
+}
@@ -7,8 +7,13 @@
 
 package spreadsheet
 
-import "github.com/unidoc/unioffice/schema/soo/sml"
+import (
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+)
 
+// Table is an Excel table, a range of cells with a header row that is filtered,
+// styled and referred to as a unit.
 type Table struct {
 	x *sml.Table
 }
@@ -30,3 +35,47 @@ func (t Table) Name() string {
 func (t Table) Reference() string {
 	return t.x.RefAttr
 }
+
+// SetStyle sets the name of the table style, e.g. "TableStyleMedium2".
+func (t Table) SetStyle(name string) {
+	t.styleInfo().NameAttr = unioffice.String(name)
+}
+
+// SetBandedRows controls if alternate rows of the table are shaded.
+func (t Table) SetBandedRows(b bool) {
+	t.styleInfo().ShowRowStripesAttr = unioffice.Bool(b)
+}
+
+// SetBandedColumns controls if alternate columns of the table are shaded.
+func (t Table) SetBandedColumns(b bool) {
+	t.styleInfo().ShowColumnStripesAttr = unioffice.Bool(b)
+}
+
+// SetShowFirstColumn controls if the first column is highlighted.
+func (t Table) SetShowFirstColumn(b bool) {
+	t.styleInfo().ShowFirstColumnAttr = unioffice.Bool(b)
+}
+
+// SetShowLastColumn controls if the last column is highlighted.
+func (t Table) SetShowLastColumn(b bool) {
+	t.styleInfo().ShowLastColumnAttr = unioffice.Bool(b)
+}
+
+// Columns returns the names of the table's columns.
+func (t Table) Columns() []string {
+	if t.x.TableColumns == nil {
+		return nil
+	}
+	ret := []string{}
+	for _, tc := range t.x.TableColumns.TableColumn {
+		ret = append(ret, tc.NameAttr)
+	}
+	return ret
+}
+
+func (t Table) styleInfo() *sml.CT_TableStyleInfo {
+	if t.x.TableStyleInfo == nil {
+		t.x.TableStyleInfo = sml.NewCT_TableStyleInfo()
+	}
+	return t.x.TableStyleInfo
+}
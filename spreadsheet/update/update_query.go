@@ -13,6 +13,13 @@ type UpdateAction byte
 const (
 	// UpdateActionRemoveColumn means updating references after removing a column.
 	UpdateActionRemoveColumn UpdateAction = iota
+
+	// UpdateActionInsertRow means updating references after inserting a row.
+	UpdateActionInsertRow
+
+	// UpdateActionRemoveRow means updating references after removing a row
+	// and moving the rows below it up.
+	UpdateActionRemoveRow
 )
 
 // UpdateQuery contains terms of how to update references after removing row/column.
@@ -23,6 +30,9 @@ type UpdateQuery struct {
 	// ColumnIdx is the index of the column removed.
 	ColumnIdx uint32
 
+	// RowIdx is the row number (1-N) of the row inserted or removed.
+	RowIdx uint32
+
 	// SheetToUpdate contains the name of the sheet on which removing happened.
 	SheetToUpdate string
 
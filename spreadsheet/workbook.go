@@ -9,19 +9,28 @@ package spreadsheet
 
 import (
 	"archive/zip"
+	"bytes"
+	"encoding/xml"
 	"errors"
 	"flag"
 	"fmt"
 	"image"
 	"image/jpeg"
 	"io"
+	"io/ioutil"
 	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/algo"
 	"github.com/unidoc/unioffice/color"
 	"github.com/unidoc/unioffice/common"
+	"github.com/unidoc/unioffice/common/encryption"
 	"github.com/unidoc/unioffice/common/license"
 	"github.com/unidoc/unioffice/vmldrawing"
 	"github.com/unidoc/unioffice/zippkg"
@@ -37,6 +46,11 @@ import (
 var ErrorNotFound = errors.New("not found")
 
 // Workbook is the top level container item for a set of spreadsheets.
+//
+// Different sheets of a workbook may have their cell values set from separate
+// goroutines as the shared string table is safe for concurrent use.  Other
+// workbook level changes, such as adding sheets, styles or drawings, must not
+// be made concurrently.
 type Workbook struct {
 	common.DocBase
 	x *sml.Workbook
@@ -55,6 +69,27 @@ type Workbook struct {
 	charts      []*crt.ChartSpace
 	tables      []*sml.Table
 	filename    string
+
+	// sheetDrawings maps worksheet relationships to the drawings they refer to
+	sheetDrawings map[*relationships.Relationship]*sd.WsDr
+
+	pivotTables    []*sml.PivotTableDefinition
+	pivotTableRels []common.Relationships
+	pivotCaches    []*sml.PivotCacheDefinition
+	pivotCacheRels []common.Relationships
+	pivotRecords   []*sml.PivotCacheRecords
+	pivotOffset    int
+
+	calcChain *sml.CalcChain
+	// formulasChanged is set when a formula is added, changed or removed
+	formulasChanged bool
+
+	streams     map[*sml.Worksheet]*StreamingSheet
+	rowIndexes  map[*sml.Worksheet]*rowIndex
+	cellIndexes cellIndexCache
+
+	inlineStrings   bool
+	stringifyValues bool
 }
 
 // X returns the inner wrapped XML type.
@@ -88,6 +123,7 @@ func (wb *Workbook) AddSheet() Sheet {
 	ws.SheetData = sml.NewCT_SheetData()
 
 	wb.comments = append(wb.comments, nil)
+	wb.vmlDrawings = append(wb.vmlDrawings, nil)
 
 	dt := unioffice.DocTypeSpreadsheet
 
@@ -129,29 +165,66 @@ func (wb *Workbook) ensureSharedStringsRelationships() {
 	}
 }
 
-// RemoveSheet removes the sheet with the given index from the workbook.
+// RemoveSheet removes the sheet with the given index from the workbook along
+// with its relationship, content type override and any defined names scoped to
+// it.  The remaining sheets are renumbered so their parts and relationships
+// stay consistent.  Excel requires at least one visible sheet, so removing the
+// last visible sheet is an error.
 func (wb *Workbook) RemoveSheet(ind int) error {
-	if wb.SheetCount() <= ind {
+	if ind < 0 || wb.SheetCount() <= ind {
 		return ErrorNotFound
 	}
+	if isSheetVisible(wb.x.Sheets.Sheet[ind]) {
+		visible := 0
+		for _, s := range wb.x.Sheets.Sheet {
+			if isSheetVisible(s) {
+				visible++
+			}
+		}
+		if visible == 1 {
+			return errors.New("cannot remove the last visible sheet")
+		}
+	}
+
+	// move the sheet to the end so that the remaining sheets keep their
+	// indices and part names in sync, then remove it
+	n := wb.SheetCount()
+	order := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if i != ind {
+			order = append(order, i)
+		}
+	}
+	wb.reorderSheets(append(order, ind))
+	last := n - 1
 
+	dt := unioffice.DocTypeSpreadsheet
 	for _, r := range wb.wbRels.Relationships() {
-		if r.ID() == wb.x.Sheets.Sheet[ind].IdAttr {
+		if r.ID() == wb.x.Sheets.Sheet[last].IdAttr {
 			wb.wbRels.Remove(r)
 			break
 		}
 	}
+	wb.ContentTypes.RemoveOverride(unioffice.AbsoluteFilename(dt, unioffice.WorksheetContentType, last+1))
+	if wb.comments[last] != nil {
+		wb.ContentTypes.RemoveOverride(unioffice.AbsoluteFilename(dt, unioffice.CommentsType, last+1))
+	}
 
-	wb.ContentTypes.RemoveOverride(unioffice.AbsoluteFilename(unioffice.DocTypeSpreadsheet,
-		unioffice.WorksheetContentType, ind+1))
-
-	copy(wb.xws[ind:], wb.xws[ind+1:])
-	wb.xws = wb.xws[:len(wb.xws)-1]
-
-	removed := wb.x.Sheets.Sheet[ind]
+	if ss, ok := wb.streams[wb.xws[last]]; ok {
+		ss.close()
+		delete(wb.streams, wb.xws[last])
+	}
+	delete(wb.rowIndexes, wb.xws[last])
+	for _, r := range wb.xws[last].SheetData.Row {
+		wb.cellIndexes.remove(r)
+	}
 
-	copy(wb.x.Sheets.Sheet[ind:], wb.x.Sheets.Sheet[ind+1:])
-	wb.x.Sheets.Sheet = wb.x.Sheets.Sheet[:len(wb.x.Sheets.Sheet)-1]
+	removed := wb.x.Sheets.Sheet[last]
+	wb.x.Sheets.Sheet = wb.x.Sheets.Sheet[:last]
+	wb.xws = wb.xws[:last]
+	wb.xwsRels = wb.xwsRels[:last]
+	wb.comments = wb.comments[:last]
+	wb.vmlDrawings = wb.vmlDrawings[:last]
 
 	// fix sheet IDs by decrementing each one after the removed sheet
 	for i := range wb.x.Sheets.Sheet {
@@ -160,15 +233,29 @@ func (wb *Workbook) RemoveSheet(ind int) error {
 		}
 	}
 
-	copy(wb.xwsRels[ind:], wb.xwsRels[ind+1:])
-	wb.xwsRels = wb.xwsRels[:len(wb.xwsRels)-1]
-
-	copy(wb.comments[ind:], wb.comments[ind+1:])
-	wb.comments = wb.comments[:len(wb.comments)-1]
-
+	for _, dn := range wb.DefinedNames() {
+		if id, ok := dn.LocalSheetID(); ok && int(id) == last {
+			wb.RemoveDefinedName(dn)
+		}
+	}
+	if wb.x.BookViews != nil {
+		for _, bv := range wb.x.BookViews.WorkbookView {
+			if bv.ActiveTabAttr != nil && int(*bv.ActiveTabAttr) >= last {
+				bv.ActiveTabAttr = nil
+			}
+			if bv.FirstSheetAttr != nil && int(*bv.FirstSheetAttr) >= last {
+				bv.FirstSheetAttr = nil
+			}
+		}
+	}
 	return nil
 }
 
+// isSheetVisible returns true if the sheet is neither hidden nor very hidden.
+func isSheetVisible(s *sml.CT_Sheet) bool {
+	return s.StateAttr != sml.ST_SheetStateHidden && s.StateAttr != sml.ST_SheetStateVeryHidden
+}
+
 // RemoveSheetByName removes the sheet with the given name from the workbook.
 func (wb *Workbook) RemoveSheetByName(name string) error {
 	sheetInd := -1
@@ -186,30 +273,67 @@ func (wb *Workbook) RemoveSheetByName(name string) error {
 	return wb.RemoveSheet(sheetInd)
 }
 
-// CopySheet copies the existing sheet at index `ind` and puts its copy with the name `copiedSheetName`.
+// CopySheet copies the existing sheet at index `ind` and puts its copy with the
+// name `copiedSheetName`. The worksheet is deep copied along with its
+// drawings, charts, comments and tables so the copy can be modified
+// independently of the original.  Defined names scoped to the source sheet are
+// duplicated with the scope of the new sheet.
 func (wb *Workbook) CopySheet(ind int, copiedSheetName string) (Sheet, error) {
 	if wb.SheetCount() <= ind {
 		return Sheet{}, ErrorNotFound
 	}
-
-	var copiedRel common.Relationship
-	for _, r := range wb.wbRels.Relationships() {
-		if r.ID() == wb.x.Sheets.Sheet[ind].IdAttr {
-			var ok bool
-			if copiedRel, ok = wb.wbRels.CopyRelationship(r.ID()); !ok {
-				return Sheet{}, ErrorNotFound
+	for _, s := range wb.x.Sheets.Sheet {
+		if s.NameAttr == copiedSheetName {
+			return Sheet{}, fmt.Errorf("sheet %s already exists", copiedSheetName)
+		}
+	}
+	dt := unioffice.DocTypeSpreadsheet
+	srcSheet := wb.x.Sheets.Sheet[ind]
+	newIdx := len(wb.xws)
+
+	copiedWs := sml.NewWorksheet()
+	if err := copyXML(wb.xws[ind], copiedWs); err != nil {
+		return Sheet{}, err
+	}
+
+	// copy the parts the sheet refers to, preserving relationship IDs so that
+	// references from the worksheet XML remain valid
+	copiedRels := common.NewRelationships()
+	var copiedComments *sml.Comments
+	var copiedVML *vmldrawing.Container
+	for _, r := range wb.xwsRels[ind].X().Relationship {
+		rel := *r
+		var err error
+		switch r.TypeAttr {
+		case unioffice.DrawingType:
+			err = wb.copyDrawing(wb, r, &rel)
+		case unioffice.TableType:
+			rel.TargetAttr, err = wb.copyTable(wb, r.TargetAttr, copiedSheetName)
+		case unioffice.CommentsType:
+			if wb.comments[ind] != nil {
+				copiedComments = sml.NewComments()
+				err = copyXML(wb.comments[ind], copiedComments)
+				fn := unioffice.AbsoluteFilename(dt, unioffice.CommentsType, newIdx+1)
+				wb.ContentTypes.AddOverride(fn, unioffice.CommentsContentType)
 			}
-
-			break
+			rel.TargetAttr = unioffice.RelativeFilename(dt, unioffice.WorksheetType, unioffice.CommentsType, newIdx+1)
+		case unioffice.VMLDrawingType:
+			if wb.vmlDrawings[ind] != nil {
+				copiedVML = vmldrawing.NewContainer()
+				err = copyXML(wb.vmlDrawings[ind], copiedVML)
+			}
+			rel.TargetAttr = unioffice.RelativeFilename(dt, unioffice.WorksheetType, unioffice.VMLDrawingType, newIdx+1)
 		}
+		if err != nil {
+			return Sheet{}, err
+		}
+		copiedRels.X().Relationship = append(copiedRels.X().Relationship, &rel)
 	}
 
-	wb.ContentTypes.CopyOverride(unioffice.AbsoluteFilename(unioffice.DocTypeSpreadsheet,
-		unioffice.WorksheetContentType, ind+1), unioffice.AbsoluteFilename(unioffice.DocTypeSpreadsheet,
-		unioffice.WorksheetContentType, len(wb.ContentTypes.X().Override)))
-
-	copiedWs := *wb.xws[ind]
-	wb.xws = append(wb.xws, &copiedWs)
+	wb.xws = append(wb.xws, copiedWs)
+	wb.xwsRels = append(wb.xwsRels, copiedRels)
+	wb.comments = append(wb.comments, copiedComments)
+	wb.vmlDrawings = append(wb.vmlDrawings, copiedVML)
 
 	var nextSheetID uint32 = 0
 	for _, s := range wb.x.Sheets.Sheet {
@@ -219,25 +343,174 @@ func (wb *Workbook) CopySheet(ind int, copiedSheetName string) (Sheet, error) {
 	}
 	nextSheetID++
 
-	copiedSheet := *wb.x.Sheets.Sheet[ind]
-	copiedSheet.IdAttr = copiedRel.ID()
+	copiedSheet := *srcSheet
+	copiedSheet.IdAttr = wb.wbRels.AddAutoRelationship(dt, unioffice.OfficeDocumentType, newIdx+1, unioffice.WorksheetType).ID()
 	copiedSheet.NameAttr = copiedSheetName
 	copiedSheet.SheetIdAttr = nextSheetID
-
 	wb.x.Sheets.Sheet = append(wb.x.Sheets.Sheet, &copiedSheet)
 
-	copiedXwsRel := common.NewRelationshipsCopy(wb.xwsRels[ind])
-	wb.xwsRels = append(wb.xwsRels, copiedXwsRel)
+	wb.ContentTypes.AddOverride(unioffice.AbsoluteFilename(dt, unioffice.WorksheetContentType, newIdx+1),
+		unioffice.WorksheetContentType)
 
-	copiedCommentsPtr := wb.comments[ind]
-	if copiedCommentsPtr == nil {
-		wb.comments = append(wb.comments, nil)
-	} else {
-		copiedComments := *copiedCommentsPtr
-		wb.comments = append(wb.comments, &copiedComments)
+	for _, dn := range wb.DefinedNames() {
+		if id, ok := dn.LocalSheetID(); ok && int(id) == ind {
+			content := renameSheetReferences(dn.Content(), srcSheet.NameAttr, copiedSheetName)
+			cdn := wb.addDefinedName(dn.Name(), content)
+			cdn.SetLocalSheetID(uint32(newIdx))
+			cdn.X().HiddenAttr = dn.X().HiddenAttr
+		}
+	}
+
+	return Sheet{wb, &copiedSheet, copiedWs}, nil
+}
+
+// copyDrawing copies the drawing that the worksheet relationship r refers to
+// to dst, along with any charts it contains, and points the relationship
+// copied at the copy.  The images the drawing refers to are also copied if dst
+// is another workbook.
+func (wb *Workbook) copyDrawing(dst *Workbook, r, copied *relationships.Relationship) error {
+	dt := unioffice.DocTypeSpreadsheet
+	i := wb.drawingIndex(r)
+	if i < 0 {
+		return nil
+	}
+	dr := wb.drawings[i]
+	copiedDr := sd.NewWsDr()
+	if err := copyXML(dr, copiedDr); err != nil {
+		return err
+	}
+	copiedRels := common.NewRelationships()
+	for _, r := range wb.drawingRels[i].X().Relationship {
+		rel := *r
+		switch r.TypeAttr {
+		case unioffice.ChartType:
+			for j, chart := range wb.charts {
+				if unioffice.RelativeFilename(dt, unioffice.DrawingType, unioffice.ChartType, j+1) != r.TargetAttr {
+					continue
+				}
+				copiedChart := crt.NewChartSpace()
+				if err := copyXML(chart, copiedChart); err != nil {
+					return err
+				}
+				dst.charts = append(dst.charts, copiedChart)
+				fn := unioffice.AbsoluteFilename(dt, unioffice.ChartContentType, len(dst.charts))
+				dst.ContentTypes.AddOverride(fn, unioffice.ChartContentType)
+				rel.TargetAttr = unioffice.RelativeFilename(dt, unioffice.DrawingType, unioffice.ChartType, len(dst.charts))
+				break
+			}
+		case unioffice.ImageType:
+			if dst == wb {
+				break
+			}
+			var err error
+			if rel.TargetAttr, err = wb.copyImage(dst, r.TargetAttr); err != nil {
+				return err
+			}
+		}
+		copiedRels.X().Relationship = append(copiedRels.X().Relationship, &rel)
+	}
+	dst.drawings = append(dst.drawings, copiedDr)
+	dst.drawingRels = append(dst.drawingRels, copiedRels)
+	fn := unioffice.AbsoluteFilename(dt, unioffice.DrawingType, len(dst.drawings))
+	dst.ContentTypes.AddOverride(fn, unioffice.DrawingContentType)
+	copied.TargetAttr = unioffice.RelativeFilename(dt, unioffice.WorksheetType, unioffice.DrawingType, len(dst.drawings))
+	dst.addSheetDrawing(copied, copiedDr)
+	return nil
+}
+
+// addSheetDrawing records that the worksheet relationship r refers to dr.
+func (wb *Workbook) addSheetDrawing(r *relationships.Relationship, dr *sd.WsDr) {
+	if wb.sheetDrawings == nil {
+		wb.sheetDrawings = map[*relationships.Relationship]*sd.WsDr{}
+	}
+	wb.sheetDrawings[r] = dr
+}
+
+// drawingIndex returns the index of the drawing that the worksheet
+// relationship r refers to, or -1 if it doesn't refer to a drawing.
+func (wb *Workbook) drawingIndex(r *relationships.Relationship) int {
+	dr, ok := wb.sheetDrawings[r]
+	if !ok {
+		return -1
+	}
+	for i, d := range wb.drawings {
+		if d == dr {
+			return i
+		}
 	}
+	return -1
+}
 
-	return Sheet{wb, &copiedSheet, &copiedWs}, nil
+// copyImage copies the image at the given drawing relationship target to dst
+// and returns the target of the copy.
+func (wb *Workbook) copyImage(dst *Workbook, target string) (string, error) {
+	idx := 0
+	if _, err := fmt.Sscanf(path.Base(target), "image%d.", &idx); err != nil || idx < 1 || idx > len(wb.Images) {
+		return target, nil
+	}
+	img := wb.Images[idx-1]
+	if _, err := dst.AddImage(common.Image{Size: img.Size(), Format: img.Format(), Path: img.Path(), Data: img.Data()}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("../media/image%d.%s", len(dst.Images), img.Format()), nil
+}
+
+// copyTable copies the table at the given worksheet relationship target to dst
+// and returns the target of the copy.  Table names must be unique within a
+// workbook, so a copy within the same workbook is named after the sheet it is
+// copied to.
+func (wb *Workbook) copyTable(dst *Workbook, target, sheetName string) (string, error) {
+	dt := unioffice.DocTypeSpreadsheet
+	for i, tbl := range wb.tables {
+		if unioffice.RelativeFilename(dt, unioffice.WorksheetType, unioffice.TableType, i+1) != target {
+			continue
+		}
+		copied := sml.NewTable()
+		if err := copyXML(tbl, copied); err != nil {
+			return "", err
+		}
+		if dst == wb {
+			for _, t := range wb.tables {
+				if t.IdAttr >= copied.IdAttr {
+					copied.IdAttr = t.IdAttr + 1
+				}
+			}
+			name := strings.Map(func(r rune) rune {
+				if r == ' ' || r == '\'' {
+					return '_'
+				}
+				return r
+			}, sheetName)
+			copied.NameAttr = unioffice.String(fmt.Sprintf("%s_%d", name, copied.IdAttr))
+			copied.DisplayNameAttr = fmt.Sprintf("%s_%d", name, copied.IdAttr)
+		}
+		dst.tables = append(dst.tables, copied)
+		fn := unioffice.AbsoluteFilename(dt, unioffice.TableType, len(dst.tables))
+		dst.ContentTypes.AddOverride(fn, unioffice.TableContentType)
+		return unioffice.RelativeFilename(dt, unioffice.WorksheetType, unioffice.TableType, len(dst.tables)), nil
+	}
+	return target, nil
+}
+
+// copyXML deep copies one XML type into another by marshaling and
+// unmarshaling it.
+func copyXML(src, dst interface{}) error {
+	buf := bytes.Buffer{}
+	if err := xml.NewEncoder(&buf).Encode(src); err != nil {
+		return err
+	}
+	return xml.NewDecoder(&buf).Decode(dst)
+}
+
+// renameSheetReferences replaces references to the sheet named from in a
+// formula with references to the sheet named to.
+func renameSheetReferences(content, from, to string) string {
+	quote := func(s string) string {
+		return "'" + strings.Replace(s, "'", "''", -1) + "'!"
+	}
+	content = strings.Replace(content, quote(from), quote(to), -1)
+	unquoted := regexp.MustCompile(`(^|[^\w.'])` + regexp.QuoteMeta(from+"!"))
+	return unquoted.ReplaceAllString(content, "${1}"+strings.Replace(quote(to), "$", "$$", -1))
 }
 
 // CopySheetByName copies the existing sheet with the name `name` and puts its copy with the name `copiedSheetName`.
@@ -257,6 +530,169 @@ func (wb *Workbook) CopySheetByName(name, copiedSheetName string) (Sheet, error)
 	return wb.CopySheet(sheetInd, copiedSheetName)
 }
 
+// MoveSheet moves the sheet at index from to index to, shifting the sheets in
+// between.  Both indices are zero based and must refer to existing sheets.
+func (wb *Workbook) MoveSheet(from, to int) error {
+	n := len(wb.xws)
+	if from < 0 || from >= n || to < 0 || to >= n {
+		return fmt.Errorf("sheet index out of range, must be in [0, %d)", n)
+	}
+	if from == to {
+		return nil
+	}
+	// order[i] is the current index of the sheet that will be at index i
+	order := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if i != from {
+			order = append(order, i)
+		}
+	}
+	order = append(order[:to], append([]int{from}, order[to:]...)...)
+	wb.reorderSheets(order)
+	return nil
+}
+
+// SetSheetIndex moves the sheet with the given name to the zero based index
+// idx.
+func (wb *Workbook) SetSheetIndex(name string, idx int) error {
+	for i, s := range wb.x.Sheets.Sheet {
+		if s.NameAttr == name {
+			return wb.MoveSheet(i, idx)
+		}
+	}
+	return ErrorNotFound
+}
+
+// reorderSheets reorders the sheets so that the sheet currently at index
+// order[i] is moved to index i, keeping the per-sheet parts, their file names
+// and anything that refers to sheets by index in sync.
+func (wb *Workbook) reorderSheets(order []int) {
+	dt := unioffice.DocTypeSpreadsheet
+	n := len(order)
+	newIdx := make([]int, n)
+	for i, o := range order {
+		newIdx[o] = i
+	}
+
+	// comments are stored in files named after the sheet index
+	for i, cmt := range wb.comments {
+		if cmt != nil {
+			wb.ContentTypes.RemoveOverride(unioffice.AbsoluteFilename(dt, unioffice.CommentsType, i+1))
+		}
+	}
+
+	sheets := make([]*sml.CT_Sheet, n)
+	xws := make([]*sml.Worksheet, n)
+	xwsRels := make([]common.Relationships, n)
+	comments := make([]*sml.Comments, n)
+	vmlDrawings := make([]*vmldrawing.Container, n)
+	for i, o := range order {
+		sheets[i] = wb.x.Sheets.Sheet[o]
+		xws[i] = wb.xws[o]
+		xwsRels[i] = wb.xwsRels[o]
+		comments[i] = wb.comments[o]
+		vmlDrawings[i] = wb.vmlDrawings[o]
+	}
+	wb.x.Sheets.Sheet = sheets
+	wb.xws = xws
+	wb.xwsRels = xwsRels
+	wb.comments = comments
+	wb.vmlDrawings = vmlDrawings
+	for i, vml := range wb.vmlDrawings {
+		if vml != nil {
+			renumberVMLDrawing(vml, i+1)
+		}
+	}
+
+	// worksheets are matched to sheets in relationship ID order when read, so
+	// the IDs are reassigned to follow the new sheet order
+	ids := make([]string, n)
+	for i, sheet := range wb.x.Sheets.Sheet {
+		ids[i] = sheet.IdAttr
+	}
+	sort.Slice(ids, func(i, j int) bool { return algo.NaturalLess(ids[i], ids[j]) })
+	wsRels := map[string]*relationships.Relationship{}
+	for _, r := range wb.wbRels.X().Relationship {
+		wsRels[r.IdAttr] = r
+	}
+
+	for i, sheet := range wb.x.Sheets.Sheet {
+		if r, ok := wsRels[sheet.IdAttr]; ok {
+			r.IdAttr = ids[i]
+			r.TargetAttr = unioffice.RelativeFilename(dt, unioffice.OfficeDocumentType, unioffice.WorksheetType, i+1)
+		}
+		sheet.IdAttr = ids[i]
+		for _, r := range wb.xwsRels[i].X().Relationship {
+			switch r.TypeAttr {
+			case unioffice.CommentsType:
+				r.TargetAttr = unioffice.RelativeFilename(dt, unioffice.WorksheetType, unioffice.CommentsType, i+1)
+			case unioffice.VMLDrawingType:
+				r.TargetAttr = unioffice.RelativeFilename(dt, unioffice.WorksheetType, unioffice.VMLDrawingType, i+1)
+			}
+		}
+		if wb.comments[i] != nil {
+			wb.ContentTypes.AddOverride(unioffice.AbsoluteFilename(dt, unioffice.CommentsType, i+1), unioffice.CommentsContentType)
+		}
+	}
+
+	for _, dn := range wb.DefinedNames() {
+		if id, ok := dn.LocalSheetID(); ok && int(id) < n {
+			dn.SetLocalSheetID(uint32(newIdx[id]))
+		}
+	}
+	if wb.x.BookViews != nil {
+		for _, bv := range wb.x.BookViews.WorkbookView {
+			if bv.ActiveTabAttr != nil && int(*bv.ActiveTabAttr) < n {
+				bv.ActiveTabAttr = unioffice.Uint32(uint32(newIdx[*bv.ActiveTabAttr]))
+			}
+			if bv.FirstSheetAttr != nil && int(*bv.FirstSheetAttr) < n {
+				first := uint32(newIdx[*bv.FirstSheetAttr])
+				// the active tab must remain scrolled into view
+				if bv.ActiveTabAttr != nil && first > *bv.ActiveTabAttr {
+					first = *bv.ActiveTabAttr
+				}
+				bv.FirstSheetAttr = unioffice.Uint32(first)
+			}
+		}
+	}
+}
+
+// vmlShapeIDRe matches the IDs that Office gives VML shapes, which are
+// allocated in blocks of 1024 given by the o:idmap of the drawing.
+var vmlShapeIDRe = regexp.MustCompile(`^_x0000_s([0-9]+)$`)
+
+// renumberVMLDrawing sets the ID block of a sheet's VML drawing, which is
+// numbered after the sheet, moving the IDs of its shapes to the new block.
+func renumberVMLDrawing(c *vmldrawing.Container, block int) {
+	if c.Layout == nil || c.Layout.Idmap == nil || c.Layout.Idmap.DataAttr == nil {
+		return
+	}
+	old, err := strconv.Atoi(*c.Layout.Idmap.DataAttr)
+	if err != nil || old == block {
+		// drawings that use several blocks are left as they are
+		return
+	}
+	c.Layout.Idmap.DataAttr = unioffice.String(strconv.Itoa(block))
+	remap := func(id *string) *string {
+		if id == nil {
+			return nil
+		}
+		m := vmlShapeIDRe.FindStringSubmatch(*id)
+		if m == nil {
+			return id
+		}
+		n, _ := strconv.Atoi(m[1])
+		if n/1024 != old {
+			return id
+		}
+		return unioffice.Stringf("_x0000_s%d", block*1024+n%1024)
+	}
+	for _, shp := range c.Shape {
+		shp.IdAttr = remap(shp.IdAttr)
+		shp.SpidAttr = remap(shp.SpidAttr)
+	}
+}
+
 // SaveToFile writes the workbook out to a file.
 func (wb *Workbook) SaveToFile(path string) error {
 	f, err := os.Create(path)
@@ -267,6 +703,21 @@ func (wb *Workbook) SaveToFile(path string) error {
 	return wb.Save(f)
 }
 
+// SaveEncrypted writes the workbook to a file encrypted with password.  The
+// workbook is encrypted using agile encryption, as Office 2010 and later do,
+// so that Excel will prompt for the password when opening the file.
+func (wb *Workbook) SaveEncrypted(path, password string) error {
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		return err
+	}
+	enc, err := encryption.Encrypt(buf.Bytes(), password)
+	if err != nil {
+		return fmt.Errorf("error encrypting workbook: %s", err)
+	}
+	return ioutil.WriteFile(path, enc, 0644)
+}
+
 // Uses1904Dates returns true if the the workbook uses dates relative to
 // 1 Jan 1904. This is uncommon.
 func (wb *Workbook) Uses1904Dates() bool {
@@ -276,15 +727,35 @@ func (wb *Workbook) Uses1904Dates() bool {
 	return *wb.x.WorkbookPr.Date1904Attr
 }
 
+// SetDate1904 sets whether the workbook uses dates relative to 1 Jan 1904
+// rather than 1 Jan 1900.  Dates set on cells afterwards are stored relative to
+// the new epoch, but existing date values aren't converted so they will be
+// displayed as dates four years and one day apart.
+func (wb *Workbook) SetDate1904(b bool) {
+	if !b {
+		if wb.x.WorkbookPr != nil {
+			wb.x.WorkbookPr.Date1904Attr = nil
+		}
+		return
+	}
+	if wb.x.WorkbookPr == nil {
+		wb.x.WorkbookPr = sml.NewCT_WorkbookPr()
+	}
+	wb.x.WorkbookPr.Date1904Attr = unioffice.Bool(true)
+}
+
 // Epoch returns the point at which the dates/times in the workbook are relative to.
 func (wb *Workbook) Epoch() time.Time {
 	if wb.Uses1904Dates() {
-		time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+		return time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
 	}
 	return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
 }
 
-// Save writes the workbook out to a writer in the zipped xlsx format.
+// Save writes the workbook out to a writer in the zipped xlsx format.  Each
+// part is compressed and written to w as it is produced, so the complete
+// file is never held in memory and w may be a network stream such as an
+// http.ResponseWriter.
 func (wb *Workbook) Save(w io.Writer) error {
 	if !license.GetLicenseKey().IsLicensed() && flag.Lookup("test.v") == nil {
 		fmt.Println("Unlicensed version of UniOffice")
@@ -302,10 +773,23 @@ func (wb *Workbook) Save(w io.Writer) error {
 		}
 	}
 
+	// a calculation chain that doesn't match the formulas causes Excel to
+	// repair the workbook, without it Excel just rebuilds the chain
+	if wb.calcChain != nil && (wb.formulasChanged || !wb.calcChainMatches()) {
+		wb.RemoveCalcChain()
+	}
+
 	z := zip.NewWriter(w)
 	defer z.Close()
 	dt := unioffice.DocTypeSpreadsheet
+	wb.EnsurePropertyParts(dt)
 
+	// content types and the package relationships are written first so that a
+	// reader processing the stream sequentially can locate every part that
+	// follows
+	if err := zippkg.MarshalXML(z, unioffice.ContentTypesFilename, wb.ContentTypes.X()); err != nil {
+		return err
+	}
 	if err := zippkg.MarshalXML(z, unioffice.BaseRelsFilename, wb.Rels.X()); err != nil {
 		return err
 	}
@@ -315,6 +799,11 @@ func (wb *Workbook) Save(w io.Writer) error {
 	if err := zippkg.MarshalXMLByType(z, dt, unioffice.CorePropertiesType, wb.CoreProperties.X()); err != nil {
 		return err
 	}
+	if wb.CustomProperties.X() != nil {
+		if err := zippkg.MarshalXMLByType(z, dt, unioffice.CustomPropertiesType, wb.CustomProperties.X()); err != nil {
+			return err
+		}
+	}
 
 	workbookFn := unioffice.AbsoluteFilename(dt, unioffice.OfficeDocumentType, 0)
 	if err := zippkg.MarshalXML(z, workbookFn, wb.x); err != nil {
@@ -334,16 +823,31 @@ func (wb *Workbook) Save(w io.Writer) error {
 		}
 	}
 	for i, sheet := range wb.xws {
-		// recalculate sheet dimensions
-		sheet.Dimension.RefAttr = Sheet{wb, nil, sheet}.Extents()
-
 		fn := unioffice.AbsoluteFilename(dt, unioffice.WorksheetType, i+1)
-		zippkg.MarshalXML(z, fn, sheet)
-		zippkg.MarshalXML(z, zippkg.RelationsPathFor(fn), wb.xwsRels[i].X())
+		if ss, ok := wb.streams[sheet]; ok {
+			sheet.Dimension.RefAttr = ss.extents()
+			if err := ss.marshal(z, fn); err != nil {
+				return err
+			}
+		} else {
+			// recalculate sheet dimensions
+			sheet.Dimension.RefAttr = Sheet{wb, nil, sheet}.Extents()
+			if err := zippkg.MarshalXML(z, fn, sheet); err != nil {
+				return err
+			}
+		}
+		if err := zippkg.MarshalXML(z, zippkg.RelationsPathFor(fn), wb.xwsRels[i].X()); err != nil {
+			return err
+		}
 	}
 	if err := zippkg.MarshalXMLByType(z, dt, unioffice.SharedStringsType, wb.SharedStrings.X()); err != nil {
 		return err
 	}
+	if wb.calcChain != nil {
+		if err := zippkg.MarshalXMLByType(z, dt, unioffice.CalcChainType, wb.calcChain); err != nil {
+			return err
+		}
+	}
 
 	if wb.Thumbnail != nil {
 		fn := unioffice.AbsoluteFilename(dt, unioffice.ThumbnailType, 0)
@@ -357,21 +861,55 @@ func (wb *Workbook) Save(w io.Writer) error {
 	}
 	for i, chart := range wb.charts {
 		fn := unioffice.AbsoluteFilename(dt, unioffice.ChartType, i+1)
-		zippkg.MarshalXML(z, fn, chart)
+		if err := zippkg.MarshalXML(z, fn, chart); err != nil {
+			return err
+		}
 	}
 	for i, tbl := range wb.tables {
 		fn := unioffice.AbsoluteFilename(dt, unioffice.TableType, i+1)
-		zippkg.MarshalXML(z, fn, tbl)
+		if err := zippkg.MarshalXML(z, fn, tbl); err != nil {
+			return err
+		}
+	}
+	for i, pt := range wb.pivotTables {
+		idx := wb.pivotOffset + i + 1
+		fn := unioffice.AbsoluteFilename(dt, unioffice.PivotTableType, idx)
+		if err := zippkg.MarshalXML(z, fn, pt); err != nil {
+			return err
+		}
+		if err := zippkg.MarshalXML(z, zippkg.RelationsPathFor(fn), wb.pivotTableRels[i].X()); err != nil {
+			return err
+		}
+		fn = unioffice.AbsoluteFilename(dt, unioffice.PivotCacheDefinitionType, idx)
+		if err := zippkg.MarshalXML(z, fn, wb.pivotCaches[i]); err != nil {
+			return err
+		}
+		if err := zippkg.MarshalXML(z, zippkg.RelationsPathFor(fn), wb.pivotCacheRels[i].X()); err != nil {
+			return err
+		}
+		fn = unioffice.AbsoluteFilename(dt, unioffice.PivotCacheRecordsType, idx)
+		if err := zippkg.MarshalXML(z, fn, wb.pivotRecords[i]); err != nil {
+			return err
+		}
 	}
 	for i, drawing := range wb.drawings {
 		fn := unioffice.AbsoluteFilename(dt, unioffice.DrawingType, i+1)
-		zippkg.MarshalXML(z, fn, drawing)
+		if err := zippkg.MarshalXML(z, fn, drawing); err != nil {
+			return err
+		}
 		if !wb.drawingRels[i].IsEmpty() {
-			zippkg.MarshalXML(z, zippkg.RelationsPathFor(fn), wb.drawingRels[i].X())
+			if err := zippkg.MarshalXML(z, zippkg.RelationsPathFor(fn), wb.drawingRels[i].X()); err != nil {
+				return err
+			}
 		}
 	}
 	for i, drawing := range wb.vmlDrawings {
-		zippkg.MarshalXML(z, unioffice.AbsoluteFilename(dt, unioffice.VMLDrawingType, i+1), drawing)
+		if drawing == nil {
+			continue
+		}
+		if err := zippkg.MarshalXML(z, unioffice.AbsoluteFilename(dt, unioffice.VMLDrawingType, i+1), drawing); err != nil {
+			return err
+		}
 		// never seen relationships for a VML drawing yet
 	}
 
@@ -380,15 +918,13 @@ func (wb *Workbook) Save(w io.Writer) error {
 			return err
 		}
 	}
-
-	if err := zippkg.MarshalXML(z, unioffice.ContentTypesFilename, wb.ContentTypes.X()); err != nil {
-		return err
-	}
 	for i, cmt := range wb.comments {
 		if cmt == nil {
 			continue
 		}
-		zippkg.MarshalXML(z, unioffice.AbsoluteFilename(dt, unioffice.CommentsType, i+1), cmt)
+		if err := zippkg.MarshalXML(z, unioffice.AbsoluteFilename(dt, unioffice.CommentsType, i+1), cmt); err != nil {
+			return err
+		}
 	}
 
 	if err := wb.WriteExtraFiles(z); err != nil {
@@ -430,6 +966,26 @@ func (wb *Workbook) Validate() error {
 			return err
 		}
 	}
+
+	// Excel requires at least one visible sheet
+	if len(wb.x.Sheets.Sheet) > 0 {
+		visible := false
+		for _, s := range wb.x.Sheets.Sheet {
+			if isSheetVisible(s) {
+				visible = true
+				break
+			}
+		}
+		if !visible {
+			return errors.New("workbook has no visible sheets")
+		}
+	}
+
+	for i, dn := range wb.DefinedNames() {
+		if err := ValidateDefinedName(dn.Name()); err != nil {
+			return fmt.Errorf("workbook/DefinedName[%d]: %s", i, err)
+		}
+	}
 	return nil
 }
 
@@ -443,6 +999,27 @@ func (wb *Workbook) Sheets() []Sheet {
 	return ret
 }
 
+// GetOrCreateCustomProperties returns the custom properties of the workbook,
+// creating them if they don't exist yet.
+func (wb *Workbook) GetOrCreateCustomProperties() common.CustomProperties {
+	if wb.CustomProperties.X() == nil {
+		wb.CustomProperties = common.NewCustomProperties()
+		wb.ContentTypes.EnsureOverride(unioffice.AbsoluteFilename(unioffice.DocTypeSpreadsheet, unioffice.CustomPropertiesType, 0), unioffice.CustomPropertiesContentType)
+		wb.Rels.AddRelationship(unioffice.RelativeFilename(unioffice.DocTypeSpreadsheet, "", unioffice.CustomPropertiesType, 0), unioffice.CustomPropertiesType)
+	}
+	return wb.CustomProperties
+}
+
+// Themes returns the themes of the workbook.  Changing a theme's colors
+// recolors every cell, font and chart that refers to them.
+func (wb *Workbook) Themes() []common.Theme {
+	ret := []common.Theme{}
+	for _, t := range wb.themes {
+		ret = append(ret, common.MakeTheme(t))
+	}
+	return ret
+}
+
 // SheetCount returns the number of sheets in the workbook.
 func (wb Workbook) SheetCount() int {
 	return len(wb.xws)
@@ -468,6 +1045,11 @@ func (wb *Workbook) onNewRelationship(decMap *zippkg.DecodeMap, target, typ stri
 		decMap.AddTarget(target, wb.AppProperties.X(), typ, 0)
 		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, 0)
 
+	case unioffice.CustomPropertiesType:
+		wb.CustomProperties = common.NewCustomProperties()
+		decMap.AddTarget(target, wb.CustomProperties.X(), typ, 0)
+		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, 0)
+
 	case unioffice.WorksheetType:
 		ws := sml.NewWorksheet()
 		idx := uint32(len(wb.xws))
@@ -475,12 +1057,13 @@ func (wb *Workbook) onNewRelationship(decMap *zippkg.DecodeMap, target, typ stri
 		decMap.AddTarget(target, ws, typ, idx)
 		// look for worksheet rels
 		wksRel := common.NewRelationships()
-		decMap.AddTarget(zippkg.RelationsPathFor(target), wksRel.X(), typ, 0)
+		decMap.AddTarget(zippkg.RelationsPathFor(target), wksRel.X(), typ, idx)
 		wb.xwsRels = append(wb.xwsRels, wksRel)
 
 		// add a comments placeholder that will be replaced if we see a comments
 		// relationship for the current sheet
 		wb.comments = append(wb.comments, nil)
+		wb.vmlDrawings = append(wb.vmlDrawings, nil)
 
 		// fix the relationship target so it points to where we'll save
 		// the worksheet
@@ -553,17 +1136,20 @@ func (wb *Workbook) onNewRelationship(decMap *zippkg.DecodeMap, target, typ stri
 		decMap.AddTarget(zippkg.RelationsPathFor(target), drel.X(), typ, idx)
 		wb.drawingRels = append(wb.drawingRels, drel)
 		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, len(wb.drawings))
+		wb.addSheetDrawing(rel, drawing)
 
 	case unioffice.VMLDrawingType:
+		// VML drawings are only read for comments, so there is at most one
+		// per sheet and it is stored alongside the sheet
 		vd := vmldrawing.NewContainer()
-		idx := uint32(len(wb.vmlDrawings))
-		decMap.AddTarget(target, vd, typ, idx)
-		wb.vmlDrawings = append(wb.vmlDrawings, vd)
+		wb.vmlDrawings[src.Index] = vd
+		decMap.AddTarget(target, vd, typ, src.Index)
+		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, int(src.Index)+1)
 
 	case unioffice.CommentsType:
 		wb.comments[src.Index] = sml.NewComments()
 		decMap.AddTarget(target, wb.comments[src.Index], typ, src.Index)
-		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, len(wb.comments))
+		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, int(src.Index)+1)
 
 	case unioffice.ChartType:
 		chart := crt.NewChartSpace()
@@ -572,6 +1158,11 @@ func (wb *Workbook) onNewRelationship(decMap *zippkg.DecodeMap, target, typ stri
 		wb.charts = append(wb.charts, chart)
 		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, len(wb.charts))
 
+	case unioffice.CalcChainType:
+		wb.calcChain = sml.NewCalcChain()
+		decMap.AddTarget(target, wb.calcChain, typ, 0)
+		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, 0)
+
 	case unioffice.TableType:
 		tbl := sml.NewTable()
 		idx := uint32(len(wb.tables))
@@ -579,7 +1170,8 @@ func (wb *Workbook) onNewRelationship(decMap *zippkg.DecodeMap, target, typ stri
 		wb.tables = append(wb.tables, tbl)
 		rel.TargetAttr = unioffice.RelativeFilename(dt, src.Typ, typ, len(wb.tables))
 	default:
-		unioffice.Log("unsupported relationship %s %s", target, typ)
+		// the target isn't consumed, so it's preserved as an extra file
+		unioffice.Log("unsupported relationship type: %s tgt: %s", typ, target)
 	}
 	return nil
 }
@@ -596,8 +1188,19 @@ func (wb *Workbook) AddDrawing() Drawing {
 }
 
 // AddDefinedName adds a name for a cell or range reference that can be used in
-// formulas and charts.
+// formulas and charts.  Names that aren't valid according to
+// ValidateDefinedName are logged but still added, and are reported by
+// Validate.
 func (wb *Workbook) AddDefinedName(name, ref string) DefinedName {
+	if err := ValidateDefinedName(name); err != nil {
+		unioffice.Log("%s", err)
+	}
+	return wb.addDefinedName(name, ref)
+}
+
+// addDefinedName adds a defined name without validating it, which is used for
+// built-in names and names copied from existing ones.
+func (wb *Workbook) addDefinedName(name, ref string) DefinedName {
 	if wb.x.DefinedNames == nil {
 		wb.x.DefinedNames = sml.NewCT_DefinedNames()
 	}
@@ -605,7 +1208,7 @@ func (wb *Workbook) AddDefinedName(name, ref string) DefinedName {
 	dn.Content = ref
 	dn.NameAttr = name
 	wb.x.DefinedNames.DefinedName = append(wb.x.DefinedNames.DefinedName, dn)
-	return DefinedName{dn}
+	return DefinedName{dn, wb}
 }
 
 // RemoveDefinedName removes an existing defined name.
@@ -613,6 +1216,9 @@ func (wb *Workbook) RemoveDefinedName(dn DefinedName) error {
 	if dn.X() == nil {
 		return errors.New("attempt to remove nil DefinedName")
 	}
+	if wb.x.DefinedNames == nil {
+		return errors.New("defined name not found")
+	}
 	for i, sdn := range wb.x.DefinedNames.DefinedName {
 		if sdn == dn.X() {
 			copy(wb.x.DefinedNames.DefinedName[i:], wb.x.DefinedNames.DefinedName[i+1:])
@@ -631,7 +1237,7 @@ func (wb *Workbook) DefinedNames() []DefinedName {
 	}
 	ret := []DefinedName{}
 	for _, dn := range wb.x.DefinedNames.DefinedName {
-		ret = append(ret, DefinedName{dn})
+		ret = append(ret, DefinedName{dn, wb})
 	}
 	return ret
 }
@@ -657,6 +1263,51 @@ func (wb *Workbook) RecalculateFormulas() {
 	}
 }
 
+// SetForceFullRecalc controls if Excel recalculates all formulas when the
+// workbook is opened. This avoids stale values being displayed for formulas
+// that were written without cached results.
+func (wb *Workbook) SetForceFullRecalc(b bool) {
+	if !b {
+		if wb.x.CalcPr != nil {
+			wb.x.CalcPr.FullCalcOnLoadAttr = nil
+		}
+		return
+	}
+	wb.calcPr().FullCalcOnLoadAttr = unioffice.Bool(true)
+}
+
+// SetCalcMode controls if formulas are recalculated automatically when their
+// inputs change or only when requested.
+func (wb *Workbook) SetCalcMode(m sml.ST_CalcMode) {
+	wb.calcPr().CalcModeAttr = m
+}
+
+// SetInlineStrings controls if Cell.SetString stores strings inline in the
+// cell rather than in the shared strings table.  Inline strings avoid growing
+// the table with text that is only used once.
+func (wb *Workbook) SetInlineStrings(b bool) {
+	wb.inlineStrings = b
+}
+
+// SetStringifyValues controls if Cell.SetValue stores values of unsupported
+// types as strings, formatted with fmt.Sprint, rather than returning an error.
+func (wb *Workbook) SetStringifyValues(b bool) {
+	wb.stringifyValues = b
+}
+
+func (wb *Workbook) calcPr() *sml.CT_CalcPr {
+	if wb.x.CalcPr == nil {
+		wb.x.CalcPr = sml.NewCT_CalcPr()
+	}
+	return wb.x.CalcPr
+}
+
+// SetDefaultFont sets the font name and size used by the Normal style and by
+// cells without an explicit font, e.g. SetDefaultFont("Arial", 10).
+func (wb *Workbook) SetDefaultFont(name string, size float64) {
+	wb.StyleSheet.SetDefaultFont(name, size)
+}
+
 // AddImage adds an image to the workbook package, returning a reference that
 // can be used to add the image to a drawing.
 func (wb *Workbook) AddImage(i common.Image) (common.ImageRef, error) {
@@ -675,6 +1326,10 @@ func (wb *Workbook) AddImage(i common.Image) (common.ImageRef, error) {
 	wb.Images = append(wb.Images, r)
 	fn := fmt.Sprintf("media/image%d.%s", len(wb.Images), i.Format)
 	wb.wbRels.AddRelationship(fn, unioffice.ImageType)
+	wb.ContentTypes.EnsureDefault("png", "image/png")
+	wb.ContentTypes.EnsureDefault("jpeg", "image/jpeg")
+	wb.ContentTypes.EnsureDefault("jpg", "image/jpeg")
+	wb.ContentTypes.EnsureDefault(i.Format, "image/"+i.Format)
 	return r, nil
 }
 
@@ -691,14 +1346,72 @@ func (wb *Workbook) SetActiveSheet(s Sheet) {
 // SetActiveSheetIndex sets the index of the active sheet (0-n) which will be
 // the tab displayed when the spreadsheet is initially opened.
 func (wb *Workbook) SetActiveSheetIndex(idx uint32) {
+	bv := wb.bookView()
+	bv.ActiveTabAttr = unioffice.Uint32(idx)
+	if bv.FirstSheetAttr != nil && *bv.FirstSheetAttr > idx {
+		bv.FirstSheetAttr = nil
+	}
+
+	// only the active sheet should be selected, otherwise Excel opens the
+	// workbook with the sheets grouped
+	for i, sheet := range wb.Sheets() {
+		if uint32(i) == idx {
+			sheet.InitialView().X().TabSelectedAttr = unioffice.Bool(true)
+			continue
+		}
+		if sheet.x.SheetViews != nil {
+			for _, sv := range sheet.x.SheetViews.SheetView {
+				sv.TabSelectedAttr = nil
+			}
+		}
+	}
+}
+
+// SetWindowSize sets the size of the workbook window in twips (1/20th of a
+// point).
+func (wb *Workbook) SetWindowSize(w, h uint32) {
+	bv := wb.bookView()
+	bv.WindowWidthAttr = unioffice.Uint32(w)
+	bv.WindowHeightAttr = unioffice.Uint32(h)
+}
+
+// SetFirstSheet sets the index of the first sheet tab displayed in the tab
+// bar, scrolling any earlier tabs out of view.
+func (wb *Workbook) SetFirstSheet(idx uint32) {
+	wb.bookView().FirstSheetAttr = unioffice.Uint32(idx)
+}
+
+// SetTabRatio sets the percentage (0-100) of the width of the window's
+// horizontal scroll area used by the sheet tabs.
+func (wb *Workbook) SetTabRatio(percent float64) {
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	// stored in thousandths of the width
+	wb.bookView().TabRatioAttr = unioffice.Uint32(uint32(percent*10 + 0.5))
+}
+
+// SetShowHorizontalScroll controls if the horizontal scroll bar is displayed.
+func (wb *Workbook) SetShowHorizontalScroll(b bool) {
+	wb.bookView().ShowHorizontalScrollAttr = unioffice.Bool(b)
+}
+
+// SetShowVerticalScroll controls if the vertical scroll bar is displayed.
+func (wb *Workbook) SetShowVerticalScroll(b bool) {
+	wb.bookView().ShowVerticalScrollAttr = unioffice.Bool(b)
+}
+
+// bookView returns the first workbook view, creating it if necessary.
+func (wb *Workbook) bookView() *sml.CT_BookView {
 	if wb.x.BookViews == nil {
 		wb.x.BookViews = sml.NewCT_BookViews()
 	}
 	if len(wb.x.BookViews.WorkbookView) == 0 {
 		wb.x.BookViews.WorkbookView = append(wb.x.BookViews.WorkbookView, sml.NewCT_BookView())
 	}
-
-	wb.x.BookViews.WorkbookView[0].ActiveTabAttr = unioffice.Uint32(idx)
+	return wb.x.BookViews.WorkbookView[0]
 }
 
 // Tables returns a slice of all defined tables in the workbook.
@@ -737,6 +1450,29 @@ func (wb *Workbook) GetSheet(name string) (Sheet, error) {
 	return Sheet{}, ErrorNotFound
 }
 
+// SheetByName returns the sheet with the given name and true, or false if no
+// sheet has exactly that name.
+func (wb *Workbook) SheetByName(name string) (Sheet, bool) {
+	for _, s := range wb.Sheets() {
+		if s.Name() == name {
+			return s, true
+		}
+	}
+	return Sheet{}, false
+}
+
+// SheetByNameCI returns the sheet with the given name ignoring case, matching
+// how Excel compares sheet names, and true, or false if no sheet was found.
+// Whitespace is significant.
+func (wb *Workbook) SheetByNameCI(name string) (Sheet, bool) {
+	for _, s := range wb.Sheets() {
+		if strings.EqualFold(s.Name(), name) {
+			return s, true
+		}
+	}
+	return Sheet{}, false
+}
+
 func workbookFinalizer(wb *Workbook) {
 	wb.Close()
 }
@@ -744,6 +1480,10 @@ func workbookFinalizer(wb *Workbook) {
 // Close closes the workbook, removing any temporary files that might have been
 // created when opening a document.
 func (wb *Workbook) Close() error {
+	for _, ss := range wb.streams {
+		ss.close()
+	}
+	wb.streams = nil
 	if wb.TmpPath != "" && strings.HasPrefix(wb.TmpPath, os.TempDir()) {
 		return os.RemoveAll(wb.TmpPath)
 	}
@@ -753,9 +1493,10 @@ func (wb *Workbook) Close() error {
 // RemoveCalcChain removes the cached caculation chain. This is sometimes needed
 // as we don't update it when rows are added/removed.
 func (wb *Workbook) RemoveCalcChain() {
+	wb.calcChain = nil
 	var calcFile string
 	for _, r := range wb.wbRels.Relationships() {
-		if r.Type() == "http://schemas.openxmlformats.org/officeDocument/2006/relationships/calcChain" {
+		if r.Type() == unioffice.CalcChainType {
 			calcFile = "xl/" + r.Target()
 			wb.wbRels.Remove(r)
 			break
@@ -7,16 +7,27 @@
 package spreadsheet_test
 
 import (
+	"archive/zip"
 	"bytes"
 	"encoding/xml"
 	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/unidoc/unioffice"
+	"github.com/unidoc/unioffice/common/encryption"
 	"github.com/unidoc/unioffice/schema/soo/sml"
 
 	"github.com/unidoc/unioffice/spreadsheet"
+	"github.com/unidoc/unioffice/spreadsheet/reference"
 	"github.com/unidoc/unioffice/testhelper"
 	"github.com/unidoc/unioffice/zippkg"
 )
@@ -218,6 +229,98 @@ func TestAddDefinedName(t *testing.T) {
 	if dn.Content() != ref {
 		t.Errorf("expected content = %s, got %s", ref, dn.Content())
 	}
+
+	if err := wb.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+
+	// invalid names are added, but are reported by Validate
+	wb.AddDefinedName("Tax Rate", ref)
+	if err := wb.Validate(); err == nil {
+		t.Errorf("expected an error validating a name with a space")
+	}
+	wb.DefinedNames()[1].Remove()
+	wb.AddSheet().AddDefinedName("A1", ref)
+	if err := wb.Validate(); err == nil {
+		t.Errorf("expected an error validating a name that conflicts with a cell reference")
+	}
+}
+
+func TestDefinedNameScopes(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	wb.AddSheet()
+	sheet := wb.AddSheet()
+	sheet.SetName("Rates")
+
+	global := wb.AddDefinedName("TaxRate", sheet.RangeReference("B2"))
+	local := sheet.AddDefinedName("TaxRate", sheet.RangeReference("C2"))
+	if _, ok := global.LocalSheetID(); ok {
+		t.Errorf("expected workbook scoped name to have no local sheet ID")
+	}
+	if id, ok := local.LocalSheetID(); !ok || id != 1 {
+		t.Errorf("expected local sheet ID 1, got %d", id)
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	dns := wb2.DefinedNames()
+	if len(dns) != 2 {
+		t.Fatalf("expected 2 defined names, got %d", len(dns))
+	}
+	if id, ok := dns[1].LocalSheetID(); !ok || id != 1 {
+		t.Errorf("expected local sheet ID to round trip")
+	}
+	if err := dns[0].Remove(); err != nil {
+		t.Errorf("error removing defined name: %s", err)
+	}
+	if dns = wb2.DefinedNames(); len(dns) != 1 || dns[0].Content() != "'Rates'!$C$2" {
+		t.Errorf("expected only the sheet scoped name to remain")
+	}
+	if err := (spreadsheet.DefinedName{}).Remove(); err == nil {
+		t.Errorf("expected an error removing a detached defined name")
+	}
+}
+
+func TestValidateDefinedName(t *testing.T) {
+	td := []struct {
+		Name  string
+		Valid bool
+	}{
+		{"TaxRate", true},
+		{"_tax", true},
+		{"\\tax", true},
+		{"Tax.Rate2", true},
+		{"ABCD1", true},
+		{"Rate", true},
+		{"", false},
+		{"Tax Rate", false},
+		{"1Tax", false},
+		{".Tax", false},
+		{"A1", false},
+		{"xfd1048576", false},
+		{"R", false},
+		{"c", false},
+		{"R1C1", false},
+		{"RC", false},
+		{"R2", false},
+		{"Tax-Rate", false},
+	}
+	for _, tc := range td {
+		err := spreadsheet.ValidateDefinedName(tc.Name)
+		if tc.Valid && err != nil {
+			t.Errorf("expected %q to be valid, got %s", tc.Name, err)
+		}
+		if !tc.Valid && err == nil {
+			t.Errorf("expected %q to be invalid", tc.Name)
+		}
+	}
 }
 
 func ExampleWorkbook_AddDefinedName() {
@@ -321,6 +424,120 @@ func TestRemoveSheet(t *testing.T) {
 	}
 }
 
+func TestRemoveMiddleSheet(t *testing.T) {
+	wb := spreadsheet.New()
+	for i := 0; i < 3; i++ {
+		sheet := wb.AddSheet()
+		sheet.SetName(fmt.Sprintf("Sheet%d", i+1))
+		sheet.Cell("A1").SetString(sheet.Name())
+		sheet.AddDefinedName("Local", "A1")
+	}
+	wb.Sheets()[2].Comments().AddComment("A1", "author").AddRun().SetText("comment")
+
+	if err := wb.RemoveSheet(1); err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if err := wb.Validate(); err != nil {
+		t.Fatalf("produced invalid workbook: %s", err)
+	}
+	for i, dn := range wb.DefinedNames() {
+		if id, _ := dn.LocalSheetID(); id != uint32(i) {
+			t.Errorf("expected defined name %d scoped to sheet %d, got %d", i, i, id)
+		}
+	}
+	if len(wb.DefinedNames()) != 2 {
+		t.Errorf("expected the removed sheet's defined name to be removed, got %d names", len(wb.DefinedNames()))
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	files := zipContents(t, buf.Bytes())
+	if _, ok := files["xl/worksheets/sheet3.xml"]; ok {
+		t.Errorf("expected no third worksheet to be written")
+	}
+	ct := files["[Content_Types].xml"]
+	if strings.Contains(ct, "sheet3.xml") || strings.Contains(ct, "comments3.xml") {
+		t.Errorf("expected no content types for the removed parts, got %s", ct)
+	}
+	if !strings.Contains(ct, "comments2.xml") {
+		t.Errorf("expected a content type for the moved comments, got %s", ct)
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	for i, exp := range []string{"Sheet1", "Sheet3"} {
+		sheet := wb2.Sheets()[i]
+		if sheet.Name() != exp {
+			t.Errorf("expected sheet %d = %s, got %s", i, exp, sheet.Name())
+		}
+		if got := sheet.Cell("A1").GetString(); got != exp {
+			t.Errorf("expected sheet %s to contain its own data, got %s", exp, got)
+		}
+	}
+	if len(wb2.Sheets()[1].Comments().Comments()) != 1 {
+		t.Errorf("expected the comment to move with its sheet")
+	}
+}
+
+func TestRemoveLastVisibleSheet(t *testing.T) {
+	wb := spreadsheet.New()
+	wb.AddSheet()
+	if err := wb.RemoveSheet(0); err == nil {
+		t.Errorf("expected an error removing the only sheet")
+	}
+	wb.AddSheet()
+	wb.X().Sheets.Sheet[0].StateAttr = sml.ST_SheetStateHidden
+	if err := wb.RemoveSheet(1); err == nil {
+		t.Errorf("expected an error removing the last visible sheet")
+	}
+	if err := wb.RemoveSheet(0); err != nil {
+		t.Errorf("expected no error removing a hidden sheet, got %s", err)
+	}
+}
+
+func TestHiddenSheets(t *testing.T) {
+	wb := spreadsheet.New()
+	visible := wb.AddSheet()
+	hidden := wb.AddSheet()
+	veryHidden := wb.AddSheet()
+	hidden.SetVisible(false)
+	veryHidden.SetVeryHidden(true)
+
+	if !visible.IsVisible() || hidden.IsVisible() || veryHidden.IsVisible() {
+		t.Errorf("unexpected sheet visibility")
+	}
+	if err := wb.Validate(); err != nil {
+		t.Errorf("expected no validation error, got %s", err)
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	wbXML := zipContents(t, buf.Bytes())["xl/workbook.xml"]
+	for _, exp := range []string{`state="hidden"`, `state="veryHidden"`} {
+		if !strings.Contains(wbXML, exp) {
+			t.Errorf("expected %s in workbook, got %s", exp, wbXML)
+		}
+	}
+
+	visible.SetVisible(false)
+	if err := wb.Validate(); err == nil {
+		t.Errorf("expected validation error with all sheets hidden")
+	}
+	veryHidden.SetVeryHidden(false)
+	if err := wb.Validate(); err != nil {
+		t.Errorf("expected no validation error, got %s", err)
+	}
+	if wb.X().Sheets.Sheet[2].StateAttr != sml.ST_SheetStateUnset {
+		t.Errorf("expected state to be cleared")
+	}
+}
+
 func TestRemoveSheetByName(t *testing.T) {
 	wb, err := spreadsheet.Open("./testdata/sheets.xlsx")
 	defer wb.Close()
@@ -408,3 +625,1325 @@ func TestCopySheetByName(t *testing.T) {
 		t.Fatalf("expected sheets count %d, got %d", wasCount+1, wb.SheetCount())
 	}
 }
+
+func TestCopySheetDeepCopy(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	sheet := wb.AddSheet()
+	sheet.SetName("Data")
+	for r := 1; r <= 5; r++ {
+		sheet.Cell(fmt.Sprintf("A%d", r)).SetNumber(float64(r))
+	}
+	sheet.MergeCells("B1", "C2")
+	sheet.AddDefinedName("_xlnm.Print_Area", sheet.RangeReference("A1:A5"))
+	sheet.AddComment("A1", "foo", "a comment")
+
+	dwng := wb.AddDrawing()
+	chrt, _ := dwng.AddChart(spreadsheet.AnchorTypeTwoCell)
+	lc := chrt.AddLineChart()
+	lc.AddSeries().Values().SetReference(`'Data'!A1:A5`)
+	chrt.AddTitle().SetText("Original")
+	sheet.SetDrawing(dwng)
+
+	cp, err := wb.CopySheet(0, "Copy")
+	if err != nil {
+		t.Fatalf("error copying sheet: %s", err)
+	}
+	if _, err := wb.CopySheet(0, "Copy"); err == nil {
+		t.Errorf("expected an error copying to an existing sheet name")
+	}
+
+	// the copy must not share any state with the original
+	cp.Cell("A1").SetString("changed")
+	if sheet.Cell("A1").GetString() != "1" {
+		t.Errorf("expected changing the copy to leave the original unchanged")
+	}
+	if len(cp.MergedCells()) != 1 || cp.MergedCells()[0].Reference() != "B1:C2" {
+		t.Errorf("expected merged cells to be copied")
+	}
+	chrt.AddTitle().SetText("Modified")
+
+	dns := wb.DefinedNames()
+	if len(dns) != 2 {
+		t.Fatalf("expected sheet scoped defined name to be duplicated, got %d names", len(dns))
+	}
+	if id, ok := dns[1].LocalSheetID(); !ok || id != 1 {
+		t.Errorf("expected duplicated name to be scoped to the copy")
+	}
+	if dns[1].Content() != "'Copy'!$A$1:$A$5" {
+		t.Errorf("expected duplicated name to refer to the copy, got %s", dns[1].Content())
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	for _, fn := range []string{"xl/charts/chart1.xml", "xl/charts/chart2.xml",
+		"xl/drawings/drawing1.xml", "xl/drawings/drawing2.xml",
+		"xl/comments1.xml", "xl/comments2.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := files[fn]; !ok {
+			t.Errorf("expected %s to be written", fn)
+		}
+	}
+	if !strings.Contains(files["xl/charts/chart1.xml"], "Modified") {
+		t.Errorf("expected the original chart to be modified")
+	}
+	if strings.Contains(files["xl/charts/chart2.xml"], "Modified") ||
+		!strings.Contains(files["xl/charts/chart2.xml"], "Original") {
+		t.Errorf("expected the copied chart to be independent of the original")
+	}
+	if !strings.Contains(files["xl/worksheets/_rels/sheet2.xml.rels"], "../drawings/drawing2.xml") {
+		t.Errorf("expected the copy to refer to its own drawing")
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	if got := wb2.Sheets()[1].Comments().Comments(); len(got) != 1 {
+		t.Errorf("expected comment to be copied")
+	}
+}
+
+func TestExtractSheet(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	for i, name := range []string{"First", "Second", "Third"} {
+		sheet := wb.AddSheet()
+		sheet.SetName(name)
+		fnt := wb.StyleSheet.AddFont()
+		fnt.SetName(fmt.Sprintf("Font %d", i))
+		cs := wb.StyleSheet.AddCellStyle()
+		cs.SetFont(fnt)
+		for r := 1; r <= 3; r++ {
+			c := sheet.Cell(fmt.Sprintf("A%d", r))
+			c.SetString(fmt.Sprintf("%s %d", name, r))
+			c.SetStyle(cs)
+			sheet.Cell(fmt.Sprintf("B%d", r)).SetNumber(float64(10*i + r))
+		}
+	}
+	second := wb.Sheets()[1]
+	second.AddComment("A1", "foo", "a comment")
+	dwng := wb.AddDrawing()
+	chrt, _ := dwng.AddChart(spreadsheet.AnchorTypeTwoCell)
+	chrt.AddLineChart().AddSeries().Values().SetReference(`'Second'!B1:B3`)
+	second.SetDrawing(dwng)
+	wb.AddDefinedName("SecondValues", `'Second'!$B$1:$B$3`)
+	wb.AddDefinedName("FirstValues", `'First'!$B$1:$B$3`)
+	second.AddDefinedName("_xlnm.Print_Area", second.RangeReference("A1:B3"))
+
+	ext, err := wb.ExtractSheet(second)
+	if err != nil {
+		t.Fatalf("error extracting sheet: %s", err)
+	}
+	defer ext.Close()
+	if _, err := wb.ExtractSheet(spreadsheet.Sheet{}); err == nil {
+		t.Errorf("expected an error extracting a sheet from another workbook")
+	}
+
+	buf := bytes.Buffer{}
+	if err := ext.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+
+	if wb2.SheetCount() != 1 || wb2.Sheets()[0].Name() != "Second" {
+		t.Fatalf("expected a single sheet named Second")
+	}
+	sheet := wb2.Sheets()[0]
+	if got := sheet.Cell("A2").GetString(); got != "Second 2" {
+		t.Errorf("expected A2 = Second 2, got %s", got)
+	}
+	if got, _ := sheet.Cell("B3").GetValueAsNumber(); got != 13 {
+		t.Errorf("expected B3 = 13, got %f", got)
+	}
+	if got := len(wb2.SharedStrings.X().Si); got != 3 {
+		t.Errorf("expected only the 3 strings of the sheet to be kept, got %d", got)
+	}
+	if got := len(wb2.StyleSheet.X().CellXfs.Xf); got != 2 {
+		t.Errorf("expected the default and the used cell format, got %d", got)
+	}
+	fonts := wb2.StyleSheet.Fonts()
+	if len(fonts) != 2 || fonts[1].X().Name[0].ValAttr != "Font 1" {
+		t.Errorf("expected the default and the used font to be kept, got %d", len(fonts))
+	}
+	if got := wb2.StyleSheet.GetCellStyle(*sheet.Cell("A1").X().SAttr).X().FontIdAttr; got == nil || *got != 1 {
+		t.Errorf("expected the cell style to refer to the remapped font")
+	}
+	if got := sheet.Comments().Comments(); len(got) != 1 {
+		t.Errorf("expected the comment to be copied")
+	}
+
+	names := map[string]bool{}
+	for _, dn := range wb2.DefinedNames() {
+		names[dn.Name()] = true
+		if id, ok := dn.LocalSheetID(); ok && id != 0 {
+			t.Errorf("expected %s to be scoped to the only sheet", dn.Name())
+		}
+	}
+	if !names["SecondValues"] || !names["_xlnm.Print_Area"] || names["FirstValues"] {
+		t.Errorf("expected only the names used by the sheet, got %v", names)
+	}
+
+	files := zipContents(t, buf.Bytes())
+	for _, fn := range []string{"xl/drawings/drawing1.xml", "xl/charts/chart1.xml", "xl/comments1.xml"} {
+		if _, ok := files[fn]; !ok {
+			t.Errorf("expected %s to be written", fn)
+		}
+	}
+	if _, ok := files["xl/worksheets/sheet2.xml"]; ok {
+		t.Errorf("expected only a single worksheet to be written")
+	}
+}
+
+func TestOptimize(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	sheet := wb.AddSheet()
+	var kept spreadsheet.CellStyle
+	for i := 1; i <= 200; i++ {
+		fnt := wb.StyleSheet.AddFont()
+		fnt.SetSize(float64(i))
+		cs := wb.StyleSheet.AddCellStyle()
+		cs.SetFont(fnt)
+		cs.SetNumberFormat(fmt.Sprintf("0.%03d", i))
+		c := sheet.Cell(fmt.Sprintf("A%d", i))
+		c.SetString(fmt.Sprintf("temporary string %d", i))
+		c.SetStyle(cs)
+		if i == 100 {
+			kept = cs
+		}
+	}
+	// replace all but two of the strings and styles
+	for i := 1; i <= 200; i++ {
+		c := sheet.Cell(fmt.Sprintf("A%d", i))
+		switch i {
+		case 100:
+		case 150:
+			c.SetString("temporary string 100")
+			c.SetStyle(kept)
+		default:
+			c.SetNumber(float64(i))
+			c.SetStyleIndex(0)
+		}
+	}
+
+	before := bytes.Buffer{}
+	if err := wb.Save(&before); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	if err := wb.Optimize(); err != nil {
+		t.Fatalf("error optimizing workbook: %s", err)
+	}
+	after := bytes.Buffer{}
+	if err := wb.Save(&after); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	if after.Len() >= before.Len() {
+		t.Errorf("expected the optimized workbook to be smaller, got %d >= %d", after.Len(), before.Len())
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(after.Bytes()), int64(after.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	sheet2 := wb2.Sheets()[0]
+	for _, ref := range []string{"A100", "A150"} {
+		c := sheet2.Cell(ref)
+		if got := c.GetString(); got != "temporary string 100" {
+			t.Errorf("expected %s = temporary string 100, got %s", ref, got)
+		}
+		cs := wb2.StyleSheet.GetCellStyle(*c.X().SAttr)
+		if got := wb2.StyleSheet.Fonts()[*cs.X().FontIdAttr].X().Sz[0].ValAttr; got != 100 {
+			t.Errorf("expected %s to keep its font size of 100, got %f", ref, got)
+		}
+		if got := c.GetFormattedValue(); got != "temporary string 100" {
+			t.Errorf("expected %s to be formatted, got %s", ref, got)
+		}
+	}
+	if got, _ := sheet2.Cell("A200").GetValueAsNumber(); got != 200 {
+		t.Errorf("expected A200 = 200, got %f", got)
+	}
+	ss := wb2.StyleSheet.X()
+	if got := len(wb2.SharedStrings.X().Si); got != 1 {
+		t.Errorf("expected a single shared string, got %d", got)
+	}
+	if len(ss.CellXfs.Xf) != 2 || len(ss.Fonts.Font) != 2 || len(ss.NumFmts.NumFmt) != 1 {
+		t.Errorf("expected unused styles to be removed, got %d formats, %d fonts and %d number formats",
+			len(ss.CellXfs.Xf), len(ss.Fonts.Font), len(ss.NumFmts.NumFmt))
+	}
+	if len(ss.Fills.Fill) != 2 || len(ss.Borders.Border) != 1 {
+		t.Errorf("expected the default fills and border to be kept")
+	}
+}
+
+func TestSaveEncrypted(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	wb.AddSheet().Cell("A1").SetString("confidential")
+
+	f, err := ioutil.TempFile("", "encrypted")
+	if err != nil {
+		t.Fatalf("error creating temp file: %s", err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	if err := wb.SaveEncrypted(f.Name(), "secret"); err != nil {
+		t.Fatalf("error saving encrypted workbook: %s", err)
+	}
+	enc, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("error reading encrypted workbook: %s", err)
+	}
+	if !encryption.IsEncrypted(enc) {
+		t.Fatalf("expected an encrypted file")
+	}
+
+	pkg, err := encryption.Decrypt(enc, "secret")
+	if err != nil {
+		t.Fatalf("error decrypting workbook: %s", err)
+	}
+	plain := bytes.Buffer{}
+	if err := wb.Save(&plain); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	if !bytes.Equal(pkg, plain.Bytes()) {
+		t.Errorf("expected the decrypted package to match the unencrypted workbook")
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(pkg), int64(len(pkg)))
+	if err != nil {
+		t.Fatalf("error reading decrypted workbook: %s", err)
+	}
+	defer wb2.Close()
+	if got := wb2.Sheets()[0].Cell("A1").GetString(); got != "confidential" {
+		t.Errorf("expected A1 = confidential, got %s", got)
+	}
+}
+
+func TestReadEncrypted(t *testing.T) {
+	for _, fn := range []string{"testdata/encrypted-agile.xlsx", "testdata/encrypted-standard.xlsx"} {
+		enc, err := ioutil.ReadFile(fn)
+		if err != nil {
+			t.Fatalf("error reading %s: %s", fn, err)
+		}
+		if _, err := spreadsheet.Read(bytes.NewReader(enc), int64(len(enc))); err != spreadsheet.ErrEncrypted {
+			t.Errorf("%s: expected ErrEncrypted reading without a password, got %v", fn, err)
+		}
+		if _, err := spreadsheet.ReadEncrypted(bytes.NewReader(enc), int64(len(enc)), "password1234"); err != spreadsheet.ErrInvalidPassword {
+			t.Errorf("%s: expected ErrInvalidPassword, got %v", fn, err)
+		}
+
+		wb, err := spreadsheet.ReadEncrypted(bytes.NewReader(enc), int64(len(enc)), "Password1234")
+		if err != nil {
+			t.Fatalf("%s: error reading encrypted workbook: %s", fn, err)
+		}
+		defer wb.Close()
+		sheet, err := wb.GetSheet("Secret")
+		if err != nil {
+			t.Fatalf("%s: %s", fn, err)
+		}
+		if got := sheet.Cell("A1").GetString(); got != "Hello encrypted" {
+			t.Errorf("%s: expected A1 = Hello encrypted, got %s", fn, got)
+		}
+		if got, _ := sheet.Cell("B1").GetValueAsNumber(); got != 42 {
+			t.Errorf("%s: expected B1 = 42, got %f", fn, got)
+		}
+	}
+
+	// unencrypted workbooks are read as is
+	plain := bytes.Buffer{}
+	wb := spreadsheet.New()
+	defer wb.Close()
+	wb.AddSheet().Cell("A1").SetString("plain")
+	if err := wb.Save(&plain); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.ReadEncrypted(bytes.NewReader(plain.Bytes()), int64(plain.Len()), "")
+	if err != nil {
+		t.Fatalf("error reading unencrypted workbook: %s", err)
+	}
+	defer wb2.Close()
+	if got := wb2.Sheets()[0].Cell("A1").GetString(); got != "plain" {
+		t.Errorf("expected A1 = plain, got %s", got)
+	}
+}
+
+func TestMoveSheet(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	for _, n := range []string{"First", "Second", "Third"} {
+		wb.AddSheet().SetName(n)
+	}
+	if err := wb.Sheets()[0].AddComment("A1", "author", "first comment"); err != nil {
+		t.Fatalf("error adding comment: %s", err)
+	}
+	wb.Sheets()[0].AddDefinedName("local", "First!$A$1")
+
+	if err := wb.MoveSheet(0, 3); err == nil {
+		t.Errorf("expected an error moving to an out of range index")
+	}
+	if err := wb.MoveSheet(-1, 0); err == nil {
+		t.Errorf("expected an error moving from an out of range index")
+	}
+	if err := wb.SetSheetIndex("Missing", 0); err == nil {
+		t.Errorf("expected an error moving a missing sheet")
+	}
+	if err := wb.MoveSheet(0, 2); err != nil {
+		t.Fatalf("error moving sheet: %s", err)
+	}
+	if err := wb.SetSheetIndex("Third", 0); err != nil {
+		t.Fatalf("error moving sheet: %s", err)
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	exp := []string{"Third", "Second", "First"}
+	for i, s := range wb2.Sheets() {
+		if s.Name() != exp[i] {
+			t.Errorf("expected sheet %d to be %s, got %s", i, exp[i], s.Name())
+		}
+	}
+	if got := wb2.Sheets()[2].Comments().Comments(); len(got) != 1 || !strings.Contains(got[0].Text(), "first comment") {
+		t.Errorf("expected the comment to move with its sheet")
+	}
+	if got := wb2.Sheets()[0].Comments().Comments(); len(got) != 0 {
+		t.Errorf("expected no comments on the first sheet, got %d", len(got))
+	}
+	dns := wb2.DefinedNames()
+	if len(dns) != 1 {
+		t.Fatalf("expected one defined name, got %d", len(dns))
+	}
+	if id, ok := dns[0].LocalSheetID(); !ok || id != 2 {
+		t.Errorf("expected the defined name to be scoped to sheet 2, got %d", id)
+	}
+}
+
+func TestMoveSheetRenumbersDrawingsAndViews(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	for _, n := range []string{"First", "Second", "Third"} {
+		sheet := wb.AddSheet()
+		sheet.SetName(n)
+		sheet.AddComment("A1", "author", n)
+	}
+	wb.SetActiveSheetIndex(2)
+	wb.X().BookViews.WorkbookView[0].FirstSheetAttr = unioffice.Uint32(1)
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+
+	// give the comment shapes the IDs that Excel uses, allocated from the
+	// drawing's block
+	modified := bytes.Buffer{}
+	zw := zip.NewWriter(&modified)
+	for name, content := range zipContents(t, buf.Bytes()) {
+		for i := 1; i <= 3; i++ {
+			if name == fmt.Sprintf("xl/drawings/vmlDrawing%d.vml", i) {
+				content = strings.Replace(content, `id="cs_0_0"`, fmt.Sprintf(`id="_x0000_s%d"`, i*1024+1), 1)
+			}
+		}
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	wb2, err := spreadsheet.Read(bytes.NewReader(modified.Bytes()), int64(modified.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+
+	// Third, First, Second
+	if err := wb2.MoveSheet(2, 0); err != nil {
+		t.Fatalf("error moving sheet: %s", err)
+	}
+	out := bytes.Buffer{}
+	if err := wb2.Save(&out); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	files := zipContents(t, out.Bytes())
+	for i, name := range []string{"Third", "First", "Second"} {
+		vml := files[fmt.Sprintf("xl/drawings/vmlDrawing%d.vml", i+1)]
+		if !strings.Contains(vml, fmt.Sprintf(`data="%d"`, i+1)) {
+			t.Errorf("expected the drawing of %s to use ID block %d, got %s", name, i+1, vml)
+		}
+		if !strings.Contains(vml, fmt.Sprintf(`id="_x0000_s%d"`, (i+1)*1024+1)) {
+			t.Errorf("expected the shape of %s to be renumbered into block %d, got %s", name, i+1, vml)
+		}
+	}
+	bv := wb2.X().BookViews.WorkbookView[0]
+	if bv.ActiveTabAttr == nil || *bv.ActiveTabAttr != 0 {
+		t.Errorf("expected the active tab to follow the sheet to 0")
+	}
+	if bv.FirstSheetAttr == nil || *bv.FirstSheetAttr != 0 {
+		t.Errorf("expected the first visible tab to keep the active tab in view")
+	}
+
+	// the first visible tab follows its sheet when the active tab remains
+	// in view
+	if err := wb2.MoveSheet(0, 2); err != nil {
+		t.Fatalf("error moving sheet: %s", err)
+	}
+	bv.FirstSheetAttr = unioffice.Uint32(1)
+	if err := wb2.MoveSheet(1, 0); err != nil {
+		t.Fatalf("error moving sheet: %s", err)
+	}
+	if *bv.ActiveTabAttr != 2 || *bv.FirstSheetAttr != 0 {
+		t.Errorf("expected active tab 2 and first sheet 0, got %d and %d", *bv.ActiveTabAttr, *bv.FirstSheetAttr)
+	}
+}
+
+func TestSheetByName(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	wb.AddSheet().SetName("Sales Data")
+	wb.AddSheet().SetName("Summary")
+	if wb.SheetCount() != 2 {
+		t.Errorf("expected 2 sheets, got %d", wb.SheetCount())
+	}
+
+	for _, tc := range []struct {
+		name   string
+		exact  bool
+		ignore bool
+	}{
+		{"Sales Data", true, true},
+		{"sales data", false, true},
+		{"SUMMARY", false, true},
+		{"Sales  Data", false, false},
+		{" Summary", false, false},
+		{"Summary ", false, false},
+		{"Missing", false, false},
+		{"", false, false},
+	} {
+		s, ok := wb.SheetByName(tc.name)
+		if ok != tc.exact {
+			t.Errorf("SheetByName(%q): expected found = %v", tc.name, tc.exact)
+		}
+		if ok && s.Name() != tc.name {
+			t.Errorf("SheetByName(%q): got sheet %s", tc.name, s.Name())
+		}
+		s, ok = wb.SheetByNameCI(tc.name)
+		if ok != tc.ignore {
+			t.Errorf("SheetByNameCI(%q): expected found = %v", tc.name, tc.ignore)
+		}
+		if ok && !strings.EqualFold(s.Name(), tc.name) {
+			t.Errorf("SheetByNameCI(%q): got sheet %s", tc.name, s.Name())
+		}
+		if !ok && s.X() != nil {
+			t.Errorf("expected a zero sheet when not found")
+		}
+	}
+}
+
+func TestAddTable(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	sheet := wb.AddSheet()
+	sheet.Cell("A1").SetString("Name")
+	sheet.Cell("B1").SetString("Name")
+	for r := 2; r <= 10; r++ {
+		sheet.Cell(fmt.Sprintf("A%d", r)).SetString(fmt.Sprintf("item %d", r))
+		sheet.Cell(fmt.Sprintf("B%d", r)).SetNumber(float64(r))
+		sheet.Cell(fmt.Sprintf("C%d", r)).SetNumber(float64(r * r))
+	}
+
+	tbl, err := sheet.AddTable("A1:C10", "Table1")
+	if err != nil {
+		t.Fatalf("error adding table: %s", err)
+	}
+	tbl.SetStyle("TableStyleLight9")
+	tbl.SetBandedRows(true)
+	exp := []string{"Name", "Name2", "Column3"}
+	if got := tbl.Columns(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected columns %v, got %v", exp, got)
+	}
+	if got := sheet.Cell("C1").GetString(); got != "Column3" {
+		t.Errorf("expected the empty header to be named, got %s", got)
+	}
+
+	if _, err := sheet.AddTable("E1:F5", "table1"); err == nil {
+		t.Errorf("expected an error adding a table with a duplicate name")
+	}
+	if _, err := sheet.AddTable("E1:F5", "A1"); err == nil {
+		t.Errorf("expected an error adding a table named like a cell reference")
+	}
+	if _, err := sheet.AddTable("E1:F1", "NoData"); err == nil {
+		t.Errorf("expected an error adding a table without data rows")
+	}
+	second, err := sheet.AddTable("E1:F5", "Table2")
+	if err != nil {
+		t.Fatalf("error adding second table: %s", err)
+	}
+	if second.X().IdAttr != 2 {
+		t.Errorf("expected the second table to have ID 2, got %d", second.X().IdAttr)
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	files := zipContents(t, buf.Bytes())
+	if _, ok := files["xl/tables/table1.xml"]; !ok {
+		t.Fatalf("expected a table part")
+	}
+	if !strings.Contains(files["[Content_Types].xml"], `ContentType="`+unioffice.TableContentType+`" PartName="/xl/tables/table1.xml"`) {
+		t.Errorf("expected a content type override for the table")
+	}
+	if !strings.Contains(files["xl/worksheets/_rels/sheet1.xml.rels"], `Target="../tables/table1.xml"`) {
+		t.Errorf("expected a worksheet relationship to the table")
+	}
+	if !strings.Contains(files["xl/worksheets/sheet1.xml"], `tableParts count="2"`) {
+		t.Errorf("expected the sheet to list its table parts")
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	tables := wb2.Tables()
+	if len(tables) != 2 {
+		t.Fatalf("expected 2 tables, got %d", len(tables))
+	}
+	x := tables[0].X()
+	if tables[0].Name() != "Table1" || tables[0].Reference() != "A1:C10" {
+		t.Errorf("expected Table1 over A1:C10, got %s over %s", tables[0].Name(), tables[0].Reference())
+	}
+	if x.AutoFilter == nil || *x.AutoFilter.RefAttr != "A1:C10" {
+		t.Errorf("expected the table to have a filter")
+	}
+	if x.TableStyleInfo == nil || *x.TableStyleInfo.NameAttr != "TableStyleLight9" || !*x.TableStyleInfo.ShowRowStripesAttr {
+		t.Errorf("expected a banded TableStyleLight9 style")
+	}
+}
+
+func TestWorkbookView(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	wb.AddSheet()
+	wb.AddSheet()
+	wb.SetWindowSize(28800, 17600)
+	wb.SetFirstSheet(1)
+	wb.SetTabRatio(75)
+	wb.SetShowHorizontalScroll(false)
+	wb.SetShowVerticalScroll(true)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+
+	bv := wb2.X().BookViews.WorkbookView[0]
+	if bv.WindowWidthAttr == nil || *bv.WindowWidthAttr != 28800 {
+		t.Errorf("expected window width 28800, got %v", bv.WindowWidthAttr)
+	}
+	if bv.WindowHeightAttr == nil || *bv.WindowHeightAttr != 17600 {
+		t.Errorf("expected window height 17600, got %v", bv.WindowHeightAttr)
+	}
+	if bv.FirstSheetAttr == nil || *bv.FirstSheetAttr != 1 {
+		t.Errorf("expected first sheet 1, got %v", bv.FirstSheetAttr)
+	}
+	if bv.TabRatioAttr == nil || *bv.TabRatioAttr != 750 {
+		t.Errorf("expected tab ratio 750, got %v", bv.TabRatioAttr)
+	}
+	if bv.ShowHorizontalScrollAttr == nil || *bv.ShowHorizontalScrollAttr {
+		t.Errorf("expected the horizontal scroll bar to be hidden")
+	}
+	if bv.ShowVerticalScrollAttr == nil || !*bv.ShowVerticalScrollAttr {
+		t.Errorf("expected the vertical scroll bar to be shown")
+	}
+}
+
+func TestSetActiveSheetAndCell(t *testing.T) {
+	wb := spreadsheet.New()
+	defer wb.Close()
+	first := wb.AddSheet()
+	second := wb.AddSheet()
+	third := wb.AddSheet()
+	wb.SetActiveSheet(first)
+	wb.SetActiveSheet(third)
+	first.SetActiveCell("C4")
+	third.SetFrozenRange(1, 0)
+	third.SetSelection("$B$2:D5 F7")
+	second.SetActiveCell("not a cell")
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	defer wb2.Close()
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+
+	bv := wb2.X().BookViews.WorkbookView[0]
+	if bv.ActiveTabAttr == nil || *bv.ActiveTabAttr != 2 {
+		t.Errorf("expected the third sheet to be the active tab")
+	}
+	sheets := wb2.Sheets()
+	for i, s := range sheets {
+		selected := false
+		if s.X().SheetViews != nil {
+			for _, sv := range s.X().SheetViews.SheetView {
+				selected = selected || (sv.TabSelectedAttr != nil && *sv.TabSelectedAttr)
+			}
+		}
+		if selected != (i == 2) {
+			t.Errorf("expected only the active sheet to be selected, sheet %d selected = %v", i, selected)
+		}
+	}
+
+	sel := sheets[0].X().SheetViews.SheetView[0].Selection
+	if len(sel) != 1 || *sel[0].ActiveCellAttr != "C4" || strings.Join(*sel[0].SqrefAttr, " ") != "C4" {
+		t.Errorf("expected C4 to be the active cell of the first sheet")
+	}
+	sel = sheets[2].X().SheetViews.SheetView[0].Selection
+	if len(sel) != 1 || sel[0].PaneAttr != sml.ST_PaneBottomLeft {
+		t.Fatalf("expected a selection in the frozen pane")
+	}
+	if *sel[0].ActiveCellAttr != "B2" || strings.Join(*sel[0].SqrefAttr, " ") != "$B$2:D5 F7" {
+		t.Errorf("expected selection B2 in $B$2:D5 F7, got %s in %v", *sel[0].ActiveCellAttr, *sel[0].SqrefAttr)
+	}
+	if sv := sheets[1].X().SheetViews; sv != nil && len(sv.SheetView[0].Selection) != 0 {
+		t.Errorf("expected an invalid active cell to be ignored")
+	}
+}
+
+func TestCalcProperties(t *testing.T) {
+	wb := spreadsheet.New()
+	wb.AddSheet().Cell("A1").SetFormulaRaw("1+2")
+	wb.SetForceFullRecalc(true)
+	wb.SetCalcMode(sml.ST_CalcModeManual)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	wbXML := ""
+	for _, f := range zr.File {
+		if f.Name == "xl/workbook.xml" {
+			rc, _ := f.Open()
+			b, _ := ioutil.ReadAll(rc)
+			rc.Close()
+			wbXML = string(b)
+		}
+	}
+	if !strings.Contains(wbXML, `<ma:calcPr calcMode="manual" fullCalcOnLoad="1"/>`) {
+		t.Errorf("expected calcPr to be written, got %s", wbXML)
+	}
+
+	wb.SetForceFullRecalc(false)
+	if wb.X().CalcPr.FullCalcOnLoadAttr != nil {
+		t.Errorf("expected full recalculation to be cleared")
+	}
+}
+
+func TestConcurrentSheetWrites(t *testing.T) {
+	wb := spreadsheet.New()
+	sheets := []spreadsheet.Sheet{}
+	for i := 0; i < 4; i++ {
+		sheets = append(sheets, wb.AddSheet())
+	}
+
+	// sheets are populated concurrently, sharing the workbook's string table
+	wg := sync.WaitGroup{}
+	for i, s := range sheets {
+		wg.Add(1)
+		go func(i int, s spreadsheet.Sheet) {
+			defer wg.Done()
+			for r := uint32(1); r <= 100; r++ {
+				row := s.Row(r)
+				row.Cell("A").SetString(fmt.Sprintf("shared %d", r))
+				row.Cell("B").SetString(fmt.Sprintf("sheet %d row %d", i, r))
+				row.Cell("C").SetNumber(float64(r))
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	for i, s := range sheets {
+		for r := uint32(1); r <= 100; r++ {
+			row := s.Row(r)
+			if got := row.Cell("A").GetString(); got != fmt.Sprintf("shared %d", r) {
+				t.Fatalf("expected shared %d, got %s", r, got)
+			}
+			if got := row.Cell("B").GetString(); got != fmt.Sprintf("sheet %d row %d", i, r) {
+				t.Fatalf("expected sheet %d row %d, got %s", i, r, got)
+			}
+		}
+	}
+	if got := len(wb.SharedStrings.X().Si); got != 100+4*100 {
+		t.Errorf("expected 500 unique shared strings, got %d", got)
+	}
+	if err := wb.Validate(); err != nil {
+		t.Errorf("created an invalid workbook: %s", err)
+	}
+}
+
+func TestReadWithOptions(t *testing.T) {
+	buf, err := ioutil.ReadFile("testdata/simple-1.xlsx")
+	if err != nil {
+		t.Fatalf("error reading fixture: %s", err)
+	}
+	wb, err := spreadsheet.ReadWithOptions(bytes.NewReader(buf), int64(len(buf)), zippkg.ReadOptions{MaxDecompressedSize: 10 << 20})
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if len(wb.Sheets()) == 0 {
+		t.Errorf("expected sheets in the workbook")
+	}
+
+	if _, err := spreadsheet.ReadWithOptions(bytes.NewReader(buf), int64(len(buf)), zippkg.ReadOptions{MaxDecompressedSize: 100}); err == nil {
+		t.Errorf("expected an error when exceeding the decompressed size limit")
+	}
+}
+
+func TestReadMalformed(t *testing.T) {
+	garbage := []byte("this is not a zip file")
+	if _, err := spreadsheet.Read(bytes.NewReader(garbage), int64(len(garbage))); err == nil {
+		t.Errorf("expected an error reading a non-zip file")
+	}
+
+	buf := bytes.Buffer{}
+	zw := zip.NewWriter(&buf)
+	fw, err := zw.Create("[Content_Types].xml")
+	if err != nil {
+		t.Fatalf("error creating zip: %s", err)
+	}
+	fw.Write([]byte("<Types><Default"))
+	zw.Close()
+	if _, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err == nil {
+		t.Errorf("expected an error reading malformed content types")
+	}
+}
+
+func TestSaveToWriter(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Cell("A1").SetString("streamed")
+
+	buf := bytes.Buffer{}
+	// hide everything but Write to ensure the zip is written sequentially
+	w := struct{ io.Writer }{&buf}
+	if err := wb.Save(w); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %s", err)
+	}
+	if len(zr.File) < 2 {
+		t.Fatalf("expected multiple files in the zip, got %d", len(zr.File))
+	}
+	if got := zr.File[0].Name; got != "[Content_Types].xml" {
+		t.Errorf("expected content types to be written first, got %s", got)
+	}
+	if got := zr.File[1].Name; got != "_rels/.rels" {
+		t.Errorf("expected package relationships to be written second, got %s", got)
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading saved workbook: %s", err)
+	}
+	if got := wb2.Sheets()[0].Cell("A1").GetString(); got != "streamed" {
+		t.Errorf("expected A1 = streamed, got %s", got)
+	}
+}
+
+func TestDocumentProperties(t *testing.T) {
+	wb := spreadsheet.New()
+	wb.AddSheet()
+	created := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+	modified := time.Date(2021, 8, 9, 10, 11, 12, 0, time.UTC)
+
+	cp := wb.CoreProperties
+	cp.SetTitle("Quarterly Report")
+	cp.SetSubject("Sales")
+	cp.SetAuthor("Jane Doe")
+	cp.SetKeywords("sales, q3")
+	cp.SetDescription("Sales figures for the third quarter")
+	cp.SetLastModifiedBy("John Doe")
+	cp.SetCreated(created)
+	cp.SetModified(modified)
+	wb.AppProperties.SetCompany("Example Corp")
+	wb.AppProperties.SetManager("Pat Smith")
+
+	// simulate a workbook read from a file without core properties
+	for _, r := range wb.Rels.Relationships() {
+		if r.Type() == unioffice.CorePropertiesType {
+			wb.Rels.Remove(r)
+		}
+	}
+	wb.ContentTypes.RemoveOverride("/docProps/core.xml")
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+
+	cp = wb2.CoreProperties
+	for _, tc := range []struct {
+		name, got, exp string
+	}{
+		{"title", cp.Title(), "Quarterly Report"},
+		{"subject", cp.Subject(), "Sales"},
+		{"author", cp.Author(), "Jane Doe"},
+		{"keywords", cp.Keywords(), "sales, q3"},
+		{"description", cp.Description(), "Sales figures for the third quarter"},
+		{"last modified by", cp.LastModifiedBy(), "John Doe"},
+		{"company", wb2.AppProperties.Company(), "Example Corp"},
+		{"manager", wb2.AppProperties.Manager(), "Pat Smith"},
+	} {
+		if tc.got != tc.exp {
+			t.Errorf("expected %s %q, got %q", tc.name, tc.exp, tc.got)
+		}
+	}
+	if !cp.Created().Equal(created) {
+		t.Errorf("expected created %s, got %s", created, cp.Created())
+	}
+	if !cp.Modified().Equal(modified) {
+		t.Errorf("expected modified %s, got %s", modified, cp.Modified())
+	}
+
+	found := false
+	for _, o := range wb2.ContentTypes.X().Override {
+		if o.PartNameAttr == "/docProps/core.xml" && o.ContentTypeAttr == unioffice.CorePropertiesContentType {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a content type override for the core properties")
+	}
+}
+
+func TestCustomProperties(t *testing.T) {
+	wb := spreadsheet.New()
+	wb.AddSheet()
+	date := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cp := wb.GetOrCreateCustomProperties()
+	cp.SetString("Department", "Finance")
+	cp.SetNumber("Version", 2.5)
+	cp.SetBool("Approved", false)
+	cp.SetDate("Reviewed", date)
+	// duplicate names replace the existing property
+	cp.SetBool("Approved", true)
+	cp.SetString("Department", "Sales")
+
+	if got := len(cp.PropertiesList()); got != 4 {
+		t.Fatalf("expected 4 properties, got %d", got)
+	}
+	pids := map[int32]bool{}
+	for _, p := range cp.PropertiesList() {
+		if p.PidAttr < 2 || pids[p.PidAttr] {
+			t.Errorf("expected unique property ids of at least 2, got %d", p.PidAttr)
+		}
+		pids[p.PidAttr] = true
+		if p.FmtidAttr != "{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" {
+			t.Errorf("unexpected fmtid %s", p.FmtidAttr)
+		}
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	cp = wb2.GetOrCreateCustomProperties()
+	for _, tc := range []struct {
+		name string
+		exp  interface{}
+	}{
+		{"Department", "Sales"},
+		{"Version", 2.5},
+		{"Approved", true},
+		{"Reviewed", date},
+	} {
+		got, ok := cp.PropertyByName(tc.name)
+		if !ok {
+			t.Errorf("expected property %s", tc.name)
+			continue
+		}
+		if d, isDate := got.(time.Time); isDate {
+			if !d.Equal(date) {
+				t.Errorf("expected %s = %v, got %v", tc.name, tc.exp, got)
+			}
+		} else if got != tc.exp {
+			t.Errorf("expected %s = %v, got %v", tc.name, tc.exp, got)
+		}
+	}
+	if _, ok := cp.PropertyByName("Missing"); ok {
+		t.Errorf("expected no value for a missing property")
+	}
+	if got := len(wb2.Rels.Relationships()); got != len(wb.Rels.Relationships()) {
+		t.Errorf("expected %d package relationships after reading, got %d", len(wb.Rels.Relationships()), got)
+	}
+}
+
+func TestPreserveUnknownParts(t *testing.T) {
+	wb := spreadsheet.New()
+	wb.AddSheet()
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+
+	// add a custom part referenced from the workbook and another referenced
+	// from the worksheet
+	const customRel = `<Relationship Id="rId99" Type="http://example.com/custom" Target="custom/data.xml"/>`
+	const sheetRel = `<Relationship Id="rId99" Type="http://example.com/sheet" Target="../custom/sheet.bin"/>`
+	const customCT = `<Override PartName="/xl/custom/data.xml" ContentType="application/x-custom+xml"/>`
+	modified := bytes.Buffer{}
+	zw := zip.NewWriter(&modified)
+	for name, content := range zipContents(t, buf.Bytes()) {
+		switch name {
+		case "xl/_rels/workbook.xml.rels":
+			content = strings.Replace(content, "</Relationships>", customRel+"</Relationships>", 1)
+		case "xl/worksheets/_rels/sheet1.xml.rels":
+			content = strings.Replace(content, `"/>`, `">`+sheetRel+"</Relationships>", 1)
+		case "[Content_Types].xml":
+			content = strings.Replace(content, "</Types>", customCT+"</Types>", 1)
+		}
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	for name, content := range map[string]string{
+		"xl/custom/data.xml":  `<data xmlns="http://example.com/custom"><item>1</item></data>`,
+		"xl/custom/sheet.bin": "\x00\x01\x02",
+	} {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(modified.Bytes()), int64(modified.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if len(wb2.ExtraFiles) != 2 {
+		t.Errorf("expected 2 extra files, got %d", len(wb2.ExtraFiles))
+	}
+	out := bytes.Buffer{}
+	if err := wb2.Save(&out); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+
+	files := zipContents(t, out.Bytes())
+	if got := files["xl/custom/data.xml"]; got != `<data xmlns="http://example.com/custom"><item>1</item></data>` {
+		t.Errorf("expected custom part to be preserved verbatim, got %q", got)
+	}
+	if got := files["xl/custom/sheet.bin"]; got != "\x00\x01\x02" {
+		t.Errorf("expected binary part to be preserved verbatim, got %q", got)
+	}
+	for fn, exp := range map[string]string{
+		"xl/_rels/workbook.xml.rels":          `Target="custom/data.xml" Type="http://example.com/custom" Id="rId99"`,
+		"xl/worksheets/_rels/sheet1.xml.rels": `Target="../custom/sheet.bin" Type="http://example.com/sheet" Id="rId99"`,
+		"[Content_Types].xml":                 `ContentType="application/x-custom+xml" PartName="/xl/custom/data.xml"`,
+	} {
+		if !strings.Contains(files[fn], exp) {
+			t.Errorf("expected %s to contain %s, got %s", fn, exp, files[fn])
+		}
+	}
+}
+
+func TestPreserveRelationshipsOfRenamedParts(t *testing.T) {
+	wb := spreadsheet.New()
+	wb.AddSheet().AddChart()
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+
+	// the chart is read from chart7.xml but written to chart1.xml, and the
+	// relationships of charts aren't otherwise read
+	const styleRel = `<Relationship Id="rId1" Type="http://schemas.microsoft.com/office/2011/relationships/chartStyle" Target="style7.xml"/>`
+	const style = `<cs:chartStyle xmlns:cs="http://schemas.microsoft.com/office/drawing/2012/chartStyle" id="201"/>`
+	modified := bytes.Buffer{}
+	zw := zip.NewWriter(&modified)
+	for name, content := range zipContents(t, buf.Bytes()) {
+		name = strings.Replace(name, "chart1.xml", "chart7.xml", 1)
+		content = strings.Replace(content, "chart1.xml", "chart7.xml", -1)
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	for name, content := range map[string]string{
+		"xl/charts/_rels/chart7.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + styleRel + `</Relationships>`,
+		"xl/charts/style7.xml":            style,
+	} {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(modified.Bytes()), int64(modified.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	out := bytes.Buffer{}
+	if err := wb2.Save(&out); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+
+	files := zipContents(t, out.Bytes())
+	if _, ok := files["xl/charts/chart1.xml"]; !ok {
+		t.Fatalf("expected the chart to be written to chart1.xml")
+	}
+	if got := files["xl/charts/_rels/chart1.xml.rels"]; !strings.Contains(got, styleRel) {
+		t.Errorf("expected the chart relationships to move with the chart, got %q", got)
+	}
+	if _, ok := files["xl/charts/_rels/chart7.xml.rels"]; ok {
+		t.Errorf("expected no relationships for the old chart name")
+	}
+	if got := files["xl/charts/style7.xml"]; got != style {
+		t.Errorf("expected the chart style to be preserved verbatim, got %q", got)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	wb := spreadsheet.New()
+	wb.AddSheet()
+	thumb := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	wb.SetThumbnail(thumb)
+	// setting it again must not duplicate the relationship
+	wb.SetThumbnail(thumb)
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	files := zipContents(t, buf.Bytes())
+	if _, ok := files["docProps/thumbnail.jpeg"]; !ok {
+		t.Fatalf("expected the thumbnail part to be written")
+	}
+	rels := files["_rels/.rels"]
+	exp := `Target="docProps/thumbnail.jpeg" Type="` + unioffice.ThumbnailType + `"`
+	if strings.Count(rels, exp) != 1 {
+		t.Errorf("expected a single thumbnail relationship, got %s", rels)
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading workbook: %s", err)
+	}
+	if wb2.Thumbnail == nil || wb2.Thumbnail.Bounds().Dx() != 32 {
+		t.Errorf("expected to read back the thumbnail")
+	}
+
+	wb2.SetThumbnail(nil)
+	buf.Reset()
+	if err := wb2.Save(&buf); err != nil {
+		t.Fatalf("error saving workbook: %s", err)
+	}
+	files = zipContents(t, buf.Bytes())
+	if _, ok := files["docProps/thumbnail.jpeg"]; ok {
+		t.Errorf("expected the thumbnail part to be removed")
+	}
+	if strings.Contains(files["_rels/.rels"], "thumbnail") {
+		t.Errorf("expected the thumbnail relationship to be removed")
+	}
+}
+
+func TestCalculationChain(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.SetName("Data")
+	sheet.Cell("A1").SetNumber(1)
+	sheet.Cell("A2").SetFormulaRaw("A1*2")
+	sheet.Cell("A3").SetFormulaRaw("A2+1")
+	other := wb.AddSheet()
+	other.SetName("Other Sheet")
+	other.Cell("B1").SetFormulaRaw("Data!A3")
+
+	chain := []reference.CellReference{}
+	for _, ref := range []string{"Data!A2", "Data!A3", "'Other Sheet'!B1"} {
+		cref, err := reference.ParseCellReference(ref)
+		if err != nil {
+			t.Fatalf("error parsing %s: %s", ref, err)
+		}
+		cref.SheetName = strings.Trim(cref.SheetName, "'")
+		chain = append(chain, cref)
+	}
+	if err := wb.SetCalculationChain(chain); err != nil {
+		t.Fatalf("error setting calculation chain: %s", err)
+	}
+
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	files := zipContents(t, buf.Bytes())
+	if !strings.Contains(files["xl/calcChain.xml"], `<ma:c r="A2" i="1"/><ma:c r="A3"/><ma:c r="B1" i="2"/>`) {
+		t.Errorf("unexpected calculation chain: %s", files["xl/calcChain.xml"])
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading: %s", err)
+	}
+	got := wb2.CalculationChain()
+	if len(got) != 3 {
+		t.Fatalf("expected 3 cells in the chain, got %d", len(got))
+	}
+	for i, exp := range []string{"Data!A2", "Data!A3", "Other Sheet!B1"} {
+		if s := fmt.Sprintf("%s!%s", got[i].SheetName, got[i]); s != exp {
+			t.Errorf("expected %s, got %s", exp, s)
+		}
+	}
+
+	if err := wb.SetCalculationChain([]reference.CellReference{{SheetName: "Missing"}}); err == nil {
+		t.Errorf("expected an error for an unknown sheet")
+	}
+}
+
+func TestCalculationChainMismatchRemoved(t *testing.T) {
+	wb := spreadsheet.New()
+	sheet := wb.AddSheet()
+	sheet.Cell("A1").SetFormulaRaw("1+1")
+	a1, _ := reference.ParseCellReference("A1")
+	a1.SheetName = sheet.Name()
+	if err := wb.SetCalculationChain([]reference.CellReference{a1}); err != nil {
+		t.Fatalf("error setting calculation chain: %s", err)
+	}
+
+	// a formula without a chain entry
+	sheet.Cell("A2").SetFormulaRaw("A1+1")
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	files := zipContents(t, buf.Bytes())
+	if _, ok := files["xl/calcChain.xml"]; ok {
+		t.Errorf("expected the mismatched calculation chain to be removed")
+	}
+	for _, fn := range []string{"[Content_Types].xml", "xl/_rels/workbook.xml.rels"} {
+		if strings.Contains(files[fn], "calcChain") {
+			t.Errorf("expected no reference to the calculation chain in %s", fn)
+		}
+	}
+	if wb.CalculationChain() != nil {
+		t.Errorf("expected no calculation chain")
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+}
+
+func TestEditedFormulaRemovesCalculationChain(t *testing.T) {
+	wb, err := spreadsheet.Open("testdata/calcchain.xlsx")
+	if err != nil {
+		t.Fatalf("error opening workbook: %s", err)
+	}
+	if len(wb.CalculationChain()) != 2 {
+		t.Fatalf("expected 2 cells in the chain, got %d", len(wb.CalculationChain()))
+	}
+
+	// saving without changes keeps the chain
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	if _, ok := zipContents(t, buf.Bytes())["xl/calcChain.xml"]; !ok {
+		t.Errorf("expected the calculation chain to be kept")
+	}
+
+	// editing a formula leaves every formula cell in the chain, but the chain
+	// is still stale
+	wb.Sheets()[0].Cell("A3").SetFormula("A2+10")
+	buf.Reset()
+	if err := wb.Save(&buf); err != nil {
+		t.Fatalf("error saving: %s", err)
+	}
+	files := zipContents(t, buf.Bytes())
+	if _, ok := files["xl/calcChain.xml"]; ok {
+		t.Errorf("expected the calculation chain to be removed")
+	}
+	for _, fn := range []string{"[Content_Types].xml", "xl/_rels/workbook.xml.rels"} {
+		if strings.Contains(files[fn], "calcChain") {
+			t.Errorf("expected no reference to the calculation chain in %s", fn)
+		}
+	}
+
+	wb2, err := spreadsheet.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading: %s", err)
+	}
+	if err := wb2.Validate(); err != nil {
+		t.Errorf("expected a valid workbook, got %s", err)
+	}
+	if f := wb2.Sheets()[0].Cell("A3").GetFormula(); f != "A2+10" {
+		t.Errorf("expected formula A2+10, got %s", f)
+	}
+	if wb2.CalculationChain() != nil {
+		t.Errorf("expected no calculation chain after reopening")
+	}
+}
@@ -37,6 +37,9 @@ type DecodeMap struct {
 	decodeFunc  OnNewRelationshipFunc
 	decoded     map[string]struct{}
 	indices     map[string]int
+	// renamed maps the paths of decoded parts to the paths they will be
+	// written to, where they differ
+	renamed map[string]string
 }
 
 // SetOnNewRelationshipFunc sets the function to be called when a new
@@ -96,7 +99,9 @@ func (d *DecodeMap) Decode(files []*zip.File) error {
 			relRaw := relSource.Ifc.(*relationships.Relationships)
 			for _, r := range relRaw.Relationship {
 				bp, _ := d.basePaths[relRaw]
-				d.decodeFunc(d, bp+r.TargetAttr, r.TypeAttr, files, r, relSource)
+				target := r.TargetAttr
+				d.decodeFunc(d, bp+target, r.TypeAttr, files, r, relSource)
+				d.recordRename(bp+target, bp+r.TargetAttr, r)
 			}
 		}
 
@@ -132,5 +137,49 @@ func (d *DecodeMap) Decode(files []*zip.File) error {
 		}
 		pass--
 	}
+	d.moveRelationships(files)
 	return nil
 }
+
+// recordRename records that a part that has been registered as a target is
+// written to a different path than it was read from.
+func (d *DecodeMap) recordRename(from, to string, r *relationships.Relationship) {
+	if r.TargetModeAttr == relationships.ST_TargetModeExternal {
+		return
+	}
+	from, to = path.Clean(from), path.Clean(to)
+	if from == to {
+		return
+	}
+	if _, ok := d.decoded[from]; !ok {
+		return
+	}
+	if d.renamed == nil {
+		d.renamed = map[string]string{}
+	}
+	d.renamed[from] = to
+}
+
+// moveRelationships renames the relationships files of renamed parts that
+// weren't decoded, so that the relationships that aren't understood, and the
+// parts they refer to, remain attached to the part when the files are
+// preserved as is.
+func (d *DecodeMap) moveRelationships(files []*zip.File) {
+	if len(d.renamed) == 0 {
+		return
+	}
+	relsPaths := map[string]string{}
+	for from, to := range d.renamed {
+		relsPaths[RelationsPathFor(from)] = RelationsPathFor(to)
+	}
+	for i, f := range files {
+		if f == nil {
+			continue
+		}
+		if to, ok := relsPaths[f.Name]; ok {
+			cp := *f
+			cp.Name = to
+			files[i] = &cp
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2017 FoxyUtils ehf. All rights reserved.
+//
+// Use of this source code is governed by the terms of the Affero GNU General
+// Public License version 3.0 as published by the Free Software Foundation and
+// appearing in the file LICENSE included in the packaging of this file. A
+// commercial license can be purchased on https://unidoc.io.
+
+package zippkg
+
+import (
+	"archive/zip"
+	"fmt"
+)
+
+// ReadOptions controls how an OOXML package is read.
+type ReadOptions struct {
+	// MaxDecompressedSize is the maximum total uncompressed size in bytes of
+	// the files in the package.  Packages that exceed it are rejected before
+	// any of their content is decompressed.  Zero means no limit.
+	MaxDecompressedSize int64
+}
+
+// CheckSize returns an error if the total uncompressed size of files exceeds
+// the limit in opts.  The sizes checked are those recorded in the zip headers,
+// which archive/zip enforces when the files are read.
+func (o ReadOptions) CheckSize(files []*zip.File) error {
+	if o.MaxDecompressedSize <= 0 {
+		return nil
+	}
+	total := uint64(0)
+	limit := uint64(o.MaxDecompressedSize)
+	for _, f := range files {
+		total += f.UncompressedSize64
+		if f.UncompressedSize64 > limit || total > limit {
+			return fmt.Errorf("decompressed size exceeds limit of %d bytes at %s", o.MaxDecompressedSize, f.Name)
+		}
+	}
+	return nil
+}